@@ -0,0 +1,319 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// AnomalyZScore scores the latest point by the number of standard deviations it is away from the mean of the trailing window.
+	AnomalyZScore = "zscore"
+	// AnomalyMAD scores the latest point by the modified z-score that uses the median and the median absolute deviation of the trailing window.
+	AnomalyMAD = "mad"
+	// AnomalySeasonal scores the latest point by the number of standard deviations it is away from the mean of the points
+	// at the same phase of the previous periods within the window.
+	AnomalySeasonal = "seasonal"
+
+	// AnomalyOutputScore makes the command return the anomaly score.
+	AnomalyOutputScore = "score"
+	// AnomalyOutputFlag makes the command return 1 if the absolute value of the anomaly score is greater than the threshold, and 0 otherwise.
+	AnomalyOutputFlag = "flag"
+)
+
+var (
+	supportedAnomalyAlgorithms = []string{AnomalyZScore, AnomalyMAD, AnomalySeasonal}
+	supportedAnomalyOutputs    = []string{AnomalyOutputScore, AnomalyOutputFlag}
+)
+
+// madScale makes the median absolute deviation of a normal distribution comparable to its standard deviation.
+const madScale = 0.6745
+
+// AnomalyCommand is an expression command that reduces every series to an anomaly score of its latest point.
+type AnomalyCommand struct {
+	VarToScore string
+	Algorithm  string
+	Window     time.Duration
+	Period     time.Duration
+	Output     string
+	Threshold  float64
+	refID      string
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand.
+func NewAnomalyCommand(refID, varToScore, algorithm string, window, period time.Duration, output string, threshold float64) (*AnomalyCommand, error) {
+	if !isSupportedAnomaly(supportedAnomalyAlgorithms, algorithm) {
+		return nil, fmt.Errorf("expected anomaly algorithm to be one of %s, got %s", strings.Join(supportedAnomalyAlgorithms, ", "), algorithm)
+	}
+	if output == "" {
+		output = AnomalyOutputScore
+	}
+	if !isSupportedAnomaly(supportedAnomalyOutputs, output) {
+		return nil, fmt.Errorf("expected anomaly output to be one of %s, got %s", strings.Join(supportedAnomalyOutputs, ", "), output)
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("anomaly window must be a positive duration, got %v", window)
+	}
+	if algorithm == AnomalySeasonal {
+		if period <= 0 {
+			return nil, fmt.Errorf("anomaly period must be a positive duration, got %v", period)
+		}
+		if window < 2*period {
+			return nil, fmt.Errorf("anomaly window must be at least two periods for the seasonal algorithm, got window %v and period %v", window, period)
+		}
+	}
+	if output == AnomalyOutputFlag && threshold <= 0 {
+		return nil, fmt.Errorf("anomaly threshold must be a positive number when output is %s", AnomalyOutputFlag)
+	}
+	return &AnomalyCommand{
+		VarToScore: varToScore,
+		Algorithm:  algorithm,
+		Window:     window,
+		Period:     period,
+		Output:     output,
+		Threshold:  threshold,
+		refID:      refID,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+	varToScore, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected anomaly variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	varToScore = strings.TrimPrefix(varToScore, "$")
+
+	algorithm, err := getAnomalyString(rn, "algorithm", true)
+	if err != nil {
+		return nil, err
+	}
+
+	rawWindow, err := getAnomalyString(rn, "window", true)
+	if err != nil {
+		return nil, err
+	}
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse anomaly "window" duration field %q: %w`, rawWindow, err)
+	}
+
+	var period time.Duration
+	rawPeriod, err := getAnomalyString(rn, "period", algorithm == AnomalySeasonal)
+	if err != nil {
+		return nil, err
+	}
+	if rawPeriod != "" {
+		period, err = gtime.ParseDuration(rawPeriod)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "period" duration field %q: %w`, rawPeriod, err)
+		}
+	}
+
+	output, err := getAnomalyString(rn, "output", false)
+	if err != nil {
+		return nil, err
+	}
+
+	var threshold float64
+	if rawThreshold, ok := rn.Query["threshold"]; ok {
+		threshold, ok = rawThreshold.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected anomaly threshold to be a number, got %T for refId %v", rawThreshold, rn.RefID)
+		}
+	}
+
+	return NewAnomalyCommand(rn.RefID, varToScore, algorithm, window, period, output, threshold)
+}
+
+func getAnomalyString(rn *rawNode, field string, required bool) (string, error) {
+	raw, ok := rn.Query[field]
+	if !ok {
+		if required {
+			return "", fmt.Errorf("no %s specified in anomaly command for refId %v", field, rn.RefID)
+		}
+		return "", nil
+	}
+	s, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("expected anomaly %s to be a string, got %T for refId %v", field, raw, rn.RefID)
+	}
+	return s, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.VarToScore}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute. Every series is reduced to a number that is the anomaly score of its latest point,
+// or the anomaly flag if the output is AnomalyOutputFlag.
+func (ac *AnomalyCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.VarToScore].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			n := mathexp.NewNumber(ac.refID, v.GetLabels())
+			score := ac.score(v)
+			if ac.Output == AnomalyOutputFlag && !math.IsNaN(score) {
+				flag := 0.0
+				if math.Abs(score) > ac.Threshold {
+					flag = 1
+				}
+				score = flag
+			}
+			n.SetValue(&score)
+			newRes.Values = append(newRes.Values, n)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// score returns the anomaly score of the latest point of the series. Points with null or NaN values are ignored when
+// the baseline is computed. The score is NaN if the latest point is null or NaN or the baseline is empty.
+func (ac *AnomalyCommand) score(s mathexp.Series) float64 {
+	if s.Len() == 0 {
+		return math.NaN()
+	}
+	latest := 0
+	for i := 1; i < s.Len(); i++ {
+		if s.GetTime(i).After(s.GetTime(latest)) {
+			latest = i
+		}
+	}
+	lt, lv := s.GetPoint(latest)
+	if lv == nil || math.IsNaN(*lv) {
+		return math.NaN()
+	}
+	x := *lv
+
+	switch ac.Algorithm {
+	case AnomalySeasonal:
+		baseline := seasonalBaseline(s, lt, ac.Window, ac.Period)
+		if len(baseline) < 2 {
+			return math.NaN()
+		}
+		mean, stddev := meanStddev(baseline)
+		return deviation(x-mean, stddev)
+	case AnomalyMAD:
+		baseline := trailingBaseline(s, lt, ac.Window)
+		if len(baseline) == 0 {
+			return math.NaN()
+		}
+		center := median(baseline)
+		deviations := make([]float64, 0, len(baseline))
+		for _, v := range baseline {
+			deviations = append(deviations, math.Abs(v-center))
+		}
+		return deviation(madScale*(x-center), median(deviations))
+	default:
+		baseline := trailingBaseline(s, lt, ac.Window)
+		if len(baseline) == 0 {
+			return math.NaN()
+		}
+		mean, stddev := meanStddev(baseline)
+		return deviation(x-mean, stddev)
+	}
+}
+
+// trailingBaseline returns the valid values of the points within the window that ends before the time of the latest point.
+func trailingBaseline(s mathexp.Series, latest time.Time, window time.Duration) []float64 {
+	start := latest.Add(-window)
+	values := make([]float64, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		if !t.Before(latest) || t.Before(start) || v == nil || math.IsNaN(*v) {
+			continue
+		}
+		values = append(values, *v)
+	}
+	return values
+}
+
+// seasonalBaseline returns, for every period within the window, the valid value of the latest point that is not after the
+// time of the latest point minus the number of periods and is within a period of that time.
+func seasonalBaseline(s mathexp.Series, latest time.Time, window, period time.Duration) []float64 {
+	seasons := int(window / period)
+	values := make([]float64, 0, seasons)
+	for k := 1; k <= seasons; k++ {
+		target := latest.Add(-time.Duration(k) * period)
+		found := -1
+		for i := 0; i < s.Len(); i++ {
+			t := s.GetTime(i)
+			if t.After(target) || !t.After(target.Add(-period)) {
+				continue
+			}
+			if found == -1 || t.After(s.GetTime(found)) {
+				found = i
+			}
+		}
+		if found == -1 {
+			continue
+		}
+		if v := s.GetValue(found); v != nil && !math.IsNaN(*v) {
+			values = append(values, *v)
+		}
+	}
+	return values
+}
+
+func meanStddev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// deviation divides diff by the spread. If the spread is zero, the score is 0 if there is no difference and infinity otherwise.
+func deviation(diff, spread float64) float64 {
+	if spread == 0 {
+		if diff == 0 {
+			return 0
+		}
+		return math.Inf(int(math.Copysign(1, diff)))
+	}
+	return diff / spread
+}
+
+func isSupportedAnomaly(supported []string, name string) bool {
+	for _, s := range supported {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	type testCase struct {
+		description   string
+		query         string
+		expected      *AnomalyCommand
+		expectedError string
+	}
+
+	cases := []testCase{
+		{
+			description: "unmarshal z-score with defaults",
+			query: `{
+				"expression": "$A",
+				"type": "anomaly",
+				"algorithm": "zscore",
+				"window": "1h"
+			}`,
+			expected: &AnomalyCommand{VarToScore: "A", Algorithm: AnomalyZScore, Window: time.Hour, Output: AnomalyOutputScore, refID: "B"},
+		},
+		{
+			description: "unmarshal seasonal flag",
+			query: `{
+				"expression": "A",
+				"type": "anomaly",
+				"algorithm": "seasonal",
+				"window": "7d",
+				"period": "1d",
+				"output": "flag",
+				"threshold": 3
+			}`,
+			expected: &AnomalyCommand{VarToScore: "A", Algorithm: AnomalySeasonal, Window: 7 * 24 * time.Hour, Period: 24 * time.Hour, Output: AnomalyOutputFlag, Threshold: 3, refID: "B"},
+		},
+		{
+			description: "unmarshal with unsupported algorithm should error",
+			query: `{
+				"expression": "A",
+				"algorithm": "foo",
+				"window": "1h"
+			}`,
+			expectedError: "expected anomaly algorithm to be one of",
+		},
+		{
+			description: "unmarshal with missing window should error",
+			query: `{
+				"expression": "A",
+				"algorithm": "mad"
+			}`,
+			expectedError: "no window specified",
+		},
+		{
+			description: "unmarshal seasonal without period should error",
+			query: `{
+				"expression": "A",
+				"algorithm": "seasonal",
+				"window": "7d"
+			}`,
+			expectedError: "no period specified",
+		},
+		{
+			description: "unmarshal seasonal with window shorter than two periods should error",
+			query: `{
+				"expression": "A",
+				"algorithm": "seasonal",
+				"window": "1d",
+				"period": "1d"
+			}`,
+			expectedError: "at least two periods",
+		},
+		{
+			description: "unmarshal flag without threshold should error",
+			query: `{
+				"expression": "A",
+				"algorithm": "zscore",
+				"window": "1h",
+				"output": "flag"
+			}`,
+			expectedError: "threshold must be a positive number",
+		},
+		{
+			description: "unmarshal with bad expression should error",
+			query: `{
+				"expression": 0,
+				"algorithm": "zscore",
+				"window": "1h"
+			}`,
+			expectedError: "expected anomaly variable to be a string",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			var qmap = make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &qmap))
+
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{
+				RefID: "B",
+				Query: qmap,
+			})
+
+			if tc.expectedError != "" {
+				require.Nil(t, cmd)
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cmd)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		})
+	}
+}
+
+func TestAnomalyCommandExecute(t *testing.T) {
+	start := time.Unix(0, 0)
+	series := func(step time.Duration, values ...*float64) mathexp.Series {
+		s := mathexp.NewSeries("A", data.Labels{"host": "a"}, len(values))
+		for i, v := range values {
+			s.SetPoint(i, start.Add(time.Duration(i)*step), v)
+		}
+		return s
+	}
+	f := func(v float64) *float64 { return &v }
+
+	type testCase struct {
+		description string
+		cmd         func() (*AnomalyCommand, error)
+		input       mathexp.Value
+		expected    float64
+	}
+
+	cases := []testCase{
+		{
+			description: "z-score of the latest point against the trailing window",
+			cmd: func() (*AnomalyCommand, error) {
+				return NewAnomalyCommand("B", "A", AnomalyZScore, 2*time.Minute, 0, "", 0)
+			},
+			input:    series(10*time.Second, f(2), f(4), f(4), f(4), f(5), f(5), f(7), f(9), f(15)),
+			expected: 5,
+		},
+		{
+			description: "z-score ignores null points in the window",
+			cmd: func() (*AnomalyCommand, error) {
+				return NewAnomalyCommand("B", "A", AnomalyZScore, 2*time.Minute, 0, "", 0)
+			},
+			input:    series(10*time.Second, f(2), f(4), nil, f(4), f(4), f(5), f(5), f(7), f(9), f(15)),
+			expected: 5,
+		},
+		{
+			description: "mad of the latest point against the trailing window",
+			cmd: func() (*AnomalyCommand, error) {
+				return NewAnomalyCommand("B", "A", AnomalyMAD, time.Minute, 0, "", 0)
+			},
+			input:    series(10*time.Second, f(1), f(2), f(3), f(4), f(5), f(11)),
+			expected: madScale * 8 / 1,
+		},
+		{
+			description: "seasonal compares the latest point to the same phase of previous periods",
+			cmd: func() (*AnomalyCommand, error) {
+				return NewAnomalyCommand("B", "A", AnomalySeasonal, 3*time.Minute, time.Minute, "", 0)
+			},
+			// period of two points, the latest point has the phase of 8, 10, 12
+			input:    series(30*time.Second, f(8), f(100), f(10), f(100), f(12), f(100), f(16)),
+			expected: (16 - 10) / math.Sqrt(8.0/3),
+		},
+		{
+			description: "constant baseline gives zero for the same value",
+			cmd: func() (*AnomalyCommand, error) {
+				return NewAnomalyCommand("B", "A", AnomalyZScore, time.Minute, 0, "", 0)
+			},
+			input:    series(10*time.Second, f(1), f(1), f(1)),
+			expected: 0,
+		},
+		{
+			description: "constant baseline gives infinity for a different value",
+			cmd: func() (*AnomalyCommand, error) {
+				return NewAnomalyCommand("B", "A", AnomalyZScore, time.Minute, 0, "", 0)
+			},
+			input:    series(10*time.Second, f(1), f(1), f(0)),
+			expected: math.Inf(-1),
+		},
+		{
+			description: "flag is 1 if the score is above the threshold",
+			cmd: func() (*AnomalyCommand, error) {
+				return NewAnomalyCommand("B", "A", AnomalyZScore, 2*time.Minute, 0, AnomalyOutputFlag, 3)
+			},
+			input:    series(10*time.Second, f(2), f(4), f(4), f(4), f(5), f(5), f(7), f(9), f(15)),
+			expected: 1,
+		},
+		{
+			description: "flag is 0 if the score is below the threshold",
+			cmd: func() (*AnomalyCommand, error) {
+				return NewAnomalyCommand("B", "A", AnomalyZScore, 2*time.Minute, 0, AnomalyOutputFlag, 6)
+			},
+			input:    series(10*time.Second, f(2), f(4), f(4), f(4), f(5), f(5), f(7), f(9), f(15)),
+			expected: 0,
+		},
+		{
+			description: "null latest point gives NaN",
+			cmd: func() (*AnomalyCommand, error) {
+				return NewAnomalyCommand("B", "A", AnomalyZScore, time.Minute, 0, AnomalyOutputFlag, 3)
+			},
+			input:    series(10*time.Second, f(1), f(2), nil),
+			expected: math.NaN(),
+		},
+		{
+			description: "empty window gives NaN",
+			cmd: func() (*AnomalyCommand, error) {
+				return NewAnomalyCommand("B", "A", AnomalyMAD, time.Second, 0, "", 0)
+			},
+			input:    series(10*time.Second, f(1), f(2)),
+			expected: math.NaN(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			cmd, err := tc.cmd()
+			require.NoError(t, err)
+
+			res, err := cmd.Execute(context.Background(), start, mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{tc.input}}})
+			require.NoError(t, err)
+			require.Len(t, res.Values, 1)
+
+			n, ok := res.Values[0].(mathexp.Number)
+			require.True(t, ok)
+			require.Equal(t, data.Labels{"host": "a"}, n.GetLabels())
+			actual := n.GetFloat64Value()
+			require.NotNil(t, actual)
+			if math.IsNaN(tc.expected) {
+				require.True(t, math.IsNaN(*actual))
+				return
+			}
+			if math.IsInf(tc.expected, 0) {
+				require.Equal(t, tc.expected, *actual)
+				return
+			}
+			require.InDelta(t, tc.expected, *actual, 1e-9)
+		})
+	}
+
+	t.Run("no data is passed through", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyZScore, time.Minute, 0, "", 0)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), start, mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{mathexp.NoData{}.New()}}})
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, mathexp.NoData{}.New(), res.Values[0])
+	})
+
+	t.Run("numbers are not supported", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyZScore, time.Minute, 0, "", 0)
+		require.NoError(t, err)
+		_, err = cmd.Execute(context.Background(), start, mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}}})
+		require.ErrorContains(t, err, "can only detect anomalies in type series")
+	})
+}
@@ -45,6 +45,9 @@ type condition struct {
 	// min and max functions.
 	Reducer reducer
 
+	// ReducerMapper is the null handling mode of the reducer. If it is nil, null and NaN values are ignored.
+	ReducerMapper mathexp.ReduceMapper
+
 	// Evaluator evaluates the reduced time series, instant metric, or result of another expression
 	// against an evaluator. An example of an evaluator is checking if it exceeds a threshold,
 	// falls within a range, or does not contain a value.
@@ -174,7 +177,7 @@ func (cmd *ConditionsCmd) executeCond(_ context.Context, _ time.Time, cond condi
 			number = v
 		case mathexp.Series:
 			name = v.GetName()
-			number = cond.Reducer.Reduce(v)
+			number = cond.Reducer.ReduceWithMapper(v, cond.ReducerMapper)
 		default:
 			return false, false, nil, fmt.Errorf("can only reduce type series, got type %v", v.Type())
 		}
@@ -270,6 +273,14 @@ type ConditionQueryJSON struct {
 type ConditionReducerJSON struct {
 	Type string `json:"type"`
 	// Params []interface{} `json:"params"` (Unused)
+	Settings *ConditionReducerSettingsJSON `json:"settings,omitempty"`
+}
+
+// ConditionReducerSettingsJSON is the JSON model for the null handling mode of the reducer.
+// It is the same as the settings of the reduce expression.
+type ConditionReducerSettingsJSON struct {
+	Mode             string   `json:"mode"`
+	ReplaceWithValue *float64 `json:"replaceWithValue,omitempty"`
 }
 
 // UnmarshalConditionsCmd creates a new ConditionsCmd.
@@ -305,6 +316,12 @@ func UnmarshalConditionsCmd(rawQuery map[string]interface{}, refID string) (*Con
 		if !cond.Reducer.ValidReduceFunc() {
 			return nil, fmt.Errorf("invalid reducer '%v' in condition %v", cond.Reducer, i+1)
 		}
+		if cj.Reducer.Settings != nil {
+			cond.ReducerMapper, err = mathexp.GetReduceMapper(cj.Reducer.Settings.Mode, cj.Reducer.Settings.ReplaceWithValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid reducer settings in condition %v: %w", i+1, err)
+			}
+		}
 
 		cond.Evaluator, err = newAlertEvaluator(cj.Evaluator)
 		if err != nil {
@@ -691,6 +691,50 @@ func TestUnmarshalConditionsCmd(t *testing.T) {
 			},
 			needsVars: []string{"A"},
 		},
+		{
+			name: "percentile condition with null handling mode",
+			rawJSON: `{
+				"conditions": [
+				  {
+					"evaluator": {
+					  "params": [
+						2
+					  ],
+					  "type": "gt"
+					},
+					"operator": {
+					  "type": "and"
+					},
+					"query": {
+					  "params": [
+						"A"
+					  ]
+					},
+					"reducer": {
+					  "params": [],
+					  "type": "p95",
+					  "settings": {
+						"mode": "replaceNN",
+						"replaceWithValue": 0
+					  }
+					},
+					"type": "query"
+				  }
+				]
+			}`,
+			expectedCommand: &ConditionsCmd{
+				Conditions: []condition{
+					{
+						InputRefID:    "A",
+						Reducer:       reducer("p95"),
+						ReducerMapper: mathexp.ReplaceNonNumberWithValue{Value: 0},
+						Operator:      "and",
+						Evaluator:     &thresholdEvaluator{Type: "gt", Threshold: 2},
+					},
+				},
+			},
+			needsVars: []string{"A"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
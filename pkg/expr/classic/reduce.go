@@ -2,25 +2,34 @@ package classic
 
 import (
 	"math"
-	"sort"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
 type reducer string
 
-func (cr reducer) ValidReduceFunc() bool {
-	switch cr {
-	case "avg", "sum", "min", "max", "count", "last", "median":
-		return true
-	case "diff", "diff_abs", "percent_diff", "percent_diff_abs", "count_non_null":
-		return true
+// name returns the name of the reducer in the registry of mathexp reducers.
+func (cr reducer) name() string {
+	if cr == "avg" {
+		return "mean"
 	}
-	return false
+	return string(cr)
+}
+
+func (cr reducer) ValidReduceFunc() bool {
+	_, err := mathexp.GetReduceFunc(cr.name())
+	return err == nil
 }
 
-//nolint:gocyclo
+// Reduce reduces the series using the null handling of classic conditions: null and NaN values are ignored,
+// and the result is null if the series has no other values. The only exception is count that counts all values.
 func (cr reducer) Reduce(series mathexp.Series) mathexp.Number {
+	return cr.ReduceWithMapper(series, nil)
+}
+
+// ReduceWithMapper reduces the series using the null handling mode of the mapper.
+// If the mapper is nil, it behaves the same way as Reduce.
+func (cr reducer) ReduceWithMapper(series mathexp.Series, mapper mathexp.ReduceMapper) mathexp.Number {
 	num := mathexp.NewNumber("", nil)
 
 	if series.GetLabels() != nil {
@@ -33,171 +42,47 @@ func (cr reducer) Reduce(series mathexp.Series) mathexp.Number {
 		return num
 	}
 
-	value := float64(0)
-	allNull := true
-
-	vF := series.Frame.Fields[1]
-	ff := mathexp.Float64Field(*vF)
-
-	switch cr {
-	case "avg":
-		validPointsCount := 0
-		for i := 0; i < ff.Len(); i++ {
-			f := ff.GetValue(i)
-			if nilOrNaN(f) {
-				continue
-			}
-			value += *f
-			validPointsCount++
-			allNull = false
-		}
-		if validPointsCount > 0 {
-			value /= float64(validPointsCount)
-		}
-	case "sum":
-		for i := 0; i < ff.Len(); i++ {
-			f := ff.GetValue(i)
-			if nilOrNaN(f) {
-				continue
-			}
-			value += *f
-			allNull = false
-		}
-	case "min":
-		value = math.MaxFloat64
-		for i := 0; i < ff.Len(); i++ {
-			f := ff.GetValue(i)
-			if nilOrNaN(f) {
-				continue
-			}
-			allNull = false
-			if value > *f {
-				value = *f
-			}
-		}
-		if allNull {
-			value = 0
-		}
-	case "max":
-		value = -math.MaxFloat64
-		for i := 0; i < ff.Len(); i++ {
-			f := ff.GetValue(i)
-			if nilOrNaN(f) {
-				continue
-			}
-			allNull = false
-			if value < *f {
-				value = *f
-			}
-		}
-		if allNull {
-			value = 0
-		}
-	case "count":
-		value = float64(ff.Len())
-		allNull = false
-	case "last":
-		for i := ff.Len() - 1; i >= 0; i-- {
-			f := ff.GetValue(i)
-			if !nilOrNaN(f) {
-				value = *f
-				allNull = false
-				break
-			}
-		}
-	case "median":
-		var values []float64
-		for i := 0; i < ff.Len(); i++ {
-			f := ff.GetValue(i)
-			if nilOrNaN(f) {
-				continue
-			}
-			allNull = false
-			values = append(values, *f)
-		}
-		if len(values) >= 1 {
-			sort.Float64s(values)
-			length := len(values)
-			if length%2 == 1 {
-				value = values[(length-1)/2]
-			} else {
-				value = (values[(length/2)-1] + values[length/2]) / 2
-			}
-		}
-	case "diff":
-		allNull, value = calculateDiff(ff, allNull, value, diff)
-	case "diff_abs":
-		allNull, value = calculateDiff(ff, allNull, value, diffAbs)
-	case "percent_diff":
-		allNull, value = calculateDiff(ff, allNull, value, percentDiff)
-	case "percent_diff_abs":
-		allNull, value = calculateDiff(ff, allNull, value, percentDiffAbs)
-	case "count_non_null":
-		for i := 0; i < ff.Len(); i++ {
-			f := ff.GetValue(i)
-			if nilOrNaN(f) {
-				continue
-			}
-			value++
-		}
-
-		if value > 0 {
-			allNull = false
+	if mapper == nil && cr != "count" {
+		if !hasNonNullValue(series) {
+			return num
 		}
+		mapper = dropNullAndNaN{}
 	}
 
-	if allNull {
+	reduced, err := series.Reduce("", cr.name(), mapper)
+	if err != nil {
 		return num
 	}
-
-	num.SetValue(&value)
+	num.SetValue(reduced.GetFloat64Value())
 	return num
 }
 
-func calculateDiff(ff mathexp.Float64Field, allNull bool, value float64, fn func(float64, float64) float64) (bool, float64) {
-	var (
-		first float64
-		i     int
-	)
-	// get the newest point
-	for i = ff.Len() - 1; i >= 0; i-- {
-		f := ff.GetValue(i)
-		if !nilOrNaN(f) {
-			first = *f
-			allNull = false
-			break
+func hasNonNullValue(series mathexp.Series) bool {
+	for i := 0; i < series.Len(); i++ {
+		if !nilOrNaN(series.GetValue(i)) {
+			return true
 		}
 	}
-	if i >= 1 {
-		// get the oldest point
-		for i := 0; i < ff.Len(); i++ {
-			f := ff.GetValue(i)
-			if !nilOrNaN(f) {
-				value = fn(first, *f)
-				allNull = false
-				break
-			}
-		}
-	}
-	return allNull, value
-}
-
-func nilOrNaN(f *float64) bool {
-	return f == nil || math.IsNaN(*f)
+	return false
 }
 
-var diff = func(newest, oldest float64) float64 {
-	return newest - oldest
-}
+// dropNullAndNaN is the ReduceMapper for the null handling of classic conditions. Unlike mathexp.DropNonNumber,
+// it keeps Inf values.
+type dropNullAndNaN struct{}
 
-var diffAbs = func(newest, oldest float64) float64 {
-	return math.Abs(newest - oldest)
+// MapInput returns nil if the input parameter is nil or points to a NaN.
+func (dropNullAndNaN) MapInput(v *float64) *float64 {
+	if nilOrNaN(v) {
+		return nil
+	}
+	return v
 }
 
-var percentDiff = func(newest, oldest float64) float64 {
-	return (newest - oldest) / math.Abs(oldest) * 100
+// MapOutput returns the input parameter as is.
+func (dropNullAndNaN) MapOutput(v *float64) *float64 {
+	return v
 }
 
-var percentDiffAbs = func(newest, oldest float64) float64 {
-	return math.Abs((newest - oldest) / oldest * 100)
+func nilOrNaN(f *float64) bool {
+	return f == nil || math.IsNaN(*f)
 }
@@ -115,6 +115,36 @@ func TestReducer(t *testing.T) {
 			inputSeries:    newSeries(nil, nil),
 			expectedNumber: newNumber(nil),
 		},
+		{
+			name:           "count should count null values",
+			reducer:        reducer("count"),
+			inputSeries:    newSeries(nil, nil, ptr.Float64(3)),
+			expectedNumber: newNumber(ptr.Float64(3)),
+		},
+		{
+			name:           "first should ignore null values",
+			reducer:        reducer("first"),
+			inputSeries:    newSeries(nil, ptr.Float64(2), ptr.Float64(3)),
+			expectedNumber: newNumber(ptr.Float64(2)),
+		},
+		{
+			name:           "stddev should ignore null values",
+			reducer:        reducer("stddev"),
+			inputSeries:    newSeries(ptr.Float64(1), nil, ptr.Float64(3)),
+			expectedNumber: newNumber(ptr.Float64(1)),
+		},
+		{
+			name:           "percentile",
+			reducer:        reducer("p50"),
+			inputSeries:    newSeries(ptr.Float64(1), ptr.Float64(math.NaN()), ptr.Float64(2), ptr.Float64(3)),
+			expectedNumber: newNumber(ptr.Float64(2)),
+		},
+		{
+			name:           "max should keep Inf values",
+			reducer:        reducer("max"),
+			inputSeries:    newSeries(ptr.Float64(1), ptr.Float64(math.Inf(1))),
+			expectedNumber: newNumber(ptr.Float64(math.Inf(1))),
+		},
 	}
 
 	for _, tt := range tests {
@@ -126,6 +156,21 @@ func TestReducer(t *testing.T) {
 	}
 }
 
+func TestReducerWithMapper(t *testing.T) {
+	t.Run("dropNN should drop Inf values", func(t *testing.T) {
+		num := reducer("max").ReduceWithMapper(newSeries(ptr.Float64(1), ptr.Float64(math.Inf(1)), nil), mathexp.DropNonNumber{})
+		require.Equal(t, newNumber(ptr.Float64(1)), num)
+	})
+	t.Run("replaceNN should replace null values", func(t *testing.T) {
+		num := reducer("avg").ReduceWithMapper(newSeries(ptr.Float64(4), nil), mathexp.ReplaceNonNumberWithValue{Value: 2})
+		require.Equal(t, newNumber(ptr.Float64(3)), num)
+	})
+	t.Run("replaceNN should count null values", func(t *testing.T) {
+		num := reducer("count_non_null").ReduceWithMapper(newSeries(nil, nil), mathexp.ReplaceNonNumberWithValue{Value: 0})
+		require.Equal(t, newNumber(ptr.Float64(2)), num)
+	})
+}
+
 func TestDiffReducer(t *testing.T) {
 	var tests = []struct {
 		name           string
@@ -121,22 +121,22 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 		case map[string]interface{}:
 			mode, ok := s["mode"]
 			if ok && mode != "" {
-				switch mode {
-				case "dropNN":
-					mapper = mathexp.DropNonNumber{}
-				case "replaceNN":
-					valueStr, ok := s["replaceWithValue"]
+				modeStr, ok := mode.(string)
+				if !ok {
+					return nil, fmt.Errorf("reducer mode '%v' is not supported. Supported only: [dropNN,replaceNN]", mode)
+				}
+				var replaceWithValue *float64
+				if rawValue, ok := s["replaceWithValue"]; ok && modeStr == mathexp.ReduceModeReplaceNN {
+					value, ok := rawValue.(float64)
 					if !ok {
-						return nil, errors.New("setting replaceWithValue must be specified when mode is 'replaceNN'")
-					}
-					switch value := valueStr.(type) {
-					case float64:
-						mapper = mathexp.ReplaceNonNumberWithValue{Value: value}
-					default:
-						return nil, fmt.Errorf("setting replaceWithValue must be a number, got %T", value)
+						return nil, fmt.Errorf("setting replaceWithValue must be a number, got %T", rawValue)
 					}
-				default:
-					return nil, fmt.Errorf("reducer mode '%s' is not supported. Supported only: [dropNN,replaceNN]", mode)
+					replaceWithValue = &value
+				}
+				var err error
+				mapper, err = mathexp.GetReduceMapper(modeStr, replaceWithValue)
+				if err != nil {
+					return nil, err
 				}
 			}
 		default:
@@ -302,6 +302,8 @@ const (
 	TypeClassicConditions
 	// TypeThreshold is the CMDType for checking if a threshold has been crossed
 	TypeThreshold
+	// TypeAnomaly is the CMDType for scoring the latest point of series against their recent history
+	TypeAnomaly
 )
 
 func (gt CommandType) String() string {
@@ -314,6 +316,10 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
+	case TypeAnomaly:
+		return "anomaly"
 	default:
 		return "unknown"
 	}
@@ -332,6 +338,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeClassicConditions, nil
 	case "threshold":
 		return TypeThreshold, nil
+	case "anomaly":
+		return TypeAnomaly, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
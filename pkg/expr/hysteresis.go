@@ -0,0 +1,180 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// Fingerprint is a hash of labels that identifies a dimension of the result of a query or expression.
+type Fingerprint uint64
+
+// FingerprintOf returns the Fingerprint of the labels. Nil and empty labels have the same fingerprint.
+func FingerprintOf(labels data.Labels) Fingerprint {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := fnv.New64a()
+	for _, k := range keys {
+		_, _ = h.Write([]byte(k))
+		_, _ = h.Write([]byte{0xff})
+		_, _ = h.Write([]byte(labels[k]))
+		_, _ = h.Write([]byte{0xff})
+	}
+	return Fingerprint(h.Sum64())
+}
+
+// String returns the hexadecimal representation of the fingerprint.
+func (f Fingerprint) String() string {
+	return strconv.FormatUint(uint64(f), 16)
+}
+
+// ParseFingerprint parses the hexadecimal representation of a fingerprint.
+func ParseFingerprint(s string) (Fingerprint, error) {
+	f, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid fingerprint %q: %w", s, err)
+	}
+	return Fingerprint(f), nil
+}
+
+// Fingerprints is a set of fingerprints.
+type Fingerprints map[Fingerprint]struct{}
+
+// loadedDimensionsField is the field of the threshold expression's query model that contains the fingerprints of
+// the dimensions that are firing.
+const loadedDimensionsField = "loadedDimensions"
+
+// HysteresisCommand is a threshold expression command with separate thresholds for firing and recovery.
+// Dimensions that are not firing (not loaded) are evaluated against the loading threshold, and dimensions that are
+// firing (loaded) keep firing until the unloading (recovery) threshold is met. This stops alerts from flapping when
+// the value is close to the threshold.
+type HysteresisCommand struct {
+	RefID                  string
+	ReferenceVar           string
+	LoadingThresholdFunc   ThresholdCommand
+	UnloadingThresholdFunc ThresholdCommand
+	LoadedDimensions       Fingerprints
+}
+
+// NewHysteresisCommand creates a new HysteresisCommand. The unloading threshold command describes the condition to
+// recover, and is therefore inverted when the command is executed.
+func NewHysteresisCommand(refID string, referenceVar string, loadCondition ThresholdCommand, unloadCondition ThresholdCommand, loadedDimensions Fingerprints) (*HysteresisCommand, error) {
+	if loadCondition.ReferenceVar != referenceVar || unloadCondition.ReferenceVar != referenceVar {
+		return nil, fmt.Errorf("loading and unloading conditions must reference %s", referenceVar)
+	}
+	unloadCondition.Invert = true
+	return &HysteresisCommand{
+		RefID:                  refID,
+		ReferenceVar:           referenceVar,
+		LoadingThresholdFunc:   loadCondition,
+		UnloadingThresholdFunc: unloadCondition,
+		LoadedDimensions:       loadedDimensions,
+	}, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (h *HysteresisCommand) NeedsVars() []string {
+	return []string{h.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (h *HysteresisCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	results := vars[h.ReferenceVar]
+	if len(h.LoadedDimensions) == 0 {
+		return h.LoadingThresholdFunc.Execute(ctx, now, vars)
+	}
+	var loaded, unloaded mathexp.Values
+	for _, value := range results.Values {
+		if _, ok := h.LoadedDimensions[FingerprintOf(value.GetLabels())]; ok {
+			loaded = append(loaded, value)
+		} else {
+			unloaded = append(unloaded, value)
+		}
+	}
+	if len(loaded) == 0 {
+		return h.LoadingThresholdFunc.Execute(ctx, now, vars)
+	}
+
+	unloadingResults, err := h.UnloadingThresholdFunc.Execute(ctx, now, mathexp.Vars{h.ReferenceVar: mathexp.Results{Values: loaded}})
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to execute unloading threshold: %w", err)
+	}
+	if len(unloaded) == 0 {
+		return unloadingResults, nil
+	}
+	loadingResults, err := h.LoadingThresholdFunc.Execute(ctx, now, mathexp.Vars{h.ReferenceVar: mathexp.Results{Values: unloaded}})
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to execute loading threshold: %w", err)
+	}
+	loadingResults.Values = append(loadingResults.Values, unloadingResults.Values...)
+	return loadingResults, nil
+}
+
+// IsHysteresisExpression returns true if the query model is a threshold expression with an unloading (recovery) threshold.
+func IsHysteresisExpression(query map[string]interface{}) bool {
+	if query["type"] != TypeThreshold.String() {
+		return false
+	}
+	conditions, ok := query["conditions"].([]interface{})
+	if !ok || len(conditions) != 1 {
+		return false
+	}
+	condition, ok := conditions[0].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	unload, ok := condition["unloadEvaluator"]
+	return ok && unload != nil
+}
+
+// SetLoadedDimensionsToHysteresisCommand sets the fingerprints of the dimensions that are firing to the query model of
+// a threshold expression with an unloading threshold.
+func SetLoadedDimensionsToHysteresisCommand(query map[string]interface{}, dimensions Fingerprints) error {
+	if !IsHysteresisExpression(query) {
+		return fmt.Errorf("not a threshold expression with an unloading threshold")
+	}
+	fingerprints := make([]string, 0, len(dimensions))
+	for f := range dimensions {
+		fingerprints = append(fingerprints, f.String())
+	}
+	sort.Strings(fingerprints)
+	query[loadedDimensionsField] = fingerprints
+	return nil
+}
+
+func readLoadedDimensions(rn *rawNode) (Fingerprints, error) {
+	raw, ok := rn.Query[loadedDimensionsField]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal %s: %w", loadedDimensionsField, err)
+	}
+	var fingerprints []string
+	if err := json.Unmarshal(b, &fingerprints); err != nil {
+		return nil, fmt.Errorf("expected %s to be a list of strings: %w", loadedDimensionsField, err)
+	}
+	result := make(Fingerprints, len(fingerprints))
+	for _, s := range fingerprints {
+		f, err := ParseFingerprint(s)
+		if err != nil {
+			return nil, err
+		}
+		result[f] = struct{}{}
+	}
+	return result, nil
+}
@@ -0,0 +1,161 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := FingerprintOf(data.Labels{"host": "a", "dc": "1"})
+	require.Equal(t, a, FingerprintOf(data.Labels{"dc": "1", "host": "a"}))
+	require.NotEqual(t, a, FingerprintOf(data.Labels{"host": "a", "dc": "2"}))
+	require.NotEqual(t, FingerprintOf(data.Labels{"a": "b,c=d"}), FingerprintOf(data.Labels{"a": "b", "c": "d"}))
+	require.Equal(t, FingerprintOf(nil), FingerprintOf(data.Labels{}))
+
+	parsed, err := ParseFingerprint(a.String())
+	require.NoError(t, err)
+	require.Equal(t, a, parsed)
+
+	_, err = ParseFingerprint("foo")
+	require.Error(t, err)
+}
+
+func TestUnmarshalHysteresisCommand(t *testing.T) {
+	query := `{
+		"expression": "A",
+		"type": "threshold",
+		"conditions": [{
+			"evaluator": {
+				"type": "gt",
+				"params": [90]
+			},
+			"unloadEvaluator": {
+				"type": "lt",
+				"params": [80]
+			}
+		}]
+	}`
+
+	t.Run("creates hysteresis command if unload evaluator is set", func(t *testing.T) {
+		var qmap = make(map[string]interface{})
+		require.NoError(t, json.Unmarshal([]byte(query), &qmap))
+		require.True(t, IsHysteresisExpression(qmap))
+
+		dims := Fingerprints{FingerprintOf(data.Labels{"host": "a"}): {}}
+		require.NoError(t, SetLoadedDimensionsToHysteresisCommand(qmap, dims))
+
+		// the model is stored and sent as JSON
+		b, err := json.Marshal(qmap)
+		require.NoError(t, err)
+		qmap = make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(b, &qmap))
+
+		cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "B", Query: qmap})
+		require.NoError(t, err)
+		h, ok := cmd.(*HysteresisCommand)
+		require.True(t, ok)
+		require.Equal(t, []string{"A"}, h.NeedsVars())
+		require.Equal(t, ThresholdCommand{RefID: "B", ReferenceVar: "A", ThresholdFunc: ThresholdIsAbove, Conditions: []float64{90}}, h.LoadingThresholdFunc)
+		require.Equal(t, ThresholdCommand{RefID: "B", ReferenceVar: "A", ThresholdFunc: ThresholdIsBelow, Conditions: []float64{80}, Invert: true}, h.UnloadingThresholdFunc)
+		require.Equal(t, dims, h.LoadedDimensions)
+	})
+
+	t.Run("fails if unload evaluator is invalid", func(t *testing.T) {
+		var qmap = make(map[string]interface{})
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"expression": "A",
+			"type": "threshold",
+			"conditions": [{
+				"evaluator": {"type": "gt", "params": [90]},
+				"unloadEvaluator": {"type": "within_range", "params": [80]}
+			}]
+		}`), &qmap))
+		_, err := UnmarshalThresholdCommand(&rawNode{RefID: "B", Query: qmap})
+		require.ErrorContains(t, err, "invalid unloading threshold")
+	})
+
+	t.Run("is not hysteresis without unload evaluator", func(t *testing.T) {
+		var qmap = make(map[string]interface{})
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"expression": "A",
+			"type": "threshold",
+			"conditions": [{"evaluator": {"type": "gt", "params": [90]}}]
+		}`), &qmap))
+		require.False(t, IsHysteresisExpression(qmap))
+		require.Error(t, SetLoadedDimensionsToHysteresisCommand(qmap, Fingerprints{}))
+	})
+}
+
+func TestHysteresisExecute(t *testing.T) {
+	number := func(host string, value float64) mathexp.Number {
+		n := mathexp.NewNumber("A", data.Labels{"host": host})
+		n.SetValue(&value)
+		return n
+	}
+	loading, err := NewThresholdCommand("B", "A", ThresholdIsAbove, []float64{90})
+	require.NoError(t, err)
+	unloading, err := NewThresholdCommand("B", "A", ThresholdIsBelow, []float64{80})
+	require.NoError(t, err)
+
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{
+		number("normal-below", 70),
+		number("normal-between", 85),
+		number("normal-above", 95),
+		number("firing-below", 70),
+		number("firing-between", 85),
+		number("firing-above", 95),
+	}}}
+
+	t.Run("uses loading threshold if no dimensions are loaded", func(t *testing.T) {
+		cmd, err := NewHysteresisCommand("B", "A", *loading, *unloading, nil)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Equal(t, map[string]*float64{
+			"normal-below":   ptr.Float64(0),
+			"normal-between": ptr.Float64(0),
+			"normal-above":   ptr.Float64(1),
+			"firing-below":   ptr.Float64(0),
+			"firing-between": ptr.Float64(0),
+			"firing-above":   ptr.Float64(1),
+		}, valuesByHost(t, res))
+	})
+
+	t.Run("uses unloading threshold for loaded dimensions", func(t *testing.T) {
+		loaded := Fingerprints{}
+		for _, host := range []string{"firing-below", "firing-between", "firing-above"} {
+			loaded[FingerprintOf(data.Labels{"host": host})] = struct{}{}
+		}
+		cmd, err := NewHysteresisCommand("B", "A", *loading, *unloading, loaded)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Equal(t, map[string]*float64{
+			"normal-below":   ptr.Float64(0),
+			"normal-between": ptr.Float64(0),
+			"normal-above":   ptr.Float64(1),
+			"firing-below":   ptr.Float64(0),
+			"firing-between": ptr.Float64(1),
+			"firing-above":   ptr.Float64(1),
+		}, valuesByHost(t, res))
+	})
+}
+
+func valuesByHost(t *testing.T, res mathexp.Results) map[string]*float64 {
+	t.Helper()
+	result := make(map[string]*float64, len(res.Values))
+	for _, v := range res.Values {
+		n, ok := v.(mathexp.Number)
+		require.True(t, ok)
+		result[n.GetLabels()["host"]] = n.GetFloat64Value()
+	}
+	return result
+}
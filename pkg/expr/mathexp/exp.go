@@ -253,6 +253,118 @@ func union(aResults, bResults Results) []*Union {
 	return unions
 }
 
+// matchedUnion creates Union objects by matching the values of both sides of a binary operation on the labels
+// selected by the vector matching modifiers of the operation. Values without a match on the other side are dropped.
+// An error is returned if the matching is ambiguous, i.e. if a value matches more than one value on the other side
+// without group_left or group_right, or if different matches produce the same labels.
+func matchedUnion(aResults, bResults Results, matching *parse.VectorMatching) ([]*Union, error) {
+	unions := []*Union{}
+	if len(aResults.Values) == 0 || len(bResults.Values) == 0 {
+		return unions, nil
+	}
+	if aResults.Values[0].Type() == parse.TypeNoData || bResults.Values[0].Type() == parse.TypeNoData {
+		if len(aResults.Values) == 1 || len(bResults.Values) == 1 {
+			return append(unions, &Union{
+				A: aResults.Values[0],
+				B: bResults.Values[0],
+			}), nil
+		}
+	}
+	for _, values := range []Values{aResults.Values, bResults.Values} {
+		for _, v := range values {
+			if t := v.Type(); t != parse.TypeSeriesSet && t != parse.TypeNumberSet {
+				return nil, fmt.Errorf("vector matching is only allowed between sets of series or numbers, got %v", t)
+			}
+		}
+	}
+
+	// the "many" side of a group_right match is the right side, swap so that the many side is always iterated over
+	many, one := aResults, bResults
+	manySide, oneSide := "left", "right"
+	swapped := matching.Card == parse.CardOneToMany
+	if swapped {
+		many, one = bResults, aResults
+		manySide, oneSide = oneSide, manySide
+	}
+
+	oneBySignature := make(map[string]Value, len(one.Values))
+	for _, v := range one.Values {
+		sig := matchingLabels(v.GetLabels(), matching).String()
+		if _, ok := oneBySignature[sig]; ok {
+			if matching.Card == parse.CardOneToOne {
+				return nil, fmt.Errorf("found duplicate series for the match group {%s} on the %s side of the operation, many-to-many matching is not allowed: matching labels must be unique on one side", sig, oneSide)
+			}
+			return nil, fmt.Errorf("found duplicate series for the match group {%s} on the %s side of the operation, the %s side of group_left and group_right matching must be unique", sig, oneSide, oneSide)
+		}
+		oneBySignature[sig] = v
+	}
+
+	manySignatures := make(map[string]struct{}, len(many.Values))
+	resultLabels := make(map[string]struct{}, len(many.Values))
+	for _, v := range many.Values {
+		sig := matchingLabels(v.GetLabels(), matching).String()
+		o, ok := oneBySignature[sig]
+		if !ok {
+			continue
+		}
+		if matching.Card == parse.CardOneToOne {
+			if _, ok := manySignatures[sig]; ok {
+				return nil, fmt.Errorf("found duplicate series for the match group {%s} on the %s side of the operation, many-to-one matching must be explicit (group_left/group_right)", sig, manySide)
+			}
+			manySignatures[sig] = struct{}{}
+		}
+		labels := matchResultLabels(v.GetLabels(), o.GetLabels(), matching)
+		key := labels.String()
+		if _, ok := resultLabels[key]; ok {
+			return nil, fmt.Errorf("multiple matches for labels {%s}: grouping labels must ensure unique matches", key)
+		}
+		resultLabels[key] = struct{}{}
+		u := &Union{Labels: labels, A: v, B: o}
+		if swapped {
+			u.A, u.B = o, v
+		}
+		unions = append(unions, u)
+	}
+	return unions, nil
+}
+
+// matchingLabels returns the labels that are used to match a value with values on the other side of a binary operation.
+func matchingLabels(labels data.Labels, matching *parse.VectorMatching) data.Labels {
+	result := data.Labels{}
+	if matching.On {
+		for _, name := range matching.MatchingLabels {
+			if value, ok := labels[name]; ok {
+				result[name] = value
+			}
+		}
+		return result
+	}
+	for name, value := range labels {
+		result[name] = value
+	}
+	for _, name := range matching.MatchingLabels {
+		delete(result, name)
+	}
+	return result
+}
+
+// matchResultLabels returns the labels of the result of a binary operation between two matched values, where many are
+// the labels of the value on the "many" side (the left side for one-to-one matching).
+func matchResultLabels(many, one data.Labels, matching *parse.VectorMatching) data.Labels {
+	if matching.Card == parse.CardOneToOne {
+		return matchingLabels(many, matching)
+	}
+	result := many.Copy()
+	for _, name := range matching.Include {
+		if value, ok := one[name]; ok {
+			result[name] = value
+		} else {
+			delete(result, name)
+		}
+	}
+	return result
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -263,7 +375,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := union(ar, br)
+	var unions []*Union
+	if node.VectorMatching != nil {
+		unions, err = matchedUnion(ar, br, node.VectorMatching)
+		if err != nil {
+			return res, fmt.Errorf("%s: %w", node, err)
+		}
+	} else {
+		unions = union(ar, br)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -1,7 +1,12 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,6 +73,71 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"sqrt": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             sqrt,
+	},
+	"exp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             exp,
+	},
+	"pow": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             pow,
+	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"increase": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      increase,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+	},
+	"stddev_over_window": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      stddevOverWindow,
+	},
+	"ewma": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeSeriesSet,
+		F:      ewma,
+	},
+	"shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+	},
+	"percentile": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeNumberSet,
+		F:      percentile,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -307,3 +377,341 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// sqrt returns the square root for each result in NumberSet, SeriesSet, or Scalar
+func sqrt(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Sqrt)
+}
+
+// exp returns e**x for each result in NumberSet, SeriesSet, or Scalar
+func exp(e *State, varSet Results) (Results, error) {
+	return perFloatResults(e, varSet, math.Exp)
+}
+
+// pow returns x**y for each result x in NumberSet, SeriesSet, or Scalar, where y is a scalar
+func pow(e *State, varSet Results, exponentArg Results) (Results, error) {
+	y, err := scalarArg("pow", exponentArg)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(x float64) float64 {
+		return math.Pow(x, y)
+	})
+}
+
+// clampMin returns the greater of the value and the scalar min for each result in NumberSet, SeriesSet, or Scalar
+func clampMin(e *State, varSet Results, minArg Results) (Results, error) {
+	m, err := scalarArg("clamp_min", minArg)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(x float64) float64 {
+		return math.Max(x, m)
+	})
+}
+
+// clampMax returns the lesser of the value and the scalar max for each result in NumberSet, SeriesSet, or Scalar
+func clampMax(e *State, varSet Results, maxArg Results) (Results, error) {
+	m, err := scalarArg("clamp_max", maxArg)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(x float64) float64 {
+		return math.Min(x, m)
+	})
+}
+
+// delta returns the difference between each point of the series and the point before it.
+// The returned series does not have a point for the first point of the input series.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "delta", varSet, func(s Series) Series {
+		return perPointPair(e, s, func(prev, cur point) float64 {
+			return cur.v - prev.v
+		})
+	})
+}
+
+// increase is like delta but treats the series as a counter: if the value decreases, the counter is considered reset
+// and the increase is the current value.
+func increase(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "increase", varSet, func(s Series) Series {
+		return perPointPair(e, s, counterIncrease)
+	})
+}
+
+// rate returns the per-second rate of increase of a counter between each point of the series and the point before it.
+// The rate of points with the same time as the point before them is NaN.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) Series {
+		return perPointPair(e, s, func(prev, cur point) float64 {
+			elapsed := cur.t.Sub(prev.t).Seconds()
+			if elapsed <= 0 {
+				return math.NaN()
+			}
+			return counterIncrease(prev, cur) / elapsed
+		})
+	})
+}
+
+// movingAvg returns, for each point of the series, the average of the points within the window ending at that point.
+func movingAvg(e *State, varSet Results, rawWindow string) (Results, error) {
+	window, err := windowArg("moving_avg", rawWindow)
+	if err != nil {
+		return Results{}, err
+	}
+	return perSeries(e, "moving_avg", varSet, func(s Series) Series {
+		return perWindow(e, s, window, func(values []float64) float64 {
+			return mean(values)
+		})
+	})
+}
+
+// stddevOverWindow returns, for each point of the series, the population standard deviation of the points within the window ending at that point.
+func stddevOverWindow(e *State, varSet Results, rawWindow string) (Results, error) {
+	window, err := windowArg("stddev_over_window", rawWindow)
+	if err != nil {
+		return Results{}, err
+	}
+	return perSeries(e, "stddev_over_window", varSet, func(s Series) Series {
+		return perWindow(e, s, window, func(values []float64) float64 {
+			m := mean(values)
+			var variance float64
+			for _, v := range values {
+				variance += (v - m) * (v - m)
+			}
+			return math.Sqrt(variance / float64(len(values)))
+		})
+	})
+}
+
+// ewma returns the exponentially weighted moving average of the series with the smoothing factor alpha in the range (0, 1].
+// Points with null or NaN values are NaN in the result and do not affect the average of the following points.
+func ewma(e *State, varSet Results, alphaArg Results) (Results, error) {
+	alpha, err := scalarArg("ewma", alphaArg)
+	if err != nil {
+		return Results{}, err
+	}
+	if !(alpha > 0 && alpha <= 1) {
+		return Results{}, fmt.Errorf("ewma: smoothing factor must be in the range (0, 1], got %v", alpha)
+	}
+	return perSeries(e, "ewma", varSet, func(s Series) Series {
+		points := sortedPoints(s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), len(points))
+		avg := math.NaN()
+		for i, p := range points {
+			nF := math.NaN()
+			if p.valid {
+				if math.IsNaN(avg) {
+					avg = p.v
+				} else {
+					avg = alpha*p.v + (1-alpha)*avg
+				}
+				nF = avg
+			}
+			newSeries.SetPoint(i, p.t, &nF)
+		}
+		return newSeries
+	})
+}
+
+// shift moves every point of the series forward in time by the duration. A negative duration moves the points backward.
+func shift(e *State, varSet Results, rawDuration string) (Results, error) {
+	d, err := gtime.ParseDuration(rawDuration)
+	if err != nil {
+		return Results{}, fmt.Errorf("shift: failed to parse duration '%v': %w", rawDuration, err)
+	}
+	return perSeries(e, "shift", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries
+	})
+}
+
+// percentile reduces each series to the p-th percentile (0-100) of its values, using linear interpolation between the closest ranks.
+// The result is NaN if the series is empty or any of its values is null or NaN.
+func percentile(e *State, varSet Results, pArg Results) (Results, error) {
+	p, err := scalarArg("percentile", pArg)
+	if err != nil {
+		return Results{}, err
+	}
+	if p < 0 || p > 100 {
+		return Results{}, fmt.Errorf("percentile: percentile must be in the range [0, 100], got %v", p)
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch res.Type() {
+		case parse.TypeSeriesSet:
+			s := res.(Series)
+			n := NewNumber(e.RefID, s.GetLabels())
+			values := make([]float64, 0, s.Len())
+			nF := math.NaN()
+			for i := 0; i < s.Len(); i++ {
+				f := s.GetValue(i)
+				if f == nil || math.IsNaN(*f) {
+					values = nil
+					break
+				}
+				values = append(values, *f)
+			}
+			if len(values) > 0 {
+				sort.Float64s(values)
+				nF = percentileOfSorted(values, p)
+			}
+			n.SetValue(&nF)
+			newRes.Values = append(newRes.Values, n)
+		case parse.TypeNoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			return newRes, fmt.Errorf("percentile: expected a time series, got %v", res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// perFloatResults calls perFloat for each result in varSet.
+func perFloatResults(e *State, varSet Results, floatF func(x float64) float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, floatF)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// perSeries passes each Series in varSet to seriesF. NoData results are passed through unchanged.
+// Any other type of result is an error because the function needs the points of a time series.
+func perSeries(e *State, name string, varSet Results, seriesF func(s Series) Series) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch res.Type() {
+		case parse.TypeSeriesSet:
+			newRes.Values = append(newRes.Values, seriesF(res.(Series)))
+		case parse.TypeNoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			return newRes, fmt.Errorf("%s: expected a time series, got %v", name, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// point is a point of a series. valid is false if the value of the point is null or NaN.
+type point struct {
+	t     time.Time
+	v     float64
+	valid bool
+}
+
+// sortedPoints returns the points of the series sorted by time from oldest to newest without modifying the series.
+func sortedPoints(s Series) []point {
+	points := make([]point, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		p := point{t: t}
+		if f != nil && !math.IsNaN(*f) {
+			p.v = *f
+			p.valid = true
+		}
+		points = append(points, p)
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].t.Before(points[j].t)
+	})
+	return points
+}
+
+// perPointPair passes each point of the series together with the point before it to pairF.
+// The returned series does not have a point for the first point of the series.
+// If either of the values is null or NaN, the function is not called and NaN is returned for the point.
+func perPointPair(e *State, s Series, pairF func(prev, cur point) float64) Series {
+	points := sortedPoints(s)
+	if len(points) < 2 {
+		return NewSeries(e.RefID, s.GetLabels(), 0)
+	}
+	newSeries := NewSeries(e.RefID, s.GetLabels(), len(points)-1)
+	for i := 1; i < len(points); i++ {
+		prev, cur := points[i-1], points[i]
+		nF := math.NaN()
+		if prev.valid && cur.valid {
+			nF = pairF(prev, cur)
+		}
+		newSeries.SetPoint(i-1, cur.t, &nF)
+	}
+	return newSeries
+}
+
+// perWindow passes the values of the points that are within the window ending at each point of the series to windowF.
+// A point is within the window if its time is after the time of the window end minus the window.
+// If any value within the window is null or NaN, the function is not called and NaN is returned for the point.
+func perWindow(e *State, s Series, window time.Duration, windowF func(values []float64) float64) Series {
+	points := sortedPoints(s)
+	newSeries := NewSeries(e.RefID, s.GetLabels(), len(points))
+	start := 0
+	values := make([]float64, 0, len(points))
+	for i, p := range points {
+		for !points[start].t.After(p.t.Add(-window)) {
+			start++
+		}
+		values = values[:0]
+		valid := true
+		for _, wp := range points[start : i+1] {
+			if !wp.valid {
+				valid = false
+				break
+			}
+			values = append(values, wp.v)
+		}
+		nF := math.NaN()
+		if valid {
+			nF = windowF(values)
+		}
+		newSeries.SetPoint(i, p.t, &nF)
+	}
+	return newSeries
+}
+
+// counterIncrease returns the increase of a counter between two points, taking counter resets into account.
+func counterIncrease(prev, cur point) float64 {
+	if cur.v < prev.v {
+		return cur.v
+	}
+	return cur.v - prev.v
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// scalarArg returns the value of a scalar argument of the function. The value must not be null or NaN.
+func scalarArg(name string, arg Results) (float64, error) {
+	if len(arg.Values) != 1 || arg.Values[0].Type() != parse.TypeScalar {
+		return 0, fmt.Errorf("%s: expected a scalar argument", name)
+	}
+	f := arg.Values[0].(Scalar).GetFloat64Value()
+	if f == nil || math.IsNaN(*f) {
+		return 0, fmt.Errorf("%s: scalar argument must be a number", name)
+	}
+	return *f, nil
+}
+
+// windowArg parses the window argument of the function. The window must be a positive duration.
+func windowArg(name string, rawWindow string) (time.Duration, error) {
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to parse window '%v': %w", name, rawWindow, err)
+	}
+	if window <= 0 {
+		return 0, fmt.Errorf("%s: window must be a positive duration, got '%v'", name, rawWindow)
+	}
+	return window, nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 )
 
@@ -159,3 +160,245 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestPowAndClampFuncs(t *testing.T) {
+	var tests = []struct {
+		name    string
+		expr    string
+		vars    Vars
+		results Results
+	}{
+		{
+			name:    "sqrt on scalar",
+			expr:    "sqrt(16)",
+			vars:    Vars{},
+			results: Results{[]Value{NewScalar("", float64Pointer(4))}},
+		},
+		{
+			name: "pow on number",
+			expr: "pow($A, 2)",
+			vars: Vars{
+				"A": Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+			},
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(9))}},
+		},
+		{
+			name: "clamp_min and clamp_max on series",
+			expr: "clamp_max(clamp_min($A, 0), 10)",
+			vars: Vars{
+				"A": Results{[]Value{
+					makeSeries("", nil,
+						tp{time.Unix(5, 0), float64Pointer(-5)},
+						tp{time.Unix(10, 0), float64Pointer(5)},
+						tp{time.Unix(15, 0), float64Pointer(15)}),
+				}},
+			},
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(5, 0), float64Pointer(0)},
+					tp{time.Unix(10, 0), float64Pointer(5)},
+					tp{time.Unix(15, 0), float64Pointer(10)}),
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars)
+			require.NoError(t, err)
+			require.Equal(t, tt.results, res)
+		})
+	}
+}
+
+func TestSeriesFuncs(t *testing.T) {
+	counter := Vars{
+		"A": Results{[]Value{
+			makeSeries("", data.Labels{"host": "a"},
+				tp{time.Unix(20, 0), float64Pointer(2)},
+				tp{time.Unix(0, 0), float64Pointer(10)},
+				tp{time.Unix(10, 0), float64Pointer(30)}),
+		}},
+	}
+	var tests = []struct {
+		name    string
+		expr    string
+		vars    Vars
+		results Results
+	}{
+		{
+			name: "delta returns the difference between consecutive points",
+			expr: "delta($A)",
+			vars: counter,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), float64Pointer(-28)}),
+			}},
+		},
+		{
+			name: "increase handles counter resets",
+			expr: "increase($A)",
+			vars: counter,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), float64Pointer(2)}),
+			}},
+		},
+		{
+			name: "rate returns per-second increase",
+			expr: "rate($A)",
+			vars: counter,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), float64Pointer(0.2)}),
+			}},
+		},
+		{
+			name: "moving_avg averages points within the window",
+			expr: `moving_avg($A, "15s")`,
+			vars: counter,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), float64Pointer(16)}),
+			}},
+		},
+		{
+			name: "stddev_over_window returns population standard deviation within the window",
+			expr: `stddev_over_window($A, "15s")`,
+			vars: counter,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(0)},
+					tp{time.Unix(10, 0), float64Pointer(10)},
+					tp{time.Unix(20, 0), float64Pointer(14)}),
+			}},
+		},
+		{
+			name: "ewma smooths the series",
+			expr: "ewma($A, 0.5)",
+			vars: counter,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), float64Pointer(11)}),
+			}},
+		},
+		{
+			name: "shift moves points forward in time",
+			expr: `shift($A, "1m")`,
+			vars: counter,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(80, 0), float64Pointer(2)},
+					tp{time.Unix(60, 0), float64Pointer(10)},
+					tp{time.Unix(70, 0), float64Pointer(30)}),
+			}},
+		},
+		{
+			name:    "percentile reduces series to number",
+			expr:    "percentile($A, 75)",
+			vars:    counter,
+			results: Results{[]Value{makeNumber("", data.Labels{"host": "a"}, float64Pointer(20))}},
+		},
+		{
+			name:    "series functions pass through no data",
+			expr:    "rate($A)",
+			vars:    Vars{"A": Results{[]Value{NewNoData()}}},
+			results: Results{[]Value{NewNoData()}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars)
+			require.NoError(t, err)
+			require.Equal(t, tt.results, res)
+		})
+	}
+
+	t.Run("points computed from null or NaN values are NaN", func(t *testing.T) {
+		vars := Vars{
+			"A": Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), nil},
+					tp{time.Unix(20, 0), NaN},
+					tp{time.Unix(30, 0), float64Pointer(4)},
+					tp{time.Unix(40, 0), float64Pointer(6)}),
+			}},
+		}
+		expected := map[string][]bool{ // true if the point is expected to be NaN
+			"delta($A)":                     {true, true, true, false},
+			`moving_avg($A, "15s")`:         {false, true, true, true, false},
+			`stddev_over_window($A, "15s")`: {false, true, true, true, false},
+			"ewma($A, 0.5)":                 {false, true, true, false, false},
+		}
+		for expr, nans := range expected {
+			e, err := New(expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", vars)
+			require.NoError(t, err)
+			require.Len(t, res.Values, 1)
+			s := res.Values[0].(Series)
+			require.Equal(t, len(nans), s.Len(), expr)
+			for i, isNaN := range nans {
+				require.Equal(t, isNaN, math.IsNaN(*s.GetValue(i)), "%s at point %d", expr, i)
+			}
+		}
+
+		e, err := New("percentile($A, 50)")
+		require.NoError(t, err)
+		res, err := e.Execute("", vars)
+		require.NoError(t, err)
+		require.True(t, math.IsNaN(*res.Values[0].(Number).GetFloat64Value()))
+	})
+
+	t.Run("rate of points with duplicate times is NaN", func(t *testing.T) {
+		vars := Vars{
+			"A": Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(11)},
+					tp{time.Unix(10, 0), float64Pointer(12)},
+					tp{time.Unix(20, 0), float64Pointer(22)}),
+			}},
+		}
+		e, err := New("rate($A)")
+		require.NoError(t, err)
+		res, err := e.Execute("", vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		s := res.Values[0].(Series)
+		require.Equal(t, 3, s.Len())
+		require.Equal(t, 1.0, *s.GetValue(0))
+		require.True(t, math.IsNaN(*s.GetValue(1)))
+		require.False(t, math.IsInf(*s.GetValue(2), 0))
+		require.Equal(t, 1.0, *s.GetValue(2))
+	})
+
+	t.Run("fails on invalid arguments", func(t *testing.T) {
+		for _, expr := range []string{"rate(1)", `moving_avg($A, 5)`, `moving_avg($A, "-5m")`, "ewma($A, 2)", "percentile($A, 101)", `shift($A, "x")`} {
+			e, err := New(expr)
+			if err != nil {
+				continue
+			}
+			_, err = e.Execute("", counter)
+			require.Error(t, err, expr)
+		}
+	})
+
+	t.Run("fails on numbers", func(t *testing.T) {
+		e, err := New("rate($A)")
+		require.NoError(t, err)
+		_, err = e.Execute("", Vars{"A": Results{[]Value{makeNumber("", nil, float64Pointer(1))}}})
+		require.Error(t, err)
+	})
+}
@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -298,6 +299,9 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// VectorMatching describes how the values of both arguments are matched. It is nil if the operation has no
+	// on, ignoring, group_left or group_right modifiers.
+	VectorMatching *VectorMatching
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -306,19 +310,75 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.VectorMatching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.VectorMatching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.VectorMatching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.VectorMatching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
 // Check performs parse time checking on the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Check(t *Tree) error {
+	if b.VectorMatching == nil {
+		return nil
+	}
+	for _, arg := range b.Args {
+		if rt := arg.Return(); rt != TypeSeriesSet && rt != TypeNumberSet && rt != TypeVariantSet {
+			return fmt.Errorf("parse: type error in %s, vector matching is only allowed between sets of series or numbers, got %s", b, rt)
+		}
+	}
 	return nil
 }
 
+// VectorMatchCardinality describes the cardinality of the relationship between the values of both arguments of a
+// binary operation.
+type VectorMatchCardinality int
+
+const (
+	// CardOneToOne requires every value to match at most one value on the other side of the operation.
+	CardOneToOne VectorMatchCardinality = iota
+	// CardManyToOne allows many values on the left side to match one value on the right side (group_left).
+	CardManyToOne
+	// CardOneToMany allows one value on the left side to match many values on the right side (group_right).
+	CardOneToMany
+)
+
+// VectorMatching describes how the values of both arguments of a binary operation are matched by their labels.
+type VectorMatching struct {
+	// Card is the cardinality of the relationship between the values of both sides.
+	Card VectorMatchCardinality
+	// On is true if the values are matched on MatchingLabels only, and false if the values are matched on all labels
+	// except MatchingLabels.
+	On             bool
+	MatchingLabels []string
+	// Include are the labels of the "one" side that are copied to the result of a many-to-one or one-to-many match.
+	Include []string
+}
+
+// String returns the string representation of the modifiers of the VectorMatching.
+func (m *VectorMatching) String() string {
+	var parts []string
+	if m.On {
+		parts = append(parts, fmt.Sprintf("on(%s)", strings.Join(m.MatchingLabels, ", ")))
+	} else if len(m.MatchingLabels) > 0 {
+		parts = append(parts, fmt.Sprintf("ignoring(%s)", strings.Join(m.MatchingLabels, ", ")))
+	}
+	switch m.Card {
+	case CardManyToOne:
+		parts = append(parts, fmt.Sprintf("group_left(%s)", strings.Join(m.Include, ", ")))
+	case CardOneToMany:
+		parts = append(parts, fmt.Sprintf("group_right(%s)", strings.Join(m.Include, ", ")))
+	}
+	return strings.Join(parts, " ")
+}
+
 // Return returns the result type of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Return() ReturnType {
 	t0 := b.Args[0].Return()
@@ -165,16 +165,19 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [mod] A}
+A -> C {"&&" [mod] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [mod] P}
+P -> M {( "+" | "-" ) [mod] M}
+M -> E {( "*" | "/" ) [mod] F}
+E -> F {( "**" ) [mod] F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+mod -> [("on" | "ignoring") labels] [("group_left" | "group_right") [labels]]
+labels -> "(" [label {"," label}] ")"
+label -> name | "string"
 */
 
 // expr:
@@ -185,7 +188,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(t.next(), n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +201,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(t.next(), n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +214,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(t.next(), n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +227,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(t.next(), n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +240,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(t.next(), n, t.E)
 		default:
 			return n
 		}
@@ -250,13 +253,80 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(t.next(), n, t.F)
 		default:
 			return n
 		}
 	}
 }
 
+// binary parses the optional vector matching modifiers after the operator and the right hand side of a binary operation.
+func (t *Tree) binary(operator item, lhs Node, rhs func() Node) Node {
+	matching := t.vectorMatching()
+	b := newBinary(operator, lhs, rhs())
+	b.VectorMatching = matching
+	return b
+}
+
+// vectorMatching is [mod] in the grammar. It returns nil if there are no modifiers.
+func (t *Tree) vectorMatching() *VectorMatching {
+	var m *VectorMatching
+	if token := t.peek(); token.typ == itemFunc && (token.val == "on" || token.val == "ignoring") {
+		t.next()
+		m = &VectorMatching{
+			On:             token.val == "on",
+			MatchingLabels: t.labels(token.val),
+		}
+	}
+	if token := t.peek(); token.typ == itemFunc && (token.val == "group_left" || token.val == "group_right") {
+		t.next()
+		if m == nil {
+			m = &VectorMatching{}
+		}
+		m.Card = CardManyToOne
+		if token.val == "group_right" {
+			m.Card = CardOneToMany
+		}
+		if t.peek().typ == itemLeftParen {
+			m.Include = t.labels(token.val)
+		}
+		if m.On {
+			for _, l := range m.Include {
+				for _, ml := range m.MatchingLabels {
+					if l == ml {
+						t.errorf("label %q must not occur in on and %s at once", l, token.val)
+					}
+				}
+			}
+		}
+	}
+	return m
+}
+
+// labels is labels in the grammar.
+func (t *Tree) labels(context string) []string {
+	t.expect(itemLeftParen, context)
+	labels := []string{}
+	for {
+		switch token := t.next(); token.typ {
+		case itemFunc:
+			labels = append(labels, token.val)
+		case itemString:
+			s, err := strconv.Unquote(token.val)
+			if err != nil {
+				t.errorf("Unquoting error: %s", err)
+			}
+			labels = append(labels, s)
+		case itemComma:
+			// separates labels
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
 // F is v | "(" O ")" | "!" O | "-" O in the grammar.
 func (t *Tree) F() Node {
 	switch token := t.peek(); token.typ {
@@ -329,6 +399,8 @@ func (t *Tree) Func() (f *FuncNode) {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+		case itemComma:
+			// separates arguments
 		case itemRightParen:
 			return
 		}
@@ -1,8 +1,11 @@
 package mathexp
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -72,6 +75,17 @@ func Count(fv *Float64Field) *float64 {
 	return &f
 }
 
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
 func Last(fv *Float64Field) *float64 {
 	var f float64
 	if fv.Len() == 0 {
@@ -81,28 +95,174 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+// Percentile returns a ReducerFunc that calculates the p-th percentile, where p is in the range [0, 100],
+// using linear interpolation between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		values, ok := validValues(fv)
+		if !ok || len(values) == 0 {
+			nan := math.NaN()
+			return &nan
+		}
+		sort.Float64s(values)
+		f := percentileOfSorted(values, p)
+		return &f
+	}
+}
+
+func Stddev(fv *Float64Field) *float64 {
+	values, ok := validValues(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	f := math.Sqrt(variance / float64(len(values)))
+	return &f
+}
+
+func Diff(fv *Float64Field) *float64 {
+	return calculateDiff(fv, func(newest, oldest float64) float64 {
+		return newest - oldest
+	})
+}
+
+func DiffAbs(fv *Float64Field) *float64 {
+	return calculateDiff(fv, func(newest, oldest float64) float64 {
+		return math.Abs(newest - oldest)
+	})
+}
+
+func PercentDiff(fv *Float64Field) *float64 {
+	return calculateDiff(fv, func(newest, oldest float64) float64 {
+		return (newest - oldest) / math.Abs(oldest) * 100
+	})
+}
+
+func PercentDiffAbs(fv *Float64Field) *float64 {
+	return calculateDiff(fv, func(newest, oldest float64) float64 {
+		return math.Abs((newest - oldest) / oldest * 100)
+	})
+}
+
+// calculateDiff applies fn to the last (newest) and the first (oldest) values of the field.
+func calculateDiff(fv *Float64Field, fn func(newest, oldest float64) float64) *float64 {
+	nan := math.NaN()
+	if fv.Len() == 0 {
+		return &nan
+	}
+	newest, oldest := fv.GetValue(fv.Len()-1), fv.GetValue(0)
+	if newest == nil || math.IsNaN(*newest) || oldest == nil || math.IsNaN(*oldest) {
+		return &nan
+	}
+	f := fn(*newest, *oldest)
+	return &f
+}
+
+// validValues returns the values of the field, and false if any of them is null or NaN.
+func validValues(fv *Float64Field) ([]float64, bool) {
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		values = append(values, *v)
+	}
+	return values, true
+}
+
+// percentileOfSorted returns the p-th percentile of the sorted non-empty values using linear interpolation between the closest ranks.
+func percentileOfSorted(values []float64, p float64) float64 {
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+}
+
+// reducers is the registry of reduction functions that are shared by the reduce expression and classic conditions.
+// Percentiles are not in the registry because their names are parsed, see GetReduceFunc.
+var reducers = map[string]ReducerFunc{
+	"sum":              Sum,
+	"mean":             Avg,
+	"min":              Min,
+	"max":              Max,
+	"count":            Count,
+	"last":             Last,
+	"median":           Median,
+	"stddev":           Stddev,
+	"first":            First,
+	"diff":             Diff,
+	"diff_abs":         DiffAbs,
+	"percent_diff":     PercentDiff,
+	"percent_diff_abs": PercentDiffAbs,
+	"count_non_null":   CountNonNull,
+}
+
+// GetReduceFunc returns the reduction function with the given name. Besides the names returned by GetSupportedReduceFuncs,
+// percentiles are supported with names in the form pN, for example p95 or p99.9, where N is in the range [0, 100].
 func GetReduceFunc(rFunc string) (ReducerFunc, error) {
-	switch strings.ToLower(rFunc) {
-	case "sum":
-		return Sum, nil
-	case "mean":
-		return Avg, nil
-	case "min":
-		return Min, nil
-	case "max":
-		return Max, nil
-	case "count":
-		return Count, nil
-	case "last":
-		return Last, nil
-	default:
-		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
+	name := strings.ToLower(rFunc)
+	if f, ok := reducers[name]; ok {
+		return f, nil
 	}
+	if strings.HasPrefix(name, "p") {
+		if p, err := strconv.ParseFloat(name[1:], 64); err == nil && p >= 0 && p <= 100 {
+			return Percentile(p), nil
+		}
+	}
+	return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 }
 
 // GetSupportedReduceFuncs returns collection of supported function names
 func GetSupportedReduceFuncs() []string {
-	return []string{"sum", "mean", "min", "max", "count", "last"}
+	return []string{"sum", "mean", "min", "max", "count", "last", "median", "stddev", "first", "diff", "diff_abs", "percent_diff", "percent_diff_abs", "count_non_null"}
+}
+
+const (
+	// ReduceModeDropNN is the null handling mode that drops null, NaN and Inf values before the reduction.
+	ReduceModeDropNN = "dropNN"
+	// ReduceModeReplaceNN is the null handling mode that replaces null, NaN and Inf values with a value before the reduction.
+	ReduceModeReplaceNN = "replaceNN"
+)
+
+// GetReduceMapper returns the ReduceMapper for the null handling mode. It returns nil if the mode is empty, which means
+// that the reduction functions are applied to the series as is.
+func GetReduceMapper(mode string, replaceWithValue *float64) (ReduceMapper, error) {
+	switch mode {
+	case "":
+		return nil, nil
+	case ReduceModeDropNN:
+		return DropNonNumber{}, nil
+	case ReduceModeReplaceNN:
+		if replaceWithValue == nil {
+			return nil, errors.New("setting replaceWithValue must be specified when mode is 'replaceNN'")
+		}
+		return ReplaceNonNumberWithValue{Value: *replaceWithValue}, nil
+	default:
+		return nil, fmt.Errorf("reducer mode '%s' is not supported. Supported only: [dropNN,replaceNN]", mode)
+	}
 }
 
 // Reduce turns the Series into a Number based on the given reduction function
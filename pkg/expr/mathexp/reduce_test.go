@@ -514,3 +514,57 @@ func TestSeriesReduceReplaceNN(t *testing.T) {
 		})
 	}
 }
+
+func TestReduceFuncs(t *testing.T) {
+	var tests = []struct {
+		name     string
+		red      string
+		values   []*float64
+		expected *float64
+	}{
+		{name: "median of odd number of values", red: "median", values: []*float64{float64Pointer(3), float64Pointer(1), float64Pointer(2)}, expected: float64Pointer(2)},
+		{name: "median of even number of values", red: "median", values: []*float64{float64Pointer(4), float64Pointer(1), float64Pointer(2), float64Pointer(3)}, expected: float64Pointer(2.5)},
+		{name: "median with a nil value", red: "median", values: []*float64{float64Pointer(1), nil}, expected: NaN},
+		{name: "median of empty series", red: "median", values: []*float64{}, expected: NaN},
+		{name: "p0 is the minimum", red: "p0", values: []*float64{float64Pointer(3), float64Pointer(1), float64Pointer(2)}, expected: float64Pointer(1)},
+		{name: "p100 is the maximum", red: "p100", values: []*float64{float64Pointer(3), float64Pointer(1), float64Pointer(2)}, expected: float64Pointer(3)},
+		{name: "p90 interpolates between ranks", red: "p90", values: []*float64{float64Pointer(10), float64Pointer(20), float64Pointer(30)}, expected: float64Pointer(28)},
+		{name: "p99.5 is supported", red: "P99.5", values: []*float64{float64Pointer(0), float64Pointer(100)}, expected: float64Pointer(99.5)},
+		{name: "stddev", red: "stddev", values: []*float64{float64Pointer(2), float64Pointer(4), float64Pointer(4), float64Pointer(4), float64Pointer(5), float64Pointer(5), float64Pointer(7), float64Pointer(9)}, expected: float64Pointer(2)},
+		{name: "stddev with a NaN value", red: "stddev", values: []*float64{float64Pointer(1), NaN}, expected: NaN},
+		{name: "first", red: "first", values: []*float64{float64Pointer(1), float64Pointer(2)}, expected: float64Pointer(1)},
+		{name: "first of empty series", red: "first", values: []*float64{}, expected: NaN},
+		{name: "diff", red: "diff", values: []*float64{float64Pointer(30), float64Pointer(10), float64Pointer(20)}, expected: float64Pointer(-10)},
+		{name: "diff_abs", red: "diff_abs", values: []*float64{float64Pointer(30), float64Pointer(10), float64Pointer(20)}, expected: float64Pointer(10)},
+		{name: "percent_diff", red: "percent_diff", values: []*float64{float64Pointer(-20), float64Pointer(10)}, expected: float64Pointer(150)},
+		{name: "percent_diff_abs", red: "percent_diff_abs", values: []*float64{float64Pointer(20), float64Pointer(10)}, expected: float64Pointer(50)},
+		{name: "diff with a nil newest value", red: "diff", values: []*float64{float64Pointer(20), nil}, expected: NaN},
+		{name: "count_non_null", red: "count_non_null", values: []*float64{float64Pointer(1), nil, NaN, float64Pointer(2)}, expected: float64Pointer(2)},
+		{name: "count_non_null of empty series", red: "count_non_null", values: []*float64{}, expected: float64Pointer(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := make([]tp, 0, len(tt.values))
+			for i, v := range tt.values {
+				points = append(points, tp{time.Unix(int64(i), 0), v})
+			}
+			n, err := makeSeries("", nil, points...).Reduce("", tt.red, nil)
+			require.NoError(t, err)
+			actual := n.GetFloat64Value()
+			require.NotNil(t, actual)
+			if math.IsNaN(*tt.expected) {
+				require.True(t, math.IsNaN(*actual))
+				return
+			}
+			require.InDelta(t, *tt.expected, *actual, 1e-9)
+		})
+	}
+
+	t.Run("invalid percentiles are not supported", func(t *testing.T) {
+		for _, red := range []string{"p", "p101", "p-1", "pfoo", "pnan"} {
+			_, err := GetReduceFunc(red)
+			require.Errorf(t, err, "expected %s to be not supported", red)
+		}
+	})
+}
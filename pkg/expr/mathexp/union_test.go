@@ -3,6 +3,7 @@ package mathexp
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 )
@@ -298,3 +299,140 @@ func Test_union(t *testing.T) {
 		})
 	}
 }
+
+func TestVectorMatchingExpr(t *testing.T) {
+	cpu := Results{Values: Values{
+		makeNumber("cpu", data.Labels{"host": "a", "cpu": "0", "job": "node"}, float64Pointer(1)),
+		makeNumber("cpu", data.Labels{"host": "a", "cpu": "1", "job": "node"}, float64Pointer(2)),
+		makeNumber("cpu", data.Labels{"host": "b", "cpu": "0", "job": "node"}, float64Pointer(3)),
+	}}
+	info := Results{Values: Values{
+		makeNumber("info", data.Labels{"host": "a", "region": "eu"}, float64Pointer(10)),
+		makeNumber("info", data.Labels{"host": "b", "region": "us"}, float64Pointer(20)),
+		makeNumber("info", data.Labels{"host": "c", "region": "us"}, float64Pointer(30)),
+	}}
+	mem := Results{Values: Values{
+		makeNumber("mem", data.Labels{"host": "a", "job": "telegraf"}, float64Pointer(100)),
+		makeNumber("mem", data.Labels{"host": "b", "job": "telegraf"}, float64Pointer(200)),
+	}}
+	total := Results{Values: Values{
+		makeNumber("total", data.Labels{"host": "a", "cpu": "total"}, float64Pointer(3)),
+	}}
+	vars := Vars{"cpu": cpu, "info": info, "mem": mem, "total": total}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		newErrIs  assert.ErrorAssertionFunc
+		execErrIs assert.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "one-to-one on labels drops unmatched values",
+			expr:      "$mem + on(host) $info",
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(110)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(220)),
+			}},
+		},
+		{
+			name:      "one-to-one ignoring labels",
+			expr:      "$mem / ignoring(job, region) $info",
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(10)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(10)),
+			}},
+		},
+		{
+			name:      "many-to-one with group_left copies included labels",
+			expr:      "$cpu * on(host) group_left(region) $info",
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a", "cpu": "0", "job": "node", "region": "eu"}, float64Pointer(10)),
+				makeNumber("", data.Labels{"host": "a", "cpu": "1", "job": "node", "region": "eu"}, float64Pointer(20)),
+				makeNumber("", data.Labels{"host": "b", "cpu": "0", "job": "node", "region": "us"}, float64Pointer(60)),
+			}},
+		},
+		{
+			name:      "one-to-many with group_right keeps operand order",
+			expr:      "$info - on(host) group_right $cpu",
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a", "cpu": "0", "job": "node"}, float64Pointer(9)),
+				makeNumber("", data.Labels{"host": "a", "cpu": "1", "job": "node"}, float64Pointer(8)),
+				makeNumber("", data.Labels{"host": "b", "cpu": "0", "job": "node"}, float64Pointer(17)),
+			}},
+		},
+		{
+			name:      "one-to-one with duplicates on the left side is ambiguous",
+			expr:      "$cpu + on(host) $info",
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			results:   Results{Values: Values{}},
+		},
+		{
+			name:      "one-to-one with duplicates on both sides is ambiguous",
+			expr:      "$info + on(region) $info",
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			results:   Results{Values: Values{}},
+		},
+		{
+			name:      "group_left with duplicates on the right side is ambiguous",
+			expr:      "$info + on(host) group_left $cpu",
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			results:   Results{Values: Values{}},
+		},
+		{
+			name:      "group_left without included labels keeps the labels of the left side",
+			expr:      "$cpu + on(host) group_left $info",
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{Values: Values{
+				makeNumber("", data.Labels{"host": "a", "cpu": "0", "job": "node"}, float64Pointer(11)),
+				makeNumber("", data.Labels{"host": "a", "cpu": "1", "job": "node"}, float64Pointer(12)),
+				makeNumber("", data.Labels{"host": "b", "cpu": "0", "job": "node"}, float64Pointer(23)),
+			}},
+		},
+		{
+			name:      "group_left producing the same labels is ambiguous",
+			expr:      "$cpu + on(host) group_left(cpu) $total",
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			results:   Results{Values: Values{}},
+		},
+		{
+			name:      "vector matching with a scalar is not allowed",
+			expr:      "$mem + on(host) 2",
+			newErrIs:  assert.Error,
+			execErrIs: assert.NoError,
+		},
+		{
+			name:      "label must not be in on and group_left",
+			expr:      "$cpu + on(host) group_left(host) $info",
+			newErrIs:  assert.Error,
+			execErrIs: assert.NoError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", vars)
+				tt.execErrIs(t, err)
+				if diff := cmp.Diff(tt.results, res, data.FrameTestCompareOptions()...); diff != "" {
+					t.Errorf("Result mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
@@ -15,6 +15,8 @@ type ThresholdCommand struct {
 	RefID         string
 	ThresholdFunc string
 	Conditions    []float64
+	// Invert negates the result of the threshold function.
+	Invert bool
 }
 
 const (
@@ -39,6 +41,9 @@ func NewThresholdCommand(refID, referenceVar, thresholdFunc string, conditions [
 
 type ThresholdConditionJSON struct {
 	Evaluator ConditionEvalJSON `json:"evaluator"`
+	// UnloadEvaluator is the condition for firing dimensions to recover. If it is not set,
+	// firing dimensions recover as soon as Evaluator is no longer met.
+	UnloadEvaluator *ConditionEvalJSON `json:"unloadEvaluator,omitempty"`
 }
 
 type ConditionEvalJSON struct {
@@ -46,8 +51,9 @@ type ConditionEvalJSON struct {
 	Type   string    `json:"type"` // e.g. "gt"
 }
 
-// UnmarshalResampleCommand creates a ResampleCMD from Grafana's frontend query.
-func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
+// UnmarshalThresholdCommand creates a ThresholdCommand from Grafana's frontend query. If the condition has an
+// unloading evaluator, a HysteresisCommand is created instead.
+func UnmarshalThresholdCommand(rn *rawNode) (Command, error) {
 	rawQuery := rn.Query
 
 	rawExpression, ok := rawQuery["expression"]
@@ -69,8 +75,13 @@ func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
 	}
 
 	for _, condition := range conditions {
-		if !IsSupportedThresholdFunc(condition.Evaluator.Type) {
-			return nil, fmt.Errorf("expected threshold function to be one of %s, got %s", strings.Join(supportedThresholdFuncs, ", "), condition.Evaluator.Type)
+		if err := validateThresholdEvaluator(condition.Evaluator); err != nil {
+			return nil, err
+		}
+		if condition.UnloadEvaluator != nil {
+			if err := validateThresholdEvaluator(*condition.UnloadEvaluator); err != nil {
+				return nil, fmt.Errorf("invalid unloading threshold: %w", err)
+			}
 		}
 	}
 
@@ -80,7 +91,36 @@ func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
 	}
 	firstCondition := conditions[0]
 
-	return NewThresholdCommand(rn.RefID, referenceVar, firstCondition.Evaluator.Type, firstCondition.Evaluator.Params)
+	loadingCmd, err := NewThresholdCommand(rn.RefID, referenceVar, firstCondition.Evaluator.Type, firstCondition.Evaluator.Params)
+	if err != nil {
+		return nil, err
+	}
+	if firstCondition.UnloadEvaluator == nil {
+		return loadingCmd, nil
+	}
+	unloadingCmd, err := NewThresholdCommand(rn.RefID, referenceVar, firstCondition.UnloadEvaluator.Type, firstCondition.UnloadEvaluator.Params)
+	if err != nil {
+		return nil, err
+	}
+	loadedDimensions, err := readLoadedDimensions(rn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read loaded dimensions for refId %v: %w", rn.RefID, err)
+	}
+	return NewHysteresisCommand(rn.RefID, referenceVar, *loadingCmd, *unloadingCmd, loadedDimensions)
+}
+
+func validateThresholdEvaluator(evaluator ConditionEvalJSON) error {
+	if !IsSupportedThresholdFunc(evaluator.Type) {
+		return fmt.Errorf("expected threshold function to be one of %s, got %s", strings.Join(supportedThresholdFuncs, ", "), evaluator.Type)
+	}
+	required := 1
+	if evaluator.Type == ThresholdIsWithinRange || evaluator.Type == ThresholdIsOutsideRange {
+		required = 2
+	}
+	if len(evaluator.Params) < required {
+		return fmt.Errorf("threshold function %s requires %d parameters, got %d", evaluator.Type, required, len(evaluator.Params))
+	}
+	return nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -94,6 +134,9 @@ func (tc *ThresholdCommand) Execute(ctx context.Context, now time.Time, vars mat
 	if err != nil {
 		return mathexp.Results{}, err
 	}
+	if tc.Invert {
+		mathExpression = fmt.Sprintf("!(%s)", mathExpression)
+	}
 
 	mathCommand, err := NewMathCommand(tc.ReferenceVar, mathExpression)
 	if err != nil {
@@ -0,0 +1,588 @@
+package framelog
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+var logger = log.New("live.framelog")
+
+// headerSize is the size of the header of records: the offset, the time in Unix nanoseconds, the length of the data
+// and the CRC-32 checksum of the data.
+const headerSize = 8 + 8 + 4 + 4
+
+const segmentExt = ".log"
+
+const (
+	// maintenanceInterval is how often retention is applied to all channels and idle segment files are closed.
+	maintenanceInterval = time.Minute
+	// idleTimeout is how long the segment file of a channel stays open after its last write, and how long a channel
+	// stays loaded after its last use.
+	idleTimeout = 5 * time.Minute
+)
+
+var ErrInvalidChannel = errors.New("invalid channel")
+
+// Options are the retention limits of the log.
+type Options struct {
+	// MaxSegmentBytes is the size above which the log of a channel starts a new segment.
+	MaxSegmentBytes int64
+	// MaxChannelBytes is the size above which the oldest segments of a channel are removed. Zero means unlimited.
+	MaxChannelBytes int64
+	// Retention is how long segments are kept after their last write. Zero means forever.
+	Retention time.Duration
+}
+
+// Entry is a record of the log.
+type Entry struct {
+	Offset uint64
+	Time   time.Time
+	Data   []byte
+}
+
+// Log is an append-only log of the frames of channels on the local disk. Each channel has its own sequence of
+// offsets starting at 1, and its records are stored in segments so that old records can be removed.
+type Log struct {
+	dir  string
+	opts Options
+	now  func() time.Time
+	// mu guards the loaded channels. The files of a channel are only accessed while holding the lock of the channel.
+	mu       sync.Mutex
+	channels map[string]*channelLog
+}
+
+// New returns a log that stores the channels in dir.
+func New(dir string, opts Options) *Log {
+	return &Log{
+		dir:      dir,
+		opts:     opts,
+		now:      time.Now,
+		channels: make(map[string]*channelLog),
+	}
+}
+
+// Append appends the data to the log of the channel, and returns its offset.
+func (l *Log) Append(orgID int64, channel string, data []byte) (uint64, error) {
+	cl, err := l.channel(orgID, channel)
+	if err != nil {
+		return 0, err
+	}
+	defer l.release(cl)
+	return cl.append(data, l.opts, l.now())
+}
+
+// Read returns at most limit entries of the channel from the offset. If the entry at the offset was removed by
+// retention, the entries start at the oldest entry that is kept.
+func (l *Log) Read(orgID int64, channel string, from uint64, limit int) ([]Entry, error) {
+	cl, err := l.channel(orgID, channel)
+	if err != nil {
+		return nil, err
+	}
+	defer l.release(cl)
+	return cl.read(from, limit, l.opts, l.now())
+}
+
+// NextOffset returns the offset of the next entry of the channel.
+func (l *Log) NextOffset(orgID int64, channel string) (uint64, error) {
+	cl, err := l.channel(orgID, channel)
+	if err != nil {
+		return 0, err
+	}
+	defer l.release(cl)
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if err := cl.load(); err != nil {
+		return 0, err
+	}
+	return cl.next, nil
+}
+
+// Run applies retention to all channels of the log directory, including the channels that are not written
+// anymore, and closes the segment files of idle channels periodically until the context is done.
+func (l *Log) Run(ctx context.Context) error {
+	ticker := time.NewTicker(maintenanceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.maintain(l.now()); err != nil {
+				logger.Error("Failed to maintain durable frame log", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// maintain applies retention to the channels of the log directory, closes the segment files of idle channels and
+// unloads the channels that were not used for the idle timeout. The channels are maintained one at a time, so that
+// the other channels can be written meanwhile.
+func (l *Log) maintain(now time.Time) error {
+	keys, errs, err := l.channelDirs()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		cl := l.acquire(key)
+		if err := cl.maintain(l.opts, now); err != nil {
+			errs = append(errs, err.Error())
+		}
+		l.release(cl)
+	}
+	if err := l.unloadIdle(now); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to maintain frame log: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// channelDirs returns the keys of the channel directories of the organization directories, and the errors of the
+// organization directories that could not be read.
+func (l *Log) channelDirs() ([]string, []string, error) {
+	orgs, err := os.ReadDir(l.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	var keys, errs []string
+	for _, org := range orgs {
+		if !org.IsDir() {
+			continue
+		}
+		if _, err := strconv.ParseInt(org.Name(), 10, 64); err != nil {
+			continue
+		}
+		channels, err := os.ReadDir(filepath.Join(l.dir, org.Name()))
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, channel := range channels {
+			if channel.IsDir() {
+				keys = append(keys, org.Name()+"/"+channel.Name())
+			}
+		}
+	}
+	return keys, errs, nil
+}
+
+// unloadIdle closes and unloads the channels that are not in use and were not used for the idle timeout.
+func (l *Log) unloadIdle(now time.Time) error {
+	var idle []*channelLog
+	l.mu.Lock()
+	for key, cl := range l.channels {
+		if cl.refs == 0 && now.Sub(cl.lastUsed) >= idleTimeout {
+			delete(l.channels, key)
+			idle = append(idle, cl)
+		}
+	}
+	l.mu.Unlock()
+
+	var errs []string
+	for _, cl := range idle {
+		if err := cl.close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Close closes the files of the log.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var errs []string
+	for _, cl := range l.channels {
+		if err := cl.close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	l.channels = make(map[string]*channelLog)
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close frame log: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// channel returns the log of the channel of the organization and marks it as used. The caller must release it.
+func (l *Log) channel(orgID int64, channel string) (*channelLog, error) {
+	name := url.PathEscape(channel)
+	if channel == "" || name == "." || name == ".." {
+		return nil, ErrInvalidChannel
+	}
+	cl := l.acquire(strconv.FormatInt(orgID, 10) + "/" + name)
+	l.mu.Lock()
+	cl.lastUsed = l.now()
+	l.mu.Unlock()
+	return cl, nil
+}
+
+// acquire returns the log of the channel with the key, the organization and the channel directory, and adds it to
+// the loaded channels if it is not loaded yet. Its segments are loaded by its first use. The channel is not unloaded
+// until it is released.
+func (l *Log) acquire(key string) *channelLog {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cl, ok := l.channels[key]
+	if !ok {
+		cl = &channelLog{dir: filepath.Join(l.dir, filepath.FromSlash(key)), next: 1}
+		l.channels[key] = cl
+	}
+	cl.refs++
+	return cl
+}
+
+func (l *Log) release(cl *channelLog) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cl.refs--
+}
+
+type segment struct {
+	base      uint64
+	path      string
+	size      int64
+	lastWrite time.Time
+}
+
+type channelLog struct {
+	// refs and lastUsed are guarded by the lock of the Log.
+	refs     int
+	lastUsed time.Time
+
+	mu       sync.Mutex
+	dir      string
+	loaded   bool
+	segments []*segment
+	active   *os.File
+	next     uint64
+}
+
+// load loads the segments of the directory if they are not loaded yet. A record that was partially written to the
+// last segment is removed. The caller must hold cl.mu.
+func (cl *channelLog) load() error {
+	if cl.loaded {
+		return nil
+	}
+	if err := os.MkdirAll(cl.dir, 0750); err != nil {
+		return err
+	}
+	files, err := os.ReadDir(cl.dir)
+	if err != nil {
+		return err
+	}
+	var segments []*segment
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), segmentExt) {
+			continue
+		}
+		base, err := strconv.ParseUint(strings.TrimSuffix(f.Name(), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			return err
+		}
+		segments = append(segments, &segment{
+			base:      base,
+			path:      filepath.Join(cl.dir, f.Name()),
+			size:      info.Size(),
+			lastWrite: info.ModTime(),
+		})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].base < segments[j].base })
+
+	if len(segments) > 0 {
+		last := segments[len(segments)-1]
+		next, size, err := scanSegment(last)
+		if err != nil {
+			return err
+		}
+		if size < last.size {
+			if err := os.Truncate(last.path, size); err != nil {
+				return fmt.Errorf("failed to truncate partial record of %s: %w", last.path, err)
+			}
+			last.size = size
+		}
+		cl.next = next
+	}
+	cl.segments = segments
+	cl.loaded = true
+	return nil
+}
+
+// scanSegment returns the offset after the last valid record of the segment, and the size of its valid records.
+func scanSegment(seg *segment) (uint64, int64, error) {
+	// nolint:gosec
+	f, err := os.Open(seg.path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	next, size := seg.base, int64(0)
+	r := bufio.NewReader(f)
+	for {
+		e, n, err := readRecord(r, seg.size)
+		if err != nil {
+			// The end of the file, or a record that was partially written.
+			return next, size, nil
+		}
+		next, size = e.Offset+1, size+int64(n)
+	}
+}
+
+func (cl *channelLog) append(data []byte, opts Options, now time.Time) (uint64, error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if err := cl.load(); err != nil {
+		return 0, err
+	}
+
+	recordSize := int64(headerSize + len(data))
+	if cl.active == nil || len(cl.segments) == 0 {
+		if err := cl.openActive(); err != nil {
+			return 0, err
+		}
+	}
+	if last := cl.segments[len(cl.segments)-1]; last.size > 0 && last.size+recordSize > opts.MaxSegmentBytes {
+		if err := cl.roll(); err != nil {
+			return 0, err
+		}
+	}
+
+	offset := cl.next
+	record := make([]byte, recordSize)
+	binary.BigEndian.PutUint64(record[0:8], offset)
+	binary.BigEndian.PutUint64(record[8:16], uint64(now.UnixNano()))
+	binary.BigEndian.PutUint32(record[16:20], uint32(len(data)))
+	binary.BigEndian.PutUint32(record[20:24], crc32.ChecksumIEEE(data))
+	copy(record[headerSize:], data)
+
+	last := cl.segments[len(cl.segments)-1]
+	if _, err := cl.active.Write(record); err != nil {
+		// Remove the part of the record that may have been written, so that the next records can be read.
+		_ = cl.active.Truncate(last.size)
+		return 0, err
+	}
+
+	last.size += recordSize
+	last.lastWrite = now
+	cl.next++
+
+	if err := cl.applyRetention(opts, now); err != nil {
+		return offset, err
+	}
+	return offset, nil
+}
+
+// openActive opens the last segment for appending, or creates the first segment.
+func (cl *channelLog) openActive() error {
+	if len(cl.segments) == 0 {
+		return cl.roll()
+	}
+	last := cl.segments[len(cl.segments)-1]
+	// nolint:gosec
+	f, err := os.OpenFile(last.path, os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	cl.active = f
+	return nil
+}
+
+// roll closes the active segment and starts a new segment at the next offset.
+func (cl *channelLog) roll() error {
+	if cl.active != nil {
+		if err := cl.active.Close(); err != nil {
+			return err
+		}
+		cl.active = nil
+	}
+	path := filepath.Join(cl.dir, fmt.Sprintf("%020d%s", cl.next, segmentExt))
+	// nolint:gosec
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	cl.active = f
+	cl.segments = append(cl.segments, &segment{base: cl.next, path: path})
+	return nil
+}
+
+// applyRetention removes the oldest segments while the channel is above its maximum size or they are older than
+// the retention. The active segment is never removed.
+func (cl *channelLog) applyRetention(opts Options, now time.Time) error {
+	var total int64
+	for _, seg := range cl.segments {
+		total += seg.size
+	}
+	for len(cl.segments) > 1 {
+		oldest := cl.segments[0]
+		tooBig := opts.MaxChannelBytes > 0 && total > opts.MaxChannelBytes
+		tooOld := opts.Retention > 0 && now.Sub(oldest.lastWrite) > opts.Retention
+		if !tooBig && !tooOld {
+			break
+		}
+		if err := os.Remove(oldest.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		total -= oldest.size
+		cl.segments = cl.segments[1:]
+	}
+	return nil
+}
+
+// maintain applies retention to the channel and closes its segment file if it is idle. If the last segment is
+// older than the retention, an empty segment is started at the next offset, so that the last segment can be removed
+// while the offsets of the channel continue after it.
+func (cl *channelLog) maintain(opts Options, now time.Time) error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if err := cl.load(); err != nil {
+		return err
+	}
+
+	if len(cl.segments) > 0 && opts.Retention > 0 {
+		if last := cl.segments[len(cl.segments)-1]; last.size > 0 && now.Sub(last.lastWrite) > opts.Retention {
+			if err := cl.roll(); err != nil {
+				return err
+			}
+		}
+	}
+	if cl.active != nil && now.Sub(cl.segments[len(cl.segments)-1].lastWrite) >= idleTimeout {
+		err := cl.active.Close()
+		cl.active = nil
+		if err != nil {
+			return err
+		}
+	}
+	return cl.applyRetention(opts, now)
+}
+
+func (cl *channelLog) read(from uint64, limit int, opts Options, now time.Time) ([]Entry, error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if err := cl.load(); err != nil {
+		return nil, err
+	}
+
+	if err := cl.applyRetention(opts, now); err != nil {
+		return nil, err
+	}
+	if from >= cl.next || limit <= 0 {
+		return nil, nil
+	}
+
+	start := 0
+	for i, seg := range cl.segments {
+		if seg.base <= from {
+			start = i
+		}
+	}
+
+	var entries []Entry
+	for _, seg := range cl.segments[start:] {
+		var err error
+		entries, err = readSegment(seg, from, limit, entries)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) >= limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// readSegment appends the entries of the segment from the offset to entries, until there are limit entries.
+func readSegment(seg *segment, from uint64, limit int, entries []Entry) ([]Entry, error) {
+	// nolint:gosec
+	f, err := os.Open(seg.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	r := bufio.NewReader(io.LimitReader(f, seg.size))
+	for len(entries) < limit {
+		e, _, err := readRecord(r, seg.size)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", seg.path, err)
+		}
+		if e.Offset >= from {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+var errCorruptRecord = errors.New("corrupt record")
+
+// readRecord reads the next record. Records whose data is larger than maxSize are corrupt.
+func readRecord(r io.Reader, maxSize int64) (Entry, int, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return Entry{}, 0, errCorruptRecord
+		}
+		return Entry{}, 0, err
+	}
+	size := binary.BigEndian.Uint32(header[16:20])
+	if int64(size) > maxSize {
+		return Entry{}, 0, errCorruptRecord
+	}
+	e := Entry{
+		Offset: binary.BigEndian.Uint64(header[0:8]),
+		Time:   time.Unix(0, int64(binary.BigEndian.Uint64(header[8:16]))),
+		Data:   make([]byte, size),
+	}
+	if _, err := io.ReadFull(r, e.Data); err != nil {
+		return Entry{}, 0, errCorruptRecord
+	}
+	if crc32.ChecksumIEEE(e.Data) != binary.BigEndian.Uint32(header[20:24]) {
+		return Entry{}, 0, errCorruptRecord
+	}
+	return e, headerSize + len(e.Data), nil
+}
+
+func (cl *channelLog) close() error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if cl.active == nil {
+		return nil
+	}
+	err := cl.active.Close()
+	cl.active = nil
+	return err
+}
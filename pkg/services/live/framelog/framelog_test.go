@@ -0,0 +1,265 @@
+package framelog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog(t *testing.T) {
+	t.Run("should read the entries from an offset", func(t *testing.T) {
+		l := New(t.TempDir(), Options{MaxSegmentBytes: 1024})
+		t.Cleanup(func() { _ = l.Close() })
+
+		appendEntries(t, l, "test/channel", 5)
+
+		entries, err := l.Read(1, "test/channel", 3, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		require.Equal(t, uint64(3), entries[0].Offset)
+		require.Equal(t, "frame 3", string(entries[0].Data))
+
+		entries, err = l.Read(1, "test/channel", 1, 2)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, uint64(2), entries[1].Offset)
+
+		entries, err = l.Read(1, "test/channel", 6, 10)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+
+		next, err := l.NextOffset(1, "test/channel")
+		require.NoError(t, err)
+		require.Equal(t, uint64(6), next)
+	})
+
+	t.Run("should keep channels and organizations apart", func(t *testing.T) {
+		l := New(t.TempDir(), Options{MaxSegmentBytes: 1024})
+		t.Cleanup(func() { _ = l.Close() })
+
+		appendEntries(t, l, "test/a", 2)
+		offset, err := l.Append(2, "test/a", []byte("other org"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), offset)
+
+		entries, err := l.Read(2, "test/a", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, "other org", string(entries[0].Data))
+
+		_, err = l.Append(1, "..", []byte("frame"))
+		require.ErrorIs(t, err, ErrInvalidChannel)
+	})
+
+	t.Run("should read entries across segments", func(t *testing.T) {
+		dir := t.TempDir()
+		l := New(dir, Options{MaxSegmentBytes: 2 * (headerSize + 7)})
+		t.Cleanup(func() { _ = l.Close() })
+
+		appendEntries(t, l, "test/channel", 5)
+		require.Len(t, segmentFiles(t, dir, "test%2Fchannel"), 3)
+
+		entries, err := l.Read(1, "test/channel", 2, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+		for i, e := range entries {
+			require.Equal(t, uint64(i+2), e.Offset)
+		}
+	})
+
+	t.Run("should remove the oldest segments above the maximum size", func(t *testing.T) {
+		dir := t.TempDir()
+		l := New(dir, Options{MaxSegmentBytes: 2 * (headerSize + 7), MaxChannelBytes: 4 * (headerSize + 7)})
+		t.Cleanup(func() { _ = l.Close() })
+
+		appendEntries(t, l, "test/channel", 6)
+		require.Len(t, segmentFiles(t, dir, "test%2Fchannel"), 2)
+
+		// The entries before the oldest segment are gone.
+		entries, err := l.Read(1, "test/channel", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+		require.Equal(t, uint64(3), entries[0].Offset)
+	})
+
+	t.Run("should remove segments older than the retention", func(t *testing.T) {
+		dir := t.TempDir()
+		now := time.Now()
+		l := New(dir, Options{MaxSegmentBytes: 2 * (headerSize + 7), Retention: time.Hour})
+		l.now = func() time.Time { return now }
+		t.Cleanup(func() { _ = l.Close() })
+
+		appendEntries(t, l, "test/channel", 4)
+		now = now.Add(2 * time.Hour)
+		appendEntries(t, l, "test/channel", 1)
+
+		entries, err := l.Read(1, "test/channel", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, uint64(5), entries[0].Offset)
+	})
+
+	t.Run("should continue the offsets after reopening", func(t *testing.T) {
+		dir := t.TempDir()
+		l := New(dir, Options{MaxSegmentBytes: 1024})
+		appendEntries(t, l, "test/channel", 3)
+		require.NoError(t, l.Close())
+
+		l = New(dir, Options{MaxSegmentBytes: 1024})
+		t.Cleanup(func() { _ = l.Close() })
+		offset, err := l.Append(1, "test/channel", []byte("frame 4"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(4), offset)
+
+		entries, err := l.Read(1, "test/channel", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+	})
+
+	t.Run("should remove a partially written record when reopening", func(t *testing.T) {
+		dir := t.TempDir()
+		l := New(dir, Options{MaxSegmentBytes: 1024})
+		appendEntries(t, l, "test/channel", 2)
+		require.NoError(t, l.Close())
+
+		files := segmentFiles(t, dir, "test%2Fchannel")
+		require.Len(t, files, 1)
+		f, err := os.OpenFile(files[0], os.O_APPEND|os.O_WRONLY, 0640)
+		require.NoError(t, err)
+		_, err = f.Write([]byte{0, 0, 0, 0, 0, 0, 0, 3, 1, 2})
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		l = New(dir, Options{MaxSegmentBytes: 1024})
+		t.Cleanup(func() { _ = l.Close() })
+		offset, err := l.Append(1, "test/channel", []byte("frame 3"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), offset)
+
+		entries, err := l.Read(1, "test/channel", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		require.Equal(t, "frame 3", string(entries[2].Data))
+	})
+
+	t.Run("should close the segment files of idle channels", func(t *testing.T) {
+		now := time.Now()
+		l := New(t.TempDir(), Options{MaxSegmentBytes: 1024})
+		l.now = func() time.Time { return now }
+		t.Cleanup(func() { _ = l.Close() })
+
+		appendEntries(t, l, "test/a", 2)
+		now = now.Add(idleTimeout / 2)
+		_, err := l.Read(1, "test/a", 1, 10)
+		require.NoError(t, err)
+		now = now.Add(idleTimeout / 2)
+		appendEntries(t, l, "test/b", 1)
+		require.NoError(t, l.maintain(now))
+		require.Nil(t, l.channels["1/test%2Fa"].active)
+		require.NotNil(t, l.channels["1/test%2Fb"].active)
+
+		// The segment file is opened again by the next write.
+		appendEntries(t, l, "test/a", 1)
+		entries, err := l.Read(1, "test/a", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+	})
+
+	t.Run("should unload the channels that are not used", func(t *testing.T) {
+		dir := t.TempDir()
+		l := New(dir, Options{MaxSegmentBytes: 1024})
+		appendEntries(t, l, "test/a", 2)
+		require.NoError(t, l.Close())
+
+		now := time.Now()
+		l = New(dir, Options{MaxSegmentBytes: 1024})
+		l.now = func() time.Time { return now }
+		t.Cleanup(func() { _ = l.Close() })
+
+		// The channels that are loaded for maintenance only are unloaded right away.
+		require.NoError(t, l.maintain(now))
+		require.Empty(t, l.channels)
+
+		appendEntries(t, l, "test/b", 1)
+		require.NoError(t, l.maintain(now))
+		require.Contains(t, l.channels, "1/test%2Fb")
+
+		now = now.Add(idleTimeout)
+		require.NoError(t, l.maintain(now))
+		require.Empty(t, l.channels)
+
+		// The offsets continue after the channel is loaded again.
+		offset, err := l.Append(1, "test/b", []byte("frame 2"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), offset)
+		offset, err = l.Append(1, "test/a", []byte("frame 3"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), offset)
+	})
+
+	t.Run("should not unload channels in use", func(t *testing.T) {
+		now := time.Now()
+		l := New(t.TempDir(), Options{MaxSegmentBytes: 1024})
+		l.now = func() time.Time { return now }
+		t.Cleanup(func() { _ = l.Close() })
+
+		cl, err := l.channel(1, "test/a")
+		require.NoError(t, err)
+		now = now.Add(idleTimeout)
+		require.NoError(t, l.maintain(now))
+		require.Same(t, cl, l.channels["1/test%2Fa"])
+		l.release(cl)
+
+		require.NoError(t, l.maintain(now))
+		require.Empty(t, l.channels)
+	})
+
+	t.Run("should apply retention to the channels that are not written anymore", func(t *testing.T) {
+		dir := t.TempDir()
+		l := New(dir, Options{MaxSegmentBytes: 2 * (headerSize + 7)})
+		appendEntries(t, l, "test/channel", 3)
+		require.NoError(t, l.Close())
+		require.Len(t, segmentFiles(t, dir, "test%2Fchannel"), 2)
+
+		l = New(dir, Options{MaxSegmentBytes: 2 * (headerSize + 7), Retention: time.Hour})
+		l.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		require.NoError(t, l.maintain(l.now()))
+		require.NoError(t, l.Close())
+
+		// The frames are removed, and the offsets continue after them.
+		files := segmentFiles(t, dir, "test%2Fchannel")
+		require.Len(t, files, 1)
+		require.Equal(t, fmt.Sprintf("%020d%s", 4, segmentExt), filepath.Base(files[0]))
+
+		l = New(dir, Options{MaxSegmentBytes: 1024})
+		t.Cleanup(func() { _ = l.Close() })
+		entries, err := l.Read(1, "test/channel", 1, 10)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+		offset, err := l.Append(1, "test/channel", []byte("frame 4"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(4), offset)
+	})
+}
+
+func appendEntries(t *testing.T, l *Log, channel string, n int) {
+	t.Helper()
+	next, err := l.NextOffset(1, channel)
+	require.NoError(t, err)
+	for i := 0; i < n; i++ {
+		offset, err := l.Append(1, channel, []byte(fmt.Sprintf("frame %d", next+uint64(i))))
+		require.NoError(t, err)
+		require.Equal(t, next+uint64(i), offset)
+	}
+}
+
+func segmentFiles(t *testing.T, dir string, channel string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "1", channel, "*"+segmentExt))
+	require.NoError(t, err)
+	return files
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/live/database"
 	"github.com/grafana/grafana/pkg/services/live/features"
+	"github.com/grafana/grafana/pkg/services/live/framelog"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/liveplugin"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
@@ -197,6 +198,11 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 				SecretsService: g.SecretsService,
 			}
 			g.pipelineStorage = storage
+			g.frameLog = framelog.New(cfg.LiveDurableOutputPath, framelog.Options{
+				MaxSegmentBytes: cfg.LiveDurableOutputSegmentSize,
+				MaxChannelBytes: cfg.LiveDurableOutputMaxChannelSize,
+				Retention:       cfg.LiveDurableOutputRetention,
+			})
 			builder = &pipeline.StorageRuleBuilder{
 				Node:                 node,
 				ManagedStream:        g.ManagedStreamRunner,
@@ -204,6 +210,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 				Storage:              storage,
 				ChannelHandlerGetter: g,
 				SecretsService:       g.SecretsService,
+				FrameLog:             g.frameLog,
 			}
 		}
 		channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
@@ -429,6 +436,7 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	frameLog            *framelog.Log
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -489,6 +497,17 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		})
 	}
 
+	if g.frameLog != nil {
+		eGroup.Go(func() error {
+			return g.frameLog.Run(eCtx)
+		})
+		defer func() {
+			if err := g.frameLog.Close(); err != nil {
+				logger.Error("Error closing durable frame log", "error", err)
+			}
+		}()
+	}
+
 	return eGroup.Wait()
 }
 
@@ -1158,6 +1177,7 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext)
 		FrameStorage:         pipeline.NewFrameStorage(),
 		Storage:              storage,
 		ChannelHandlerGetter: g,
+		FrameLog:             g.frameLog,
 	}
 	channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
 	pipe, err := pipeline.New(channelRuleGetter)
@@ -40,6 +40,8 @@ type ConverterConfig struct {
 	ExactJsonConverterConfig  *ExactJsonConverterConfig  `json:"jsonExact,omitempty"`
 	AutoInfluxConverterConfig *AutoInfluxConverterConfig `json:"influxAuto,omitempty"`
 	JsonFrameConverterConfig  *JsonFrameConverterConfig  `json:"jsonFrame,omitempty"`
+	PrometheusConverterConfig *PrometheusConverterConfig `json:"prometheus,omitempty"`
+	CSVConverterConfig        *CSVConverterConfig        `json:"csv,omitempty"`
 }
 
 type DropFieldsFrameProcessorConfig struct {
@@ -50,11 +52,53 @@ type KeepFieldsFrameProcessorConfig struct {
 	FieldNames []string `json:"fieldNames"`
 }
 
+type RenameFieldsFrameProcessorConfig struct {
+	// FieldNames maps the names of fields to their new names.
+	FieldNames map[string]string `json:"fieldNames,omitempty"`
+	// LabelNames maps the names of labels to their new names.
+	LabelNames map[string]string `json:"labelNames,omitempty"`
+	// Labels are added to the labels of the fields, except time fields.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type ExpressionFrameProcessorConfig struct {
+	// FieldName is the name of the computed field. A field with the same name is replaced.
+	FieldName string `json:"fieldName"`
+	// Expression is a math expression of the fields of the row, like $temperature * 1.8 + 32.
+	// Fields with spaces in their names are written as ${field name}.
+	Expression string `json:"expression"`
+}
+
+type ConvertUnitsFrameProcessorConfig struct {
+	Conversions []UnitConversion `json:"conversions"`
+}
+
+// UnitConversion converts the values of a field between two units of the same kind.
+type UnitConversion struct {
+	FieldName string `json:"fieldName"`
+	// From is the unit of the values. By default, it is the unit of the field config.
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+}
+
+type DownsampleFrameProcessorConfig struct {
+	// IntervalMilliseconds is the size of the time buckets.
+	IntervalMilliseconds int64 `json:"intervalMilliseconds"`
+	// Aggregation of the numeric fields in a bucket: mean (default), min, max or last.
+	Aggregation string `json:"aggregation,omitempty"`
+	// FieldAggregations sets the aggregation of fields by name.
+	FieldAggregations map[string]string `json:"fieldAggregations,omitempty"`
+}
+
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                        string                            `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig   *DropFieldsFrameProcessorConfig   `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig   *KeepFieldsFrameProcessorConfig   `json:"keepFields,omitempty"`
+	MultipleProcessorConfig     *MultipleFrameProcessorConfig     `json:"multiple,omitempty"`
+	RenameFieldsProcessorConfig *RenameFieldsFrameProcessorConfig `json:"renameFields,omitempty"`
+	ExpressionProcessorConfig   *ExpressionFrameProcessorConfig   `json:"expression,omitempty"`
+	ConvertUnitsProcessorConfig *ConvertUnitsFrameProcessorConfig `json:"convertUnits,omitempty"`
+	DownsampleProcessorConfig   *DownsampleFrameProcessorConfig   `json:"downsample,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -83,9 +127,15 @@ type MultipleSubscriberConfig struct {
 	Subscribers []SubscriberConfig `json:"subscribers"`
 }
 
+type DurableSubscriberConfig struct {
+	// MaxFrames is the maximum number of frames sent in the subscribe reply. Default is 1000.
+	MaxFrames int `json:"maxFrames,omitempty"`
+}
+
 type SubscriberConfig struct {
 	Type                     string                    `json:"type" ts_type:"Omit<keyof SubscriberConfig, 'type'>"`
 	MultipleSubscriberConfig *MultipleSubscriberConfig `json:"multiple,omitempty"`
+	DurableSubscriberConfig  *DurableSubscriberConfig  `json:"durable,omitempty"`
 }
 
 // RedirectDataOutputConfig ...
@@ -152,4 +202,22 @@ type AutoInfluxConverterConfig struct {
 
 type JsonFrameConverterConfig struct{}
 
+type PrometheusConverterConfig struct {
+	// OpenMetrics parses the input as OpenMetrics text. Input that ends with
+	// "# EOF" is always parsed as OpenMetrics text.
+	OpenMetrics bool `json:"openMetrics,omitempty"`
+}
+
+type CSVConverterConfig struct {
+	// Delimiter is the character between the values, a comma by default.
+	Delimiter string `json:"delimiter,omitempty"`
+	// Header is the names of the columns if the input has no header line.
+	Header []string `json:"header,omitempty"`
+	// TimeField is the name of the time column. By default, it is the column
+	// named time, timestamp or ts, or the first column with RFC 3339 times.
+	TimeField string `json:"timeField,omitempty"`
+	// LabelFields are the names of the columns used as the labels of the other fields.
+	LabelFields []string `json:"labelFields,omitempty"`
+}
+
 type ManagedStreamOutputConfig struct{}
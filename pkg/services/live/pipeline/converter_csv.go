@@ -0,0 +1,272 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// CSVConverter decodes CSV lines to frames. The first line is the header unless
+// the names of the columns are set in the config. The type of each column is
+// inferred from its values, and the columns listed as label fields become the
+// labels of the other fields: the lines are grouped to a frame per set of labels.
+type CSVConverter struct {
+	config      CSVConverterConfig
+	nowTimeFunc func() time.Time
+}
+
+// NewCSVConverter creates new CSVConverter.
+func NewCSVConverter(c CSVConverterConfig) *CSVConverter {
+	return &CSVConverter{config: c}
+}
+
+const ConverterTypeCSV = "csv"
+
+func (c *CSVConverter) Type() string {
+	return ConverterTypeCSV
+}
+
+// timeColumnNames are the names of the columns that are used as the time field
+// if the config has no time field.
+var timeColumnNames = []string{"time", "timestamp", "ts"}
+
+var errCSVInvalidDelimiter = errors.New("delimiter must be a single character")
+
+func (c *CSVConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	r := csv.NewReader(bytes.NewReader(body))
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = len(c.config.Header)
+	if c.config.Delimiter != "" {
+		delimiter, size := utf8.DecodeRuneInString(c.config.Delimiter)
+		if size != len(c.config.Delimiter) {
+			return nil, errCSVInvalidDelimiter
+		}
+		r.Comma = delimiter
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing CSV: %w", err)
+	}
+
+	header := c.config.Header
+	if len(header) == 0 {
+		if len(records) == 0 {
+			return nil, nil
+		}
+		header, records = records[0], records[1:]
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	nowTimeFunc := c.nowTimeFunc
+	if nowTimeFunc == nil {
+		nowTimeFunc = time.Now
+	}
+
+	labelColumns := map[int]struct{}{}
+	for _, name := range c.config.LabelFields {
+		i := csvColumnIndex(header, name)
+		if i < 0 {
+			return nil, fmt.Errorf("label field %s is not a column", name)
+		}
+		labelColumns[i] = struct{}{}
+	}
+	timeColumn, err := c.timeColumn(header, records, labelColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	columnTypes := make([]data.FieldType, len(header))
+	for i := range header {
+		if _, ok := labelColumns[i]; ok || i == timeColumn {
+			continue
+		}
+		columnTypes[i] = inferCSVColumnType(records, i)
+	}
+
+	// Group the lines by their labels, in the order of their first line.
+	var groups [][][]string
+	groupLabels := map[string]int{}
+	for _, record := range records {
+		key := labelsOfRecord(header, record, c.config.LabelFields).String()
+		g, ok := groupLabels[key]
+		if !ok {
+			g = len(groups)
+			groupLabels[key] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], record)
+	}
+
+	channelFrames := make([]*ChannelFrame, 0, len(groups))
+	for _, group := range groups {
+		lbls := labelsOfRecord(header, group[0], c.config.LabelFields)
+		fields := make([]*data.Field, 0, len(header)+1)
+
+		timeField := data.NewFieldFromFieldType(data.FieldTypeTime, len(group))
+		timeField.Name = "time"
+		if timeColumn >= 0 {
+			timeField.Name = header[timeColumn]
+		}
+		for row, record := range group {
+			t := nowTimeFunc()
+			if timeColumn >= 0 {
+				var ok bool
+				if t, ok = parseCSVTime(record[timeColumn]); !ok {
+					return nil, fmt.Errorf("invalid time %q in column %s", record[timeColumn], header[timeColumn])
+				}
+			}
+			timeField.Set(row, t)
+		}
+		fields = append(fields, timeField)
+
+		for i, name := range header {
+			if _, ok := labelColumns[i]; ok || i == timeColumn {
+				continue
+			}
+			field := data.NewFieldFromFieldType(columnTypes[i], len(group))
+			field.Name = name
+			if len(lbls) > 0 {
+				field.Labels = lbls
+			}
+			for row, record := range group {
+				// Empty values stay null.
+				if record[i] != "" {
+					field.Set(row, parseCSVValue(record[i], columnTypes[i]))
+				}
+			}
+			fields = append(fields, field)
+		}
+
+		channelFrames = append(channelFrames, &ChannelFrame{
+			Channel: "",
+			Frame:   data.NewFrame(vars.Path, fields...),
+		})
+	}
+	return channelFrames, nil
+}
+
+// timeColumn returns the index of the time column, or -1 if the lines have no
+// time column. Without a time field in the config, the time column is the
+// first column with a usual name of time columns, or the first column with
+// RFC 3339 times.
+func (c *CSVConverter) timeColumn(header []string, records [][]string, labelColumns map[int]struct{}) (int, error) {
+	if c.config.TimeField != "" {
+		i := csvColumnIndex(header, c.config.TimeField)
+		if i < 0 {
+			return -1, fmt.Errorf("time field %s is not a column", c.config.TimeField)
+		}
+		return i, nil
+	}
+	for i, name := range header {
+		if _, ok := labelColumns[i]; ok {
+			continue
+		}
+		for _, timeName := range timeColumnNames {
+			if strings.EqualFold(name, timeName) {
+				return i, nil
+			}
+		}
+	}
+	for i := range header {
+		if _, ok := labelColumns[i]; ok {
+			continue
+		}
+		isTime := true
+		for _, record := range records {
+			if _, err := time.Parse(time.RFC3339Nano, record[i]); err != nil {
+				isTime = false
+				break
+			}
+		}
+		if isTime {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// inferCSVColumnType returns the type of the column: a number if all its values
+// are numbers, a boolean if all its values are booleans, and a string otherwise.
+// Empty values are nulls, and do not change the type.
+func inferCSVColumnType(records [][]string, column int) data.FieldType {
+	isNumber, isBool := true, true
+	for _, record := range records {
+		v := record[column]
+		if v == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			isNumber = false
+		}
+		if _, err := strconv.ParseBool(v); err != nil {
+			isBool = false
+		}
+	}
+	switch {
+	case isNumber:
+		return data.FieldTypeNullableFloat64
+	case isBool:
+		return data.FieldTypeNullableBool
+	default:
+		return data.FieldTypeNullableString
+	}
+}
+
+// parseCSVValue returns the value for a field of the type.
+func parseCSVValue(v string, fieldType data.FieldType) interface{} {
+	switch fieldType {
+	case data.FieldTypeNullableFloat64:
+		f, _ := strconv.ParseFloat(v, 64)
+		return &f
+	case data.FieldTypeNullableBool:
+		b, _ := strconv.ParseBool(v)
+		return &b
+	default:
+		return &v
+	}
+}
+
+// parseCSVTime parses RFC 3339 times, and Unix times in seconds or milliseconds.
+// Numbers from 1e11 are milliseconds.
+func parseCSVTime(v string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, true
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, false
+	}
+	if math.Abs(f) >= 1e11 {
+		return time.UnixMilli(int64(f)).UTC(), true
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC(), true
+}
+
+func labelsOfRecord(header []string, record []string, labelFields []string) data.Labels {
+	lbls := data.Labels{}
+	for _, name := range labelFields {
+		lbls[name] = record[csvColumnIndex(header, name)]
+	}
+	return lbls
+}
+
+func csvColumnIndex(values []string, v string) int {
+	for i, value := range values {
+		if value == v {
+			return i
+		}
+	}
+	return -1
+}
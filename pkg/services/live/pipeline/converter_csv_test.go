@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVConverter_Convert(t *testing.T) {
+	now := time.Date(2021, 01, 01, 12, 12, 12, 0, time.UTC)
+	convert := func(t *testing.T, config CSVConverterConfig, body string) []*ChannelFrame {
+		t.Helper()
+		converter := NewCSVConverter(config)
+		converter.nowTimeFunc = func() time.Time { return now }
+		channelFrames, err := converter.Convert(context.Background(), Vars{Path: "sensors"}, []byte(body))
+		require.NoError(t, err)
+		return channelFrames
+	}
+
+	t.Run("should infer the types of the columns", func(t *testing.T) {
+		channelFrames := convert(t, CSVConverterConfig{}, `time,temperature,online,room
+2021-01-01T12:00:00Z,21.5,true,kitchen
+2021-01-01T12:01:00Z,,false,hall
+`)
+		require.Len(t, channelFrames, 1)
+		require.Empty(t, channelFrames[0].Channel)
+		frame := channelFrames[0].Frame
+		require.Equal(t, "sensors", frame.Name)
+		require.Equal(t, 2, frame.Rows())
+		require.Len(t, frame.Fields, 4)
+		require.Equal(t, data.FieldTypeTime, frame.Fields[0].Type())
+		require.Equal(t, time.Date(2021, 01, 01, 12, 1, 0, 0, time.UTC), frame.Fields[0].At(1))
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		require.Equal(t, 21.5, *frame.Fields[1].At(0).(*float64))
+		require.Nil(t, frame.Fields[1].At(1))
+		require.Equal(t, data.FieldTypeNullableBool, frame.Fields[2].Type())
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[3].Type())
+		require.Equal(t, "hall", *frame.Fields[3].At(1).(*string))
+	})
+
+	t.Run("should group the lines by their labels", func(t *testing.T) {
+		channelFrames := convert(t, CSVConverterConfig{
+			Delimiter:   ";",
+			Header:      []string{"ts", "host", "cpu"},
+			LabelFields: []string{"host"},
+		}, `1609502400;a;10
+1609502400000;b;20
+1609502460;a;30
+`)
+		require.Len(t, channelFrames, 2)
+		frame := channelFrames[0].Frame
+		require.Equal(t, 2, frame.Rows())
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, "ts", frame.Fields[0].Name)
+		require.Equal(t, time.Unix(1609502460, 0).UTC(), frame.Fields[0].At(1))
+		require.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+		require.Equal(t, 30.0, *frame.Fields[1].At(1).(*float64))
+
+		frame = channelFrames[1].Frame
+		require.Equal(t, time.UnixMilli(1609502400000).UTC(), frame.Fields[0].At(0))
+		require.Equal(t, data.Labels{"host": "b"}, frame.Fields[1].Labels)
+	})
+
+	t.Run("should use the current time without a time column", func(t *testing.T) {
+		channelFrames := convert(t, CSVConverterConfig{}, "value\n1\n")
+		require.Len(t, channelFrames, 1)
+		frame := channelFrames[0].Frame
+		require.Equal(t, "time", frame.Fields[0].Name)
+		require.Equal(t, now, frame.Fields[0].At(0))
+	})
+
+	t.Run("should return an error for invalid input", func(t *testing.T) {
+		converter := NewCSVConverter(CSVConverterConfig{TimeField: "time"})
+		_, err := converter.Convert(context.Background(), Vars{}, []byte("time,value\nyesterday,1\n"))
+		require.Error(t, err)
+
+		converter = NewCSVConverter(CSVConverterConfig{LabelFields: []string{"host"}})
+		_, err = converter.Convert(context.Background(), Vars{}, []byte("time,value\n1,1\n"))
+		require.Error(t, err)
+
+		converter = NewCSVConverter(CSVConverterConfig{})
+		_, err = converter.Convert(context.Background(), Vars{}, []byte("time,value\n1,1,1\n"))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,172 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/textparse"
+)
+
+// PrometheusConverter decodes the Prometheus text exposition format or the
+// OpenMetrics text format, and transforms it to a ChannelFrame per metric
+// family where Channel is constructed from original channel + / + <family_name>.
+// Each frame has a time field, and a value field with the labels of each series
+// of the family.
+type PrometheusConverter struct {
+	config      PrometheusConverterConfig
+	nowTimeFunc func() time.Time
+}
+
+// NewPrometheusConverter creates new PrometheusConverter.
+func NewPrometheusConverter(c PrometheusConverterConfig) *PrometheusConverter {
+	return &PrometheusConverter{config: c}
+}
+
+const ConverterTypePrometheus = "prometheus"
+
+func (c *PrometheusConverter) Type() string {
+	return ConverterTypePrometheus
+}
+
+// familySuffixes are the suffixes of the series of histograms, summaries,
+// counters and info metrics.
+var familySuffixes = []string{"_bucket", "_count", "_sum", "_total", "_created", "_info", "_gcount", "_gsum"}
+
+type promSeries struct {
+	name   string
+	labels data.Labels
+	values map[int64]float64
+}
+
+type promFamily struct {
+	name   string
+	series []*promSeries
+	index  map[string]int
+	times  map[int64]struct{}
+}
+
+func (c *PrometheusConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	contentType := "text/plain"
+	if c.config.OpenMetrics || bytes.HasSuffix(bytes.TrimSpace(body), []byte("# EOF")) {
+		contentType = "application/openmetrics-text"
+	}
+	parser := textparse.New(body, contentType)
+	nowTimeFunc := c.nowTimeFunc
+	if nowTimeFunc == nil {
+		nowTimeFunc = time.Now
+	}
+	// Samples without a timestamp get the time of the push.
+	now := nowTimeFunc().UnixMilli()
+
+	types := map[string]struct{}{}
+	families := map[string]*promFamily{}
+	var order []string
+	for {
+		entry, err := parser.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error parsing metrics: %w", err)
+		}
+		switch entry {
+		case textparse.EntryType:
+			name, _ := parser.Type()
+			types[string(name)] = struct{}{}
+		case textparse.EntrySeries:
+			_, ts, value := parser.Series()
+			var lbls labels.Labels
+			parser.Metric(&lbls)
+			name := lbls.Get(labels.MetricName)
+			familyName := promFamilyName(name, types)
+
+			f, ok := families[familyName]
+			if !ok {
+				f = &promFamily{name: familyName, index: map[string]int{}, times: map[int64]struct{}{}}
+				families[familyName] = f
+				order = append(order, familyName)
+			}
+			key := lbls.String()
+			i, ok := f.index[key]
+			if !ok {
+				seriesLabels := data.Labels{}
+				for _, l := range lbls {
+					if l.Name != labels.MetricName {
+						seriesLabels[l.Name] = l.Value
+					}
+				}
+				i = len(f.series)
+				f.index[key] = i
+				f.series = append(f.series, &promSeries{name: name, labels: seriesLabels, values: map[int64]float64{}})
+			}
+			t := now
+			if ts != nil {
+				t = *ts
+			}
+			f.series[i].values[t] = value
+			f.times[t] = struct{}{}
+		}
+	}
+
+	channelFrames := make([]*ChannelFrame, 0, len(order))
+	for _, name := range order {
+		channelFrames = append(channelFrames, &ChannelFrame{
+			Channel: vars.Channel + "/" + name,
+			Frame:   families[name].frame(),
+		})
+	}
+	return channelFrames, nil
+}
+
+// promFamilyName returns the name of the family of the series: the name of the
+// series without the suffix of its type if the family has a type, or the name
+// of the series otherwise.
+func promFamilyName(name string, types map[string]struct{}) string {
+	if _, ok := types[name]; ok {
+		return name
+	}
+	for _, suffix := range familySuffixes {
+		if !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		if _, ok := types[strings.TrimSuffix(name, suffix)]; ok {
+			return strings.TrimSuffix(name, suffix)
+		}
+	}
+	return name
+}
+
+// frame returns a frame with a row per timestamp of the family.
+func (f *promFamily) frame() *data.Frame {
+	timestamps := make([]int64, 0, len(f.times))
+	for t := range f.times {
+		timestamps = append(timestamps, t)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	timeValues := make([]time.Time, len(timestamps))
+	for i, t := range timestamps {
+		timeValues[i] = time.UnixMilli(t).UTC()
+	}
+	fields := make([]*data.Field, 0, len(f.series)+1)
+	fields = append(fields, data.NewField("time", nil, timeValues))
+	for _, s := range f.series {
+		values := make([]*float64, len(timestamps))
+		for i, t := range timestamps {
+			if v, ok := s.values[t]; ok {
+				v := v
+				values[i] = &v
+			}
+		}
+		fields = append(fields, data.NewField(s.name, s.labels, values))
+	}
+	return data.NewFrame(f.name, fields...)
+}
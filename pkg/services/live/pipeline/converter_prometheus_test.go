@@ -0,0 +1,94 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusConverter_Convert(t *testing.T) {
+	now := time.Date(2021, 01, 01, 12, 12, 12, 0, time.UTC)
+	convert := func(t *testing.T, config PrometheusConverterConfig, body string) []*ChannelFrame {
+		t.Helper()
+		converter := NewPrometheusConverter(config)
+		converter.nowTimeFunc = func() time.Time { return now }
+		channelFrames, err := converter.Convert(context.Background(), Vars{Channel: "stream/test/metrics"}, []byte(body))
+		require.NoError(t, err)
+		return channelFrames
+	}
+
+	t.Run("should convert the exposition format to a frame per family", func(t *testing.T) {
+		channelFrames := convert(t, PrometheusConverterConfig{}, `# HELP http_requests_total The number of requests.
+# TYPE http_requests_total counter
+http_requests_total{code="200",method="get"} 1027
+http_requests_total{code="400",method="get"} 3
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 10
+request_duration_seconds_bucket{le="+Inf"} 12
+request_duration_seconds_sum 1.5
+request_duration_seconds_count 12
+temperature 21.5
+`)
+		require.Len(t, channelFrames, 3)
+		require.Equal(t, "stream/test/metrics/http_requests_total", channelFrames[0].Channel)
+		require.Equal(t, "stream/test/metrics/request_duration_seconds", channelFrames[1].Channel)
+		require.Equal(t, "stream/test/metrics/temperature", channelFrames[2].Channel)
+
+		frame := channelFrames[0].Frame
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, now, frame.Fields[0].At(0))
+		require.Equal(t, "http_requests_total", frame.Fields[1].Name)
+		require.Equal(t, data.Labels{"code": "200", "method": "get"}, frame.Fields[1].Labels)
+		require.Equal(t, 1027.0, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, data.Labels{"code": "400", "method": "get"}, frame.Fields[2].Labels)
+
+		frame = channelFrames[1].Frame
+		require.Len(t, frame.Fields, 5)
+		require.Equal(t, "request_duration_seconds_bucket", frame.Fields[1].Name)
+		require.Equal(t, data.Labels{"le": "+Inf"}, frame.Fields[2].Labels)
+		require.Equal(t, "request_duration_seconds_count", frame.Fields[4].Name)
+	})
+
+	t.Run("should use the timestamps of the samples", func(t *testing.T) {
+		channelFrames := convert(t, PrometheusConverterConfig{}, `temperature{room="a"} 21 1609459200000
+temperature{room="a"} 22 1609459260000
+temperature{room="b"} 19 1609459260000
+`)
+		require.Len(t, channelFrames, 1)
+		frame := channelFrames[0].Frame
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, time.UnixMilli(1609459200000).UTC(), frame.Fields[0].At(0))
+		require.Equal(t, 21.0, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, 22.0, *frame.Fields[1].At(1).(*float64))
+		require.Nil(t, frame.Fields[2].At(0))
+		require.Equal(t, 19.0, *frame.Fields[2].At(1).(*float64))
+	})
+
+	t.Run("should convert OpenMetrics text", func(t *testing.T) {
+		channelFrames := convert(t, PrometheusConverterConfig{}, `# TYPE requests counter
+requests_total{path="/"} 5 1609459200.5
+requests_created{path="/"} 1609459000
+# EOF
+`)
+		require.Len(t, channelFrames, 1)
+		require.Equal(t, "stream/test/metrics/requests", channelFrames[0].Channel)
+		frame := channelFrames[0].Frame
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, "requests_total", frame.Fields[1].Name)
+		require.Equal(t, time.UnixMilli(1609459200500).UTC(), frame.Fields[0].At(0))
+	})
+
+	t.Run("should return an error for invalid input", func(t *testing.T) {
+		converter := NewPrometheusConverter(PrometheusConverterConfig{OpenMetrics: true})
+		_, err := converter.Convert(context.Background(), Vars{}, []byte("temperature 21\n"))
+		require.Error(t, err)
+
+		converter = NewPrometheusConverter(PrometheusConverterConfig{})
+		_, err = converter.Convert(context.Background(), Vars{}, []byte("temperature{ 21\n"))
+		require.Error(t, err)
+	})
+}
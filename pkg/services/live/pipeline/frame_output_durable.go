@@ -0,0 +1,62 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/live/framelog"
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+// FrameLog stores the frames of channels durably, so that subscribers can get the frames they missed.
+type FrameLog interface {
+	Append(orgID int64, channel string, data []byte) (uint64, error)
+	Read(orgID int64, channel string, from uint64, limit int) ([]framelog.Entry, error)
+	NextOffset(orgID int64, channel string) (uint64, error)
+}
+
+// DurableFrameOutput appends frames to the log of the channel, and sends them to the local subscribers of the
+// channel with their offset in the log.
+type DurableFrameOutput struct {
+	frameLog FrameLog
+	// TODO: refactor to depend on interface (avoid Centrifuge dependency here).
+	node *centrifuge.Node
+}
+
+func NewDurableFrameOutput(frameLog FrameLog, node *centrifuge.Node) *DurableFrameOutput {
+	return &DurableFrameOutput{frameLog: frameLog, node: node}
+}
+
+const FrameOutputTypeDurable = "durable"
+
+func (out *DurableFrameOutput) Type() string {
+	return FrameOutputTypeDurable
+}
+
+func (out *DurableFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	frameJSON, err := json.Marshal(frame)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := out.frameLog.Append(vars.OrgID, vars.Channel, frameJSON)
+	if err != nil {
+		return nil, fmt.Errorf("error appending frame to log: %w", err)
+	}
+	if out.node == nil {
+		return nil, nil
+	}
+	pub := &centrifuge.Publication{
+		Data:   frameJSON,
+		Offset: offset,
+	}
+	channel := orgchannel.PrependOrgID(vars.OrgID, vars.Channel)
+	err = out.node.Hub().BroadcastPublication(channel, pub, centrifuge.StreamPosition{})
+	if err != nil {
+		return nil, fmt.Errorf("error publishing frame %d: %w", offset, err)
+	}
+	return nil, nil
+}
@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/framelog"
+)
+
+func TestDurableOutputAndSubscriber(t *testing.T) {
+	frameLog := framelog.New(t.TempDir(), framelog.Options{MaxSegmentBytes: 1024 * 1024})
+	t.Cleanup(func() { _ = frameLog.Close() })
+
+	vars := Vars{OrgID: 1, Channel: "stream/test/durable"}
+	outputter := NewDurableFrameOutput(frameLog, nil)
+	for i := 0; i < 3; i++ {
+		frame := data.NewFrame("test", data.NewField("value", nil, []float64{float64(i)}))
+		channelFrames, err := outputter.OutputFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+		require.Nil(t, channelFrames)
+	}
+
+	subscribe := func(t *testing.T, sub *DurableSubscriber, data string) DurableSubscribeReply {
+		t.Helper()
+		reply, status, err := sub.Subscribe(context.Background(), vars, []byte(data))
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusOK, status)
+		var r DurableSubscribeReply
+		require.NoError(t, json.Unmarshal(reply.Data, &r))
+		return r
+	}
+
+	t.Run("should return the next offset without frames if no offset is requested", func(t *testing.T) {
+		r := subscribe(t, NewDurableSubscriber(frameLog, DurableSubscriberConfig{}), "")
+		require.Empty(t, r.Frames)
+		require.False(t, r.More)
+		require.Equal(t, uint64(4), r.NextOffset)
+	})
+
+	t.Run("should return the frames from the offset", func(t *testing.T) {
+		r := subscribe(t, NewDurableSubscriber(frameLog, DurableSubscriberConfig{}), `{"offset": 2}`)
+		require.Len(t, r.Frames, 2)
+		require.False(t, r.More)
+		require.Equal(t, uint64(4), r.NextOffset)
+		require.Equal(t, uint64(2), r.Frames[0].Offset)
+
+		var frame data.Frame
+		require.NoError(t, json.Unmarshal(r.Frames[0].Frame, &frame))
+		require.Equal(t, 1.0, frame.Fields[0].At(0))
+	})
+
+	t.Run("should limit the number of frames", func(t *testing.T) {
+		r := subscribe(t, NewDurableSubscriber(frameLog, DurableSubscriberConfig{MaxFrames: 2}), `{"offset": 1}`)
+		require.Len(t, r.Frames, 2)
+		require.True(t, r.More)
+		require.Equal(t, uint64(4), r.NextOffset)
+	})
+
+	t.Run("should reject invalid subscribe data", func(t *testing.T) {
+		_, _, err := NewDurableSubscriber(frameLog, DurableSubscriberConfig{}).Subscribe(context.Background(), vars, []byte(`{"offset": "first"}`))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,131 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ConvertUnitsFrameProcessor converts the values of numeric fields between units
+// of the same kind, and sets the unit of their field config. Units are named by
+// their Grafana unit ID.
+type ConvertUnitsFrameProcessor struct {
+	config ConvertUnitsFrameProcessorConfig
+}
+
+func NewConvertUnitsFrameProcessor(config ConvertUnitsFrameProcessorConfig) (*ConvertUnitsFrameProcessor, error) {
+	for _, c := range config.Conversions {
+		if _, ok := units[c.To]; !ok {
+			return nil, fmt.Errorf("unsupported unit: %s", c.To)
+		}
+		if _, ok := units[c.From]; c.From != "" && !ok {
+			return nil, fmt.Errorf("unsupported unit: %s", c.From)
+		}
+		if c.From != "" && units[c.From].kind != units[c.To].kind {
+			return nil, fmt.Errorf("can not convert %s to %s", c.From, c.To)
+		}
+	}
+	return &ConvertUnitsFrameProcessor{config: config}, nil
+}
+
+const FrameProcessorTypeConvertUnits = "convertUnits"
+
+func (p *ConvertUnitsFrameProcessor) Type() string {
+	return FrameProcessorTypeConvertUnits
+}
+
+// unit converts values to the base unit of its kind: base = value * scale + offset.
+type unit struct {
+	kind   string
+	scale  float64
+	offset float64
+}
+
+var units = map[string]unit{
+	"celsius":    {kind: "temperature", scale: 1, offset: 273.15},
+	"fahrenheit": {kind: "temperature", scale: 5.0 / 9, offset: 459.67 * 5 / 9},
+	"kelvin":     {kind: "temperature", scale: 1},
+
+	"ns": {kind: "time", scale: 1e-9},
+	"µs": {kind: "time", scale: 1e-6},
+	"ms": {kind: "time", scale: 1e-3},
+	"s":  {kind: "time", scale: 1},
+	"m":  {kind: "time", scale: 60},
+	"h":  {kind: "time", scale: 3600},
+	"d":  {kind: "time", scale: 86400},
+
+	"bytes":     {kind: "data", scale: 1},
+	"kbytes":    {kind: "data", scale: 1 << 10},
+	"mbytes":    {kind: "data", scale: 1 << 20},
+	"gbytes":    {kind: "data", scale: 1 << 30},
+	"decbytes":  {kind: "data", scale: 1},
+	"deckbytes": {kind: "data", scale: 1e3},
+	"decmbytes": {kind: "data", scale: 1e6},
+	"decgbytes": {kind: "data", scale: 1e9},
+
+	"lengthmm": {kind: "length", scale: 1e-3},
+	"lengthm":  {kind: "length", scale: 1},
+	"lengthkm": {kind: "length", scale: 1e3},
+	"lengthft": {kind: "length", scale: 0.3048},
+	"lengthmi": {kind: "length", scale: 1609.344},
+
+	"velocityms":   {kind: "velocity", scale: 1},
+	"velocitykmh":  {kind: "velocity", scale: 1 / 3.6},
+	"velocitymph":  {kind: "velocity", scale: 0.44704},
+	"velocityknot": {kind: "velocity", scale: 1852.0 / 3600},
+
+	"pressurembar": {kind: "pressure", scale: 100},
+	"pressurehpa":  {kind: "pressure", scale: 100},
+	"pressurebar":  {kind: "pressure", scale: 1e5},
+	"pressurekbar": {kind: "pressure", scale: 1e8},
+	"pressurepsi":  {kind: "pressure", scale: 6894.757293168},
+	"pressurehg":   {kind: "pressure", scale: 3386.389},
+}
+
+func (p *ConvertUnitsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	for _, c := range p.config.Conversions {
+		for i, field := range frame.Fields {
+			if field.Name != c.FieldName || !field.Type().Numeric() {
+				continue
+			}
+			from := c.From
+			if from == "" && field.Config != nil {
+				from = field.Config.Unit
+			}
+			fromUnit, ok := units[from]
+			if !ok || fromUnit.kind != units[c.To].kind {
+				return nil, fmt.Errorf("can not convert field %s from %q to %s", field.Name, from, c.To)
+			}
+			converted, err := convertUnit(field, fromUnit, units[c.To])
+			if err != nil {
+				return nil, err
+			}
+			converted.Config = &data.FieldConfig{}
+			if field.Config != nil {
+				config := *field.Config
+				converted.Config = &config
+			}
+			converted.Config.Unit = c.To
+			frame.Fields[i] = converted
+		}
+	}
+	return frame, nil
+}
+
+// convertUnit returns a nullable float64 field with the values of the field converted between the units.
+func convertUnit(field *data.Field, from unit, to unit) (*data.Field, error) {
+	values := make([]*float64, field.Len())
+	for i := range values {
+		v, err := field.NullableFloatAt(i)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		converted := ((*v*from.scale + from.offset) - to.offset) / to.scale
+		values[i] = &converted
+	}
+	return data.NewField(field.Name, field.Labels, values), nil
+}
@@ -0,0 +1,53 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertUnitsFrameProcessor(t *testing.T) {
+	t.Run("should convert the values and set the unit", func(t *testing.T) {
+		p, err := NewConvertUnitsFrameProcessor(ConvertUnitsFrameProcessorConfig{Conversions: []UnitConversion{
+			{FieldName: "temperature", From: "celsius", To: "fahrenheit"},
+			{FieldName: "speed", To: "velocitykmh"},
+		}})
+		require.NoError(t, err)
+		speed := data.NewField("speed", data.Labels{"car": "a"}, []float64{10})
+		speed.Config = &data.FieldConfig{Unit: "velocityms", DisplayName: "Speed"}
+		frame := data.NewFrame("test",
+			data.NewField("temperature", nil, []*float64{floatPtr(100), nil}),
+			speed,
+		)
+		frame, err = p.ProcessFrame(context.Background(), Vars{}, frame)
+		require.NoError(t, err)
+		require.InDelta(t, 212.0, *frame.Fields[0].At(0).(*float64), 1e-9)
+		require.Nil(t, frame.Fields[0].At(1))
+		require.Equal(t, "fahrenheit", frame.Fields[0].Config.Unit)
+		require.InDelta(t, 36.0, *frame.Fields[1].At(0).(*float64), 1e-9)
+		require.Equal(t, data.Labels{"car": "a"}, frame.Fields[1].Labels)
+		require.Equal(t, "velocitykmh", frame.Fields[1].Config.Unit)
+		require.Equal(t, "Speed", frame.Fields[1].Config.DisplayName)
+		require.Equal(t, "velocityms", speed.Config.Unit)
+	})
+
+	t.Run("should return an error for units of different kinds", func(t *testing.T) {
+		_, err := NewConvertUnitsFrameProcessor(ConvertUnitsFrameProcessorConfig{Conversions: []UnitConversion{
+			{FieldName: "temperature", From: "celsius", To: "ms"},
+		}})
+		require.Error(t, err)
+
+		p, err := NewConvertUnitsFrameProcessor(ConvertUnitsFrameProcessorConfig{Conversions: []UnitConversion{
+			{FieldName: "temperature", To: "celsius"},
+		}})
+		require.NoError(t, err)
+		_, err = p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test", data.NewField("temperature", nil, []float64{1})))
+		require.Error(t, err)
+	})
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
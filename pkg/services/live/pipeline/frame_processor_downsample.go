@@ -0,0 +1,335 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// DownsampleFrameProcessor aggregates the rows of the frames of a channel to a
+// row per time bucket, so that high-frequency streams are thinned before they
+// are sent to subscribers. A bucket is sent when the first row of a later bucket
+// arrives, until then the frames are dropped. When the stream pauses, the bucket
+// is sent on its own once the wall-clock time passes the end of the bucket, or,
+// for buckets that already ended, once no rows arrived for an interval. Numeric
+// fields are aggregated, and other fields keep their last value. Rows that arrive
+// late are aggregated to the current bucket.
+type DownsampleFrameProcessor struct {
+	config DownsampleFrameProcessorConfig
+
+	mu      sync.Mutex
+	buckets map[string]*downsampleBucket
+	sender  FrameSender
+}
+
+const (
+	downsampleAggregationMean = "mean"
+	downsampleAggregationMin  = "min"
+	downsampleAggregationMax  = "max"
+	downsampleAggregationLast = "last"
+)
+
+func NewDownsampleFrameProcessor(config DownsampleFrameProcessorConfig) (*DownsampleFrameProcessor, error) {
+	if config.IntervalMilliseconds <= 0 {
+		return nil, errors.New("downsample processor requires a positive interval")
+	}
+	aggregations := []string{config.Aggregation}
+	for _, a := range config.FieldAggregations {
+		aggregations = append(aggregations, a)
+	}
+	for _, a := range aggregations {
+		switch a {
+		case "", downsampleAggregationMean, downsampleAggregationMin, downsampleAggregationMax, downsampleAggregationLast:
+		default:
+			return nil, fmt.Errorf("unknown aggregation: %s", a)
+		}
+	}
+	return &DownsampleFrameProcessor{
+		config:  config,
+		buckets: map[string]*downsampleBucket{},
+	}, nil
+}
+
+const FrameProcessorTypeDownsample = "downsample"
+
+func (p *DownsampleFrameProcessor) Type() string {
+	return FrameProcessorTypeDownsample
+}
+
+// SetFrameSender sets the sender of the buckets that are sent on their own.
+// Without a sender, a bucket is only sent when a later bucket starts.
+func (p *DownsampleFrameProcessor) SetFrameSender(sender FrameSender) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sender = sender
+}
+
+type downsampleBucket struct {
+	vars       Vars
+	schema     string
+	frameName  string
+	timeName   string
+	timeLabels data.Labels
+	start      time.Time
+	fields     []*downsampleField
+	timer      *time.Timer
+}
+
+type downsampleField struct {
+	name        string
+	labels      data.Labels
+	config      *data.FieldConfig
+	fieldType   data.FieldType
+	aggregation string
+	count       int
+	sum         float64
+	min         float64
+	max         float64
+	last        interface{}
+}
+
+func (p *DownsampleFrameProcessor) ProcessFrame(ctx context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	timeIndex := -1
+	for i, field := range frame.Fields {
+		if field.Type().Time() {
+			timeIndex = i
+			break
+		}
+	}
+	if timeIndex < 0 {
+		return frame, nil
+	}
+	processed, reset, sender, err := p.processFrame(vars, frame, timeIndex)
+	if reset != nil && sender != nil {
+		// The rows of the reset bucket are sent before the rows of the frame.
+		sender.SendFrame(ctx, p, reset.vars, reset.frame())
+	}
+	return processed, err
+}
+
+// processFrame aggregates the rows of the frame to the buckets of the channel.
+// It returns the frame of the buckets that ended, and the bucket that was reset
+// because the fields of the channel changed.
+func (p *DownsampleFrameProcessor) processFrame(vars Vars, frame *data.Frame, timeIndex int) (*data.Frame, *downsampleBucket, FrameSender, error) {
+	interval := time.Duration(p.config.IntervalMilliseconds) * time.Millisecond
+	key := strconv.FormatInt(vars.OrgID, 10) + "/" + vars.Channel
+	schema := downsampleSchema(frame)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var reset *downsampleBucket
+	if b := p.buckets[key]; b != nil && b.schema != schema {
+		// The fields of the channel changed, the rows of the current bucket can not be aggregated anymore.
+		b.stop()
+		delete(p.buckets, key)
+		reset = b
+	}
+
+	var times []time.Time
+	var rows [][]interface{}
+	var rowFields []*downsampleField
+	for row := 0; row < frame.Rows(); row++ {
+		t, ok := frame.Fields[timeIndex].ConcreteAt(row)
+		if !ok {
+			continue
+		}
+		start := t.(time.Time).Truncate(interval)
+
+		b := p.buckets[key]
+		if b != nil && start.After(b.start) {
+			b.stop()
+			times = append(times, b.start)
+			rows = append(rows, b.values())
+			rowFields = b.fields
+			b = nil
+		}
+		if b == nil {
+			b = p.newBucket(vars, frame, timeIndex, schema, start)
+			p.buckets[key] = b
+		}
+		if err := b.add(frame, timeIndex, row); err != nil {
+			return nil, reset, p.sender, err
+		}
+	}
+	if b := p.buckets[key]; b != nil && p.sender != nil {
+		p.scheduleFlush(key, b, interval)
+	}
+	if len(rows) == 0 {
+		return nil, reset, p.sender, nil
+	}
+	return downsampleFrame(frame.Name, frame.Fields[timeIndex].Name, frame.Fields[timeIndex].Labels, times, rows, rowFields), reset, p.sender, nil
+}
+
+// scheduleFlush sends the bucket when the wall-clock time passes its end, or, if
+// the bucket already ended, when no rows arrive for an interval.
+func (p *DownsampleFrameProcessor) scheduleFlush(key string, b *downsampleBucket, interval time.Duration) {
+	delay := time.Until(b.start.Add(interval))
+	if delay <= 0 {
+		delay = interval
+	}
+	if b.timer != nil {
+		b.timer.Reset(delay)
+		return
+	}
+	b.timer = time.AfterFunc(delay, func() {
+		p.flush(key, b)
+	})
+}
+
+func (p *DownsampleFrameProcessor) flush(key string, b *downsampleBucket) {
+	p.mu.Lock()
+	if p.buckets[key] != b {
+		// The bucket was sent with the frame of a later bucket.
+		p.mu.Unlock()
+		return
+	}
+	delete(p.buckets, key)
+	sender := p.sender
+	p.mu.Unlock()
+	sender.SendFrame(context.Background(), p, b.vars, b.frame())
+}
+
+// downsampleFrame returns a frame with a row per bucket.
+func downsampleFrame(name string, timeName string, timeLabels data.Labels, times []time.Time, rows [][]interface{}, rowFields []*downsampleField) *data.Frame {
+	fields := make([]*data.Field, 0, len(rowFields)+1)
+	fields = append(fields, data.NewField(timeName, timeLabels, times))
+	for i, f := range rowFields {
+		fieldType := f.fieldType
+		if fieldType.Numeric() {
+			fieldType = data.FieldTypeNullableFloat64
+		}
+		field := data.NewFieldFromFieldType(fieldType, len(rows))
+		field.Name = f.name
+		field.Labels = f.labels
+		field.Config = f.config
+		for row, values := range rows {
+			if values[i] != nil {
+				field.Set(row, values[i])
+			}
+		}
+		fields = append(fields, field)
+	}
+	return data.NewFrame(name, fields...)
+}
+
+// downsampleSchema returns a key of the names, types and labels of the fields of the frame.
+func downsampleSchema(frame *data.Frame) string {
+	var sb strings.Builder
+	for _, field := range frame.Fields {
+		sb.WriteString(field.Name)
+		sb.WriteString("\x00")
+		sb.WriteString(field.Type().ItemTypeString())
+		sb.WriteString("\x00")
+		sb.WriteString(field.Labels.String())
+		sb.WriteString("\x00")
+	}
+	return sb.String()
+}
+
+func (p *DownsampleFrameProcessor) newBucket(vars Vars, frame *data.Frame, timeIndex int, schema string, start time.Time) *downsampleBucket {
+	b := &downsampleBucket{
+		vars:       vars,
+		schema:     schema,
+		frameName:  frame.Name,
+		timeName:   frame.Fields[timeIndex].Name,
+		timeLabels: frame.Fields[timeIndex].Labels,
+		start:      start,
+	}
+	for i, field := range frame.Fields {
+		if i == timeIndex {
+			continue
+		}
+		aggregation := p.config.FieldAggregations[field.Name]
+		if aggregation == "" {
+			aggregation = p.config.Aggregation
+		}
+		if aggregation == "" {
+			aggregation = downsampleAggregationMean
+		}
+		b.fields = append(b.fields, &downsampleField{
+			name:        field.Name,
+			labels:      field.Labels,
+			config:      field.Config,
+			fieldType:   field.Type(),
+			aggregation: aggregation,
+		})
+	}
+	return b
+}
+
+func (b *downsampleBucket) add(frame *data.Frame, timeIndex int, row int) error {
+	i := 0
+	for j, field := range frame.Fields {
+		if j == timeIndex {
+			continue
+		}
+		f := b.fields[i]
+		i++
+		if !field.Type().Numeric() {
+			f.last = field.CopyAt(row)
+			continue
+		}
+		v, err := field.NullableFloatAt(row)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			continue
+		}
+		if f.count == 0 || *v < f.min {
+			f.min = *v
+		}
+		if f.count == 0 || *v > f.max {
+			f.max = *v
+		}
+		f.sum += *v
+		f.count++
+		f.last = *v
+	}
+	return nil
+}
+
+// frame returns a frame with the row of the bucket.
+func (b *downsampleBucket) frame() *data.Frame {
+	return downsampleFrame(b.frameName, b.timeName, b.timeLabels, []time.Time{b.start}, [][]interface{}{b.values()}, b.fields)
+}
+
+func (b *downsampleBucket) stop() {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+}
+
+// values returns the aggregated values of the bucket, nil for fields without values.
+func (b *downsampleBucket) values() []interface{} {
+	values := make([]interface{}, len(b.fields))
+	for i, f := range b.fields {
+		if !f.fieldType.Numeric() {
+			values[i] = f.last
+			continue
+		}
+		if f.count == 0 {
+			continue
+		}
+		var v float64
+		switch f.aggregation {
+		case downsampleAggregationMin:
+			v = f.min
+		case downsampleAggregationMax:
+			v = f.max
+		case downsampleAggregationLast:
+			v = f.last.(float64)
+		default:
+			v = f.sum / float64(f.count)
+		}
+		values[i] = &v
+	}
+	return values
+}
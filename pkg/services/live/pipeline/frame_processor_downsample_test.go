@@ -0,0 +1,168 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+type testFrameSender struct {
+	mu     sync.Mutex
+	frames []*data.Frame
+}
+
+func (s *testFrameSender) SendFrame(_ context.Context, _ FrameProcessor, _ Vars, frame *data.Frame) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.frames = append(s.frames, frame)
+}
+
+func (s *testFrameSender) sent() []*data.Frame {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.frames
+}
+
+func TestDownsampleFrameProcessor(t *testing.T) {
+	start := time.Date(2021, 01, 01, 12, 0, 0, 0, time.UTC)
+	frameAt := func(offset time.Duration, value float64, state string) *data.Frame {
+		return data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{start.Add(offset)}),
+			data.NewField("value", data.Labels{"sensor": "a"}, []float64{value}),
+			data.NewField("state", nil, []string{state}),
+		)
+	}
+	vars := Vars{OrgID: 1, Channel: "stream/test/sensor"}
+
+	t.Run("should send a row per bucket when the next bucket starts", func(t *testing.T) {
+		p, err := NewDownsampleFrameProcessor(DownsampleFrameProcessorConfig{IntervalMilliseconds: 10000})
+		require.NoError(t, err)
+
+		for i, v := range []float64{1, 2, 6} {
+			frame, err := p.ProcessFrame(context.Background(), vars, frameAt(time.Duration(i)*time.Second, v, "ok"))
+			require.NoError(t, err)
+			require.Nil(t, frame)
+		}
+		frame, err := p.ProcessFrame(context.Background(), vars, frameAt(12*time.Second, 10, "ok"))
+		require.NoError(t, err)
+		require.NotNil(t, frame)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, start, frame.Fields[0].At(0))
+		require.Equal(t, 3.0, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, data.Labels{"sensor": "a"}, frame.Fields[1].Labels)
+		require.Equal(t, "ok", frame.Fields[2].At(0))
+
+		// The other channels have their own buckets.
+		frame, err = p.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/other"}, frameAt(30*time.Second, 1, "ok"))
+		require.NoError(t, err)
+		require.Nil(t, frame)
+	})
+
+	t.Run("should aggregate the fields", func(t *testing.T) {
+		p, err := NewDownsampleFrameProcessor(DownsampleFrameProcessorConfig{
+			IntervalMilliseconds: 10000,
+			Aggregation:          "max",
+			FieldAggregations:    map[string]string{"min": "min", "last": "last"},
+		})
+		require.NoError(t, err)
+		frame := data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{start, start.Add(time.Second), start.Add(2 * time.Second), start.Add(11 * time.Second), start.Add(21 * time.Second)}),
+			data.NewField("max", nil, []float64{1, 5, 3, 2, 0}),
+			data.NewField("min", nil, []float64{1, 5, 3, 2, 0}),
+			data.NewField("last", nil, []float64{1, 5, 3, 2, 0}),
+		)
+		frame, err = p.ProcessFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, start.Add(10*time.Second), frame.Fields[0].At(1))
+		require.Equal(t, 5.0, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, 1.0, *frame.Fields[2].At(0).(*float64))
+		require.Equal(t, 3.0, *frame.Fields[3].At(0).(*float64))
+		require.Equal(t, 2.0, *frame.Fields[1].At(1).(*float64))
+	})
+
+	t.Run("should send the bucket when the stream pauses", func(t *testing.T) {
+		p, err := NewDownsampleFrameProcessor(DownsampleFrameProcessorConfig{IntervalMilliseconds: 50})
+		require.NoError(t, err)
+		sender := &testFrameSender{}
+		p.SetFrameSender(sender)
+
+		for _, v := range []float64{1, 3} {
+			frame, err := p.ProcessFrame(context.Background(), vars, frameAt(0, v, "ok"))
+			require.NoError(t, err)
+			require.Nil(t, frame)
+		}
+		require.Eventually(t, func() bool {
+			return len(sender.sent()) == 1
+		}, time.Second, 10*time.Millisecond)
+		frame := sender.sent()[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, start, frame.Fields[0].At(0))
+		require.Equal(t, 2.0, *frame.Fields[1].At(0).(*float64))
+
+		// The sent bucket is not sent again with the next bucket.
+		frame, err = p.ProcessFrame(context.Background(), vars, frameAt(time.Second, 5, "ok"))
+		require.NoError(t, err)
+		require.Nil(t, frame)
+	})
+
+	t.Run("should send the bucket when the fields change", func(t *testing.T) {
+		p, err := NewDownsampleFrameProcessor(DownsampleFrameProcessorConfig{IntervalMilliseconds: 10000})
+		require.NoError(t, err)
+		sender := &testFrameSender{}
+		p.SetFrameSender(sender)
+
+		frame, err := p.ProcessFrame(context.Background(), vars, frameAt(0, 1, "ok"))
+		require.NoError(t, err)
+		require.Nil(t, frame)
+
+		changed := data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{start.Add(time.Second)}),
+			data.NewField("value", data.Labels{"sensor": "b"}, []float64{2}),
+		)
+		frame, err = p.ProcessFrame(context.Background(), vars, changed)
+		require.NoError(t, err)
+		require.Nil(t, frame)
+		require.Len(t, sender.sent(), 1)
+		sent := sender.sent()[0]
+		require.Equal(t, 1.0, *sent.Fields[1].At(0).(*float64))
+		require.Equal(t, data.Labels{"sensor": "a"}, sent.Fields[1].Labels)
+		require.Equal(t, "ok", sent.Fields[2].At(0))
+	})
+
+	t.Run("should send the bucket through the processors that follow it", func(t *testing.T) {
+		p, err := NewDownsampleFrameProcessor(DownsampleFrameProcessorConfig{IntervalMilliseconds: 50})
+		require.NoError(t, err)
+		multiple := NewMultipleFrameProcessor(p, NewDropFieldsFrameProcessor(DropFieldsFrameProcessorConfig{FieldNames: []string{"state"}}))
+		sender := &testFrameSender{}
+		multiple.SetFrameSender(sender)
+
+		frame, err := multiple.ProcessFrame(context.Background(), vars, frameAt(0, 1, "ok"))
+		require.NoError(t, err)
+		require.Nil(t, frame)
+		require.Eventually(t, func() bool {
+			return len(sender.sent()) == 1
+		}, time.Second, 10*time.Millisecond)
+		require.Len(t, sender.sent()[0].Fields, 2)
+	})
+
+	t.Run("should pass frames without a time field", func(t *testing.T) {
+		p, err := NewDownsampleFrameProcessor(DownsampleFrameProcessorConfig{IntervalMilliseconds: 10000})
+		require.NoError(t, err)
+		frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+		processed, err := p.ProcessFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+		require.Equal(t, frame, processed)
+	})
+
+	t.Run("should return an error for invalid config", func(t *testing.T) {
+		_, err := NewDownsampleFrameProcessor(DownsampleFrameProcessorConfig{})
+		require.Error(t, err)
+		_, err = NewDownsampleFrameProcessor(DownsampleFrameProcessorConfig{IntervalMilliseconds: 1000, Aggregation: "median"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// ExpressionFrameProcessor computes a field from the other fields of each row
+// with a math expression, like the math expressions of server-side expressions.
+// The values of numeric fields are the variables of the expression. A row gets
+// a null value if the expression refers to a missing or null field.
+type ExpressionFrameProcessor struct {
+	config ExpressionFrameProcessorConfig
+	expr   *mathexp.Expr
+}
+
+func NewExpressionFrameProcessor(config ExpressionFrameProcessorConfig) (*ExpressionFrameProcessor, error) {
+	if config.FieldName == "" {
+		return nil, errors.New("expression processor requires a field name")
+	}
+	expr, err := mathexp.New(config.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", config.Expression, err)
+	}
+	return &ExpressionFrameProcessor{config: config, expr: expr}, nil
+}
+
+const FrameProcessorTypeExpression = "expression"
+
+func (p *ExpressionFrameProcessor) Type() string {
+	return FrameProcessorTypeExpression
+}
+
+func (p *ExpressionFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	fields := map[string]*data.Field{}
+	for _, name := range p.expr.VarNames {
+		if field, i := frame.FieldByName(name); i >= 0 && field.Type().Numeric() {
+			fields[name] = field
+		}
+	}
+
+	rows := frame.Rows()
+	values := make([]*float64, rows)
+	for row := 0; row < rows; row++ {
+		v, err := p.evaluate(fields, row)
+		if err != nil {
+			return nil, err
+		}
+		values[row] = v
+	}
+
+	result := data.NewField(p.config.FieldName, nil, values)
+	if _, i := frame.FieldByName(p.config.FieldName); i >= 0 {
+		frame.Fields[i] = result
+		return frame, nil
+	}
+	frame.Fields = append(frame.Fields, result)
+	return frame, nil
+}
+
+// evaluate returns the value of the expression for the row.
+func (p *ExpressionFrameProcessor) evaluate(fields map[string]*data.Field, row int) (*float64, error) {
+	vars := mathexp.Vars{}
+	for _, name := range p.expr.VarNames {
+		field, ok := fields[name]
+		if !ok {
+			return nil, nil
+		}
+		v, err := field.NullableFloatAt(row)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			return nil, nil
+		}
+		vars[name] = mathexp.NewScalarResults(name, v)
+	}
+
+	res, err := p.expr.Execute("", vars)
+	if err != nil {
+		return nil, fmt.Errorf("error evaluating expression %q: %w", p.config.Expression, err)
+	}
+	if len(res.Values) != 1 {
+		return nil, nil
+	}
+	switch v := res.Values[0].(type) {
+	case mathexp.Scalar:
+		return v.GetFloat64Value(), nil
+	case mathexp.Number:
+		return v.GetFloat64Value(), nil
+	default:
+		return nil, fmt.Errorf("expression %q must return a number", p.config.Expression)
+	}
+}
@@ -0,0 +1,58 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpressionFrameProcessor(t *testing.T) {
+	t.Run("should compute a field from the fields of each row", func(t *testing.T) {
+		p, err := NewExpressionFrameProcessor(ExpressionFrameProcessorConfig{
+			FieldName:  "heat index",
+			Expression: "$temperature * 2 + ${relative humidity}",
+		})
+		require.NoError(t, err)
+		one := 1.0
+		frame := data.NewFrame("test",
+			data.NewField("temperature", nil, []int64{20, 25, 30}),
+			data.NewField("relative humidity", nil, []*float64{&one, nil, &one}),
+		)
+		frame, err = p.ProcessFrame(context.Background(), Vars{}, frame)
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 3)
+		field := frame.Fields[2]
+		require.Equal(t, "heat index", field.Name)
+		require.Equal(t, 41.0, *field.At(0).(*float64))
+		require.Nil(t, field.At(1))
+		require.Equal(t, 61.0, *field.At(2).(*float64))
+	})
+
+	t.Run("should replace a field with the same name", func(t *testing.T) {
+		p, err := NewExpressionFrameProcessor(ExpressionFrameProcessorConfig{FieldName: "value", Expression: "abs($value)"})
+		require.NoError(t, err)
+		frame := data.NewFrame("test", data.NewField("value", nil, []float64{-2}))
+		frame, err = p.ProcessFrame(context.Background(), Vars{}, frame)
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 1)
+		require.Equal(t, 2.0, *frame.Fields[0].At(0).(*float64))
+	})
+
+	t.Run("should return null values for missing fields", func(t *testing.T) {
+		p, err := NewExpressionFrameProcessor(ExpressionFrameProcessorConfig{FieldName: "result", Expression: "$missing + 1"})
+		require.NoError(t, err)
+		frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+		frame, err = p.ProcessFrame(context.Background(), Vars{}, frame)
+		require.NoError(t, err)
+		require.Nil(t, frame.Fields[1].At(0))
+	})
+
+	t.Run("should return an error for invalid expressions", func(t *testing.T) {
+		_, err := NewExpressionFrameProcessor(ExpressionFrameProcessorConfig{FieldName: "result", Expression: "$value +"})
+		require.Error(t, err)
+		_, err = NewExpressionFrameProcessor(ExpressionFrameProcessorConfig{Expression: "$value"})
+		require.Error(t, err)
+	})
+}
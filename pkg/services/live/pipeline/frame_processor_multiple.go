@@ -26,10 +26,50 @@ func (p *MultipleFrameProcessor) ProcessFrame(ctx context.Context, vars Vars, fr
 			logger.Error("Error processing frame", "error", err)
 			return nil, err
 		}
+		if frame == nil {
+			// The frame was dropped, for example by downsampling.
+			return nil, nil
+		}
 	}
 	return frame, nil
 }
 
+// SetFrameSender sets the sender of the processors that hold frames back. The
+// frames they send are processed with the processors that follow them first.
+func (p *MultipleFrameProcessor) SetFrameSender(sender FrameSender) {
+	for _, proc := range p.Processors {
+		if flusher, ok := proc.(FrameFlusher); ok {
+			flusher.SetFrameSender(multipleFrameSender{multiple: p, sender: sender})
+		}
+	}
+}
+
+type multipleFrameSender struct {
+	multiple *MultipleFrameProcessor
+	sender   FrameSender
+}
+
+func (s multipleFrameSender) SendFrame(ctx context.Context, proc FrameProcessor, vars Vars, frame *data.Frame) {
+	for i, p := range s.multiple.Processors {
+		if p != proc {
+			continue
+		}
+		for _, next := range s.multiple.Processors[i+1:] {
+			var err error
+			frame, err = next.ProcessFrame(ctx, vars, frame)
+			if err != nil {
+				logger.Error("Error processing frame", "error", err)
+				return
+			}
+			if frame == nil {
+				return
+			}
+		}
+		s.sender.SendFrame(ctx, s.multiple, vars, frame)
+		return
+	}
+}
+
 func NewMultipleFrameProcessor(processors ...FrameProcessor) *MultipleFrameProcessor {
 	return &MultipleFrameProcessor{Processors: processors}
 }
@@ -0,0 +1,46 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// RenameFieldsFrameProcessor can rename fields and their labels, and add labels to fields.
+type RenameFieldsFrameProcessor struct {
+	config RenameFieldsFrameProcessorConfig
+}
+
+func NewRenameFieldsFrameProcessor(config RenameFieldsFrameProcessorConfig) *RenameFieldsFrameProcessor {
+	return &RenameFieldsFrameProcessor{config: config}
+}
+
+const FrameProcessorTypeRenameFields = "renameFields"
+
+func (p *RenameFieldsFrameProcessor) Type() string {
+	return FrameProcessorTypeRenameFields
+}
+
+func (p *RenameFieldsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	for _, field := range frame.Fields {
+		if name, ok := p.config.FieldNames[field.Name]; ok {
+			field.Name = name
+		}
+		if field.Type().Time() || (len(field.Labels) == 0 && len(p.config.Labels) == 0) {
+			continue
+		}
+		// Fields of a frame can share their labels, so they get new labels.
+		labels := make(data.Labels, len(field.Labels)+len(p.config.Labels))
+		for k, v := range field.Labels {
+			if name, ok := p.config.LabelNames[k]; ok {
+				k = name
+			}
+			labels[k] = v
+		}
+		for k, v := range p.config.Labels {
+			labels[k] = v
+		}
+		field.Labels = labels
+	}
+	return frame, nil
+}
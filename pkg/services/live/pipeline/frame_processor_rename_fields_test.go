@@ -0,0 +1,38 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameFieldsFrameProcessor(t *testing.T) {
+	shared := data.Labels{"host": "a", "dc": "eu"}
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Now()}),
+		data.NewField("temp", shared, []float64{21}),
+		data.NewField("hum", shared, []float64{40}),
+		data.NewField("state", nil, []string{"ok"}),
+	)
+	p := NewRenameFieldsFrameProcessor(RenameFieldsFrameProcessorConfig{
+		FieldNames: map[string]string{"temp": "temperature", "hum": "humidity"},
+		LabelNames: map[string]string{"host": "instance"},
+		Labels:     map[string]string{"source": "live"},
+	})
+
+	processed, err := p.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Equal(t, "time", processed.Fields[0].Name)
+	require.Nil(t, processed.Fields[0].Labels)
+	require.Equal(t, "temperature", processed.Fields[1].Name)
+	require.Equal(t, data.Labels{"instance": "a", "dc": "eu", "source": "live"}, processed.Fields[1].Labels)
+	require.Equal(t, "humidity", processed.Fields[2].Name)
+	require.Equal(t, data.Labels{"instance": "a", "dc": "eu", "source": "live"}, processed.Fields[2].Labels)
+	require.Equal(t, "state", processed.Fields[3].Name)
+	require.Equal(t, data.Labels{"source": "live"}, processed.Fields[3].Labels)
+	// The labels shared by the fields are not modified.
+	require.Equal(t, data.Labels{"host": "a", "dc": "eu"}, shared)
+}
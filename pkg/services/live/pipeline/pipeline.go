@@ -98,6 +98,18 @@ type FrameProcessor interface {
 	ProcessFrame(ctx context.Context, vars Vars, frame *data.Frame) (*data.Frame, error)
 }
 
+// FrameFlusher is implemented by a FrameProcessor that holds frames back, so
+// that it can send them later on its own, for example when a stream pauses.
+type FrameFlusher interface {
+	SetFrameSender(sender FrameSender)
+}
+
+// FrameSender processes a frame that a FrameProcessor sends on its own with the
+// processors and outputters that follow the FrameProcessor.
+type FrameSender interface {
+	SendFrame(ctx context.Context, proc FrameProcessor, vars Vars, frame *data.Frame)
+}
+
 // FrameOutputter outputs data.Frame to a custom destination. Or simply
 // do nothing if some conditions not met.
 type FrameOutputter interface {
@@ -405,17 +417,54 @@ func (p *Pipeline) processFrame(ctx context.Context, orgID int64, channelID stri
 		Path:      ch.Path,
 	}
 
-	if len(rule.FrameProcessors) > 0 {
-		for _, proc := range rule.FrameProcessors {
-			frame, err = p.execProcessor(ctx, proc, vars, frame)
-			if err != nil {
+	return p.processRuleFrame(ctx, rule, vars, rule.FrameProcessors, frame)
+}
+
+// SendFrame processes a frame that a FrameProcessor of the channel rule sends on
+// its own with the processors and outputters that follow it in the rule.
+func (p *Pipeline) SendFrame(ctx context.Context, proc FrameProcessor, vars Vars, frame *data.Frame) {
+	rule, ok, err := p.ruleGetter.Get(vars.OrgID, vars.Channel)
+	if err != nil {
+		logger.Error("Error getting rule", "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	for i, ruleProc := range rule.FrameProcessors {
+		if ruleProc != proc {
+			continue
+		}
+		frames, err := p.processRuleFrame(ctx, rule, vars, rule.FrameProcessors[i+1:], frame)
+		if err != nil {
+			return
+		}
+		if len(frames) > 0 {
+			visitedChannels := map[string]struct{}{vars.Channel: {}}
+			if err := p.processChannelFrames(ctx, vars.OrgID, vars.Channel, frames, visitedChannels); err != nil {
 				logger.Error("Error processing frame", "error", err)
-				return nil, err
-			}
-			if frame == nil {
-				return nil, nil
 			}
 		}
+		return
+	}
+	// The rule was rebuilt after the processor held the frame back.
+	logger.Debug("Processor not found in rule", "channel", vars.Channel, "processor", proc.Type())
+}
+
+func (p *Pipeline) processRuleFrame(ctx context.Context, rule *LiveChannelRule, vars Vars, processors []FrameProcessor, frame *data.Frame) ([]*ChannelFrame, error) {
+	var err error
+	for _, proc := range processors {
+		if flusher, ok := proc.(FrameFlusher); ok {
+			flusher.SetFrameSender(p)
+		}
+		frame, err = p.execProcessor(ctx, proc, vars, frame)
+		if err != nil {
+			logger.Error("Error processing frame", "error", err)
+			return nil, err
+		}
+		if frame == nil {
+			return nil, nil
+		}
 	}
 
 	if len(rule.FrameOutputters) > 0 {
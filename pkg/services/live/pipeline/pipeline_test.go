@@ -100,6 +100,31 @@ func TestPipeline(t *testing.T) {
 	require.NotNil(t, outputter.frame)
 }
 
+func TestPipeline_SendFrame(t *testing.T) {
+	outputter := &testOutputter{}
+	sending := NewRenameFieldsFrameProcessor(RenameFieldsFrameProcessorConfig{})
+	rule := &LiveChannelRule{
+		FrameProcessors: []FrameProcessor{sending, NewKeepFieldsFrameProcessor(KeepFieldsFrameProcessorConfig{FieldNames: []string{"value"}})},
+		FrameOutputters: []FrameOutputter{outputter},
+	}
+	p, err := New(&testRuleGetter{
+		rules: map[string]*LiveChannelRule{"stream/test/xxx": rule},
+	})
+	require.NoError(t, err)
+
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}), data.NewField("state", nil, []string{"ok"}))
+	p.SendFrame(context.Background(), sending, Vars{OrgID: 1, Channel: "stream/test/xxx"}, frame)
+	require.NotNil(t, outputter.frame)
+	// The processors that follow the sending processor are applied.
+	require.Len(t, outputter.frame.Fields, 1)
+	require.Equal(t, "value", outputter.frame.Fields[0].Name)
+
+	// Frames of processors that are not in the rule anymore are dropped.
+	outputter.frame = nil
+	p.SendFrame(context.Background(), NewRenameFieldsFrameProcessor(RenameFieldsFrameProcessorConfig{}), Vars{OrgID: 1, Channel: "stream/test/xxx"}, frame)
+	require.Nil(t, outputter.frame)
+}
+
 func TestPipeline_OutputError(t *testing.T) {
 	boomErr := errors.New("boom")
 	outputter := &testOutputter{err: boomErr}
@@ -15,6 +15,11 @@ var SubscribersRegistry = []EntityInfo{
 		Type:        SubscriberTypeManagedStream,
 		Description: "apply managed stream subscribe logic",
 	},
+	{
+		Type:        SubscriberTypeDurable,
+		Description: "send the frames of the durable log from the offset in the subscribe data",
+		Example:     DurableSubscriberConfig{},
+	},
 }
 
 var FrameOutputsRegistry = []EntityInfo{
@@ -48,6 +53,10 @@ var FrameOutputsRegistry = []EntityInfo{
 		Type:        FrameOutputTypeLoki,
 		Description: "output frame as JSON to Loki",
 	},
+	{
+		Type:        FrameOutputTypeDurable,
+		Description: "append frame to the durable log of the channel and send it to local subscribers with its offset",
+	},
 }
 
 var ConvertersRegistry = []EntityInfo{
@@ -66,6 +75,18 @@ var ConvertersRegistry = []EntityInfo{
 		Type:        ConverterTypeJsonFrame,
 		Description: "JSON-encoded Grafana data frame",
 	},
+	{
+		Type:        ConverterTypePrometheus,
+		Description: "accept Prometheus text exposition format or OpenMetrics text",
+		Example:     PrometheusConverterConfig{},
+	},
+	{
+		Type:        ConverterTypeCSV,
+		Description: "accept CSV lines with a header, inferring the type of each column",
+		Example: CSVConverterConfig{
+			LabelFields: []string{"host"},
+		},
+	},
 }
 
 var FrameProcessorsRegistry = []EntityInfo{
@@ -79,6 +100,36 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeRenameFields,
+		Description: "rename fields and labels, and add labels to fields",
+		Example: RenameFieldsFrameProcessorConfig{
+			FieldNames: map[string]string{"temp": "temperature"},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeExpression,
+		Description: "compute a field from the other fields of each row with a math expression",
+		Example: ExpressionFrameProcessorConfig{
+			FieldName:  "temperature_f",
+			Expression: "$temperature * 1.8 + 32",
+		},
+	},
+	{
+		Type:        FrameProcessorTypeConvertUnits,
+		Description: "convert the values of fields to another unit",
+		Example: ConvertUnitsFrameProcessorConfig{
+			Conversions: []UnitConversion{{FieldName: "temperature", From: "celsius", To: "fahrenheit"}},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeDownsample,
+		Description: "aggregate the rows of a channel to a row per time bucket",
+		Example: DownsampleFrameProcessorConfig{
+			IntervalMilliseconds: 10000,
+			Aggregation:          "mean",
+		},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
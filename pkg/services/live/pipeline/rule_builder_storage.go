@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/centrifugal/centrifuge"
@@ -17,6 +18,7 @@ type StorageRuleBuilder struct {
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
+	FrameLog             FrameLog
 }
 
 func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
@@ -29,6 +31,14 @@ func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscr
 		return NewBuiltinSubscriber(f.ChannelHandlerGetter), nil
 	case SubscriberTypeManagedStream:
 		return NewManagedStreamSubscriber(f.ManagedStream), nil
+	case SubscriberTypeDurable:
+		if f.FrameLog == nil {
+			return nil, errors.New("durable frame log is not available")
+		}
+		if config.DurableSubscriberConfig == nil {
+			config.DurableSubscriberConfig = &DurableSubscriberConfig{}
+		}
+		return NewDurableSubscriber(f.FrameLog, *config.DurableSubscriberConfig), nil
 	case SubscriberTypeMultiple:
 		if config.MultipleSubscriberConfig == nil {
 			return nil, missingConfiguration
@@ -69,6 +79,16 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 			return nil, missingConfiguration
 		}
 		return NewAutoInfluxConverter(*config.AutoInfluxConverterConfig), nil
+	case ConverterTypePrometheus:
+		if config.PrometheusConverterConfig == nil {
+			config.PrometheusConverterConfig = &PrometheusConverterConfig{}
+		}
+		return NewPrometheusConverter(*config.PrometheusConverterConfig), nil
+	case ConverterTypeCSV:
+		if config.CSVConverterConfig == nil {
+			config.CSVConverterConfig = &CSVConverterConfig{}
+		}
+		return NewCSVConverter(*config.CSVConverterConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown converter type: %s", config.Type)
 	}
@@ -104,6 +124,26 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			processors = append(processors, proc)
 		}
 		return NewMultipleFrameProcessor(processors...), nil
+	case FrameProcessorTypeRenameFields:
+		if config.RenameFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewRenameFieldsFrameProcessor(*config.RenameFieldsProcessorConfig), nil
+	case FrameProcessorTypeExpression:
+		if config.ExpressionProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewExpressionFrameProcessor(*config.ExpressionProcessorConfig)
+	case FrameProcessorTypeConvertUnits:
+		if config.ConvertUnitsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewConvertUnitsFrameProcessor(*config.ConvertUnitsProcessorConfig)
+	case FrameProcessorTypeDownsample:
+		if config.DownsampleProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewDownsampleFrameProcessor(*config.DownsampleProcessorConfig)
 	default:
 		return nil, fmt.Errorf("unknown processor type: %s", config.Type)
 	}
@@ -249,6 +289,11 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 			return nil, missingConfiguration
 		}
 		return NewChangeLogFrameOutput(f.FrameStorage, *config.ChangeLogOutputConfig), nil
+	case FrameOutputTypeDurable:
+		if f.FrameLog == nil {
+			return nil, errors.New("durable frame log is not available")
+		}
+		return NewDurableFrameOutput(f.FrameLog, f.Node), nil
 	default:
 		return nil, fmt.Errorf("unknown output type: %s", config.Type)
 	}
@@ -0,0 +1,94 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/services/live/model"
+)
+
+const defaultDurableMaxFrames = 1000
+
+// DurableSubscriber lets subscribers resume from an offset of the log of the channel. Subscribers send the offset
+// of the first frame they missed, and get the frames from the offset in the subscribe reply.
+type DurableSubscriber struct {
+	frameLog  FrameLog
+	maxFrames int
+}
+
+const SubscriberTypeDurable = "durable"
+
+func NewDurableSubscriber(frameLog FrameLog, config DurableSubscriberConfig) *DurableSubscriber {
+	maxFrames := config.MaxFrames
+	if maxFrames <= 0 {
+		maxFrames = defaultDurableMaxFrames
+	}
+	return &DurableSubscriber{frameLog: frameLog, maxFrames: maxFrames}
+}
+
+func (s *DurableSubscriber) Type() string {
+	return SubscriberTypeDurable
+}
+
+type durableSubscribeRequest struct {
+	// Offset is the offset of the first frame to get. No frames are sent if it is not set.
+	Offset *uint64 `json:"offset,omitempty"`
+}
+
+// DurableSubscribeReply is the data of the subscribe reply. Publications of the channel start at NextOffset. If More
+// is true, there were more frames than the maximum, and subscribers get the others by subscribing again from the
+// offset after the last frame.
+type DurableSubscribeReply struct {
+	Frames     []DurableFrame `json:"frames,omitempty"`
+	More       bool           `json:"more,omitempty"`
+	NextOffset uint64         `json:"nextOffset"`
+}
+
+type DurableFrame struct {
+	Offset uint64          `json:"offset"`
+	Time   time.Time       `json:"time"`
+	Frame  json.RawMessage `json:"frame"`
+}
+
+func (s *DurableSubscriber) Subscribe(_ context.Context, vars Vars, data []byte) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	var req durableSubscribeRequest
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &req); err != nil {
+			return model.SubscribeReply{}, 0, fmt.Errorf("invalid subscribe data: %w", err)
+		}
+	}
+
+	reply := DurableSubscribeReply{}
+	if req.Offset != nil {
+		entries, err := s.frameLog.Read(vars.OrgID, vars.Channel, *req.Offset, s.maxFrames)
+		if err != nil {
+			return model.SubscribeReply{}, 0, err
+		}
+		for _, e := range entries {
+			reply.Frames = append(reply.Frames, DurableFrame{
+				Offset: e.Offset,
+				Time:   e.Time,
+				Frame:  e.Data,
+			})
+		}
+	}
+
+	next, err := s.frameLog.NextOffset(vars.OrgID, vars.Channel)
+	if err != nil {
+		return model.SubscribeReply{}, 0, err
+	}
+	reply.NextOffset = next
+	if n := len(reply.Frames); n > 0 {
+		reply.More = reply.Frames[n-1].Offset+1 < next
+	}
+
+	replyJSON, err := json.Marshal(reply)
+	if err != nil {
+		return model.SubscribeReply{}, 0, err
+	}
+	return model.SubscribeReply{Data: replyJSON}, backend.SubscribeStreamStatusOK, nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -77,6 +78,45 @@ func TestTsFromFramesMultipleFrames(t *testing.T) {
 	require.Equal(t, 4.0, ts[1].Samples[1].Value)
 }
 
+func TestTsFromFramesWithName(t *testing.T) {
+	now := time.Now()
+	number := data.NewFrame("",
+		data.NewField("", map[string]string{"host": "a"}, []*float64{fp(1.0)}),
+	)
+	series := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{now.Add(-time.Minute), now}),
+		data.NewField("value", map[string]string{"host": "b"}, []*float64{fp(2.0), nil}),
+	)
+	ts, err := TimeSeriesFromFramesWithName("test_metric", now, map[string]string{"team": "x"}, number, series)
+	require.NoError(t, err)
+	require.Len(t, ts, 2)
+
+	require.Equal(t, []prompb.Label{
+		{Name: "host", Value: "a"},
+		{Name: "team", Value: "x"},
+		{Name: "__name__", Value: "test_metric"},
+	}, ts[0].Labels)
+	require.Equal(t, []prompb.Sample{{Timestamp: toSampleTime(now), Value: 1.0}}, ts[0].Samples)
+
+	// the last non-null value of the series is used.
+	require.Equal(t, "b", ts[1].Labels[0].Value)
+	require.Equal(t, []prompb.Sample{{Timestamp: toSampleTime(now), Value: 2.0}}, ts[1].Samples)
+
+	t.Run("fails on invalid metric name", func(t *testing.T) {
+		_, err := TimeSeriesFromFramesWithName("-", now, nil, number)
+		require.Error(t, err)
+	})
+
+	t.Run("fails on duplicate series", func(t *testing.T) {
+		_, err := TimeSeriesFromFramesWithName("test_metric", now, nil, number, number)
+		require.Error(t, err)
+	})
+}
+
+func fp(f float64) *float64 {
+	return &f
+}
+
 func TestSerialize(t *testing.T) {
 	frame := data.NewFrame("test",
 		data.NewField("time", nil, []time.Time{time.Now(), time.Now().Add(time.Second)}),
@@ -36,7 +36,7 @@ type ExternalAlertmanagerProvider interface {
 
 type Alertmanager interface {
 	// Configuration
-	SaveAndApplyConfig(ctx context.Context, config *apimodels.PostableUserConfig) error
+	SaveAndApplyConfig(ctx context.Context, config *apimodels.PostableUserConfig, createdBy string) error
 	SaveAndApplyDefaultConfig(ctx context.Context) error
 	GetStatus() apimodels.GettableStatus
 
@@ -45,6 +45,7 @@ type Alertmanager interface {
 	DeleteSilence(silenceID string) error
 	GetSilence(silenceID string) (apimodels.GettableSilence, error)
 	ListSilences(filter []string) (apimodels.GettableSilences, error)
+	GetSilencedAlerts(silenceID string) (apimodels.GettableAlerts, error)
 
 	// Alerts
 	GetAlerts(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.GettableAlerts, error)
@@ -115,6 +116,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		NewLotexRuler(proxy, logger),
 		&RulerSrv{
 			conditionValidator: api.EvaluatorFactory,
+			amConfigStore:      api.AlertingStore,
 			QuotaService:       api.QuotaService,
 			scheduleService:    api.Schedule,
 			store:              api.RuleStore,
@@ -133,7 +135,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			accessControl:   api.AccessControl,
 			evaluator:       api.EvaluatorFactory,
 			cfg:             &api.Cfg.UnifiedAlerting,
-			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory),
+			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory, api.Policies, api.MuteTimings),
 			featureManager:  api.FeatureManager,
 		}), m)
 	api.RegisterConfigurationApiEndpoints(NewConfiguration(
@@ -147,6 +149,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 
 	api.RegisterProvisioningApiEndpoints(NewProvisioningApi(&ProvisioningSrv{
 		log:                 logger,
+		ac:                  api.AccessControl,
 		policies:            api.Policies,
 		contactPointService: api.ContactPointService,
 		templates:           api.Templates,
@@ -25,6 +25,7 @@ import (
 const (
 	defaultTestReceiversTimeout = 15 * time.Second
 	maxTestReceiversTimeout     = 30 * time.Second
+	defaultConfigHistoryLimit   = 100
 )
 
 type AlertmanagerSrv struct {
@@ -132,6 +133,96 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *contextmodel.ReqContext) re
 	return response.JSON(http.StatusOK, config)
 }
 
+func (srv AlertmanagerSrv) RouteGetAlertingConfigHistory(c *contextmodel.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	if limit < 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("limit must not be negative"), "")
+	}
+	if limit == 0 {
+		limit = defaultConfigHistoryLimit
+	}
+	history, err := srv.mam.GetAlertmanagerConfigurationHistory(c.Req.Context(), c.OrgID, limit)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, apimodels.GettableHistoricUserConfigs(history))
+}
+
+func (srv AlertmanagerSrv) RouteGetAlertingConfigHistoryDiff(c *contextmodel.ReqContext) response.Response {
+	from, err := strconv.ParseInt(c.Query("from"), 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse from")
+	}
+	to, err := strconv.ParseInt(c.Query("to"), 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse to")
+	}
+	diff, err := srv.mam.DiffAlertmanagerConfigurations(c.Req.Context(), c.OrgID, from, to)
+	if err != nil {
+		if errors.Is(err, store.ErrHistoricalConfigurationNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, diff)
+}
+
+func (srv AlertmanagerSrv) RoutePostAlertingConfigHistoryActivate(c *contextmodel.ReqContext, id string) response.Response {
+	configID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse id")
+	}
+	historic, err := srv.mam.GetHistoricalConfiguration(c.Req.Context(), c.OrgID, configID)
+	if err != nil {
+		if errors.Is(err, store.ErrHistoricalConfigurationNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	currentConfig, err := srv.mam.GetAlertmanagerConfiguration(c.Req.Context(), c.OrgID)
+	// If a config is present and valid we proceed with the guard, otherwise we
+	// just bypass the guard which is okay as we are anyway in an invalid state.
+	if err == nil {
+		if err := srv.provenanceGuard(currentConfig, withoutSecureSettings(*historic)); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+	}
+	err = srv.mam.ActivateHistoricalConfiguration(c.Req.Context(), c.OrgID, configID, c.SignedInUser.Login)
+	if err == nil {
+		return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration activated"})
+	}
+	if errors.Is(err, store.ErrHistoricalConfigurationNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	var configRejectedError notifier.AlertmanagerConfigRejectedError
+	if errors.As(err, &configRejectedError) {
+		return ErrResp(http.StatusBadRequest, configRejectedError, "")
+	}
+	if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	}
+	return ErrResp(http.StatusInternalServerError, err, "")
+}
+
+// withoutSecureSettings returns a copy of the configuration without the secure settings of the contact points.
+// The secure settings of stored configurations are encrypted, and the provenance guard would take every one of them
+// for a change of a provisioned contact point.
+func withoutSecureSettings(cfg apimodels.PostableUserConfig) apimodels.PostableUserConfig {
+	receivers := make([]*apimodels.PostableApiReceiver, 0, len(cfg.AlertmanagerConfig.Receivers))
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		receiver := *r
+		receiver.GrafanaManagedReceivers = make([]*apimodels.PostableGrafanaReceiver, 0, len(r.GrafanaManagedReceivers))
+		for _, cp := range r.GrafanaManagedReceivers {
+			contactPoint := *cp
+			contactPoint.SecureSettings = nil
+			receiver.GrafanaManagedReceivers = append(receiver.GrafanaManagedReceivers, &contactPoint)
+		}
+		receivers = append(receivers, &receiver)
+	}
+	cfg.AlertmanagerConfig.Receivers = receivers
+	return cfg
+}
+
 func (srv AlertmanagerSrv) RouteGetAMAlertGroups(c *contextmodel.ReqContext) response.Response {
 	am, errResp := srv.AlertmanagerFor(c.OrgID)
 	if errResp != nil {
@@ -200,6 +291,23 @@ func (srv AlertmanagerSrv) RouteGetSilence(c *contextmodel.ReqContext, silenceID
 	return response.JSON(http.StatusOK, gettableSilence)
 }
 
+func (srv AlertmanagerSrv) RouteGetSilencedAlerts(c *contextmodel.ReqContext, silenceID string) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	alerts, err := am.GetSilencedAlerts(silenceID)
+	if err != nil {
+		if errors.Is(err, alertingNotify.ErrSilenceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		// any other error here should be an unexpected failure and thus an internal error
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, alerts)
+}
+
 func (srv AlertmanagerSrv) RouteGetSilences(c *contextmodel.ReqContext) response.Response {
 	am, errResp := srv.AlertmanagerFor(c.OrgID)
 	if errResp != nil {
@@ -226,7 +334,7 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *contextmodel.ReqContext, b
 			return ErrResp(http.StatusBadRequest, err, "")
 		}
 	}
-	err = srv.mam.ApplyAlertmanagerConfiguration(c.Req.Context(), c.OrgID, body)
+	err = srv.mam.ApplyAlertmanagerConfiguration(c.Req.Context(), c.OrgID, body, c.SignedInUser.Login)
 	if err == nil {
 		return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration created"})
 	}
@@ -291,6 +291,99 @@ func TestAlertmanagerConfig(t *testing.T) {
 	})
 }
 
+func TestAlertmanagerConfigHistory(t *testing.T) {
+	requestCtx := func(url string) *contextmodel.ReqContext {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		return &contextmodel.ReqContext{
+			Context: &web.Context{
+				Req: req,
+			},
+			SignedInUser: &user.SignedInUser{
+				OrgID: 1,
+				Login: "admin",
+			},
+		}
+	}
+	// postConfigs saves validConfig and then a config with a changed template.
+	postConfigs := func(t *testing.T, sut AlertmanagerSrv) {
+		t.Helper()
+		request := createAmConfigRequest(t)
+		require.Equal(t, http.StatusAccepted, sut.RoutePostAlertingConfig(requestCtx(""), request).Status())
+		request = createAmConfigRequest(t)
+		request.TemplateFiles["a"] = "changed"
+		require.Equal(t, http.StatusAccepted, sut.RoutePostAlertingConfig(requestCtx(""), request).Status())
+	}
+
+	t.Run("GET history returns saved configs newest first", func(t *testing.T) {
+		sut := createSut(t, nil)
+		postConfigs(t, sut)
+
+		response := sut.RouteGetAlertingConfigHistory(requestCtx("/?limit=1"))
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var body apimodels.GettableHistoricUserConfigs
+		require.NoError(t, json.Unmarshal(response.Body(), &body))
+		require.Len(t, body, 1)
+		require.Equal(t, "changed", body[0].TemplateFiles["a"])
+		require.Equal(t, "admin", body[0].CreatedBy)
+	})
+
+	t.Run("GET history diff", func(t *testing.T) {
+		sut := createSut(t, nil)
+		postConfigs(t, sut)
+
+		t.Run("returns 400 if versions are not numbers", func(t *testing.T) {
+			response := sut.RouteGetAlertingConfigHistoryDiff(requestCtx("/?from=a&to=2"))
+			require.Equal(t, http.StatusBadRequest, response.Status())
+		})
+		t.Run("returns 404 if version does not exist", func(t *testing.T) {
+			response := sut.RouteGetAlertingConfigHistoryDiff(requestCtx("/?from=1&to=10"))
+			require.Equal(t, http.StatusNotFound, response.Status())
+		})
+		t.Run("returns diff of versions", func(t *testing.T) {
+			response := sut.RouteGetAlertingConfigHistoryDiff(requestCtx("/?from=1&to=2"))
+			require.Equal(t, http.StatusOK, response.Status())
+			var body apimodels.GettableHistoricUserConfigDiff
+			require.NoError(t, json.Unmarshal(response.Body(), &body))
+			require.Contains(t, body.Diff, "-    a: template")
+			require.Contains(t, body.Diff, "+    a: changed")
+		})
+	})
+
+	t.Run("POST activate", func(t *testing.T) {
+		t.Run("returns 400 if id is not a number", func(t *testing.T) {
+			sut := createSut(t, nil)
+			response := sut.RoutePostAlertingConfigHistoryActivate(requestCtx(""), "a")
+			require.Equal(t, http.StatusBadRequest, response.Status())
+		})
+		t.Run("returns 404 if id does not exist", func(t *testing.T) {
+			sut := createSut(t, nil)
+			response := sut.RoutePostAlertingConfigHistoryActivate(requestCtx(""), "10")
+			require.Equal(t, http.StatusNotFound, response.Status())
+		})
+		t.Run("applies previous config", func(t *testing.T) {
+			sut := createSut(t, nil)
+			postConfigs(t, sut)
+
+			response := sut.RoutePostAlertingConfigHistoryActivate(requestCtx(""), "1")
+			require.Equal(t, http.StatusAccepted, response.Status())
+
+			body := asGettableUserConfig(t, sut.RouteGetAlertingConfig(createRequestCtxInOrg(1)))
+			require.Equal(t, "template", body.TemplateFiles["a"])
+		})
+		t.Run("returns 400 if previous config changes provisioned objects", func(t *testing.T) {
+			sut := createSut(t, nil)
+			postConfigs(t, sut)
+			setTemplateProvenance(t, 1, "a", sut.mam.ProvStore)
+
+			response := sut.RoutePostAlertingConfigHistoryActivate(requestCtx(""), "1")
+			require.Equal(t, http.StatusBadRequest, response.Status())
+			require.Contains(t, string(response.Body()), "cannot save provisioned template 'a'")
+		})
+	})
+}
+
 func TestSilenceCreate(t *testing.T) {
 	makeSilence := func(comment string, createdBy string,
 		startsAt, endsAt strfmt.DateTime, matchers amv2.Matchers) amv2.Silence {
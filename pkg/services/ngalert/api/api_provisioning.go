@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -22,6 +24,7 @@ const disableProvenanceHeaderName = "X-Disable-Provenance"
 
 type ProvisioningSrv struct {
 	log                 log.Logger
+	ac                  accesscontrol.AccessControl
 	policies            NotificationPolicyService
 	contactPointService ContactPointService
 	templates           TemplateService
@@ -80,6 +83,23 @@ func (srv *ProvisioningSrv) RouteGetPolicyTree(c *contextmodel.ReqContext) respo
 	return response.JSON(http.StatusOK, policies)
 }
 
+// RouteGetPolicyTreeExport retrieves the notification policy tree in a format compatible with file provisioning.
+func (srv *ProvisioningSrv) RouteGetPolicyTreeExport(c *contextmodel.ReqContext) response.Response {
+	policies, err := srv.policies.GetPolicyTree(c.Req.Context(), c.OrgID)
+	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	e := file.AlertingFileExport{
+		APIVersion: 1,
+		Policies:   []file.NotificationPolicyExport{definitions.NewNotificationPolicyExport(c.OrgID, policies)},
+	}
+	return exportResponse(c, e)
+}
+
 func (srv *ProvisioningSrv) RoutePutPolicyTree(c *contextmodel.ReqContext, tree definitions.Route) response.Response {
 	err := srv.policies.UpdatePolicyTree(c.Req.Context(), c.OrgID, tree, alerting_models.ProvenanceAPI)
 	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
@@ -115,6 +135,29 @@ func (srv *ProvisioningSrv) RouteGetContactPoints(c *contextmodel.ReqContext) re
 	return response.JSON(http.StatusOK, cps)
 }
 
+// RouteGetContactPointsExport retrieves the contact points in a format compatible with file provisioning.
+// The secure settings are redacted unless the user is allowed to read them and asks to decrypt them.
+func (srv *ProvisioningSrv) RouteGetContactPointsExport(c *contextmodel.ReqContext) response.Response {
+	q := provisioning.ContactPointQuery{
+		Name:    c.Query("name"),
+		OrgID:   c.OrgID,
+		Decrypt: c.QueryBoolWithDefault("decrypt", false),
+	}
+	if q.Decrypt && !accesscontrol.HasAccess(srv.ac, c)(accesscontrol.ReqOrgAdmin, accesscontrol.EvalPermission(accesscontrol.ActionAlertingProvisioningReadSecrets)) {
+		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "user is not allowed to decrypt secure settings")
+	}
+	cps, err := srv.contactPointService.GetContactPoints(c.Req.Context(), q)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	contactPoints, err := definitions.NewContactPointExports(c.OrgID, cps)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
+	}
+	return exportResponse(c, file.AlertingFileExport{APIVersion: 1, ContactPoints: contactPoints})
+}
+
 func (srv *ProvisioningSrv) RoutePostContactPoint(c *contextmodel.ReqContext, cp definitions.EmbeddedContactPoint) response.Response {
 	// TODO: provenance is hardcoded for now, change it later to make it more flexible
 	contactPoint, err := srv.contactPointService.CreateContactPoint(c.Req.Context(), c.OrgID, cp, alerting_models.ProvenanceAPI)
@@ -173,6 +216,41 @@ func (srv *ProvisioningSrv) RouteGetTemplate(c *contextmodel.ReqContext, name st
 	return response.Empty(http.StatusNotFound)
 }
 
+// RouteGetTemplatesExport retrieves all notification templates in a format compatible with file provisioning.
+func (srv *ProvisioningSrv) RouteGetTemplatesExport(c *contextmodel.ReqContext) response.Response {
+	templates, err := srv.templates.GetTemplates(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	e := file.AlertingFileExport{APIVersion: 1}
+	for _, name := range names {
+		e.Templates = append(e.Templates, definitions.NewNotificationTemplateExport(c.OrgID, definitions.NotificationTemplate{Name: name, Template: templates[name]}))
+	}
+	return exportResponse(c, e)
+}
+
+// RouteGetTemplateExport retrieves the given notification template in a format compatible with file provisioning.
+func (srv *ProvisioningSrv) RouteGetTemplateExport(c *contextmodel.ReqContext, name string) response.Response {
+	templates, err := srv.templates.GetTemplates(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	tmpl, ok := templates[name]
+	if !ok {
+		return response.Empty(http.StatusNotFound)
+	}
+	e := file.AlertingFileExport{
+		APIVersion: 1,
+		Templates:  []file.NotificationTemplateExport{definitions.NewNotificationTemplateExport(c.OrgID, definitions.NotificationTemplate{Name: name, Template: tmpl})},
+	}
+	return exportResponse(c, e)
+}
+
 func (srv *ProvisioningSrv) RoutePutTemplate(c *contextmodel.ReqContext, body definitions.NotificationTemplateContent, name string) response.Response {
 	tmpl := definitions.NotificationTemplate{
 		Name:       name,
@@ -218,6 +296,37 @@ func (srv *ProvisioningSrv) RouteGetMuteTimings(c *contextmodel.ReqContext) resp
 	return response.JSON(http.StatusOK, timings)
 }
 
+// RouteGetMuteTimingsExport retrieves all mute timings in a format compatible with file provisioning.
+func (srv *ProvisioningSrv) RouteGetMuteTimingsExport(c *contextmodel.ReqContext) response.Response {
+	timings, err := srv.muteTimings.GetMuteTimings(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	e := file.AlertingFileExport{APIVersion: 1}
+	for _, timing := range timings {
+		e.MuteTimes = append(e.MuteTimes, definitions.NewMuteTimeIntervalExport(c.OrgID, timing))
+	}
+	return exportResponse(c, e)
+}
+
+// RouteGetMuteTimingExport retrieves the given mute timing in a format compatible with file provisioning.
+func (srv *ProvisioningSrv) RouteGetMuteTimingExport(c *contextmodel.ReqContext, name string) response.Response {
+	timings, err := srv.muteTimings.GetMuteTimings(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	for _, timing := range timings {
+		if name == timing.Name {
+			e := file.AlertingFileExport{
+				APIVersion: 1,
+				MuteTimes:  []file.MuteTimeIntervalExport{definitions.NewMuteTimeIntervalExport(c.OrgID, timing)},
+			}
+			return exportResponse(c, e)
+		}
+	}
+	return response.Empty(http.StatusNotFound)
+}
+
 func (srv *ProvisioningSrv) RoutePostMuteTiming(c *contextmodel.ReqContext, mt definitions.MuteTimeInterval) response.Response {
 	mt.Provenance = alerting_models.ProvenanceAPI
 	created, err := srv.muteTimings.CreateMuteTiming(c.Req.Context(), mt, c.OrgID)
@@ -385,10 +494,11 @@ func (srv *ProvisioningSrv) RouteGetAlertRuleExport(c *contextmodel.ReqContext,
 
 	e, err := file.NewAlertingFileExport([]file.AlertRuleGroupWithFolderTitle{{
 		AlertRuleGroup: &alerting_models.AlertRuleGroup{
-			Title:     rule.AlertRule.RuleGroup,
-			FolderUID: rule.AlertRule.NamespaceUID,
-			Interval:  rule.AlertRule.IntervalSeconds,
-			Rules:     []alerting_models.AlertRule{rule.AlertRule},
+			Title:             rule.AlertRule.RuleGroup,
+			FolderUID:         rule.AlertRule.NamespaceUID,
+			Interval:          rule.AlertRule.IntervalSeconds,
+			EvaluationTimeout: rule.AlertRule.GroupEvaluationTimeout,
+			Rules:             []alerting_models.AlertRule{rule.AlertRule},
 		},
 		OrgID:       c.OrgID,
 		FolderTitle: rule.FolderTitle,
@@ -439,12 +549,19 @@ func exportResponse(c *contextmodel.ReqContext, body any) response.Response {
 		format = "json"
 	}
 
+	if strings.Contains(acceptHeader, "hcl") {
+		format = "hcl"
+	}
+
 	queryFormat := c.Query("format")
-	if queryFormat == "yaml" || queryFormat == "json" {
+	if queryFormat == "yaml" || queryFormat == "json" || queryFormat == "hcl" {
 		format = queryFormat
 	}
 
 	download := c.QueryBoolWithDefault("download", false)
+	if format == "hcl" {
+		return hclResponse(body, download)
+	}
 	if download {
 		r := response.JSONDownload
 		if format == "yaml" {
@@ -459,3 +576,15 @@ func exportResponse(c *contextmodel.ReqContext, body any) response.Response {
 	}
 	return r(http.StatusOK, body)
 }
+
+func hclResponse(body any, download bool) response.Response {
+	b, err := encodeHCL(body)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "body hcl marshal")
+	}
+	r := response.Respond(http.StatusOK, b).SetHeader("Content-Type", "text/hcl")
+	if download {
+		r = r.SetHeader("Content-Disposition", `attachment;filename="export.hcl"`)
+	}
+	return r
+}
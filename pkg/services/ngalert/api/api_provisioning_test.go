@@ -19,6 +19,8 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -802,6 +804,134 @@ func TestProvisioningApi(t *testing.T) {
 				require.Equal(t, expectedResponse, string(response.Body()))
 			})
 		})
+
+		t.Run("contact points", func(t *testing.T) {
+			t.Run("GET redacts secure settings", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				rc.Context.Req.Form.Set("format", "json")
+
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				expectedResponse := `{"apiVersion":1,"contactPoints":[{"orgId":1,"name":"email receiver","receivers":[{"uid":"email-uid","type":"email","settings":{"addresses":"\u003cexample@email.com\u003e"},"disableResolveMessage":false}]},{"orgId":1,"name":"webhook","receivers":[{"uid":"webhook-uid","type":"webhook","settings":{"password":"[REDACTED]","url":"http://localhost/hook"},"disableResolveMessage":false}]}]}`
+				require.Equal(t, expectedResponse, string(response.Body()))
+			})
+
+			t.Run("GET filters by name", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				rc.Context.Req.Form.Set("name", "webhook")
+
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				expectedResponse := "apiVersion: 1\ncontactPoints:\n    - orgId: 1\n      name: webhook\n      receivers:\n        - uid: webhook-uid\n          type: webhook\n          settings:\n            password: '[REDACTED]'\n            url: http://localhost/hook\n          disableResolveMessage: false\n"
+				require.Equal(t, expectedResponse, string(response.Body()))
+			})
+
+			t.Run("decrypt=true without permission, GET returns 403", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				rc.Context.Req.Form.Set("decrypt", "true")
+
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 403, response.Status())
+			})
+
+			t.Run("decrypt=true with permission, GET returns secure settings", func(t *testing.T) {
+				env := createTestEnv(t)
+				env.ac = acmock.New().WithPermissions([]accesscontrol.Permission{{Action: accesscontrol.ActionAlertingProvisioningReadSecrets}})
+				sut := createProvisioningSrvSutFromEnv(t, &env)
+				rc := createTestRequestCtx()
+				rc.Context.Req.Form.Set("decrypt", "true")
+				rc.Context.Req.Form.Set("name", "webhook")
+
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Contains(t, string(response.Body()), "password: secret\n")
+			})
+		})
+
+		t.Run("notification policies", func(t *testing.T) {
+			t.Run("yaml body content is as expected", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				response := sut.RouteGetPolicyTreeExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "apiVersion: 1\npolicies:\n    - orgId: 1\n      receiver: some-receiver\n", string(response.Body()))
+			})
+
+			t.Run("org has no AM config, GET returns 404", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				rc.SignedInUser.OrgID = 2
+
+				response := sut.RouteGetPolicyTreeExport(&rc)
+
+				require.Equal(t, 404, response.Status())
+			})
+		})
+
+		t.Run("mute timings", func(t *testing.T) {
+			t.Run("json body content is as expected", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				rc.Context.Req.Header.Add("Accept", "application/json")
+
+				response := sut.RouteGetMuteTimingsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, `{"apiVersion":1,"muteTimes":[{"orgId":1,"name":"interval","time_intervals":[]}]}`, string(response.Body()))
+			})
+
+			t.Run("are missing, GET returns 404", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				response := sut.RouteGetMuteTimingExport(&rc, "does not exist")
+
+				require.Equal(t, 404, response.Status())
+			})
+		})
+
+		t.Run("templates", func(t *testing.T) {
+			t.Run("yaml body content is as expected", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				response := sut.RouteGetTemplateExport(&rc, "a")
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "apiVersion: 1\ntemplates:\n    - orgId: 1\n      name: a\n      template: template\n", string(response.Body()))
+			})
+
+			t.Run("are missing, GET returns 404", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				response := sut.RouteGetTemplateExport(&rc, "does not exist")
+
+				require.Equal(t, 404, response.Status())
+			})
+
+			t.Run("query format contains hcl, GET returns text hcl", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				rc.Context.Req.Form.Set("format", "hcl")
+
+				response := sut.RouteGetTemplatesExport(&rc)
+				response.WriteTo(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "text/hcl", rc.Context.Resp.Header().Get("Content-Type"))
+				require.Equal(t, "apiVersion = 1\ntemplates {\n  orgId    = 1\n  name     = \"a\"\n  template = \"template\"\n}\n", string(response.Body()))
+			})
+		})
 	})
 }
 
@@ -815,6 +945,7 @@ type testEnvironment struct {
 	xact             provisioning.TransactionManager
 	quotas           provisioning.QuotaChecker
 	prov             provisioning.ProvisioningStore
+	ac               *acmock.Mock
 }
 
 func createTestEnv(t *testing.T) testEnvironment {
@@ -864,6 +995,7 @@ func createTestEnv(t *testing.T) testEnvironment {
 		xact:             xact,
 		prov:             prov,
 		quotas:           quotas,
+		ac:               acmock.New(),
 	}
 }
 
@@ -879,11 +1011,12 @@ func createProvisioningSrvSutFromEnv(t *testing.T, env *testEnvironment) Provisi
 
 	return ProvisioningSrv{
 		log:                 env.log,
+		ac:                  env.ac,
 		policies:            newFakeNotificationPolicyService(),
 		contactPointService: provisioning.NewContactPointService(env.configs, env.secrets, env.prov, env.xact, env.log),
 		templates:           provisioning.NewTemplateService(env.configs, env.prov, env.xact, env.log),
 		muteTimings:         provisioning.NewMuteTimingService(env.configs, env.prov, env.xact, env.log),
-		alertRules:          provisioning.NewAlertRuleService(env.store, env.prov, env.dashboardService, env.quotas, env.xact, 60, 10, env.log),
+		alertRules:          provisioning.NewAlertRuleService(env.store, env.prov, env.configs, env.dashboardService, env.quotas, env.xact, 60, 10, env.log),
 	}
 }
 
@@ -1117,6 +1250,20 @@ var testConfig = `
 					"addresses": "<example@email.com>"
 				}
 			}]
+		},
+		{
+			"name": "webhook",
+			"grafana_managed_receiver_configs": [{
+				"uid": "webhook-uid",
+				"name": "webhook",
+				"type": "webhook",
+				"settings": {
+					"url": "http://localhost/hook"
+				},
+				"secureSettings": {
+					"password": "c2VjcmV0"
+				}
+			}]
 		}],
 		"mute_time_intervals": [{
 			"name": "interval",
@@ -42,6 +42,7 @@ type RulerSrv struct {
 	cfg                *setting.UnifiedAlertingSettings
 	ac                 accesscontrol.AccessControl
 	conditionValidator ConditionValidator
+	amConfigStore      AlertingStore
 }
 
 var (
@@ -100,6 +101,7 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceT
 			deletionCandidates[key] = append(deletionCandidates[key], rule)
 		}
 		rulesToDelete := make([]string, 0, len(q.Result))
+		deleted := make([]*ngmodels.AlertRule, 0, len(q.Result))
 		for groupKey, rules := range deletionCandidates {
 			if !authorizeAccessToRuleGroup(rules, hasAccess) {
 				unauthz = true
@@ -116,9 +118,13 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceT
 				keys = append(keys, rule.GetKey())
 			}
 			rulesToDelete = append(rulesToDelete, uid...)
+			deleted = append(deleted, rules...)
 			deletedGroups[groupKey] = keys
 		}
 		if len(rulesToDelete) > 0 {
+			if err := srv.verifyRuleDependencies(ctx, c.SignedInUser.OrgID, &store.GroupDelta{Delete: deleted}); err != nil {
+				return err
+			}
 			return srv.store.DeleteAlertRulesByUID(ctx, c.SignedInUser.OrgID, rulesToDelete...)
 		}
 		// if none rules were deleted return an error.
@@ -139,7 +145,7 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceT
 		if errors.Is(err, ErrAuthorization) {
 			return ErrResp(http.StatusUnauthorized, err, "failed to delete rule group")
 		}
-		if errors.Is(err, errProvisionedResource) {
+		if errors.Is(err, errProvisionedResource) || errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
 			return ErrResp(http.StatusBadRequest, err, "failed to delete rule group")
 		}
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete rule group")
@@ -313,6 +319,13 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *contextmodel.ReqContext, ruleGro
 		return ErrResp(http.StatusBadRequest, err, "")
 	}
 
+	if err := srv.validateNotificationSettings(c.Req.Context(), c.SignedInUser.OrgID, rules); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
 	groupKey := ngmodels.AlertRuleGroupKey{
 		OrgID:        c.SignedInUser.OrgID,
 		NamespaceUID: namespace.UID,
@@ -322,6 +335,16 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *contextmodel.ReqContext, ruleGro
 	return srv.updateAlertRulesInGroup(c, groupKey, rules)
 }
 
+// validateNotificationSettings checks that the contact points and the mute timings of the notification settings of
+// the rules exist in the Alertmanager configuration of the organization.
+func (srv RulerSrv) validateNotificationSettings(ctx context.Context, orgID int64, rules []*ngmodels.AlertRuleWithOptionals) error {
+	alertRules := make([]*ngmodels.AlertRule, 0, len(rules))
+	for _, rule := range rules {
+		alertRules = append(alertRules, &rule.AlertRule)
+	}
+	return provisioning.ValidateNotificationSettings(ctx, srv.amConfigStore, orgID, alertRules)
+}
+
 // updateAlertRulesInGroup calculates changes (rules to add,update,delete), verifies that the user is authorized to do the calculated changes and updates database.
 // All operations are performed in a single transaction
 func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) response.Response {
@@ -354,6 +377,10 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 			return err
 		}
 
+		if err := srv.verifyRuleDependencies(tranCtx, groupKey.OrgID, groupChanges); err != nil {
+			return err
+		}
+
 		finalChanges = store.UpdateCalculatedRuleFields(groupChanges)
 		logger.Debug("updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
 
@@ -446,17 +473,19 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 func toGettableRuleGroupConfig(groupName string, rules ngmodels.RulesGroup, namespaceID int64, provenanceRecords map[string]ngmodels.Provenance) apimodels.GettableRuleGroupConfig {
 	rules.SortByGroupIndex()
 	ruleNodes := make([]apimodels.GettableExtendedRuleNode, 0, len(rules))
-	var interval time.Duration
+	var interval, evaluationTimeout time.Duration
 	if len(rules) > 0 {
 		interval = time.Duration(rules[0].IntervalSeconds) * time.Second
+		evaluationTimeout = rules[0].GroupEvaluationTimeout
 	}
 	for _, r := range rules {
 		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespaceID, provenanceRecords))
 	}
 	return apimodels.GettableRuleGroupConfig{
-		Name:     groupName,
-		Interval: model.Duration(interval),
-		Rules:    ruleNodes,
+		Name:              groupName,
+		Interval:          model.Duration(interval),
+		EvaluationTimeout: model.Duration(evaluationTimeout),
+		Rules:             ruleNodes,
 	}
 }
 
@@ -467,30 +496,54 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provena
 	}
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
-			ID:              r.ID,
-			OrgID:           r.OrgID,
-			Title:           r.Title,
-			Condition:       r.Condition,
-			Data:            r.Data,
-			Updated:         r.Updated,
-			IntervalSeconds: r.IntervalSeconds,
-			Version:         r.Version,
-			UID:             r.UID,
-			NamespaceUID:    r.NamespaceUID,
-			NamespaceID:     namespaceID,
-			RuleGroup:       r.RuleGroup,
-			NoDataState:     apimodels.NoDataState(r.NoDataState),
-			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
-			Provenance:      provenance,
-			IsPaused:        r.IsPaused,
+			ID:                r.ID,
+			OrgID:             r.OrgID,
+			Title:             r.Title,
+			Condition:         r.Condition,
+			Data:              r.Data,
+			Updated:           r.Updated,
+			IntervalSeconds:   r.IntervalSeconds,
+			Version:           r.Version,
+			UID:               r.UID,
+			NamespaceUID:      r.NamespaceUID,
+			NamespaceID:       namespaceID,
+			RuleGroup:         r.RuleGroup,
+			NoDataState:       apimodels.NoDataState(r.NoDataState),
+			ExecErrState:      apimodels.ExecutionErrorState(r.ExecErrState),
+			Provenance:        provenance,
+			IsPaused:          r.IsPaused,
+			DependsOn:         r.DependsOn,
+			EvaluationTimeout: model.Duration(r.EvaluationTimeout),
+			MaxSeriesPerQuery: r.MaxSeriesPerQuery,
 		},
 	}
+	if r.NotificationSettings != nil {
+		gettableExtendedRuleNode.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{
+			Receiver:          r.NotificationSettings.Receiver,
+			GroupBy:           r.NotificationSettings.GroupBy,
+			GroupWait:         r.NotificationSettings.GroupWait,
+			GroupInterval:     r.NotificationSettings.GroupInterval,
+			RepeatInterval:    r.NotificationSettings.RepeatInterval,
+			MuteTimeIntervals: r.NotificationSettings.MuteTimeIntervals,
+		}
+	}
+	if r.Record != nil {
+		gettableExtendedRuleNode.GrafanaManagedAlert.Record = &apimodels.Record{
+			Metric:              r.Record.Metric,
+			From:                r.Record.From,
+			TargetDatasourceUID: r.Record.TargetDatasourceUID,
+		}
+	}
 	forDuration := model.Duration(r.For)
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
 		For:         &forDuration,
 		Annotations: r.Annotations,
 		Labels:      r.Labels,
 	}
+	if r.KeepFiringFor > 0 {
+		keepFiringFor := model.Duration(r.KeepFiringFor)
+		gettableExtendedRuleNode.ApiRuleNode.KeepFiringFor = &keepFiringFor
+	}
 	return gettableExtendedRuleNode
 }
 
@@ -504,6 +557,43 @@ func toNamespaceErrorResponse(err error) response.Response {
 	return apierrors.ToFolderErrorResponse(err)
 }
 
+// verifyRuleDependencies checks that the dependencies of new and updated rules refer to existing rules of the organization and do not form a cycle,
+// and that no remaining rule depends on a deleted rule.
+func (srv RulerSrv) verifyRuleDependencies(ctx context.Context, orgID int64, ch *store.GroupDelta) error {
+	changed := make([]*ngmodels.AlertRule, 0, len(ch.New)+len(ch.Update))
+	changed = append(changed, ch.New...)
+	// the deleted rules can be dependencies of other rules
+	hasDependencies := len(ch.Delete) > 0
+	for _, rule := range ch.New {
+		hasDependencies = hasDependencies || len(rule.DependsOn) > 0
+	}
+	for _, update := range ch.Update {
+		changed = append(changed, update.New)
+		hasDependencies = hasDependencies || len(update.New.DependsOn) > 0
+	}
+	if !hasDependencies {
+		return nil
+	}
+
+	q := ngmodels.ListAlertRulesQuery{OrgID: orgID}
+	if err := srv.store.ListAlertRules(ctx, &q); err != nil {
+		return fmt.Errorf("failed to fetch alert rules of the organization: %w", err)
+	}
+	orgRules := make(map[string]*ngmodels.AlertRule, len(q.Result)+len(ch.New))
+	for _, rule := range q.Result {
+		orgRules[rule.UID] = rule
+	}
+	for _, rule := range ch.Delete {
+		delete(orgRules, rule.UID)
+	}
+	for _, rule := range changed {
+		if rule.UID != "" {
+			orgRules[rule.UID] = rule
+		}
+	}
+	return ngmodels.ValidateRuleDependencies(append(changed, ngmodels.DependentRules(orgRules, ch.Delete)...), orgRules)
+}
+
 // verifyProvisionedRulesNotAffected check that neither of provisioned alerts are affected by changes.
 // Returns errProvisionedResource if there is at least one rule in groups affected by changes that was provisioned.
 func verifyProvisionedRulesNotAffected(ctx context.Context, provenanceStore provisioning.ProvisioningStore, orgID int64, ch *store.GroupDelta) error {
@@ -40,9 +40,16 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 		RuleUID:      ruleUID,
 		OrgID:        c.OrgID,
 		SignedInUser: c.SignedInUser,
-		From:         time.Unix(from, 0),
-		To:           time.Unix(to, 0),
 		Labels:       labels,
+		Limit:        c.QueryInt("limit"),
+		Offset:       c.QueryInt("offset"),
+	}
+	// A missing time range is left to the defaults of the backend instead of starting at the Unix epoch.
+	if from > 0 {
+		query.From = time.Unix(from, 0)
+	}
+	if to > 0 {
+		query.To = time.Unix(to, 0)
 	}
 	frame, err := srv.hist.QueryStates(c.Req.Context(), query)
 	if err != nil {
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/prometheus/common/model"
+
 	"github.com/grafana/grafana/pkg/services/folder"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -66,9 +68,19 @@ func validateRuleNode(
 		}
 	}
 
+	condition := ruleNode.GrafanaManagedAlert.Condition
+	var record *ngmodels.Record
+	if ruleNode.GrafanaManagedAlert.Record != nil {
+		record, err = validateRecord(ruleNode.GrafanaManagedAlert, cfg)
+		if err != nil {
+			return nil, err
+		}
+		condition = record.From
+	}
+
 	if len(ruleNode.GrafanaManagedAlert.Data) == 0 {
 		if canPatch {
-			if ruleNode.GrafanaManagedAlert.Condition != "" {
+			if condition != "" {
 				return nil, fmt.Errorf("%w: query is not specified by condition is. You must specify both query and condition to update existing alert rule", ngmodels.ErrAlertRuleFailedValidation)
 			}
 		} else {
@@ -78,7 +90,7 @@ func validateRuleNode(
 
 	if len(ruleNode.GrafanaManagedAlert.Data) != 0 {
 		cond := ngmodels.Condition{
-			Condition: ruleNode.GrafanaManagedAlert.Condition,
+			Condition: condition,
 			Data:      ruleNode.GrafanaManagedAlert.Data,
 		}
 		if err = conditionValidator(cond); err != nil {
@@ -89,7 +101,7 @@ func validateRuleNode(
 	newAlertRule := ngmodels.AlertRule{
 		OrgID:           orgId,
 		Title:           ruleNode.GrafanaManagedAlert.Title,
-		Condition:       ruleNode.GrafanaManagedAlert.Condition,
+		Condition:       condition,
 		Data:            ruleNode.GrafanaManagedAlert.Data,
 		UID:             ruleNode.GrafanaManagedAlert.UID,
 		IntervalSeconds: intervalSeconds,
@@ -97,12 +109,54 @@ func validateRuleNode(
 		RuleGroup:       groupName,
 		NoDataState:     noDataState,
 		ExecErrState:    errorState,
+		Record:          record,
+		DependsOn:       ruleNode.GrafanaManagedAlert.DependsOn,
+	}
+
+	for _, uid := range newAlertRule.DependsOn {
+		if uid == "" {
+			return nil, fmt.Errorf("%w: field `depends_on` cannot contain empty UIDs", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if uid == newAlertRule.UID {
+			return nil, fmt.Errorf("%w: rule cannot depend on itself", ngmodels.ErrAlertRuleFailedValidation)
+		}
 	}
 
 	newAlertRule.For, err = validateForInterval(ruleNode)
 	if err != nil {
 		return nil, err
 	}
+	if record != nil && newAlertRule.For > 0 {
+		return nil, fmt.Errorf("%w: field `for` is not supported by recording rules", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
+	newAlertRule.KeepFiringFor, err = validateKeepFiringFor(ruleNode)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil && newAlertRule.KeepFiringFor > 0 {
+		return nil, fmt.Errorf("%w: field `keep_firing_for` is not supported by recording rules", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
+	newAlertRule.EvaluationTimeout, err = validateEvaluationTimeout(ruleNode, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	newAlertRule.MaxSeriesPerQuery, err = validateMaxSeriesPerQuery(ruleNode)
+	if err != nil {
+		return nil, err
+	}
+
+	if ruleNode.GrafanaManagedAlert.NotificationSettings != nil {
+		if record != nil {
+			return nil, fmt.Errorf("%w: field `notification_settings` is not supported by recording rules", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		newAlertRule.NotificationSettings, err = validateNotificationSettings(ruleNode.GrafanaManagedAlert.NotificationSettings)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	if ruleNode.ApiRuleNode != nil {
 		newAlertRule.Annotations = ruleNode.ApiRuleNode.Annotations
@@ -116,6 +170,42 @@ func validateRuleNode(
 	return &newAlertRule, nil
 }
 
+// validateNotificationSettings validates the notification settings of the rule and converts them to models.NotificationSettings.
+// It does not check that the contact point and the mute timings exist.
+func validateNotificationSettings(settings *apimodels.AlertRuleNotificationSettings) (*ngmodels.NotificationSettings, error) {
+	result := &ngmodels.NotificationSettings{
+		Receiver:          settings.Receiver,
+		GroupBy:           settings.GroupBy,
+		GroupWait:         settings.GroupWait,
+		GroupInterval:     settings.GroupInterval,
+		RepeatInterval:    settings.RepeatInterval,
+		MuteTimeIntervals: settings.MuteTimeIntervals,
+	}
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validateRecord validates the recording part of the rule and converts it to models.Record.
+func validateRecord(rule *apimodels.PostableGrafanaRule, cfg *setting.UnifiedAlertingSettings) (*ngmodels.Record, error) {
+	if !cfg.RecordingRules.Enabled {
+		return nil, fmt.Errorf("%w: recording rules are not enabled", ngmodels.ErrAlertRuleFailedValidation)
+	}
+	record := &ngmodels.Record{
+		Metric:              rule.Record.Metric,
+		From:                rule.Record.From,
+		TargetDatasourceUID: rule.Record.TargetDatasourceUID,
+	}
+	if err := record.Validate(); err != nil {
+		return nil, err
+	}
+	if rule.Condition != "" && rule.Condition != record.From {
+		return nil, fmt.Errorf("%w: condition of a recording rule must refer to the recorded query or expression %s", ngmodels.ErrAlertRuleFailedValidation, record.From)
+	}
+	return record, nil
+}
+
 func validateInterval(cfg *setting.UnifiedAlertingSettings, interval time.Duration) (int64, error) {
 	intervalSeconds := int64(interval.Seconds())
 
@@ -147,6 +237,55 @@ func validateForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Dur
 	return duration, nil
 }
 
+// validateKeepFiringFor validates ApiRuleNode.KeepFiringFor and converts it to time.Duration. If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
+func validateKeepFiringFor(ruleNode *apimodels.PostableExtendedRuleNode) (time.Duration, error) {
+	if ruleNode.ApiRuleNode == nil || ruleNode.ApiRuleNode.KeepFiringFor == nil {
+		if ruleNode.GrafanaManagedAlert.UID != "" {
+			return -1, nil // will be patched later with the real value of the current version of the rule
+		}
+		return 0, nil
+	}
+	duration := time.Duration(*ruleNode.ApiRuleNode.KeepFiringFor)
+	if duration < 0 {
+		return 0, fmt.Errorf("field `keep_firing_for` cannot be negative [%v]. 0 or any positive duration are allowed", *ruleNode.ApiRuleNode.KeepFiringFor)
+	}
+	return duration, nil
+}
+
+// validateEvaluationTimeout validates GrafanaManagedAlert.EvaluationTimeout and converts it to time.Duration. The timeout cannot be longer than the evaluation interval.
+// If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
+func validateEvaluationTimeout(ruleNode *apimodels.PostableExtendedRuleNode, interval time.Duration) (time.Duration, error) {
+	if ruleNode.GrafanaManagedAlert.EvaluationTimeout == nil {
+		if ruleNode.GrafanaManagedAlert.UID != "" {
+			return -1, nil // will be patched later with the real value of the current version of the rule
+		}
+		return 0, nil
+	}
+	timeout := time.Duration(*ruleNode.GrafanaManagedAlert.EvaluationTimeout)
+	if timeout < 0 {
+		return 0, fmt.Errorf("%w: field `evaluation_timeout` cannot be negative [%v]. 0 or any positive duration are allowed", ngmodels.ErrAlertRuleFailedValidation, *ruleNode.GrafanaManagedAlert.EvaluationTimeout)
+	}
+	if timeout > interval {
+		return 0, fmt.Errorf("%w: field `evaluation_timeout` [%v] cannot be longer than the evaluation interval [%v]", ngmodels.ErrAlertRuleFailedValidation, *ruleNode.GrafanaManagedAlert.EvaluationTimeout, model.Duration(interval))
+	}
+	return timeout, nil
+}
+
+// validateMaxSeriesPerQuery validates GrafanaManagedAlert.MaxSeriesPerQuery. If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
+func validateMaxSeriesPerQuery(ruleNode *apimodels.PostableExtendedRuleNode) (int64, error) {
+	if ruleNode.GrafanaManagedAlert.MaxSeriesPerQuery == nil {
+		if ruleNode.GrafanaManagedAlert.UID != "" {
+			return -1, nil // will be patched later with the real value of the current version of the rule
+		}
+		return 0, nil
+	}
+	limit := *ruleNode.GrafanaManagedAlert.MaxSeriesPerQuery
+	if limit < 0 {
+		return 0, fmt.Errorf("%w: field `max_series_per_query` cannot be negative [%d]. 0 or any positive number are allowed", ngmodels.ErrAlertRuleFailedValidation, limit)
+	}
+	return limit, nil
+}
+
 // validateRuleGroup validates API model (definitions.PostableRuleGroupConfig) and converts it to a collection of models.AlertRule.
 // Returns a slice that contains all rules described by API model or error if either group specification or an alert definition is not valid.
 // It also returns a map containing current existing alerts that don't contain the is_paused field in the body of the call.
@@ -176,6 +315,11 @@ func validateRuleGroup(
 
 	// TODO should we validate that interval is >= cfg.MinInterval? Currently, we allow to save but fix the specified interval if it is < cfg.MinInterval
 
+	evaluationTimeout := time.Duration(ruleGroupConfig.EvaluationTimeout)
+	if evaluationTimeout < 0 || evaluationTimeout > interval {
+		return nil, fmt.Errorf("rule group evaluation timeout (%v) cannot be negative or longer than the evaluation interval (%v)", ruleGroupConfig.EvaluationTimeout, model.Duration(interval))
+	}
+
 	result := make([]*ngmodels.AlertRuleWithOptionals, 0, len(ruleGroupConfig.Rules))
 	uids := make(map[string]int, cap(result))
 	for idx := range ruleGroupConfig.Rules {
@@ -203,6 +347,7 @@ func validateRuleGroup(
 		ruleWithOptionals := ngmodels.AlertRuleWithOptionals{}
 		rule.IsPaused = isPaused
 		rule.RuleGroupIndex = idx + 1
+		rule.GroupEvaluationTimeout = evaluationTimeout
 		ruleWithOptionals.AlertRule = *rule
 		ruleWithOptionals.HasPause = hasPause
 
@@ -148,6 +148,35 @@ func TestValidateRuleGroup(t *testing.T) {
 			require.True(t, alert.HasPause)
 		}
 	})
+
+	t.Run("should set group evaluation timeout to all rules", func(t *testing.T) {
+		ruleTimeout := model.Duration(time.Second)
+		withTimeout := validRule()
+		withTimeout.GrafanaManagedAlert.EvaluationTimeout = &ruleTimeout
+		g := validGroup(cfg, validRule(), withTimeout)
+		g.EvaluationTimeout = model.Duration(5 * time.Second)
+		alerts, err := validateRuleGroup(&g, orgId, folder, func(condition models.Condition) error {
+			return nil
+		}, cfg)
+		require.NoError(t, err)
+		for _, alert := range alerts {
+			require.Equal(t, 5*time.Second, alert.GroupEvaluationTimeout)
+		}
+		require.Equal(t, time.Duration(-1), alerts[0].EvaluationTimeout)
+		require.Equal(t, time.Second, alerts[1].EvaluationTimeout)
+	})
+
+	t.Run("should clear group evaluation timeout if it is not specified", func(t *testing.T) {
+		g := validGroup(cfg, validRule(), validRule())
+		g.EvaluationTimeout = 0
+		alerts, err := validateRuleGroup(&g, orgId, folder, func(condition models.Condition) error {
+			return nil
+		}, cfg)
+		require.NoError(t, err)
+		for _, alert := range alerts {
+			require.Zero(t, alert.GroupEvaluationTimeout)
+		}
+	})
 }
 
 func TestValidateRuleGroupFailures(t *testing.T) {
@@ -194,6 +223,23 @@ func TestValidateRuleGroupFailures(t *testing.T) {
 				return &g
 			},
 		},
+		{
+			name: "fail if evaluation timeout is negative",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				g := validGroup(cfg)
+				g.EvaluationTimeout = model.Duration(-time.Second)
+				return &g
+			},
+		},
+		{
+			name: "fail if evaluation timeout is longer than interval",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				g := validGroup(cfg)
+				g.Interval = model.Duration(cfg.BaseInterval)
+				g.EvaluationTimeout = model.Duration(2 * cfg.BaseInterval)
+				return &g
+			},
+		},
 		{
 			name: "fail if two rules have same UID",
 			group: func() *apimodels.PostableRuleGroupConfig {
@@ -316,6 +362,66 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				require.Equal(t, int64(panelId), *alert.PanelID)
 			},
 		},
+		{
+			name: "converts keep_firing_for",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				keepFiringFor := model.Duration(5 * time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, 5*time.Minute, alert.KeepFiringFor)
+			},
+		},
+		{
+			name: "converts depends_on",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.DependsOn = []string{util.GenerateShortUID()}
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, api.GrafanaManagedAlert.DependsOn, alert.DependsOn)
+			},
+		},
+		{
+			name: "converts evaluation_timeout and max_series_per_query",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				timeout := model.Duration(5 * time.Second)
+				r.GrafanaManagedAlert.EvaluationTimeout = &timeout
+				maxSeries := int64(100)
+				r.GrafanaManagedAlert.MaxSeriesPerQuery = &maxSeries
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, 5*time.Second, alert.EvaluationTimeout)
+				require.Equal(t, int64(100), alert.MaxSeriesPerQuery)
+			},
+		},
+		{
+			name: "converts notification_settings",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				groupWait := model.Duration(time.Minute)
+				r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{
+					Receiver:          "team-a",
+					GroupBy:           []string{"alertname"},
+					GroupWait:         &groupWait,
+					MuteTimeIntervals: []string{"weekends"},
+				}
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, &models.NotificationSettings{
+					Receiver:          "team-a",
+					GroupBy:           []string{"alertname"},
+					GroupWait:         api.GrafanaManagedAlert.NotificationSettings.GroupWait,
+					MuteTimeIntervals: []string{"weekends"},
+				}, alert.NotificationSettings)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -456,6 +562,69 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if keep_firing_for is negative",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				keepFiringFor := model.Duration(-time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+				return &r
+			},
+		},
+		{
+			name: "fail if evaluation_timeout is negative",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				timeout := model.Duration(-time.Second)
+				r.GrafanaManagedAlert.EvaluationTimeout = &timeout
+				return &r
+			},
+		},
+		{
+			name: "fail if evaluation_timeout is longer than interval",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				timeout := model.Duration(24 * time.Hour)
+				r.GrafanaManagedAlert.EvaluationTimeout = &timeout
+				return &r
+			},
+		},
+		{
+			name: "fail if max_series_per_query is negative",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				maxSeries := int64(-1)
+				r.GrafanaManagedAlert.MaxSeriesPerQuery = &maxSeries
+				return &r
+			},
+		},
+		{
+			name: "fail if notification_settings has no receiver",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{}
+				return &r
+			},
+		},
+		{
+			name: "fail if notification_settings has invalid group_by",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{
+					Receiver: "team-a",
+					GroupBy:  []string{"invalid-label"},
+				}
+				return &r
+			},
+		},
+		{
+			name: "fail if depends_on contains empty UID",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.DependsOn = []string{""}
+				return &r
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -561,6 +730,30 @@ func TestValidateRuleNode_UID(t *testing.T) {
 				require.Equal(t, int64(panelId), *alert.PanelID)
 			},
 		},
+		{
+			name: "use -1 for KeepFiringFor if it is not specified",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.ApiRuleNode.KeepFiringFor = nil
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, time.Duration(-1), alert.KeepFiringFor)
+			},
+		},
+		{
+			name: "use -1 for EvaluationTimeout and MaxSeriesPerQuery if they are not specified",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.EvaluationTimeout = nil
+				r.GrafanaManagedAlert.MaxSeriesPerQuery = nil
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, time.Duration(-1), alert.EvaluationTimeout)
+				require.Equal(t, int64(-1), alert.MaxSeriesPerQuery)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -733,3 +926,102 @@ func TestValidateRuleNodeIntervalFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRuleNode_Record(t *testing.T) {
+	orgId := rand.Int63()
+	folder := randFolder()
+	name := util.GenerateShortUID()
+	cfg := config(t)
+	cfg.RecordingRules.Enabled = true
+	interval := cfg.BaseInterval * time.Duration(rand.Int63n(10)+1)
+
+	recordingRule := func() *apimodels.PostableExtendedRuleNode {
+		r := validRule()
+		r.ApiRuleNode.For = nil
+		r.GrafanaManagedAlert.UID = ""
+		r.GrafanaManagedAlert.Condition = ""
+		r.GrafanaManagedAlert.Record = &apimodels.Record{
+			Metric: "test_metric",
+			From:   "A",
+		}
+		return &r
+	}
+
+	t.Run("converts record and uses it as condition", func(t *testing.T) {
+		r := recordingRule()
+		r.GrafanaManagedAlert.Record.TargetDatasourceUID = util.GenerateShortUID()
+		alert, err := validateRuleNode(r, name, interval, orgId, folder, func(condition models.Condition) error {
+			return nil
+		}, cfg)
+		require.NoError(t, err)
+		require.Equal(t, models.RuleTypeRecording, alert.Type())
+		require.Equal(t, "A", alert.Condition)
+		require.Equal(t, &models.Record{
+			Metric:              "test_metric",
+			From:                "A",
+			TargetDatasourceUID: r.GrafanaManagedAlert.Record.TargetDatasourceUID,
+		}, alert.Record)
+	})
+
+	testCases := []struct {
+		name   string
+		cfg    func(cfg *setting.UnifiedAlertingSettings)
+		mutate func(r *apimodels.PostableExtendedRuleNode)
+	}{
+		{
+			name: "fail if recording rules are disabled",
+			cfg: func(cfg *setting.UnifiedAlertingSettings) {
+				cfg.RecordingRules.Enabled = false
+			},
+		},
+		{
+			name: "fail if metric name is invalid",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				r.GrafanaManagedAlert.Record.Metric = "invalid-metric"
+			},
+		},
+		{
+			name: "fail if condition differs from the recorded node",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				r.GrafanaManagedAlert.Condition = "B"
+			},
+		},
+		{
+			name: "fail if for is positive",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				forDuration := model.Duration(time.Minute)
+				r.ApiRuleNode.For = &forDuration
+			},
+		},
+		{
+			name: "fail if keep_firing_for is positive",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				keepFiringFor := model.Duration(time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+			},
+		},
+		{
+			name: "fail if notification_settings are set",
+			mutate: func(r *apimodels.PostableExtendedRuleNode) {
+				r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{Receiver: "team-a"}
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := *cfg
+			if testCase.cfg != nil {
+				testCase.cfg(&c)
+			}
+			r := recordingRule()
+			if testCase.mutate != nil {
+				testCase.mutate(r)
+			}
+			_, err := validateRuleNode(r, name, interval, orgId, folder, func(condition models.Condition) error {
+				return nil
+			}, &c)
+			require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		})
+	}
+}
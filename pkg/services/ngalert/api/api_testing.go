@@ -3,6 +3,7 @@ package api
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -198,6 +199,10 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		Labels:          cmd.Labels,
 	}
 
+	if cmd.SimulateNotifications {
+		return srv.backtestNotifications(c, rule, cmd)
+	}
+
 	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
 	if err != nil {
 		if errors.Is(err, backtesting.ErrInvalidInputData) {
@@ -212,3 +217,139 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	}
 	return response.JSON(http.StatusOK, body)
 }
+
+func (srv TestingApiSrv) backtestNotifications(c *contextmodel.ReqContext, rule *ngmodels.AlertRule, cmd apimodels.BacktestConfig) response.Response {
+	states, notifications, err := srv.backtesting.TestNotifications(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(400, err, "Failed to evaluate")
+		}
+		return ErrResp(500, err, "Failed to evaluate")
+	}
+
+	statesBody, err := data.FrameToJSON(states, data.IncludeAll)
+	if err != nil {
+		return ErrResp(500, err, "Failed to convert frame to JSON")
+	}
+	notificationsBody, err := data.FrameToJSON(notifications, data.IncludeAll)
+	if err != nil {
+		return ErrResp(500, err, "Failed to convert frame to JSON")
+	}
+	return response.JSON(http.StatusOK, apimodels.BacktestNotificationsResult{
+		States:        statesBody,
+		Notifications: notificationsBody,
+	})
+}
+
+func (srv TestingApiSrv) RouteReplayRule(c *contextmodel.ReqContext, cmd apimodels.ReplayConfig) response.Response {
+	if !srv.featureManager.IsEnabled(featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backtesting API is not enabled")
+	}
+
+	if cmd.From.After(cmd.To) {
+		return ErrResp(http.StatusBadRequest, nil, "From cannot be greater than To")
+	}
+	noDataState, err := ngmodels.NoDataStateFromString(string(cmd.NoDataState))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	execErrState, err := ngmodels.ErrStateFromString(string(cmd.ExecErrState))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	forInterval := time.Duration(cmd.For)
+	if forInterval < 0 {
+		return ErrResp(http.StatusBadRequest, nil, "Bad For interval")
+	}
+	keepFiringFor := time.Duration(cmd.KeepFiringFor)
+	if keepFiringFor < 0 {
+		return ErrResp(http.StatusBadRequest, nil, "Bad KeepFiringFor interval")
+	}
+	intervalSeconds, err := validateInterval(srv.cfg, time.Duration(cmd.Interval))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	if !authorizeDatasourceAccessForRule(&ngmodels.AlertRule{Data: cmd.Data}, func(evaluator accesscontrol.Evaluator) bool {
+		return accesscontrol.HasAccess(srv.accessControl, c)(accesscontrol.ReqSignedIn, evaluator)
+	}) {
+		return errorToResponse(fmt.Errorf("%w to query one or many data sources used by the rule", ErrAuthorization))
+	}
+
+	rule := &ngmodels.AlertRule{
+		Title: cmd.Title,
+		// prefix replay- is to distinguish between executions of regular rule and replay in logs
+		UID:             "replay-" + util.GenerateShortUID(),
+		OrgID:           c.OrgID,
+		Condition:       cmd.Condition,
+		Data:            cmd.Data,
+		IntervalSeconds: intervalSeconds,
+		NoDataState:     noDataState,
+		ExecErrState:    execErrState,
+		For:             forInterval,
+		KeepFiringFor:   keepFiringFor,
+		Annotations:     cmd.Annotations,
+		Labels:          cmd.Labels,
+	}
+
+	steps, err := srv.backtesting.Replay(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(http.StatusBadRequest, err, "Failed to evaluate")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "Failed to evaluate")
+	}
+	return response.JSON(http.StatusOK, toReplayResult(steps))
+}
+
+func toReplayResult(steps []backtesting.ReplayStep) apimodels.ReplayResult {
+	result := apimodels.ReplayResult{Steps: make([]apimodels.ReplayStep, 0, len(steps))}
+	for _, step := range steps {
+		s := apimodels.ReplayStep{
+			Time:    step.Time,
+			Results: make([]apimodels.ReplayEvalResult, 0, len(step.Results)),
+			States:  make([]apimodels.ReplayState, 0, len(step.States)),
+		}
+		for _, r := range step.Results {
+			values := make(map[string]*float64, len(r.Values))
+			for k, v := range r.Values {
+				// NaN and infinite values cannot be encoded in JSON
+				if v != nil && !math.IsNaN(*v) && !math.IsInf(*v, 0) {
+					values[k] = v
+				} else {
+					values[k] = nil
+				}
+			}
+			s.Results = append(s.Results, apimodels.ReplayEvalResult{
+				Instance: r.Instance,
+				State:    r.State.String(),
+				Values:   values,
+				Error:    errorString(r.Error),
+			})
+		}
+		for _, st := range step.States {
+			s.States = append(s.States, apimodels.ReplayState{
+				Labels:              st.Labels,
+				Annotations:         st.Annotations,
+				State:               st.State.String(),
+				StateReason:         st.StateReason,
+				PreviousState:       st.PreviousState.String(),
+				PreviousStateReason: st.PreviousStateReason,
+				Changed:             st.Changed(),
+				Resolved:            st.Resolved,
+				StartsAt:            st.StartsAt,
+				EndsAt:              st.EndsAt,
+				Error:               errorString(st.Error),
+			})
+		}
+		result.Steps = append(result.Steps, s)
+	}
+	return result
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
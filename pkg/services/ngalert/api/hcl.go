@@ -0,0 +1,156 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var hclIdentifierRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// encodeHCL encodes the value in HCL syntax. The value is encoded with the names of its YAML fields: lists of objects
+// become repeated blocks, and all other fields become attributes.
+func encodeHCL(v any) ([]byte, error) {
+	var doc yaml.Node
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("cannot encode %s as HCL body", root.Tag)
+	}
+	var buf bytes.Buffer
+	writeHCLBody(&buf, root, 0)
+	return buf.Bytes(), nil
+}
+
+// hclAttribute is an attribute of a body that is aligned with the consecutive attributes.
+type hclAttribute struct {
+	name  string
+	value string
+}
+
+func writeHCLBody(buf *bytes.Buffer, body *yaml.Node, indent int) {
+	prefix := strings.Repeat("  ", indent)
+	var attributes []hclAttribute
+	flush := func() {
+		width := 0
+		for _, a := range attributes {
+			if len(a.name) > width {
+				width = len(a.name)
+			}
+		}
+		for _, a := range attributes {
+			fmt.Fprintf(buf, "%s%-*s = %s\n", prefix, width, a.name, a.value)
+		}
+		attributes = attributes[:0]
+	}
+
+	for i := 0; i+1 < len(body.Content); i += 2 {
+		name, value := hclKey(body.Content[i].Value), body.Content[i+1]
+		if isBlockSequence(value) {
+			flush()
+			for _, item := range value.Content {
+				fmt.Fprintf(buf, "%s%s {\n", prefix, name)
+				writeHCLBody(buf, item, indent+1)
+				fmt.Fprintf(buf, "%s}\n", prefix)
+			}
+			continue
+		}
+		expr := hclExpression(value, indent)
+		if strings.Contains(expr, "\n") {
+			// multi-line values are not aligned with the other attributes
+			flush()
+			fmt.Fprintf(buf, "%s%s = %s\n", prefix, name, expr)
+			continue
+		}
+		attributes = append(attributes, hclAttribute{name: name, value: expr})
+	}
+	flush()
+}
+
+// isBlockSequence returns true if the node is a non-empty list of objects.
+func isBlockSequence(n *yaml.Node) bool {
+	if n.Kind != yaml.SequenceNode || len(n.Content) == 0 {
+		return false
+	}
+	for _, item := range n.Content {
+		if item.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+func hclExpression(n *yaml.Node, indent int) string {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return hclExpression(n.Alias, indent)
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(n.Content))
+		multiline := false
+		for _, item := range n.Content {
+			expr := hclExpression(item, indent+1)
+			multiline = multiline || item.Kind == yaml.MappingNode || strings.Contains(expr, "\n")
+			items = append(items, expr)
+		}
+		if !multiline {
+			return "[" + strings.Join(items, ", ") + "]"
+		}
+		prefix := strings.Repeat("  ", indent+1)
+		return "[\n" + prefix + strings.Join(items, ",\n"+prefix) + ",\n" + strings.Repeat("  ", indent) + "]"
+	case yaml.MappingNode:
+		if len(n.Content) == 0 {
+			return "{}"
+		}
+		var sb strings.Builder
+		sb.WriteString("{\n")
+		prefix := strings.Repeat("  ", indent+1)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			fmt.Fprintf(&sb, "%s%s = %s\n", prefix, hclKey(n.Content[i].Value), hclExpression(n.Content[i+1], indent+1))
+		}
+		sb.WriteString(strings.Repeat("  ", indent) + "}")
+		return sb.String()
+	default:
+		switch n.ShortTag() {
+		case "!!null":
+			return "null"
+		case "!!bool", "!!int", "!!float":
+			return n.Value
+		default:
+			return hclString(n.Value)
+		}
+	}
+}
+
+// hclKey returns the name as identifier, or as quoted string if it is not a valid identifier.
+func hclKey(name string) string {
+	if hclIdentifierRegexp.MatchString(name) {
+		return name
+	}
+	return hclString(name)
+}
+
+// hclString returns the quoted string. Template sequences are escaped, so the string is used literally.
+func hclString(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", "$${",
+		"%{", "%%{",
+	)
+	return `"` + r.Replace(s) + `"`
+}
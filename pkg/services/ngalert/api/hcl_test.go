@@ -0,0 +1,79 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeHCL(t *testing.T) {
+	type query struct {
+		RefID string         `yaml:"refId"`
+		Model map[string]any `yaml:"model"`
+	}
+	type rule struct {
+		Title  string            `yaml:"title"`
+		For    string            `yaml:"for"`
+		Data   []query           `yaml:"data"`
+		Labels map[string]string `yaml:"labels,omitempty"`
+	}
+	type group struct {
+		OrgID int64    `yaml:"orgId"`
+		Name  string   `yaml:"name"`
+		Tags  []string `yaml:"tags"`
+		Rules []rule   `yaml:"rules"`
+	}
+	type export struct {
+		APIVersion int64   `yaml:"apiVersion"`
+		Groups     []group `yaml:"groups"`
+	}
+
+	body := export{
+		APIVersion: 1,
+		Groups: []group{{
+			OrgID: 1,
+			Name:  "group",
+			Tags:  []string{"a", "b"},
+			Rules: []rule{{
+				Title: `rule "1"`,
+				For:   "5m",
+				Data: []query{{
+					RefID: "A",
+					Model: map[string]any{"expr": "up{job=\"${job}\"}", "intervalMs": 1000},
+				}},
+				Labels: map[string]string{"team-a": "true", "__name__": "x"},
+			}},
+		}},
+	}
+
+	expected := `apiVersion = 1
+groups {
+  orgId = 1
+  name  = "group"
+  tags  = ["a", "b"]
+  rules {
+    title = "rule \"1\""
+    for   = "5m"
+    data {
+      refId = "A"
+      model = {
+        expr = "up{job=\"$${job}\"}"
+        intervalMs = 1000
+      }
+    }
+    labels = {
+      __name__ = "x"
+      team-a = "true"
+    }
+  }
+}
+`
+	b, err := encodeHCL(body)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(b))
+
+	t.Run("should fail if value is not an object", func(t *testing.T) {
+		_, err := encodeHCL([]string{"a"})
+		require.Error(t, err)
+	})
+}
@@ -81,6 +81,32 @@ import (
 //       200: GettableStatus
 //       400: ValidationError
 
+// swagger:route GET /api/alertmanager/grafana/config/history alertmanager RouteGetGrafanaAlertingConfigHistory
+//
+// gets the previous Alerting configs, newest first
+//
+//     Responses:
+//       200: GettableHistoricUserConfigs
+//       400: ValidationError
+
+// swagger:route GET /api/alertmanager/grafana/config/history/diff alertmanager RouteGetGrafanaAlertingConfigHistoryDiff
+//
+// gets the differences between two previous Alerting configs
+//
+//     Responses:
+//       200: GettableHistoricUserConfigDiff
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route POST /api/alertmanager/grafana/config/history/{id}/_activate alertmanager RoutePostGrafanaAlertingConfigHistoryActivate
+//
+// reverts the Alerting config to a previous version
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:route GET /api/alertmanager/{DatasourceUID}/api/v2/status alertmanager RouteGetAMStatus
 //
 // get alertmanager status and configuration
@@ -196,6 +222,14 @@ import (
 //       200: gettableSilence
 //       400: ValidationError
 
+// swagger:route GET /api/alertmanager/grafana/api/v2/silence/{SilenceId}/alerts alertmanager RouteGetGrafanaSilencedAlerts
+//
+// get the active alerts suppressed by the silence
+//
+//     Responses:
+//       200: gettableAlerts
+//       404: NotFound
+
 // swagger:route GET /api/alertmanager/{DatasourceUID}/api/v2/silence/{SilenceId} alertmanager RouteGetSilence
 //
 // get silence
@@ -278,7 +312,7 @@ type CreateSilenceParams struct {
 	Silence PostableSilence
 }
 
-// swagger:parameters RouteGetSilence RouteDeleteSilence RouteGetGrafanaSilence RouteDeleteGrafanaSilence
+// swagger:parameters RouteGetSilence RouteDeleteSilence RouteGetGrafanaSilence RouteDeleteGrafanaSilence RouteGetGrafanaSilencedAlerts
 type GetDeleteSilenceParams struct {
 	// in:path
 	SilenceId string
@@ -446,6 +480,36 @@ type BodyAlertingConfig struct {
 	Body PostableUserConfig
 }
 
+// swagger:parameters RouteGetGrafanaAlertingConfigHistory
+type RouteGetGrafanaAlertingConfigHistoryParams struct {
+	// Limit is the maximum number of configurations to return.
+	// in:query
+	// required: false
+	// default: 100
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters RouteGetGrafanaAlertingConfigHistoryDiff
+type RouteGetGrafanaAlertingConfigHistoryDiffParams struct {
+	// ID of the previous configuration to compare from.
+	// in:query
+	// required: true
+	From int64 `json:"from"`
+
+	// ID of the previous configuration to compare to.
+	// in:query
+	// required: true
+	To int64 `json:"to"`
+}
+
+// swagger:parameters RoutePostGrafanaAlertingConfigHistoryActivate
+type HistoricalConfigIdParams struct {
+	// ID of the previous configuration to activate.
+	// in:path
+	// required: true
+	Id int64 `json:"id"`
+}
+
 // alertmanager routes
 // swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMStatus RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig
 // testing routes
@@ -509,6 +573,32 @@ func (c *PostableUserConfig) validate() error {
 	return nil
 }
 
+// GettableHistoricUserConfig is a previous Alerting config.
+// swagger:model
+type GettableHistoricUserConfig struct {
+	ID                 int64                     `yaml:"id" json:"id"`
+	TemplateFiles      map[string]string         `yaml:"template_files" json:"template_files"`
+	AlertmanagerConfig GettableApiAlertingConfig `yaml:"alertmanager_config" json:"alertmanager_config"`
+	// CreatedAt is the time at which the config was saved.
+	CreatedAt strfmt.DateTime `yaml:"created_at" json:"created_at"`
+	// CreatedBy is the login of the user who saved the config. It is empty if the config was saved by Grafana.
+	CreatedBy string `yaml:"created_by,omitempty" json:"created_by,omitempty"`
+	// LastApplied is the time at which the config was last applied to the Alertmanager, if it was applied.
+	LastApplied *strfmt.DateTime `yaml:"last_applied,omitempty" json:"last_applied,omitempty"`
+}
+
+// swagger:model
+type GettableHistoricUserConfigs []GettableHistoricUserConfig
+
+// GettableHistoricUserConfigDiff is the difference between two previous Alerting configs.
+// swagger:model
+type GettableHistoricUserConfigDiff struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// Diff is the unified diff of the configs in YAML. Secure settings are not part of the configs.
+	Diff string `json:"diff"`
+}
+
 // GetGrafanaReceiverMap returns a map that associates UUIDs to grafana receivers
 func (c *PostableUserConfig) GetGrafanaReceiverMap() map[string]*PostableGrafanaReceiver {
 	UIDs := make(map[string]*PostableGrafanaReceiver)
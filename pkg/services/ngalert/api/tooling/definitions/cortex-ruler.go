@@ -168,9 +168,11 @@ type NamespaceConfigResponse map[string][]GettableRuleGroupConfig
 
 // swagger:model
 type PostableRuleGroupConfig struct {
-	Name     string                     `yaml:"name" json:"name"`
-	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
-	Rules    []PostableExtendedRuleNode `yaml:"rules" json:"rules"`
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	// Maximum duration of the evaluations of the rules of the group that do not have their own evaluation timeout.
+	EvaluationTimeout model.Duration             `yaml:"evaluation_timeout,omitempty" json:"evaluation_timeout,omitempty"`
+	Rules             []PostableExtendedRuleNode `yaml:"rules" json:"rules"`
 }
 
 func (c *PostableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -214,10 +216,12 @@ func (c *PostableRuleGroupConfig) validate() error {
 
 // swagger:model
 type GettableRuleGroupConfig struct {
-	Name          string                     `yaml:"name" json:"name"`
-	Interval      model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
-	SourceTenants []string                   `yaml:"source_tenants,omitempty" json:"source_tenants,omitempty"`
-	Rules         []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	// Maximum duration of the evaluations of the rules of the group that do not have their own evaluation timeout.
+	EvaluationTimeout model.Duration             `yaml:"evaluation_timeout,omitempty" json:"evaluation_timeout,omitempty"`
+	SourceTenants     []string                   `yaml:"source_tenants,omitempty" json:"source_tenants,omitempty"`
+	Rules             []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
 }
 
 func (c *GettableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -260,12 +264,13 @@ func (c *GettableRuleGroupConfig) validate() error {
 }
 
 type ApiRuleNode struct {
-	Record      string            `yaml:"record,omitempty" json:"record,omitempty"`
-	Alert       string            `yaml:"alert,omitempty" json:"alert,omitempty"`
-	Expr        string            `yaml:"expr" json:"expr"`
-	For         *model.Duration   `yaml:"for,omitempty" json:"for,omitempty"`
-	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
-	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	Record        string            `yaml:"record,omitempty" json:"record,omitempty"`
+	Alert         string            `yaml:"alert,omitempty" json:"alert,omitempty"`
+	Expr          string            `yaml:"expr" json:"expr"`
+	For           *model.Duration   `yaml:"for,omitempty" json:"for,omitempty"`
+	KeepFiringFor *model.Duration   `yaml:"keep_firing_for,omitempty" json:"keep_firing_for,omitempty"`
+	Labels        map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations   map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 }
 
 type RuleType int
@@ -375,24 +380,69 @@ type PostableGrafanaRule struct {
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
 	IsPaused     *bool               `json:"is_paused" yaml:"is_paused"`
+	Record       *Record             `json:"record,omitempty" yaml:"record,omitempty"`
+	// UIDs of rules of the same organization this rule depends on. The rule is not evaluated while any of them is firing.
+	DependsOn []string `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	// Maximum duration of an evaluation of the rule. Overrides the evaluation timeout of the group and the default evaluation timeout.
+	EvaluationTimeout *model.Duration `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty"`
+	// Maximum number of series a query of the rule can return. Overrides the default limit.
+	MaxSeriesPerQuery *int64 `json:"max_series_per_query,omitempty" yaml:"max_series_per_query,omitempty"`
+	// Contact point and notification options of the alerts of the rule. If set, the alerts are not routed by the notification policy tree.
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 }
 
 // swagger:model
 type GettableGrafanaRule struct {
-	ID              int64               `json:"id" yaml:"id"`
-	OrgID           int64               `json:"orgId" yaml:"orgId"`
-	Title           string              `json:"title" yaml:"title"`
-	Condition       string              `json:"condition" yaml:"condition"`
-	Data            []models.AlertQuery `json:"data" yaml:"data"`
-	Updated         time.Time           `json:"updated" yaml:"updated"`
-	IntervalSeconds int64               `json:"intervalSeconds" yaml:"intervalSeconds"`
-	Version         int64               `json:"version" yaml:"version"`
-	UID             string              `json:"uid" yaml:"uid"`
-	NamespaceUID    string              `json:"namespace_uid" yaml:"namespace_uid"`
-	NamespaceID     int64               `json:"namespace_id" yaml:"namespace_id"`
-	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
-	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
-	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
-	Provenance      models.Provenance   `json:"provenance,omitempty" yaml:"provenance,omitempty"`
-	IsPaused        bool                `json:"is_paused" yaml:"is_paused"`
+	ID                   int64                          `json:"id" yaml:"id"`
+	OrgID                int64                          `json:"orgId" yaml:"orgId"`
+	Title                string                         `json:"title" yaml:"title"`
+	Condition            string                         `json:"condition" yaml:"condition"`
+	Data                 []models.AlertQuery            `json:"data" yaml:"data"`
+	Updated              time.Time                      `json:"updated" yaml:"updated"`
+	IntervalSeconds      int64                          `json:"intervalSeconds" yaml:"intervalSeconds"`
+	Version              int64                          `json:"version" yaml:"version"`
+	UID                  string                         `json:"uid" yaml:"uid"`
+	NamespaceUID         string                         `json:"namespace_uid" yaml:"namespace_uid"`
+	NamespaceID          int64                          `json:"namespace_id" yaml:"namespace_id"`
+	RuleGroup            string                         `json:"rule_group" yaml:"rule_group"`
+	NoDataState          NoDataState                    `json:"no_data_state" yaml:"no_data_state"`
+	ExecErrState         ExecutionErrorState            `json:"exec_err_state" yaml:"exec_err_state"`
+	Provenance           models.Provenance              `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+	IsPaused             bool                           `json:"is_paused" yaml:"is_paused"`
+	Record               *Record                        `json:"record,omitempty" yaml:"record,omitempty"`
+	DependsOn            []string                       `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	EvaluationTimeout    model.Duration                 `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty"`
+	MaxSeriesPerQuery    int64                          `json:"max_series_per_query,omitempty" yaml:"max_series_per_query,omitempty"`
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
+}
+
+// Record defines how the result of a recording rule is written.
+// swagger:model
+type Record struct {
+	// Name of the recorded metric.
+	// required: true
+	Metric string `json:"metric" yaml:"metric"`
+	// RefID of the query or expression whose result is recorded.
+	// required: true
+	From string `json:"from" yaml:"from"`
+	// UID of the Prometheus data source the metric is written to. Uses the default remote write target if empty.
+	TargetDatasourceUID string `json:"target_datasource_uid,omitempty" yaml:"target_datasource_uid,omitempty"`
+}
+
+// AlertRuleNotificationSettings defines the contact point the alerts of a rule are sent to, and how they are grouped and muted.
+// swagger:model
+type AlertRuleNotificationSettings struct {
+	// Name of the contact point.
+	// required: true
+	Receiver string `json:"receiver" yaml:"receiver"`
+	// Labels the alerts are grouped by. Inherited from the default notification policy if empty.
+	GroupBy []string `json:"group_by,omitempty" yaml:"group_by,omitempty"`
+	// Inherited from the default notification policy if empty.
+	GroupWait *model.Duration `json:"group_wait,omitempty" yaml:"group_wait,omitempty"`
+	// Inherited from the default notification policy if empty.
+	GroupInterval *model.Duration `json:"group_interval,omitempty" yaml:"group_interval,omitempty"`
+	// Inherited from the default notification policy if empty.
+	RepeatInterval *model.Duration `json:"repeat_interval,omitempty" yaml:"repeat_interval,omitempty"`
+	// Names of the mute timings.
+	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty" yaml:"mute_time_intervals,omitempty"`
 }
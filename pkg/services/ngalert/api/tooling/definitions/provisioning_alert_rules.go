@@ -40,6 +40,7 @@ import (
 //     - application/json
 //     - application/yaml
 //     - text/yaml
+//     - text/hcl
 //
 //     Responses:
 //       200: AlertingFileExport
@@ -128,6 +129,8 @@ type ProvisionedAlertRule struct {
 	ExecErrState models.ExecutionErrorState `json:"execErrState"`
 	// required: true
 	For model.Duration `json:"for"`
+	// example: 5m
+	KeepFiringFor model.Duration `json:"keepFiringFor,omitempty"`
 	// example: {"runbook_url": "https://supercoolrunbook.com/page/13"}
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// example: {"team": "sre-team-1"}
@@ -136,46 +139,80 @@ type ProvisionedAlertRule struct {
 	Provenance models.Provenance `json:"provenance,omitempty"`
 	// example: false
 	IsPaused bool `json:"isPaused"`
+	// Record is set only for recording rules.
+	Record *models.Record `json:"record,omitempty"`
+	// Maximum duration of an evaluation of the rule. Uses the default evaluation timeout if not set.
+	// example: 30s
+	EvaluationTimeout model.Duration `json:"evaluationTimeout,omitempty"`
+	// Maximum number of series a query of the rule can return. Uses the default limit if not set.
+	// example: 1000
+	MaxSeriesPerQuery int64 `json:"maxSeriesPerQuery,omitempty"`
+	// Contact point and notification options of the alerts of the rule. If set, the alerts are not routed by the notification policy tree.
+	NotificationSettings *models.NotificationSettings `json:"notificationSettings,omitempty"`
+	// UIDs of the rules of the same organization the rule depends on. The rule is not evaluated while one of them is firing.
+	// example: ["upstream_rule_uid"]
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
+	condition := a.Condition
+	if a.Record != nil {
+		if err := a.Record.Validate(); err != nil {
+			return models.AlertRule{}, err
+		}
+		if condition == "" {
+			condition = a.Record.From
+		}
+	}
 	return models.AlertRule{
-		ID:           a.ID,
-		UID:          a.UID,
-		OrgID:        a.OrgID,
-		NamespaceUID: a.FolderUID,
-		RuleGroup:    a.RuleGroup,
-		Title:        a.Title,
-		Condition:    a.Condition,
-		Data:         a.Data,
-		Updated:      a.Updated,
-		NoDataState:  a.NoDataState,
-		ExecErrState: a.ExecErrState,
-		For:          time.Duration(a.For),
-		Annotations:  a.Annotations,
-		Labels:       a.Labels,
-		IsPaused:     a.IsPaused,
+		ID:                   a.ID,
+		UID:                  a.UID,
+		OrgID:                a.OrgID,
+		NamespaceUID:         a.FolderUID,
+		RuleGroup:            a.RuleGroup,
+		Title:                a.Title,
+		Condition:            condition,
+		Data:                 a.Data,
+		Updated:              a.Updated,
+		NoDataState:          a.NoDataState,
+		ExecErrState:         a.ExecErrState,
+		For:                  time.Duration(a.For),
+		KeepFiringFor:        time.Duration(a.KeepFiringFor),
+		Annotations:          a.Annotations,
+		Labels:               a.Labels,
+		IsPaused:             a.IsPaused,
+		Record:               a.Record,
+		EvaluationTimeout:    time.Duration(a.EvaluationTimeout),
+		MaxSeriesPerQuery:    a.MaxSeriesPerQuery,
+		NotificationSettings: a.NotificationSettings,
+		DependsOn:            a.DependsOn,
 	}, nil
 }
 
 func NewAlertRule(rule models.AlertRule, provenance models.Provenance) ProvisionedAlertRule {
 	return ProvisionedAlertRule{
-		ID:           rule.ID,
-		UID:          rule.UID,
-		OrgID:        rule.OrgID,
-		FolderUID:    rule.NamespaceUID,
-		RuleGroup:    rule.RuleGroup,
-		Title:        rule.Title,
-		For:          model.Duration(rule.For),
-		Condition:    rule.Condition,
-		Data:         rule.Data,
-		Updated:      rule.Updated,
-		NoDataState:  rule.NoDataState,
-		ExecErrState: rule.ExecErrState,
-		Annotations:  rule.Annotations,
-		Labels:       rule.Labels,
-		Provenance:   provenance,
-		IsPaused:     rule.IsPaused,
+		ID:                   rule.ID,
+		UID:                  rule.UID,
+		OrgID:                rule.OrgID,
+		FolderUID:            rule.NamespaceUID,
+		RuleGroup:            rule.RuleGroup,
+		Title:                rule.Title,
+		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
+		Condition:            rule.Condition,
+		Data:                 rule.Data,
+		Updated:              rule.Updated,
+		NoDataState:          rule.NoDataState,
+		ExecErrState:         rule.ExecErrState,
+		Annotations:          rule.Annotations,
+		Labels:               rule.Labels,
+		Provenance:           provenance,
+		IsPaused:             rule.IsPaused,
+		Record:               rule.Record,
+		EvaluationTimeout:    model.Duration(rule.EvaluationTimeout),
+		MaxSeriesPerQuery:    rule.MaxSeriesPerQuery,
+		NotificationSettings: rule.NotificationSettings,
+		DependsOn:            rule.DependsOn,
 	}
 }
 
@@ -203,6 +240,7 @@ func NewAlertRules(rules []*models.AlertRule) ProvisionedAlertRules {
 //     - application/json
 //     - application/yaml
 //     - text/yaml
+//     - text/hcl
 //
 //     Responses:
 //       200: AlertingFileExport
@@ -242,7 +280,7 @@ type AlertRuleGroupMetadata struct {
 	Interval int64 `json:"interval"`
 }
 
-// swagger:parameters RouteGetAlertRuleGroupExport RouteGetAlertRuleExport RouteGetAlertRulesExport
+// swagger:parameters RouteGetAlertRuleGroupExport RouteGetAlertRuleExport RouteGetAlertRulesExport RouteGetContactpointsExport RouteGetPolicyTreeExport RouteGetMuteTimingsExport RouteGetMuteTimingExport RouteGetTemplatesExport RouteGetTemplateExport
 type ExportQueryParams struct {
 	// Whether to initiate a download of the file or not.
 	// in: query
@@ -250,7 +288,7 @@ type ExportQueryParams struct {
 	// default: false
 	Download bool `json:"download"`
 
-	// Format of the downloaded file, either yaml or json. Accept header can also be used, but the query parameter will take precedence.
+	// Format of the downloaded file, either yaml, json or hcl. Accept header can also be used, but the query parameter will take precedence.
 	// in: query
 	// required: false
 	// default: yaml
@@ -259,10 +297,12 @@ type ExportQueryParams struct {
 
 // swagger:model
 type AlertRuleGroup struct {
-	Title     string                 `json:"title"`
-	FolderUID string                 `json:"folderUid"`
-	Interval  int64                  `json:"interval"`
-	Rules     []ProvisionedAlertRule `json:"rules"`
+	Title     string `json:"title"`
+	FolderUID string `json:"folderUid"`
+	Interval  int64  `json:"interval"`
+	// Maximum duration of the evaluations of the rules of the group that do not have their own evaluation timeout.
+	EvaluationTimeout model.Duration         `json:"evaluationTimeout,omitempty"`
+	Rules             []ProvisionedAlertRule `json:"rules"`
 }
 
 // AlertingFileExport is the full provisioned file export.
@@ -271,9 +311,10 @@ type AlertingFileExport = file.AlertingFileExport
 
 func (a *AlertRuleGroup) ToModel() (models.AlertRuleGroup, error) {
 	ruleGroup := models.AlertRuleGroup{
-		Title:     a.Title,
-		FolderUID: a.FolderUID,
-		Interval:  a.Interval,
+		Title:             a.Title,
+		FolderUID:         a.FolderUID,
+		Interval:          a.Interval,
+		EvaluationTimeout: time.Duration(a.EvaluationTimeout),
 	}
 	for i := range a.Rules {
 		converted, err := a.Rules[i].UpstreamModel()
@@ -291,9 +332,10 @@ func NewAlertRuleGroupFromModel(d models.AlertRuleGroup) AlertRuleGroup {
 		rules = append(rules, NewAlertRule(d.Rules[i], d.Provenance))
 	}
 	return AlertRuleGroup{
-		Title:     d.Title,
-		FolderUID: d.FolderUID,
-		Interval:  d.Interval,
-		Rules:     rules,
+		Title:             d.Title,
+		FolderUID:         d.FolderUID,
+		Interval:          d.Interval,
+		EvaluationTimeout: model.Duration(d.EvaluationTimeout),
+		Rules:             rules,
 	}
 }
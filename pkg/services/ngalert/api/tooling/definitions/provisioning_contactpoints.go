@@ -2,6 +2,7 @@ package definitions
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/grafana/alerting/logging"
 	alertingNotify "github.com/grafana/alerting/notify"
@@ -9,6 +10,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels_config"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/localwebhook"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting/file"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -19,6 +22,20 @@ import (
 //     Responses:
 //       200: ContactPoints
 
+// swagger:route GET /api/v1/provisioning/contact-points/export provisioning stable RouteGetContactpointsExport
+//
+// Export all contact points in provisioning file format.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//     - text/yaml
+//     - text/hcl
+//
+//     Responses:
+//       200: AlertingFileExport
+//       403: PermissionDenied
+
 // swagger:route POST /api/v1/provisioning/contact-points provisioning stable RoutePostContactpoints
 //
 // Create a contact point.
@@ -58,7 +75,7 @@ type ContactPointUIDReference struct {
 	UID string
 }
 
-// swagger:parameters RouteGetContactpoints
+// swagger:parameters RouteGetContactpoints RouteGetContactpointsExport
 type ContactPointParams struct {
 	// Filter by name
 	// in: query
@@ -66,6 +83,15 @@ type ContactPointParams struct {
 	Name string `json:"name"`
 }
 
+// swagger:parameters RouteGetContactpointsExport
+type ContactPointExportParams struct {
+	// Whether any contained secure settings should be decrypted or left redacted. Redacted settings will contain RedactedValue instead. Currently, only org admin can view decrypted secure settings.
+	// in: query
+	// required: false
+	// default: false
+	Decrypt bool `json:"decrypt"`
+}
+
 // swagger:parameters RoutePostContactpoints RoutePutContactpoint
 type ContactPointPayload struct {
 	// in:body
@@ -101,6 +127,39 @@ type EmbeddedContactPoint struct {
 
 const RedactedValue = "[REDACTED]"
 
+// NewContactPointExports creates the provisioned file exports of the contact points. Contact points with the same
+// name are exported as the receivers of one contact point, so the contact points must be sorted by name and then
+// by UID to keep the order of the receivers stable.
+func NewContactPointExports(orgID int64, cps []EmbeddedContactPoint) ([]file.ContactPointExport, error) {
+	result := make([]file.ContactPointExport, 0, len(cps))
+	for _, cp := range cps {
+		settings := map[string]any{}
+		if cp.Settings != nil {
+			var err error
+			settings, err = cp.Settings.Map()
+			if err != nil {
+				return nil, fmt.Errorf("failed to export settings of contact point %s: %w", cp.UID, err)
+			}
+		}
+		receiver := file.ReceiverExport{
+			UID:                   cp.UID,
+			Type:                  cp.Type,
+			Settings:              settings,
+			DisableResolveMessage: cp.DisableResolveMessage,
+		}
+		if len(result) > 0 && result[len(result)-1].Name == cp.Name {
+			result[len(result)-1].Receivers = append(result[len(result)-1].Receivers, receiver)
+			continue
+		}
+		result = append(result, file.ContactPointExport{
+			OrgID:     orgID,
+			Name:      cp.Name,
+			Receivers: []file.ReceiverExport{receiver},
+		})
+	}
+	return result, nil
+}
+
 func (e *EmbeddedContactPoint) Valid(decryptFunc receivers.GetDecryptedValueFn) error {
 	if e.Type == "" {
 		return fmt.Errorf("type should not be an empty string")
@@ -109,7 +168,8 @@ func (e *EmbeddedContactPoint) Valid(decryptFunc receivers.GetDecryptedValueFn)
 		return fmt.Errorf("settings should not be empty")
 	}
 	factory, exists := alertingNotify.Factory(e.Type)
-	if !exists {
+	isLocalWebhook := strings.EqualFold(e.Type, localwebhook.Type)
+	if !exists && !isLocalWebhook {
 		return fmt.Errorf("unknown type '%s'", e.Type)
 	}
 	jsonBytes, err := e.Settings.MarshalJSON()
@@ -122,6 +182,11 @@ func (e *EmbeddedContactPoint) Valid(decryptFunc receivers.GetDecryptedValueFn)
 	}, nil, decryptFunc, nil, nil, func(ctx ...interface{}) logging.Logger {
 		return &logging.FakeLogger{}
 	}, setting.BuildVersion)
+	if isLocalWebhook {
+		// The directories the local webhook can write to are checked when the configuration is applied.
+		_, err := localwebhook.ValidateConfig(cfg)
+		return err
+	}
 	if _, err := factory(cfg); err != nil {
 		return err
 	}
@@ -4,6 +4,7 @@ import (
 	"github.com/prometheus/alertmanager/config"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting/file"
 )
 
 // swagger:route GET /api/v1/provisioning/mute-timings provisioning stable RouteGetMuteTimings
@@ -21,6 +22,33 @@ import (
 //       200: MuteTimeInterval
 //       404: description: Not found.
 
+// swagger:route GET /api/v1/provisioning/mute-timings/export provisioning stable RouteGetMuteTimingsExport
+//
+// Export all mute timings in provisioning file format.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//     - text/yaml
+//     - text/hcl
+//
+//     Responses:
+//       200: AlertingFileExport
+
+// swagger:route GET /api/v1/provisioning/mute-timings/{name}/export provisioning stable RouteGetMuteTimingExport
+//
+// Export a mute timing in provisioning file format.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//     - text/yaml
+//     - text/hcl
+//
+//     Responses:
+//       200: AlertingFileExport
+//       404: description: Not found.
+
 // swagger:route POST /api/v1/provisioning/mute-timings provisioning stable RoutePostMuteTiming
 //
 // Create a new mute timing.
@@ -55,7 +83,7 @@ import (
 // swagger:model
 type MuteTimings []MuteTimeInterval
 
-// swagger:parameters RouteGetTemplate RouteGetMuteTiming RoutePutMuteTiming stable RouteDeleteMuteTiming
+// swagger:parameters RouteGetTemplate RouteGetMuteTiming RoutePutMuteTiming stable RouteDeleteMuteTiming RouteGetMuteTimingExport
 type RouteGetMuteTimingParam struct {
 	// Mute timing name
 	// in:path
@@ -81,3 +109,11 @@ func (mt *MuteTimeInterval) ResourceType() string {
 func (mt *MuteTimeInterval) ResourceID() string {
 	return mt.MuteTimeInterval.Name
 }
+
+// NewMuteTimeIntervalExport creates the provisioned file export of the mute timing.
+func NewMuteTimeIntervalExport(orgID int64, mt MuteTimeInterval) file.MuteTimeIntervalExport {
+	return file.MuteTimeIntervalExport{
+		OrgID:            orgID,
+		MuteTimeInterval: mt.MuteTimeInterval,
+	}
+}
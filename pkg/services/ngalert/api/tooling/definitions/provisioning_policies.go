@@ -1,5 +1,9 @@
 package definitions
 
+import (
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting/file"
+)
+
 // swagger:route GET /api/v1/provisioning/policies provisioning stable RouteGetPolicyTree
 //
 // Get the notification policy tree.
@@ -8,6 +12,20 @@ package definitions
 //       200: Route
 //         description: The currently active notification routing tree
 
+// swagger:route GET /api/v1/provisioning/policies/export provisioning stable RouteGetPolicyTreeExport
+//
+// Export the notification policy tree in provisioning file format.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//     - text/yaml
+//     - text/hcl
+//
+//     Responses:
+//       200: AlertingFileExport
+//       404: NotFound
+
 // swagger:route PUT /api/v1/provisioning/policies provisioning stable RoutePutPolicyTree
 //
 // Sets the notification policy tree.
@@ -35,3 +53,34 @@ type Policytree struct {
 	// in:body
 	Body Route
 }
+
+// NewNotificationPolicyExport creates the provisioned file export of the notification policy tree.
+func NewNotificationPolicyExport(orgID int64, tree Route) file.NotificationPolicyExport {
+	return file.NotificationPolicyExport{
+		OrgID:       orgID,
+		RouteExport: newRouteExport(&tree),
+	}
+}
+
+func newRouteExport(r *Route) file.RouteExport {
+	export := file.RouteExport{
+		Receiver:          r.Receiver,
+		GroupByStr:        r.GroupByStr,
+		Match:             r.Match,
+		MatchRE:           r.MatchRE,
+		Matchers:          r.Matchers,
+		MuteTimeIntervals: r.MuteTimeIntervals,
+		Continue:          r.Continue,
+		GroupWait:         r.GroupWait,
+		GroupInterval:     r.GroupInterval,
+		RepeatInterval:    r.RepeatInterval,
+	}
+	for _, m := range r.ObjectMatchers {
+		export.ObjectMatchers = append(export.ObjectMatchers, [3]string{m.Name, m.Type.String(), m.Value})
+	}
+	for _, child := range r.Routes {
+		childExport := newRouteExport(child)
+		export.Routes = append(export.Routes, &childExport)
+	}
+	return export
+}
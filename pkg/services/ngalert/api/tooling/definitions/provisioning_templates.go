@@ -2,6 +2,7 @@ package definitions
 
 import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting/file"
 )
 
 // swagger:route GET /api/v1/provisioning/templates provisioning stable RouteGetTemplates
@@ -20,6 +21,33 @@ import (
 //       200: NotificationTemplate
 //       404: description: Not found.
 
+// swagger:route GET /api/v1/provisioning/templates/export provisioning stable RouteGetTemplatesExport
+//
+// Export all notification templates in provisioning file format.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//     - text/yaml
+//     - text/hcl
+//
+//     Responses:
+//       200: AlertingFileExport
+
+// swagger:route GET /api/v1/provisioning/templates/{name}/export provisioning stable RouteGetTemplateExport
+//
+// Export a notification template in provisioning file format.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//     - text/yaml
+//     - text/hcl
+//
+//     Responses:
+//       200: AlertingFileExport
+//       404: description: Not found.
+
 // swagger:route PUT /api/v1/provisioning/templates/{name} provisioning stable RoutePutTemplate
 //
 // Updates an existing notification template.
@@ -38,7 +66,7 @@ import (
 //     Responses:
 //       204: description: The template was deleted successfully.
 
-// swagger:parameters RouteGetTemplate RoutePutTemplate RouteDeleteTemplate
+// swagger:parameters RouteGetTemplate RoutePutTemplate RouteDeleteTemplate RouteGetTemplateExport
 type RouteGetTemplateParam struct {
 	// Template Name
 	// in:path
@@ -72,3 +100,12 @@ func (t *NotificationTemplate) ResourceType() string {
 func (t *NotificationTemplate) ResourceID() string {
 	return t.Name
 }
+
+// NewNotificationTemplateExport creates the provisioned file export of the notification template.
+func NewNotificationTemplateExport(orgID int64, t NotificationTemplate) file.NotificationTemplateExport {
+	return file.NotificationTemplateExport{
+		OrgID:    orgID,
+		Name:     t.Name,
+		Template: t.Template,
+	}
+}
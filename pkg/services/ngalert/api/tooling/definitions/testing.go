@@ -67,6 +67,20 @@ import (
 //     Responses:
 //       200: BacktestResult
 
+// swagger:route Post /api/v1/rule/replay testing RouteReplayRule
+//
+// Replay a rule over a time range through the state machine of the alert rules
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ReplayResult
+//       400: ValidationError
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -196,7 +210,87 @@ type BacktestConfig struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 
 	NoDataState NoDataState `json:"no_data_state"`
+
+	// SimulateNotifications enables the simulation of the notifications that the notification policies
+	// of the organization would have sent for the rule. The response is a BacktestNotificationsResult.
+	SimulateNotifications bool `json:"simulate_notifications,omitempty"`
 }
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:model
+type BacktestNotificationsResult struct {
+	// States is the frame of states of the rule, the same as BacktestResult.
+	States json.RawMessage `json:"states"`
+	// Notifications is the frame of notifications that would have been sent to contact points.
+	Notifications json.RawMessage `json:"notifications"`
+}
+
+// swagger:parameters RouteReplayRule
+type ReplayConfigRequest struct {
+	// in:body
+	Body ReplayConfig
+}
+
+// swagger:model
+type ReplayConfig struct {
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Interval model.Duration `json:"interval,omitempty"`
+
+	Condition     string              `json:"condition"`
+	Data          []models.AlertQuery `json:"data"`
+	For           model.Duration      `json:"for,omitempty"`
+	KeepFiringFor model.Duration      `json:"keep_firing_for,omitempty"`
+
+	Title       string            `json:"title"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	NoDataState  NoDataState         `json:"no_data_state"`
+	ExecErrState ExecutionErrorState `json:"exec_err_state"`
+}
+
+// swagger:model
+type ReplayResult struct {
+	// Steps are the evaluations of the rule, one per interval of the time range.
+	Steps []ReplayStep `json:"steps"`
+}
+
+type ReplayStep struct {
+	Time time.Time `json:"time"`
+	// Results are the results of the evaluation of the condition.
+	Results []ReplayEvalResult `json:"results"`
+	// States are the states of the rule after the evaluation, including the states that were resolved
+	// because their series disappeared.
+	States []ReplayState `json:"states"`
+}
+
+type ReplayEvalResult struct {
+	Instance map[string]string `json:"instance,omitempty"`
+	// example: Alerting
+	State string `json:"state"`
+	// Values are the values of the expressions of the condition. A value is null if it is not a number.
+	Values map[string]*float64 `json:"values,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+type ReplayState struct {
+	// Labels are the labels of the alert, with the templates of the labels of the rule expanded.
+	Labels map[string]string `json:"labels"`
+	// Annotations are the annotations of the alert, with the templates of the annotations of the rule expanded.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// example: Pending
+	State               string `json:"state"`
+	StateReason         string `json:"state_reason,omitempty"`
+	PreviousState       string `json:"previous_state"`
+	PreviousStateReason string `json:"previous_state_reason,omitempty"`
+	// Changed is true if the evaluation changed the state or its reason.
+	Changed bool `json:"changed"`
+	// Resolved is true if the alert was firing and it was resolved by the evaluation.
+	Resolved bool      `json:"resolved,omitempty"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Error    string    `json:"error,omitempty"`
+}
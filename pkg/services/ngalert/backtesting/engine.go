@@ -39,11 +39,15 @@ type stateManager interface {
 type Engine struct {
 	evalFactory        eval.EvaluatorFactory
 	createStateManager func() stateManager
+	policies           policyTreeProvider
+	muteTimings        muteTimingsProvider
 }
 
-func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory) *Engine {
+func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, policies policyTreeProvider, muteTimings muteTimingsProvider) *Engine {
 	return &Engine{
 		evalFactory: evalFactory,
+		policies:    policies,
+		muteTimings: muteTimings,
 		createStateManager: func() stateManager {
 			cfg := state.ManagerCfg{
 				Metrics:       nil,
@@ -59,34 +63,48 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory) *Engine {
 }
 
 func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
-	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
-	logger := logger.FromContext(ctx)
+	return e.test(ctx, user, rule, from, to, nil)
+}
 
-	if !from.Before(to) {
-		return nil, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+// TestNotifications tests the rule like Test does, and also sends the alerts of the states to the notification policy
+// tree of the organization of the rule. It returns the frame of states and a frame with the notifications that
+// would have been sent to the contact points.
+func (e *Engine) TestNotifications(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from, to time.Time) (*data.Frame, *data.Frame, error) {
+	tree, err := e.policies.GetPolicyTree(ctx, rule.OrgID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get notification policy tree: %w", err)
 	}
-	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
-		return nil, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	muteTimings, err := e.muteTimings.GetMuteTimings(ctx, rule.OrgID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get mute timings: %w", err)
 	}
-	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
-
-	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition())
+	policy, err := newNotificationPolicy(tree, muteTimings)
 	if err != nil {
-		return nil, multierror.Append(ErrInvalidInputData, err)
+		return nil, nil, err
 	}
+	simulator := newNotificationSimulator(policy, rule)
+	states, err := e.test(ctx, user, rule, from, to, simulator.process)
+	if err != nil {
+		return nil, nil, err
+	}
+	simulator.advance(to)
+	return states, simulator.frame(), nil
+}
 
-	stateManager := e.createStateManager()
-
-	logger.Info("Start testing alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
-
-	start := time.Now()
+func (e *Engine) test(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from, to time.Time, onStates func(now time.Time, states []state.StateTransition)) (*data.Frame, error) {
+	length, err := evaluationsCount(rule, from, to)
+	if err != nil {
+		return nil, err
+	}
 
 	tsField := data.NewField("Time", nil, make([]time.Time, length))
 	valueFields := make(map[string]*data.Field)
 
-	err = evaluator.Eval(ruleCtx, from, to, time.Duration(rule.IntervalSeconds)*time.Second, func(currentTime time.Time, results eval.Results) error {
+	err = e.evaluate(ctx, user, rule, from, to, evaluateOptions{}, func(currentTime time.Time, _ eval.Results, states []state.StateTransition) {
 		idx := int(currentTime.Sub(from).Seconds()) / int(rule.IntervalSeconds)
-		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil)
+		if onStates != nil {
+			onStates(currentTime, states)
+		}
 		tsField.Set(idx, currentTime)
 		for _, s := range states {
 			field, ok := valueFields[s.CacheID]
@@ -103,20 +121,75 @@ func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models
 				continue
 			}
 		}
-		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
 	fields := make([]*data.Field, 0, len(valueFields)+1)
 	fields = append(fields, tsField)
 	for _, f := range valueFields {
 		fields = append(fields, f)
 	}
-	result := data.NewFrame("Backtesting results", fields...)
+	return data.NewFrame("Backtesting results", fields...), nil
+}
 
+// evaluateOptions changes how evaluate processes the results of the rule.
+type evaluateOptions struct {
+	// extraLabels are added to every state, like the scheduler adds the labels of the rule and its folder.
+	extraLabels data.Labels
+	// errorsAsResults makes a failed evaluation produce an Error result, like in the scheduler, instead of
+	// stopping the evaluation of the rule.
+	errorsAsResults bool
+}
+
+// evaluate evaluates the rule at every interval of the range [from, to), processes the results with a new state
+// manager and calls the callback with the results and the state transitions of every evaluation.
+func (e *Engine) evaluate(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from, to time.Time, opts evaluateOptions, callback func(now time.Time, results eval.Results, states []state.StateTransition)) error {
+	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+	logger := logger.FromContext(ctx)
+
+	length, err := evaluationsCount(rule, from, to)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	evalFactory := e.evalFactory
+	if opts.errorsAsResults {
+		evalFactory = errorResultsEvaluatorFactory{EvaluatorFactory: evalFactory}
+	}
+	evaluator, err := backtestingEvaluatorFactory(ruleCtx, evalFactory, user, rule.GetEvalCondition())
+	if err != nil {
+		return multierror.Append(ErrInvalidInputData, err)
+	}
+
+	stateManager := e.createStateManager()
+
+	logger.Info("Start testing alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
+
+	start := time.Now()
+
+	err = evaluator.Eval(ruleCtx, from, to, time.Duration(rule.IntervalSeconds)*time.Second, func(currentTime time.Time, results eval.Results) error {
+		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, opts.extraLabels)
+		callback(currentTime, results, states)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 	logger.Info("Rule testing finished successfully", "duration", time.Since(start))
-	return result, nil
+	return nil
+}
+
+// evaluationsCount returns the number of evaluations of the rule in the range [from, to).
+func evaluationsCount(rule *models.AlertRule, from, to time.Time) (int, error) {
+	if !from.Before(to) {
+		return 0, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+	}
+	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
+		return 0, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	}
+	return int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds), nil
 }
 
 func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user *user.SignedInUser, condition models.Condition) (backtestingEvaluator, error) {
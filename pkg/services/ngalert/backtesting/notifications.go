@@ -0,0 +1,319 @@
+package backtesting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	alertingModels "github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+const (
+	// noDataAlertName and errorAlertName are the names of the alerts that the scheduler sends for the states NoData and Error.
+	noDataAlertName = "DatasourceNoData"
+	errorAlertName  = "DatasourceError"
+	ruleNameLabel   = "rulename"
+)
+
+type policyTreeProvider interface {
+	GetPolicyTree(ctx context.Context, orgID int64) (apimodels.Route, error)
+}
+
+type muteTimingsProvider interface {
+	GetMuteTimings(ctx context.Context, orgID int64) ([]apimodels.MuteTimeInterval, error)
+}
+
+// notificationPolicy is the notification policy tree of an organization with the mute timings the tree refers to.
+type notificationPolicy struct {
+	route         *dispatch.Route
+	muteIntervals map[string][]timeinterval.TimeInterval
+}
+
+func newNotificationPolicy(tree apimodels.Route, muteTimings []apimodels.MuteTimeInterval) (*notificationPolicy, error) {
+	if err := tree.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid notification policy tree: %w", err)
+	}
+	muteIntervals := make(map[string][]timeinterval.TimeInterval, len(muteTimings))
+	for _, mt := range muteTimings {
+		muteIntervals[mt.Name] = mt.TimeIntervals
+	}
+	return &notificationPolicy{
+		route:         dispatch.NewRoute(tree.AsAMRoute(), nil),
+		muteIntervals: muteIntervals,
+	}, nil
+}
+
+// muted returns true if any of the mute timings of the route contains the time.
+func (p *notificationPolicy) muted(route *dispatch.Route, t time.Time) bool {
+	for _, name := range route.RouteOpts.MuteTimeIntervals {
+		for _, ti := range p.muteIntervals[name] {
+			if ti.ContainsTime(t.UTC()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// simulatedNotification is a notification that the Alertmanager would have sent to a contact point.
+type simulatedNotification struct {
+	time        time.Time
+	receiver    string
+	groupLabels model.LabelSet
+	firing      int
+	resolved    int
+}
+
+// simulatedAlert is an alert in an aggregation group.
+type simulatedAlert struct {
+	labels   model.LabelSet
+	resolved bool
+}
+
+// aggregationGroupKey identifies an aggregation group. Like in the Alertmanager dispatcher, groups belong to a route,
+// because the keys of sibling routes with the same matchers are equal.
+type aggregationGroupKey struct {
+	route  *dispatch.Route
+	labels model.Fingerprint
+}
+
+// aggregationGroup simulates the aggregation group of the Alertmanager dispatcher.
+type aggregationGroup struct {
+	id        aggregationGroupKey
+	key       string
+	route     *dispatch.Route
+	labels    model.LabelSet
+	alerts    map[model.Fingerprint]*simulatedAlert
+	nextFlush time.Time
+}
+
+// notificationLogEntry is what the Alertmanager notification log stores about the last notification of a group.
+type notificationLogEntry struct {
+	time     time.Time
+	firing   map[model.Fingerprint]struct{}
+	resolved map[model.Fingerprint]struct{}
+}
+
+// notificationSimulator simulates how the Alertmanager routes, groups, throttles and mutes the alerts that are sent
+// for the state transitions of a rule. It follows the grouping, group_wait, group_interval and repeat_interval of
+// the matching routes and their mute timings. Inhibition rules and silences are not simulated, and every contact
+// point is assumed to send resolved notifications.
+type notificationSimulator struct {
+	policy        *notificationPolicy
+	rule          *models.AlertRule
+	groups        map[aggregationGroupKey]*aggregationGroup
+	log           map[string]*notificationLogEntry
+	active        map[string]model.LabelSet
+	notifications []simulatedNotification
+}
+
+func newNotificationSimulator(policy *notificationPolicy, rule *models.AlertRule) *notificationSimulator {
+	return &notificationSimulator{
+		policy: policy,
+		rule:   rule,
+		groups: make(map[aggregationGroupKey]*aggregationGroup),
+		log:    make(map[string]*notificationLogEntry),
+		active: make(map[string]model.LabelSet),
+	}
+}
+
+// process flushes the aggregation groups that are due at or before now, and then sends the alerts for the state
+// transitions of the evaluation at now.
+func (s *notificationSimulator) process(now time.Time, transitions []state.StateTransition) {
+	s.advance(now)
+	for _, t := range transitions {
+		current, ok := s.active[t.CacheID]
+		var lbls model.LabelSet
+		firing := t.State.State == eval.Alerting || t.State.State == eval.NoData || t.State.State == eval.Error
+		if firing {
+			lbls = s.alertLabels(t.State)
+		}
+		// the alert was resolved or it was replaced by an alert with a different name, e.g. DatasourceNoData
+		if ok && (!firing || current.Fingerprint() != lbls.Fingerprint()) {
+			s.insert(now, current, true)
+			delete(s.active, t.CacheID)
+		}
+		if firing {
+			s.insert(now, lbls, false)
+			s.active[t.CacheID] = lbls
+		}
+	}
+}
+
+// advance flushes all aggregation groups that are due at or before now.
+func (s *notificationSimulator) advance(now time.Time) {
+	for {
+		var next *aggregationGroup
+		for _, g := range s.groups {
+			if g.nextFlush.After(now) {
+				continue
+			}
+			if next == nil || g.nextFlush.Before(next.nextFlush) || (g.nextFlush.Equal(next.nextFlush) && g.key < next.key) {
+				next = g
+			}
+		}
+		if next == nil {
+			return
+		}
+		s.flush(next)
+	}
+}
+
+func (s *notificationSimulator) insert(now time.Time, lbls model.LabelSet, resolved bool) {
+	fp := lbls.Fingerprint()
+	for _, route := range s.policy.route.Match(lbls) {
+		groupLabels := getGroupLabels(lbls, route)
+		id := aggregationGroupKey{route: route, labels: groupLabels.Fingerprint()}
+		g, ok := s.groups[id]
+		if !ok {
+			if resolved {
+				continue
+			}
+			g = &aggregationGroup{
+				id:        id,
+				key:       route.Key() + ":" + groupLabels.String(),
+				route:     route,
+				labels:    groupLabels,
+				alerts:    make(map[model.Fingerprint]*simulatedAlert),
+				nextFlush: now.Add(route.RouteOpts.GroupWait),
+			}
+			s.groups[id] = g
+		}
+		g.alerts[fp] = &simulatedAlert{labels: lbls, resolved: resolved}
+	}
+}
+
+// flush simulates the notification pipeline of the Alertmanager for the aggregation group: muted groups do not notify,
+// and the notification log deduplicates notifications until the alerts change or the repeat interval has passed.
+func (s *notificationSimulator) flush(g *aggregationGroup) {
+	now := g.nextFlush
+	firing := make(map[model.Fingerprint]struct{})
+	resolved := make(map[model.Fingerprint]struct{})
+	for fp, a := range g.alerts {
+		if a.resolved {
+			resolved[fp] = struct{}{}
+		} else {
+			firing[fp] = struct{}{}
+		}
+	}
+
+	if !s.policy.muted(g.route, now) {
+		logKey := g.key + ":" + g.route.RouteOpts.Receiver
+		entry := s.log[logKey]
+		if needsUpdate(entry, firing, resolved, now, g.route.RouteOpts.RepeatInterval) {
+			s.notifications = append(s.notifications, simulatedNotification{
+				time:        now,
+				receiver:    g.route.RouteOpts.Receiver,
+				groupLabels: g.labels,
+				firing:      len(firing),
+				resolved:    len(resolved),
+			})
+			s.log[logKey] = &notificationLogEntry{time: now, firing: firing, resolved: resolved}
+		}
+	}
+
+	for fp := range resolved {
+		delete(g.alerts, fp)
+	}
+	if len(g.alerts) == 0 {
+		delete(s.groups, g.id)
+		return
+	}
+	g.nextFlush = now.Add(g.route.RouteOpts.GroupInterval)
+}
+
+// needsUpdate is a copy of the logic of the deduplication stage of the Alertmanager notification pipeline.
+func needsUpdate(entry *notificationLogEntry, firing, resolved map[model.Fingerprint]struct{}, now time.Time, repeat time.Duration) bool {
+	if entry == nil {
+		return len(firing) > 0
+	}
+	if !isSubset(firing, entry.firing) {
+		return true
+	}
+	if len(firing) == 0 {
+		return len(entry.firing) > 0
+	}
+	if !isSubset(resolved, entry.resolved) {
+		return true
+	}
+	return entry.time.Before(now.Add(-repeat))
+}
+
+func isSubset(set, superset map[model.Fingerprint]struct{}) bool {
+	for fp := range set {
+		if _, ok := superset[fp]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func getGroupLabels(lbls model.LabelSet, route *dispatch.Route) model.LabelSet {
+	groupLabels := model.LabelSet{}
+	for ln, lv := range lbls {
+		if _, ok := route.RouteOpts.GroupBy[ln]; ok || route.RouteOpts.GroupByAll {
+			groupLabels[ln] = lv
+		}
+	}
+	return groupLabels
+}
+
+// alertLabels returns the labels of the alert that the scheduler would send for the state. The state manager of the
+// backtesting does not add the labels of the rule that the scheduler adds to every state, so they are added here.
+func (s *notificationSimulator) alertLabels(st *state.State) model.LabelSet {
+	lbls := make(model.LabelSet, len(st.Labels)+2)
+	lbls[model.AlertNameLabel] = model.LabelValue(s.rule.Title)
+	lbls[alertingModels.RuleUIDLabel] = model.LabelValue(s.rule.UID)
+	for k, v := range st.Labels {
+		lbls[model.LabelName(k)] = model.LabelValue(v)
+	}
+	switch st.State {
+	case eval.NoData:
+		lbls[ruleNameLabel] = lbls[model.AlertNameLabel]
+		lbls[model.AlertNameLabel] = noDataAlertName
+	case eval.Error:
+		lbls[ruleNameLabel] = lbls[model.AlertNameLabel]
+		lbls[model.AlertNameLabel] = errorAlertName
+	}
+	return lbls
+}
+
+// frame returns the simulated notifications ordered by time and contact point.
+func (s *notificationSimulator) frame() *data.Frame {
+	sort.SliceStable(s.notifications, func(i, j int) bool {
+		if s.notifications[i].time.Equal(s.notifications[j].time) {
+			return s.notifications[i].receiver < s.notifications[j].receiver
+		}
+		return s.notifications[i].time.Before(s.notifications[j].time)
+	})
+	length := len(s.notifications)
+	timeField := data.NewField("Time", nil, make([]time.Time, length))
+	receiverField := data.NewField("Contact point", nil, make([]string, length))
+	groupField := data.NewField("Group", nil, make([]string, length))
+	statusField := data.NewField("Status", nil, make([]string, length))
+	firingField := data.NewField("Firing", nil, make([]int64, length))
+	resolvedField := data.NewField("Resolved", nil, make([]int64, length))
+	for i, n := range s.notifications {
+		status := "firing"
+		if n.firing == 0 {
+			status = "resolved"
+		}
+		timeField.Set(i, n.time)
+		receiverField.Set(i, n.receiver)
+		groupField.Set(i, n.groupLabels.String())
+		statusField.Set(i, status)
+		firingField.Set(i, int64(n.firing))
+		resolvedField.Set(i, int64(n.resolved))
+	}
+	return data.NewFrame("Simulated notifications", timeField, receiverField, groupField, statusField, firingField, resolvedField)
+}
@@ -0,0 +1,215 @@
+package backtesting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestNotificationSimulator(t *testing.T) {
+	rule := &models.AlertRule{Title: "test-rule", UID: "test-uid", IntervalSeconds: 60}
+	from := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	duration := func(d time.Duration) *model.Duration {
+		md := model.Duration(d)
+		return &md
+	}
+	transition := func(host string, s eval.State) state.StateTransition {
+		return state.StateTransition{State: &state.State{
+			CacheID: host,
+			Labels:  data.Labels{"host": host, "team": "a"},
+			State:   s,
+		}}
+	}
+	tree := func() apimodels.Route {
+		return apimodels.Route{
+			Receiver:       "default",
+			GroupByStr:     []string{model.AlertNameLabel},
+			GroupWait:      duration(30 * time.Second),
+			GroupInterval:  duration(5 * time.Minute),
+			RepeatInterval: duration(time.Hour),
+		}
+	}
+	simulate := func(t *testing.T, policy *notificationPolicy, to time.Time, statesAt func(now time.Time) []state.StateTransition) []simulatedNotification {
+		t.Helper()
+		simulator := newNotificationSimulator(policy, rule)
+		for now := from; now.Before(to); now = now.Add(time.Minute) {
+			simulator.process(now, statesAt(now))
+		}
+		simulator.advance(to)
+		simulator.frame()
+		return simulator.notifications
+	}
+
+	t.Run("should group alerts and wait for group_wait and group_interval", func(t *testing.T) {
+		policy, err := newNotificationPolicy(tree(), nil)
+		require.NoError(t, err)
+
+		notifications := simulate(t, policy, from.Add(20*time.Minute), func(now time.Time) []state.StateTransition {
+			elapsed := now.Sub(from)
+			hostA := eval.Alerting
+			if elapsed >= 6*time.Minute {
+				hostA = eval.Normal
+			}
+			result := []state.StateTransition{transition("a", hostA)}
+			if elapsed >= time.Minute {
+				result = append(result, transition("b", eval.Alerting))
+			}
+			return result
+		})
+
+		groupLabels := model.LabelSet{model.AlertNameLabel: "test-rule"}
+		require.Equal(t, []simulatedNotification{
+			{time: from.Add(30 * time.Second), receiver: "default", groupLabels: groupLabels, firing: 1},
+			{time: from.Add(5*time.Minute + 30*time.Second), receiver: "default", groupLabels: groupLabels, firing: 2},
+			{time: from.Add(10*time.Minute + 30*time.Second), receiver: "default", groupLabels: groupLabels, firing: 1, resolved: 1},
+		}, notifications)
+	})
+
+	t.Run("should repeat notifications after repeat_interval", func(t *testing.T) {
+		policy, err := newNotificationPolicy(tree(), nil)
+		require.NoError(t, err)
+
+		notifications := simulate(t, policy, from.Add(3*time.Hour), func(now time.Time) []state.StateTransition {
+			return []state.StateTransition{transition("a", eval.Alerting)}
+		})
+
+		times := make([]time.Time, 0, len(notifications))
+		for _, n := range notifications {
+			times = append(times, n.time)
+		}
+		require.Equal(t, []time.Time{
+			from.Add(30 * time.Second),
+			from.Add(time.Hour + 5*time.Minute + 30*time.Second),
+			from.Add(2*time.Hour + 10*time.Minute + 30*time.Second),
+		}, times)
+	})
+
+	t.Run("should route to matching policies and respect mute timings", func(t *testing.T) {
+		routes := tree()
+		teamA, err := labels.NewMatcher(labels.MatchEqual, "team", "a")
+		require.NoError(t, err)
+		routes.Routes = []*apimodels.Route{
+			{
+				Receiver:          "team-a",
+				ObjectMatchers:    apimodels.ObjectMatchers{teamA},
+				MuteTimeIntervals: []string{"always"},
+				Continue:          true,
+			},
+			{
+				Receiver:       "team-a-pager",
+				ObjectMatchers: apimodels.ObjectMatchers{teamA},
+			},
+		}
+		muteTimings := []apimodels.MuteTimeInterval{
+			{MuteTimeInterval: config.MuteTimeInterval{Name: "always", TimeIntervals: []timeinterval.TimeInterval{{}}}},
+		}
+		policy, err := newNotificationPolicy(routes, muteTimings)
+		require.NoError(t, err)
+
+		notifications := simulate(t, policy, from.Add(5*time.Minute), func(now time.Time) []state.StateTransition {
+			return []state.StateTransition{transition("a", eval.Alerting)}
+		})
+
+		require.Len(t, notifications, 1)
+		require.Equal(t, "team-a-pager", notifications[0].receiver)
+	})
+
+	t.Run("should send NoData and Error states as separate alerts", func(t *testing.T) {
+		routes := tree()
+		routes.GroupByStr = []string{"..."}
+		policy, err := newNotificationPolicy(routes, nil)
+		require.NoError(t, err)
+
+		notifications := simulate(t, policy, from.Add(10*time.Minute), func(now time.Time) []state.StateTransition {
+			if now.Sub(from) < 5*time.Minute {
+				return []state.StateTransition{transition("a", eval.Alerting)}
+			}
+			return []state.StateTransition{transition("a", eval.NoData)}
+		})
+
+		require.Len(t, notifications, 3)
+		require.Equal(t, model.LabelValue("test-rule"), notifications[0].groupLabels[model.AlertNameLabel])
+		require.Equal(t, 1, notifications[0].firing)
+		// the alert of the rule is resolved and the NoData alert is created in another group
+		require.Equal(t, from.Add(5*time.Minute+30*time.Second), notifications[1].time)
+		require.Equal(t, from.Add(5*time.Minute+30*time.Second), notifications[2].time)
+		byName := map[model.LabelValue]simulatedNotification{}
+		for _, n := range notifications[1:] {
+			byName[n.groupLabels[model.AlertNameLabel]] = n
+		}
+		require.Equal(t, 1, byName[noDataAlertName].firing)
+		require.Equal(t, model.LabelValue("test-rule"), byName[noDataAlertName].groupLabels[ruleNameLabel])
+		require.Equal(t, 1, byName["test-rule"].resolved)
+	})
+
+	t.Run("should fail if policy tree is invalid", func(t *testing.T) {
+		_, err := newNotificationPolicy(apimodels.Route{}, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestEngineTestNotifications(t *testing.T) {
+	evaluator := &fakeBacktestingEvaluator{
+		evalCallback: func(now time.Time) (eval.Results, error) {
+			return eval.Results{}, nil
+		},
+	}
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user *user.SignedInUser, condition models.Condition) (backtestingEvaluator, error) {
+		return evaluator, nil
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	firing := state.StateTransition{State: &state.State{
+		CacheID: "a",
+		Labels:  data.Labels{"host": "a"},
+		State:   eval.Alerting,
+	}}
+	engine := &Engine{
+		createStateManager: func() stateManager {
+			return &fakeStateManager{stateCallback: func(now time.Time) []state.StateTransition {
+				return []state.StateTransition{firing}
+			}}
+		},
+		policies:    fakePolicies{tree: apimodels.Route{Receiver: "default"}},
+		muteTimings: fakePolicies{},
+	}
+	rule := models.AlertRuleGen(models.WithInterval(time.Minute))()
+	from := time.Unix(0, 0)
+	to := from.Add(5 * time.Minute)
+
+	states, notifications, err := engine.TestNotifications(context.Background(), nil, rule, from, to)
+	require.NoError(t, err)
+	require.Len(t, states.Fields, 2)
+
+	require.Equal(t, 1, notifications.Rows())
+	require.Equal(t, from.Add(30*time.Second), notifications.Fields[0].At(0))
+	require.Equal(t, "default", notifications.Fields[1].At(0))
+	require.Equal(t, "firing", notifications.Fields[3].At(0))
+}
+
+type fakePolicies struct {
+	tree apimodels.Route
+}
+
+func (f fakePolicies) GetPolicyTree(_ context.Context, _ int64) (apimodels.Route, error) {
+	return f.tree, nil
+}
+
+func (f fakePolicies) GetMuteTimings(_ context.Context, _ int64) ([]apimodels.MuteTimeInterval, error) {
+	return nil, nil
+}
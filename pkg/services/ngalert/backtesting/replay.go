@@ -0,0 +1,152 @@
+package backtesting
+
+import (
+	"context"
+	"time"
+
+	alertingModels "github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// ReplayStep is the outcome of an evaluation of a replayed rule.
+type ReplayStep struct {
+	Time    time.Time
+	Results []ReplayResult
+	States  []ReplayState
+}
+
+// ReplayResult is a result of the evaluation of the condition of the rule.
+type ReplayResult struct {
+	Instance data.Labels
+	State    eval.State
+	Values   map[string]*float64
+	Error    error
+}
+
+// ReplayState is a state of the rule right after an evaluation, with the labels and annotations of the rule
+// expanded by the state manager.
+type ReplayState struct {
+	Labels              data.Labels
+	Annotations         map[string]string
+	State               eval.State
+	StateReason         string
+	PreviousState       eval.State
+	PreviousStateReason string
+	Resolved            bool
+	StartsAt            time.Time
+	EndsAt              time.Time
+	Error               error
+}
+
+// Changed returns true if the evaluation changed the state or its reason.
+func (s ReplayState) Changed() bool {
+	return s.PreviousState != s.State || s.PreviousStateReason != s.StateReason
+}
+
+// Replay evaluates the rule at every interval of the range [from, to) and processes the results like the scheduler
+// does: failed evaluations produce Error results, the results are processed by the state manager according to the
+// NoData and Error states of the rule, and the labels and annotations of the rule are expanded for every state.
+// It returns the results and the states of every evaluation.
+func (e *Engine) Replay(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from, to time.Time) ([]ReplayStep, error) {
+	var steps []ReplayStep
+	opts := evaluateOptions{
+		extraLabels:     replayExtraLabels(rule),
+		errorsAsResults: true,
+	}
+	err := e.evaluate(ctx, user, rule, from, to, opts, func(now time.Time, results eval.Results, states []state.StateTransition) {
+		steps = append(steps, newReplayStep(now, results, states))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// replayExtraLabels returns the labels the scheduler adds to the states of the rule. The folder title is not added
+// because the rule is not saved.
+func replayExtraLabels(rule *models.AlertRule) data.Labels {
+	lbls := data.Labels{
+		prometheusModel.AlertNameLabel: rule.Title,
+		alertingModels.RuleUIDLabel:    rule.UID,
+	}
+	if rule.NamespaceUID != "" {
+		lbls[alertingModels.NamespaceUIDLabel] = rule.NamespaceUID
+	}
+	if rule.NotificationSettings != nil {
+		for k, v := range rule.NotificationSettings.ToLabels() {
+			lbls[k] = v
+		}
+	}
+	return lbls
+}
+
+// newReplayStep copies the results and the states, because the state manager changes the states in the
+// next evaluations.
+func newReplayStep(now time.Time, results eval.Results, states []state.StateTransition) ReplayStep {
+	step := ReplayStep{
+		Time:    now,
+		Results: make([]ReplayResult, 0, len(results)),
+		States:  make([]ReplayState, 0, len(states)),
+	}
+	for _, r := range results {
+		step.Results = append(step.Results, ReplayResult{
+			Instance: r.Instance.Copy(),
+			State:    r.State,
+			Values:   state.NewEvaluationValues(r.Values),
+			Error:    r.Error,
+		})
+	}
+	for _, s := range states {
+		annotations := make(map[string]string, len(s.Annotations))
+		for k, v := range s.Annotations {
+			annotations[k] = v
+		}
+		step.States = append(step.States, ReplayState{
+			Labels:              s.Labels.Copy(),
+			Annotations:         annotations,
+			State:               s.State.State,
+			StateReason:         s.StateReason,
+			PreviousState:       s.PreviousState,
+			PreviousStateReason: s.PreviousStateReason,
+			Resolved:            s.Resolved,
+			StartsAt:            s.StartsAt,
+			EndsAt:              s.EndsAt,
+			Error:               s.Error,
+		})
+	}
+	return step
+}
+
+// errorResultsEvaluatorFactory creates evaluators that turn a failed evaluation into an Error result.
+type errorResultsEvaluatorFactory struct {
+	eval.EvaluatorFactory
+}
+
+func (f errorResultsEvaluatorFactory) Create(ctx eval.EvaluationContext, condition models.Condition) (eval.ConditionEvaluator, error) {
+	evaluator, err := f.EvaluatorFactory.Create(ctx, condition)
+	if err != nil {
+		return nil, err
+	}
+	return errorResultsEvaluator{ConditionEvaluator: evaluator}, nil
+}
+
+// errorResultsEvaluator returns a single Error result when the evaluation fails, like the scheduler does,
+// so the state manager applies the execution error state of the rule.
+type errorResultsEvaluator struct {
+	eval.ConditionEvaluator
+}
+
+func (e errorResultsEvaluator) Evaluate(ctx context.Context, now time.Time) (eval.Results, error) {
+	start := time.Now()
+	results, err := e.ConditionEvaluator.Evaluate(ctx, now)
+	if err != nil && ctx.Err() == nil {
+		return eval.Results{eval.NewResultFromError(err, now, time.Since(start))}, nil
+	}
+	return results, err
+}
@@ -0,0 +1,110 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestEngineReplay(t *testing.T) {
+	rule := models.AlertRuleGen(models.WithInterval(time.Second), models.WithFor(time.Second), models.WithKeepFiringFor(0))()
+	rule.Labels = map[string]string{"team": "{{ $labels.instance }}-team"}
+	rule.Annotations = map[string]string{"summary": "{{ $labels.instance }} is down"}
+	rule.NoDataState = models.Alerting
+	rule.ExecErrState = models.ErrorErrState
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+
+	from := time.Unix(0, 0)
+	to := from.Add(5 * interval)
+	instance := data.Labels{"instance": "a"}
+	expectedErr := errors.New("test-error")
+
+	m := &eval_mocks.ConditionEvaluatorMock{}
+	m.EXPECT().Evaluate(mock.Anything, from).Return(eval.Results{{Instance: instance, State: eval.Normal, EvaluatedAt: from}}, nil)
+	for i := 1; i < 3; i++ {
+		now := from.Add(time.Duration(i) * interval)
+		m.EXPECT().Evaluate(mock.Anything, now).Return(eval.Results{{Instance: instance, State: eval.Alerting, EvaluatedAt: now}}, nil)
+	}
+	m.EXPECT().Evaluate(mock.Anything, from.Add(3*interval)).Return(nil, expectedErr)
+	m.EXPECT().Evaluate(mock.Anything, from.Add(4*interval)).Return(eval.Results{{State: eval.NoData, EvaluatedAt: from.Add(4 * interval)}}, nil)
+
+	engine := &Engine{
+		evalFactory: eval_mocks.NewEvaluatorFactory(m),
+		createStateManager: func() stateManager {
+			return state.NewManager(state.ManagerCfg{
+				ExternalURL: &url.URL{},
+				Images:      &NoopImageService{},
+				Clock:       clock.New(),
+			})
+		},
+	}
+
+	steps, err := engine.Replay(context.Background(), nil, rule, from, to)
+	require.NoError(t, err)
+	require.Len(t, steps, 5)
+
+	t.Run("should expand labels and annotations", func(t *testing.T) {
+		s := steps[0].States[0]
+		require.Equal(t, "a-team", s.Labels["team"])
+		require.Equal(t, "a", s.Labels["instance"])
+		require.Equal(t, rule.Title, s.Labels["alertname"])
+		require.Equal(t, "a is down", s.Annotations["summary"])
+	})
+
+	t.Run("should go through pending to firing", func(t *testing.T) {
+		require.Equal(t, eval.Normal, steps[0].States[0].State)
+		require.False(t, steps[0].States[0].Changed())
+
+		require.Equal(t, eval.Pending, steps[1].States[0].State)
+		require.Equal(t, eval.Normal, steps[1].States[0].PreviousState)
+		require.True(t, steps[1].States[0].Changed())
+
+		require.Equal(t, eval.Alerting, steps[2].States[0].State)
+		require.Equal(t, eval.Pending, steps[2].States[0].PreviousState)
+		require.Equal(t, steps[2].Time, steps[2].States[0].StartsAt)
+	})
+
+	t.Run("should apply execution error state when evaluation fails", func(t *testing.T) {
+		require.Len(t, steps[3].Results, 1)
+		require.Equal(t, eval.Error, steps[3].Results[0].State)
+		require.ErrorIs(t, steps[3].Results[0].Error, expectedErr)
+
+		s := findReplayState(t, steps[3], eval.Error)
+		require.ErrorIs(t, s.Error, expectedErr)
+		require.Equal(t, expectedErr.Error(), s.Annotations["Error"])
+	})
+
+	t.Run("should apply no data state", func(t *testing.T) {
+		s := findReplayState(t, steps[4], eval.Alerting)
+		require.Equal(t, eval.Error, s.PreviousState)
+		require.Equal(t, eval.NoData.String(), s.StateReason)
+	})
+
+	t.Run("should not change states of previous steps", func(t *testing.T) {
+		require.Equal(t, eval.Alerting, steps[2].States[0].State)
+		require.Equal(t, "a is down", steps[2].States[0].Annotations["summary"])
+	})
+}
+
+func findReplayState(t *testing.T, step ReplayStep, s eval.State) ReplayState {
+	t.Helper()
+	for _, state := range step.States {
+		if state.State == s {
+			return state
+		}
+	}
+	require.Failf(t, "state not found", "step at %s has no state %s", step.Time, s)
+	return ReplayState{}
+}
@@ -2,14 +2,48 @@ package eval
 
 import (
 	"context"
+	"time"
 
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
+// AlertingResultsReader provides the fingerprints of the results of the previous evaluation that are in alerting state.
+// It is used by expressions that depend on the previous state of the rule, such as thresholds with hysteresis.
+type AlertingResultsReader interface {
+	Read() expr.Fingerprints
+}
+
 // EvaluationContext represents the context in which a condition is evaluated.
 type EvaluationContext struct {
 	Ctx  context.Context
 	User *user.SignedInUser
+	// AlertingResultsReader is optional. If it is nil, expressions are evaluated as if nothing was alerting.
+	AlertingResultsReader AlertingResultsReader
+	// Limits override the default limits of the evaluator.
+	Limits Limits
+}
+
+// Limits restrict the resources used by an evaluation. The default limits of the evaluator are used for zero values.
+type Limits struct {
+	// Timeout is the maximum duration of the evaluation.
+	Timeout time.Duration
+	// MaxSeriesPerQuery is the maximum number of series a query can return.
+	MaxSeriesPerQuery int64
+}
+
+// LimitsFromRule returns the limits of the evaluations of the rule.
+// The evaluation timeout of the rule group is used if the rule has no evaluation timeout of its own.
+func LimitsFromRule(rule *models.AlertRule) Limits {
+	timeout := rule.EvaluationTimeout
+	if timeout == 0 {
+		timeout = rule.GroupEvaluationTimeout
+	}
+	return Limits{
+		Timeout:           timeout,
+		MaxSeriesPerQuery: rule.MaxSeriesPerQuery,
+	}
 }
 
 func Context(ctx context.Context, user *user.SignedInUser) EvaluationContext {
@@ -18,3 +52,13 @@ func Context(ctx context.Context, user *user.SignedInUser) EvaluationContext {
 		User: user,
 	}
 }
+
+// NewContextWithPreviousResults creates an EvaluationContext that provides the results of the previous evaluation
+// to the expressions that need them.
+func NewContextWithPreviousResults(ctx context.Context, user *user.SignedInUser, reader AlertingResultsReader) EvaluationContext {
+	return EvaluationContext{
+		Ctx:                   ctx,
+		User:                  user,
+		AlertingResultsReader: reader,
+	}
+}
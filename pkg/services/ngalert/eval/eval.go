@@ -4,6 +4,7 @@ package eval
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"runtime/debug"
@@ -26,6 +27,13 @@ import (
 
 var logger = log.New("ngalert.eval")
 
+var (
+	// ErrEvaluationTimeout is returned when the evaluation of a condition takes longer than its timeout.
+	ErrEvaluationTimeout = errors.New("evaluation timed out")
+	// ErrSeriesLimitExceeded is returned when a query returns more series than the limit of series per query.
+	ErrSeriesLimitExceeded = errors.New("series limit exceeded")
+)
+
 type EvaluatorFactory interface {
 	// Validate validates that the condition is correct. Returns nil if the condition is correct. Otherwise, error that describes the failure
 	Validate(ctx EvaluationContext, condition models.Condition) error
@@ -50,6 +58,7 @@ type conditionEvaluator struct {
 	expressionService expressionService
 	condition         models.Condition
 	evalTimeout       time.Duration
+	maxSeriesPerQuery int64
 }
 
 func (r *conditionEvaluator) EvaluateRaw(ctx context.Context, now time.Time) (resp *backend.QueryDataResponse, err error) {
@@ -71,7 +80,69 @@ func (r *conditionEvaluator) EvaluateRaw(ctx context.Context, now time.Time) (re
 		defer cancel()
 		execCtx = timeoutCtx
 	}
-	return r.expressionService.ExecutePipeline(execCtx, now, r.pipeline)
+	resp, err = r.expressionService.ExecutePipeline(execCtx, now, r.pipeline)
+	// the results of the queries that did not complete in time are errors, so the whole evaluation is considered failed
+	if err != nil && ctx.Err() == nil && errors.Is(execCtx.Err(), context.DeadlineExceeded) {
+		return nil, timeoutError{timeout: r.evalTimeout}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkSeriesLimit(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// timeoutError is ErrEvaluationTimeout that also matches context.DeadlineExceeded.
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e timeoutError) Error() string {
+	return fmt.Sprintf("%s after %s", ErrEvaluationTimeout, e.timeout)
+}
+
+func (e timeoutError) Is(target error) bool {
+	return target == ErrEvaluationTimeout
+}
+
+func (e timeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// checkSeriesLimit returns ErrSeriesLimitExceeded if a query returned more series than the limit.
+// Series produced by expressions are not limited.
+func (r *conditionEvaluator) checkSeriesLimit(resp *backend.QueryDataResponse) error {
+	if r.maxSeriesPerQuery <= 0 || resp == nil {
+		return nil
+	}
+	for _, q := range r.condition.Data {
+		if expr.IsDataSource(q.DatasourceUID) {
+			continue
+		}
+		res, ok := resp.Responses[q.RefID]
+		if !ok {
+			continue
+		}
+		if series := countSeries(res.Frames); int64(series) > r.maxSeriesPerQuery {
+			return fmt.Errorf("%w: query %s returned %d series, the limit is %d", ErrSeriesLimitExceeded, q.RefID, series, r.maxSeriesPerQuery)
+		}
+	}
+	return nil
+}
+
+// countSeries returns the number of series in the frames. Every numeric field is a series.
+func countSeries(frames data.Frames) int {
+	count := 0
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if field.Type().Numeric() {
+				count++
+			}
+		}
+	}
+	return count
 }
 
 // Evaluate evaluates the condition and converts the response to Results
@@ -86,6 +157,7 @@ func (r *conditionEvaluator) Evaluate(ctx context.Context, now time.Time) (Resul
 
 type evaluatorImpl struct {
 	evaluationTimeout time.Duration
+	maxSeriesPerQuery int64
 	dataSourceCache   datasources.CacheService
 	expressionService *expr.Service
 	pluginsStore      plugins.Store
@@ -99,6 +171,7 @@ func NewEvaluatorFactory(
 ) EvaluatorFactory {
 	return &evaluatorImpl{
 		evaluationTimeout: cfg.EvaluationTimeout,
+		maxSeriesPerQuery: cfg.MaxSeriesPerQuery,
 		dataSourceCache:   datasourceCache,
 		expressionService: expressionService,
 		pluginsStore:      pluginsStore,
@@ -251,11 +324,29 @@ func getExprRequest(ctx EvaluationContext, data []models.AlertQuery, dsCacheServ
 
 	datasources := make(map[string]*datasources.DataSource, len(data))
 
+	var loadedDimensions expr.Fingerprints
 	for _, q := range data {
 		model, err := q.GetModel()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get query model from '%s': %w", q.RefID, err)
 		}
+		if ctx.AlertingResultsReader != nil && expr.IsDataSource(q.DatasourceUID) {
+			var query map[string]interface{}
+			if err := json.Unmarshal(model, &query); err != nil {
+				return nil, fmt.Errorf("failed to parse expression '%s': %w", q.RefID, err)
+			}
+			if expr.IsHysteresisExpression(query) {
+				if loadedDimensions == nil {
+					loadedDimensions = ctx.AlertingResultsReader.Read()
+				}
+				if err := expr.SetLoadedDimensionsToHysteresisCommand(query, loadedDimensions); err != nil {
+					return nil, fmt.Errorf("failed to set loaded dimensions to expression '%s': %w", q.RefID, err)
+				}
+				if model, err = json.Marshal(query); err != nil {
+					return nil, fmt.Errorf("failed to build expression '%s': %w", q.RefID, err)
+				}
+			}
+		}
 		interval, err := q.GetIntervalDuration()
 		if err != nil {
 			return nil, fmt.Errorf("failed to retrieve intervalMs from '%s': %w", q.RefID, err)
@@ -617,7 +708,7 @@ func (e *evaluatorImpl) Validate(ctx EvaluationContext, condition models.Conditi
 			return fmt.Errorf("datasource refID %s is not a backend datasource", query.RefID)
 		}
 	}
-	_, err = e.create(condition, req)
+	_, err = e.create(condition, req, ctx.Limits)
 	return err
 }
 
@@ -632,14 +723,22 @@ func (e *evaluatorImpl) Create(ctx EvaluationContext, condition models.Condition
 	if err != nil {
 		return nil, err
 	}
-	return e.create(condition, req)
+	return e.create(condition, req, ctx.Limits)
 }
 
-func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request) (ConditionEvaluator, error) {
+func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request, limits Limits) (ConditionEvaluator, error) {
 	pipeline, err := e.expressionService.BuildPipeline(req)
 	if err != nil {
 		return nil, err
 	}
+	evalTimeout := e.evaluationTimeout
+	if limits.Timeout > 0 {
+		evalTimeout = limits.Timeout
+	}
+	maxSeriesPerQuery := e.maxSeriesPerQuery
+	if limits.MaxSeriesPerQuery > 0 {
+		maxSeriesPerQuery = limits.MaxSeriesPerQuery
+	}
 	conditions := make([]string, 0, len(pipeline))
 	for _, node := range pipeline {
 		if node.RefID() == condition.Condition {
@@ -647,7 +746,8 @@ func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request) (C
 				pipeline:          pipeline,
 				expressionService: e.expressionService,
 				condition:         condition,
-				evalTimeout:       e.evaluationTimeout,
+				evalTimeout:       evalTimeout,
+				maxSeriesPerQuery: maxSeriesPerQuery,
 			}, nil
 		}
 		conditions = append(conditions, node.RefID())
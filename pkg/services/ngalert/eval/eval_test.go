@@ -570,7 +570,59 @@ func TestEvaluateRaw(t *testing.T) {
 
 		_, err := e.EvaluateRaw(context.Background(), time.Now())
 		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorIs(t, err, ErrEvaluationTimeout)
 	})
+
+	t.Run("should fail if a query returns more series than the limit", func(t *testing.T) {
+		e := conditionEvaluator{
+			expressionService: &fakeExpressionService{
+				hook: func(ctx context.Context, now time.Time, pipeline expr.DataPipeline) (*backend.QueryDataResponse, error) {
+					return &backend.QueryDataResponse{Responses: backend.Responses{
+						"A": {Frames: data.Frames{
+							data.NewFrame("", data.NewField("", nil, []float64{1})),
+							data.NewFrame("", data.NewField("", nil, []float64{2})),
+						}},
+					}}, nil
+				},
+			},
+			condition:         models.Condition{Data: []models.AlertQuery{{RefID: "A", DatasourceUID: "test"}}},
+			evalTimeout:       time.Second,
+			maxSeriesPerQuery: 1,
+		}
+
+		_, err := e.EvaluateRaw(context.Background(), time.Now())
+		require.ErrorIs(t, err, ErrSeriesLimitExceeded)
+
+		e.maxSeriesPerQuery = 2
+		_, err = e.EvaluateRaw(context.Background(), time.Now())
+		require.NoError(t, err)
+	})
+
+	t.Run("should not limit series of expressions", func(t *testing.T) {
+		e := conditionEvaluator{
+			expressionService: &fakeExpressionService{
+				hook: func(ctx context.Context, now time.Time, pipeline expr.DataPipeline) (*backend.QueryDataResponse, error) {
+					return &backend.QueryDataResponse{Responses: backend.Responses{
+						"B": {Frames: data.Frames{
+							data.NewFrame("", data.NewField("", nil, []float64{1})),
+							data.NewFrame("", data.NewField("", nil, []float64{2})),
+						}},
+					}}, nil
+				},
+			},
+			condition:         models.Condition{Data: []models.AlertQuery{{RefID: "B", DatasourceUID: expr.DatasourceUID}}},
+			evalTimeout:       time.Second,
+			maxSeriesPerQuery: 1,
+		}
+
+		_, err := e.EvaluateRaw(context.Background(), time.Now())
+		require.NoError(t, err)
+	})
+}
+
+func TestLimitsFromRule(t *testing.T) {
+	rule := models.AlertRuleGen(models.WithEvaluationTimeout(5*time.Second), models.WithMaxSeriesPerQuery(10))()
+	require.Equal(t, Limits{Timeout: 5 * time.Second, MaxSeriesPerQuery: 10}, LimitsFromRule(rule))
 }
 
 type fakeExpressionService struct {
@@ -7,11 +7,13 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	alertingModels "github.com/grafana/alerting/models"
+	prometheusModel "github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/util/cmputil"
@@ -107,18 +109,25 @@ const (
 )
 
 const (
-	StateReasonMissingSeries = "MissingSeries"
-	StateReasonError         = "Error"
-	StateReasonPaused        = "Paused"
-	StateReasonUpdated       = "Updated"
-	StateReasonRuleDeleted   = "RuleDeleted"
+	StateReasonMissingSeries  = "MissingSeries"
+	StateReasonError          = "Error"
+	StateReasonPaused         = "Paused"
+	StateReasonUpdated        = "Updated"
+	StateReasonRuleDeleted    = "RuleDeleted"
+	StateReasonKeepFiring     = "KeepFiring"
+	StateReasonUpstreamFiring = "UpstreamFiring"
+	// StateReasonSeriesLimitExceeded is the reason of the Error state of rules whose queries returned more series than allowed.
+	StateReasonSeriesLimitExceeded = "SeriesLimitExceeded"
 )
 
 var (
 	// InternalLabelNameSet are labels that grafana automatically include as part of the labelset.
 	InternalLabelNameSet = map[string]struct{}{
-		alertingModels.RuleUIDLabel:      {},
-		alertingModels.NamespaceUIDLabel: {},
+		alertingModels.RuleUIDLabel:         {},
+		alertingModels.NamespaceUIDLabel:    {},
+		AutogeneratedRouteLabel:             {},
+		AutogeneratedRouteReceiverNameLabel: {},
+		AutogeneratedRouteSettingsHashLabel: {},
 	}
 	InternalAnnotationNameSet = map[string]struct{}{
 		DashboardUIDAnnotation:              {},
@@ -129,11 +138,12 @@ var (
 
 // AlertRuleGroup is the base model for a rule group in unified alerting.
 type AlertRuleGroup struct {
-	Title      string
-	FolderUID  string
-	Interval   int64
-	Provenance Provenance
-	Rules      []AlertRule
+	Title             string
+	FolderUID         string
+	Interval          int64
+	EvaluationTimeout time.Duration
+	Provenance        Provenance
+	Rules             []AlertRule
 }
 
 // AlertRule is the model for alert rules in unified alerting.
@@ -156,10 +166,60 @@ type AlertRule struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
-	IsPaused    bool
+	For time.Duration
+	// KeepFiringFor is how long an alert keeps firing after its condition has stopped being met.
+	KeepFiringFor time.Duration
+	Annotations   map[string]string
+	Labels        map[string]string
+	IsPaused      bool
+	// Record is set only for recording rules. It defines the metric the result of the rule is written to.
+	Record *Record `xorm:"json 'record'"`
+	// DependsOn contains UIDs of rules of the same organization this rule depends on.
+	// The rule is not evaluated while any of them is firing.
+	DependsOn []string `xorm:"depends_on"`
+	// EvaluationTimeout is the maximum duration of an evaluation of the rule.
+	// The default evaluation timeout is used if it is zero.
+	EvaluationTimeout time.Duration
+	// GroupEvaluationTimeout is the evaluation timeout of the rule group. It is used if EvaluationTimeout is zero.
+	GroupEvaluationTimeout time.Duration
+	// MaxSeriesPerQuery is the maximum number of series a query of the rule can return.
+	// The default limit is used if it is zero.
+	MaxSeriesPerQuery int64
+	// NotificationSettings are set if the alerts of the rule are sent to a receiver instead of being routed
+	// by the notification policy tree.
+	NotificationSettings *NotificationSettings `xorm:"json 'notification_settings'"`
+}
+
+// RuleType is the type of the alert rule.
+type RuleType string
+
+const (
+	// RuleTypeAlerting is a rule whose evaluation results are converted to alert instances.
+	RuleTypeAlerting RuleType = "alerting"
+	// RuleTypeRecording is a rule whose evaluation results are written as a metric to a target data source.
+	RuleTypeRecording RuleType = "recording"
+)
+
+// Record contains the configuration of a recording rule.
+type Record struct {
+	// Metric is the name of the metric the result of the rule is written to.
+	Metric string `json:"metric"`
+	// From is the RefID of the query or expression whose result is recorded.
+	From string `json:"from"`
+	// TargetDatasourceUID is the UID of the Prometheus data source the result is written to.
+	// If empty, the remote write endpoint configured in the settings is used.
+	TargetDatasourceUID string `json:"targetDatasourceUid,omitempty"`
+}
+
+// Validate checks that the metric name is a valid Prometheus metric name and the source node is set.
+func (r *Record) Validate() error {
+	if !prometheusModel.IsValidMetricName(prometheusModel.LabelValue(r.Metric)) {
+		return fmt.Errorf("%w: metric name '%s' is not a valid Prometheus metric name", ErrAlertRuleFailedValidation, r.Metric)
+	}
+	if r.From == "" {
+		return fmt.Errorf("%w: recording rule must specify the query or expression to record", ErrAlertRuleFailedValidation)
+	}
+	return nil
 }
 
 // AlertRuleWithOptionals This is to avoid having to pass in additional arguments deep in the call stack. Alert rule
@@ -212,6 +272,14 @@ func (alertRule *AlertRule) GetLabels(opts ...LabelOption) map[string]string {
 	return labels
 }
 
+// Type returns the type of the rule.
+func (alertRule *AlertRule) Type() RuleType {
+	if alertRule.Record != nil {
+		return RuleTypeRecording
+	}
+	return RuleTypeAlerting
+}
+
 func (alertRule *AlertRule) GetEvalCondition() Condition {
 	return Condition{
 		Condition: alertRule.Condition,
@@ -352,10 +420,17 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
-	IsPaused    bool
+	For                    time.Duration
+	KeepFiringFor          time.Duration
+	Annotations            map[string]string
+	Labels                 map[string]string
+	IsPaused               bool
+	Record                 *Record  `xorm:"json 'record'"`
+	DependsOn              []string `xorm:"depends_on"`
+	EvaluationTimeout      time.Duration
+	GroupEvaluationTimeout time.Duration
+	MaxSeriesPerQuery      int64
+	NotificationSettings   *NotificationSettings `xorm:"json 'notification_settings'"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -451,7 +526,7 @@ func (c Condition) IsValid() bool {
 // There are several exceptions:
 // 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations and AlertRule.Labels
 // 2. There are fields that are patched together:
-//   - AlertRule.Condition and AlertRule.Data
+//   - AlertRule.Condition, AlertRule.Data and AlertRule.Record
 //
 // If either of the pair is specified, neither is patched.
 func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOptionals) {
@@ -461,6 +536,7 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.Condition == "" || len(ruleToPatch.Data) == 0 {
 		ruleToPatch.Condition = existingRule.Condition
 		ruleToPatch.Data = existingRule.Data
+		ruleToPatch.Record = existingRule.Record
 	}
 	if ruleToPatch.IntervalSeconds == 0 {
 		ruleToPatch.IntervalSeconds = existingRule.IntervalSeconds
@@ -480,6 +556,18 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.For == -1 {
 		ruleToPatch.For = existingRule.For
 	}
+	if ruleToPatch.KeepFiringFor == -1 {
+		ruleToPatch.KeepFiringFor = existingRule.KeepFiringFor
+	}
+	if ruleToPatch.DependsOn == nil {
+		ruleToPatch.DependsOn = existingRule.DependsOn
+	}
+	if ruleToPatch.EvaluationTimeout == -1 {
+		ruleToPatch.EvaluationTimeout = existingRule.EvaluationTimeout
+	}
+	if ruleToPatch.MaxSeriesPerQuery == -1 {
+		ruleToPatch.MaxSeriesPerQuery = existingRule.MaxSeriesPerQuery
+	}
 	if !ruleToPatch.HasPause {
 		ruleToPatch.IsPaused = existingRule.IsPaused
 	}
@@ -493,8 +581,93 @@ func ValidateRuleGroupInterval(intervalSeconds, baseIntervalSeconds int64) error
 	return nil
 }
 
+// ValidateEvaluationTimeouts checks that neither the evaluation timeout of the rule nor the one of its group is longer
+// than the evaluation interval of the rule.
+func (alertRule *AlertRule) ValidateEvaluationTimeouts() error {
+	interval := time.Duration(alertRule.IntervalSeconds) * time.Second
+	if alertRule.EvaluationTimeout > interval {
+		return fmt.Errorf("%w: evaluation timeout (%v) of rule %s cannot be longer than the evaluation interval (%v)", ErrAlertRuleFailedValidation, alertRule.EvaluationTimeout, alertRule.UID, interval)
+	}
+	if alertRule.GroupEvaluationTimeout > interval {
+		return fmt.Errorf("%w: evaluation timeout (%v) of rule group %s cannot be longer than the evaluation interval (%v)", ErrAlertRuleFailedValidation, alertRule.GroupEvaluationTimeout, alertRule.RuleGroup, interval)
+	}
+	return nil
+}
+
 type RulesGroup []*AlertRule
 
+// ValidateRuleDependencies checks that every rule the changed rules depend on is present in orgRules, which must contain
+// all rules of the organization as they are after the changes are applied, and that the dependencies do not form a cycle.
+// Because orgRules contains rules of a single organization, references to rules of other organizations are rejected as missing.
+func ValidateRuleDependencies(changed []*AlertRule, orgRules map[string]*AlertRule) error {
+	for _, rule := range changed {
+		for _, uid := range rule.DependsOn {
+			if _, ok := orgRules[uid]; !ok {
+				return fmt.Errorf("%w: rule '%s' depends on rule with UID '%s' that does not exist in the organization", ErrAlertRuleFailedValidation, rule.Title, uid)
+			}
+		}
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	marks := make(map[string]int, len(orgRules))
+	var visit func(uid string, path []string) error
+	visit = func(uid string, path []string) error {
+		path = append(path, uid)
+		switch marks[uid] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: rule dependencies form a cycle: %s", ErrAlertRuleFailedValidation, strings.Join(path, " -> "))
+		}
+		marks[uid] = visiting
+		if rule, ok := orgRules[uid]; ok {
+			for _, dep := range rule.DependsOn {
+				if err := visit(dep, path); err != nil {
+					return err
+				}
+			}
+		}
+		marks[uid] = visited
+		return nil
+	}
+	for _, rule := range changed {
+		if rule.UID == "" { // new rules without UID cannot be referenced by other rules
+			continue
+		}
+		if err := visit(rule.UID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DependentRules returns the rules of orgRules that depend on any of the deleted rules, ordered by UID.
+func DependentRules(orgRules map[string]*AlertRule, deleted []*AlertRule) []*AlertRule {
+	if len(deleted) == 0 {
+		return nil
+	}
+	deletedUIDs := make(map[string]struct{}, len(deleted))
+	for _, rule := range deleted {
+		deletedUIDs[rule.UID] = struct{}{}
+	}
+	var result []*AlertRule
+	for _, rule := range orgRules {
+		for _, uid := range rule.DependsOn {
+			if _, ok := deletedUIDs[uid]; ok {
+				result = append(result, rule)
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].UID < result[j].UID
+	})
+	return result
+}
+
 func (g RulesGroup) SortByGroupIndex() {
 	sort.Slice(g, func(i, j int) bool {
 		if g[i].RuleGroupIndex == g[j].RuleGroupIndex {
@@ -199,12 +199,36 @@ func TestPatchPartialAlertRule(t *testing.T) {
 					r.For = -1
 				},
 			},
+			{
+				name: "KeepFiringFor is -1",
+				mutator: func(r *AlertRuleWithOptionals) {
+					r.KeepFiringFor = -1
+				},
+			},
 			{
 				name: "IsPaused did not come in request",
 				mutator: func(r *AlertRuleWithOptionals) {
 					r.IsPaused = true
 				},
 			},
+			{
+				name: "DependsOn is nil",
+				mutator: func(r *AlertRuleWithOptionals) {
+					r.DependsOn = nil
+				},
+			},
+			{
+				name: "EvaluationTimeout is -1",
+				mutator: func(r *AlertRuleWithOptionals) {
+					r.EvaluationTimeout = -1
+				},
+			},
+			{
+				name: "MaxSeriesPerQuery is -1",
+				mutator: func(r *AlertRuleWithOptionals) {
+					r.MaxSeriesPerQuery = -1
+				},
+			},
 		}
 
 		for _, testCase := range testCases {
@@ -213,7 +237,7 @@ func TestPatchPartialAlertRule(t *testing.T) {
 				for {
 					rule := AlertRuleGen(func(rule *AlertRule) {
 						rule.For = time.Duration(rand.Int63n(1000) + 1)
-					})()
+					}, WithDependsOn("upstream"))()
 					existing = &AlertRuleWithOptionals{AlertRule: *rule}
 					cloned := *existing
 					testCase.mutator(&cloned)
@@ -684,3 +708,106 @@ func TestTimeRangeYAML(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, yamlRaw, string(serialized))
 }
+
+func TestRecordValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		record Record
+		err    bool
+	}{
+		{
+			name:   "valid record",
+			record: Record{Metric: "job:http_requests:rate5m", From: "A"},
+		},
+		{
+			name:   "invalid metric name",
+			record: Record{Metric: "http-requests", From: "A"},
+			err:    true,
+		},
+		{
+			name:   "empty metric name",
+			record: Record{From: "A"},
+			err:    true,
+		},
+		{
+			name:   "empty source node",
+			record: Record{Metric: "http_requests"},
+			err:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.record.Validate()
+			if tc.err {
+				require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAlertRuleType(t *testing.T) {
+	rule := AlertRuleGen()()
+	require.Equal(t, RuleTypeAlerting, rule.Type())
+
+	WithRecord("http_requests", "A")(rule)
+	require.Equal(t, RuleTypeRecording, rule.Type())
+	require.Equal(t, "A", rule.Condition)
+}
+
+func TestValidateRuleDependencies(t *testing.T) {
+	orgRules := func(rules ...*AlertRule) map[string]*AlertRule {
+		result := make(map[string]*AlertRule, len(rules))
+		for _, rule := range rules {
+			result[rule.UID] = rule
+		}
+		return result
+	}
+
+	t.Run("accepts dependencies on existing rules", func(t *testing.T) {
+		upstream := AlertRuleGen()()
+		dependent := AlertRuleGen(WithDependsOn(upstream.UID))()
+		require.NoError(t, ValidateRuleDependencies([]*AlertRule{dependent}, orgRules(upstream, dependent)))
+	})
+
+	t.Run("accepts new rules without UID", func(t *testing.T) {
+		upstream := AlertRuleGen()()
+		dependent := AlertRuleGen(WithDependsOn(upstream.UID))()
+		dependent.UID = ""
+		require.NoError(t, ValidateRuleDependencies([]*AlertRule{dependent}, orgRules(upstream)))
+	})
+
+	t.Run("rejects dependencies on rules that do not exist in the organization", func(t *testing.T) {
+		upstream := AlertRuleGen()()
+		dependent := AlertRuleGen(WithDependsOn(upstream.UID))()
+		err := ValidateRuleDependencies([]*AlertRule{dependent}, orgRules(dependent))
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("rejects cycles", func(t *testing.T) {
+		a := AlertRuleGen(WithDependsOn("c"))()
+		a.UID = "a"
+		b := AlertRuleGen(WithDependsOn("a"))()
+		b.UID = "b"
+		c := AlertRuleGen(WithDependsOn("b"))()
+		c.UID = "c"
+		err := ValidateRuleDependencies([]*AlertRule{a}, orgRules(a, b, c))
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "a -> c -> b -> a")
+	})
+}
+
+func TestDependentRules(t *testing.T) {
+	deleted := AlertRuleGen()()
+	a := AlertRuleGen(WithDependsOn(deleted.UID))()
+	a.UID = "a"
+	b := AlertRuleGen(WithDependsOn("other", deleted.UID))()
+	b.UID = "b"
+	independent := AlertRuleGen()()
+	independent.DependsOn = nil
+	orgRules := map[string]*AlertRule{a.UID: a, b.UID: b, independent.UID: independent}
+
+	require.Equal(t, []*AlertRule{a, b}, DependentRules(orgRules, []*AlertRule{deleted}))
+	require.Empty(t, DependentRules(orgRules, nil))
+}
@@ -21,6 +21,9 @@ type HistoricAlertConfiguration struct {
 	// LastApplied a timestamp indicating the most recent time at which the configuration was applied to an Alertmanager, or 0 otherwise.
 	// Only set this field if the configuration has been applied by the caller.
 	LastApplied int64 `xorm:"last_applied"`
+
+	// CreatedBy is the login of the user who saved the configuration, or empty if the configuration was saved by Grafana.
+	CreatedBy string `xorm:"created_by"`
 }
 
 // GetLatestAlertmanagerConfigurationQuery is the query to get the latest alertmanager configuration.
@@ -37,6 +40,7 @@ type SaveAlertmanagerConfigurationCmd struct {
 	Default                   bool
 	OrgID                     int64
 	LastApplied               int64
+	CreatedBy                 string
 }
 
 // MarkConfigurationAsAppliedCmd is the command for marking a previously saved configuration as successfully applied.
@@ -51,6 +55,21 @@ type GetAppliedConfigurationsQuery struct {
 	Result []*HistoricAlertConfiguration
 }
 
+// GetAlertmanagerConfigurationHistoryQuery is the query for getting the previous configurations of an organization.
+type GetAlertmanagerConfigurationHistoryQuery struct {
+	OrgID int64
+	// Limit is the maximum number of configurations to return. All configurations are returned if it is 0.
+	Limit  int
+	Result []*HistoricAlertConfiguration
+}
+
+// GetHistoricalAlertmanagerConfigurationQuery is the query for getting a previous configuration by its ID.
+type GetHistoricalAlertmanagerConfigurationQuery struct {
+	OrgID  int64
+	ID     int64
+	Result *HistoricAlertConfiguration
+}
+
 func HistoricConfigFromAlertConfig(config AlertConfiguration) HistoricAlertConfiguration {
 	// Reset the Id so it can be generated by the DB.
 	config.ID = 0
@@ -14,4 +14,32 @@ type HistoryQuery struct {
 	From         time.Time
 	To           time.Time
 	SignedInUser *user.SignedInUser
+	// Limit is the maximum number of entries to return, and Offset is the number of matching entries to skip
+	// before them. They are only applied by the sql backend.
+	Limit  int
+	Offset int
+}
+
+// StateHistoryEntry is a single alert state transition as stored by the sql state history backend.
+type StateHistoryEntry struct {
+	ID            int64             `xorm:"pk autoincr 'id'"`
+	OrgID         int64             `xorm:"org_id"`
+	RuleUID       string            `xorm:"rule_uid"`
+	NamespaceUID  string            `xorm:"namespace_uid"`
+	RuleGroup     string            `xorm:"rule_group"`
+	Labels        map[string]string `xorm:"labels"`
+	PreviousState string            `xorm:"previous_state"`
+	CurrentState  string            `xorm:"current_state"`
+	Error         string            `xorm:"error"`
+	// Values is a JSON object with the values of the evaluated expressions.
+	Values       string `xorm:"state_values"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	PanelID      int64  `xorm:"panel_id"`
+	// Epoch is the time of the transition in milliseconds since the Unix epoch.
+	Epoch int64 `xorm:"epoch"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (e *StateHistoryEntry) TableName() string {
+	return "alert_state_history"
 }
@@ -14,6 +14,19 @@ type AlertInstance struct {
 	CurrentStateSince time.Time
 	CurrentStateEnd   time.Time
 	LastEvalTime      time.Time
+	// ResultFingerprint is the fingerprint of the labels of the evaluation result that created the instance.
+	ResultFingerprint string
+	// KeepFiringSince is the time the instance started being kept firing by the keep_firing_for of its rule.
+	// It is stored as Unix seconds, with 0 if the instance is not kept firing.
+	KeepFiringSince time.Time
+}
+
+// KeepFiringSinceUnix returns the Unix seconds of KeepFiringSince, or 0 if it is zero.
+func (a AlertInstance) KeepFiringSinceUnix() int64 {
+	if a.KeepFiringSince.IsZero() {
+		return 0
+	}
+	return a.KeepFiringSince.Unix()
 }
 
 type AlertInstanceKey struct {
@@ -52,6 +65,8 @@ func (i InstanceStateType) IsValid() bool {
 type ListAlertInstancesQuery struct {
 	RuleUID   string
 	RuleOrgID int64 `json:"-"`
+	// RuleUIDs restricts the query to the instances of these rules if it is not empty.
+	RuleUIDs []string
 
 	Result []*AlertInstance
 }
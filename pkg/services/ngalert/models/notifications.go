@@ -0,0 +1,167 @@
+package models
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
+)
+
+const (
+	// AutogeneratedRouteLabel is the label of the alerts of rules with notification settings. The auto-generated
+	// route of the Alertmanager configuration matches it.
+	AutogeneratedRouteLabel = "__grafana_autogenerated__"
+	// AutogeneratedRouteReceiverNameLabel is the label that contains the receiver of the notification settings.
+	AutogeneratedRouteReceiverNameLabel = "__grafana_receiver__"
+	// AutogeneratedRouteSettingsHashLabel is the label that contains the fingerprint of the notification settings.
+	AutogeneratedRouteSettingsHashLabel = "__grafana_route_settings_hash__"
+)
+
+// NotificationSettings are the notification settings of a rule. The alerts of the rule are sent to the receiver
+// with the grouping, timings and mute timings of the settings, instead of being routed by the notification policy tree.
+type NotificationSettings struct {
+	Receiver string `json:"receiver"`
+	// GroupBy, GroupWait, GroupInterval and RepeatInterval are inherited from the root of the notification policy tree if not set.
+	GroupBy           []string                  `json:"group_by,omitempty"`
+	GroupWait         *prometheusModel.Duration `json:"group_wait,omitempty"`
+	GroupInterval     *prometheusModel.Duration `json:"group_interval,omitempty"`
+	RepeatInterval    *prometheusModel.Duration `json:"repeat_interval,omitempty"`
+	MuteTimeIntervals []string                  `json:"mute_time_intervals,omitempty"`
+}
+
+// Validate checks that the settings have a receiver and valid timings. It does not check that the receiver and
+// the mute timings exist.
+func (s *NotificationSettings) Validate() error {
+	if s.Receiver == "" {
+		return fmt.Errorf("%w: receiver of the notification settings must be specified", ErrAlertRuleFailedValidation)
+	}
+	groupBy := make(map[string]struct{}, len(s.GroupBy))
+	for _, l := range s.GroupBy {
+		if l != "..." && !prometheusModel.LabelName(l).IsValid() {
+			return fmt.Errorf("%w: invalid label name '%s' in group_by of the notification settings", ErrAlertRuleFailedValidation, l)
+		}
+		if _, ok := groupBy[l]; ok {
+			return fmt.Errorf("%w: duplicated label '%s' in group_by of the notification settings", ErrAlertRuleFailedValidation, l)
+		}
+		groupBy[l] = struct{}{}
+	}
+	if _, ok := groupBy["..."]; ok && len(groupBy) > 1 {
+		return fmt.Errorf("%w: group_by of the notification settings cannot have the wildcard '...' and other labels at the same time", ErrAlertRuleFailedValidation)
+	}
+	if s.GroupWait != nil && *s.GroupWait < 0 {
+		return fmt.Errorf("%w: group_wait of the notification settings cannot be negative", ErrAlertRuleFailedValidation)
+	}
+	if s.GroupInterval != nil && *s.GroupInterval <= 0 {
+		return fmt.Errorf("%w: group_interval of the notification settings must be positive", ErrAlertRuleFailedValidation)
+	}
+	if s.RepeatInterval != nil && *s.RepeatInterval <= 0 {
+		return fmt.Errorf("%w: repeat_interval of the notification settings must be positive", ErrAlertRuleFailedValidation)
+	}
+	return nil
+}
+
+// Fingerprint returns a fnv64 hash of the settings. The order of the labels in GroupBy and of the mute timings
+// does not matter.
+func (s *NotificationSettings) Fingerprint() uint64 {
+	sorted := func(values []string) string {
+		result := make([]string, len(values))
+		copy(result, values)
+		sort.Strings(result)
+		return strings.Join(result, "\xfe")
+	}
+	duration := func(d *prometheusModel.Duration) string {
+		if d == nil {
+			return ""
+		}
+		return d.String()
+	}
+	h := fnv.New64()
+	// We can ignore err as fnv64 does not return an error
+	// nolint:errcheck,gosec
+	h.Write([]byte(strings.Join([]string{
+		s.Receiver,
+		sorted(s.GroupBy),
+		duration(s.GroupWait),
+		duration(s.GroupInterval),
+		duration(s.RepeatInterval),
+		sorted(s.MuteTimeIntervals),
+	}, "\xff")))
+	return h.Sum64()
+}
+
+// ToLabels returns the labels the scheduler adds to the alerts of the rule so that the auto-generated route of the
+// Alertmanager routes them according to the settings.
+func (s *NotificationSettings) ToLabels() data.Labels {
+	return data.Labels{
+		AutogeneratedRouteLabel:             "true",
+		AutogeneratedRouteReceiverNameLabel: s.Receiver,
+		AutogeneratedRouteSettingsHashLabel: strconv.FormatUint(s.Fingerprint(), 16),
+	}
+}
+
+// CopyNotificationSettings returns a deep copy of the settings.
+func CopyNotificationSettings(s NotificationSettings) NotificationSettings {
+	copyDuration := func(d *prometheusModel.Duration) *prometheusModel.Duration {
+		if d == nil {
+			return nil
+		}
+		v := *d
+		return &v
+	}
+	result := NotificationSettings{
+		Receiver:       s.Receiver,
+		GroupWait:      copyDuration(s.GroupWait),
+		GroupInterval:  copyDuration(s.GroupInterval),
+		RepeatInterval: copyDuration(s.RepeatInterval),
+	}
+	if s.GroupBy != nil {
+		result.GroupBy = make([]string, len(s.GroupBy))
+		copy(result.GroupBy, s.GroupBy)
+	}
+	if s.MuteTimeIntervals != nil {
+		result.MuteTimeIntervals = make([]string, len(s.MuteTimeIntervals))
+		copy(result.MuteTimeIntervals, s.MuteTimeIntervals)
+	}
+	return result
+}
+
+// NotificationSettingsValidator checks that the receiver and the mute timings of notification settings exist in
+// an Alertmanager configuration.
+type NotificationSettingsValidator struct {
+	receivers map[string]struct{}
+	muteTimes map[string]struct{}
+}
+
+// NewNotificationSettingsValidator creates a validator for the names of the receivers and the mute timings of an
+// Alertmanager configuration.
+func NewNotificationSettingsValidator(receivers []string, muteTimeIntervals []string) NotificationSettingsValidator {
+	v := NotificationSettingsValidator{
+		receivers: make(map[string]struct{}, len(receivers)),
+		muteTimes: make(map[string]struct{}, len(muteTimeIntervals)),
+	}
+	for _, name := range receivers {
+		v.receivers[name] = struct{}{}
+	}
+	for _, name := range muteTimeIntervals {
+		v.muteTimes[name] = struct{}{}
+	}
+	return v
+}
+
+// Validate returns an error wrapping ErrAlertRuleFailedValidation if the receiver or one of the mute timings of the
+// settings does not exist.
+func (v NotificationSettingsValidator) Validate(s NotificationSettings) error {
+	if _, ok := v.receivers[s.Receiver]; !ok {
+		return fmt.Errorf("%w: receiver '%s' of the notification settings does not exist", ErrAlertRuleFailedValidation, s.Receiver)
+	}
+	for _, name := range s.MuteTimeIntervals {
+		if _, ok := v.muteTimes[name]; !ok {
+			return fmt.Errorf("%w: mute time interval '%s' of the notification settings does not exist", ErrAlertRuleFailedValidation, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	prometheusModel "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationSettingsValidate(t *testing.T) {
+	duration := func(d time.Duration) *prometheusModel.Duration {
+		result := prometheusModel.Duration(d)
+		return &result
+	}
+
+	testCases := []struct {
+		name     string
+		settings NotificationSettings
+		expErr   string
+	}{
+		{
+			name:     "valid settings",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{"alertname"}, GroupWait: duration(0), GroupInterval: duration(time.Minute)},
+		},
+		{
+			name:     "wildcard group_by",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{"..."}},
+		},
+		{
+			name:     "missing receiver",
+			settings: NotificationSettings{},
+			expErr:   "receiver of the notification settings must be specified",
+		},
+		{
+			name:     "invalid label in group_by",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{"invalid-label"}},
+			expErr:   "invalid label name 'invalid-label'",
+		},
+		{
+			name:     "duplicated label in group_by",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{"alertname", "alertname"}},
+			expErr:   "duplicated label 'alertname'",
+		},
+		{
+			name:     "wildcard and labels in group_by",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{"...", "alertname"}},
+			expErr:   "cannot have the wildcard",
+		},
+		{
+			name:     "negative group_wait",
+			settings: NotificationSettings{Receiver: "receiver", GroupWait: duration(-time.Second)},
+			expErr:   "group_wait of the notification settings cannot be negative",
+		},
+		{
+			name:     "zero group_interval",
+			settings: NotificationSettings{Receiver: "receiver", GroupInterval: duration(0)},
+			expErr:   "group_interval of the notification settings must be positive",
+		},
+		{
+			name:     "zero repeat_interval",
+			settings: NotificationSettings{Receiver: "receiver", RepeatInterval: duration(0)},
+			expErr:   "repeat_interval of the notification settings must be positive",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.settings.Validate()
+			if tc.expErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, tc.expErr)
+		})
+	}
+}
+
+func TestNotificationSettingsFingerprint(t *testing.T) {
+	groupWait := prometheusModel.Duration(time.Minute)
+	settings := NotificationSettings{
+		Receiver:          "receiver",
+		GroupBy:           []string{"alertname", "grafana_folder"},
+		GroupWait:         &groupWait,
+		MuteTimeIntervals: []string{"weekends", "nights"},
+	}
+
+	t.Run("should not depend on the order of group_by and mute timings", func(t *testing.T) {
+		reordered := CopyNotificationSettings(settings)
+		reordered.GroupBy = []string{"grafana_folder", "alertname"}
+		reordered.MuteTimeIntervals = []string{"nights", "weekends"}
+		require.Equal(t, settings.Fingerprint(), reordered.Fingerprint())
+	})
+
+	t.Run("should change when the settings change", func(t *testing.T) {
+		changed := CopyNotificationSettings(settings)
+		changed.GroupWait = nil
+		require.NotEqual(t, settings.Fingerprint(), changed.Fingerprint())
+
+		changed = CopyNotificationSettings(settings)
+		changed.Receiver = "other"
+		require.NotEqual(t, settings.Fingerprint(), changed.Fingerprint())
+	})
+
+	t.Run("labels should contain the receiver and the fingerprint", func(t *testing.T) {
+		lbls := settings.ToLabels()
+		require.Equal(t, "true", lbls[AutogeneratedRouteLabel])
+		require.Equal(t, "receiver", lbls[AutogeneratedRouteReceiverNameLabel])
+		require.NotEmpty(t, lbls[AutogeneratedRouteSettingsHashLabel])
+	})
+}
+
+func TestNotificationSettingsValidator(t *testing.T) {
+	validator := NewNotificationSettingsValidator([]string{"team-a"}, []string{"weekends"})
+
+	require.NoError(t, validator.Validate(NotificationSettings{Receiver: "team-a", MuteTimeIntervals: []string{"weekends"}}))
+	require.ErrorIs(t, validator.Validate(NotificationSettings{Receiver: "unknown"}), ErrAlertRuleFailedValidation)
+	require.ErrorIs(t, validator.Validate(NotificationSettings{Receiver: "team-a", MuteTimeIntervals: []string{"unknown"}}), ErrAlertRuleFailedValidation)
+}
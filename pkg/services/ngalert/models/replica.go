@@ -0,0 +1,14 @@
+package models
+
+// SchedulerReplica is a Grafana instance that takes part in sharding the evaluation of alert rules.
+type SchedulerReplica struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	ReplicaID string `xorm:"replica_id"`
+	// LastHeartbeat is the time of the last heartbeat of the replica in seconds since the Unix epoch.
+	LastHeartbeat int64 `xorm:"last_heartbeat"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (r *SchedulerReplica) TableName() string {
+	return "alert_scheduler_replica"
+}
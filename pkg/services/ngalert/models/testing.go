@@ -164,6 +164,51 @@ func WithFor(duration time.Duration) AlertRuleMutator {
 	}
 }
 
+func WithKeepFiringFor(duration time.Duration) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.KeepFiringFor = duration
+	}
+}
+
+// WithEvaluationTimeout sets the maximum duration of an evaluation of the rule.
+func WithEvaluationTimeout(timeout time.Duration) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.EvaluationTimeout = timeout
+	}
+}
+
+// WithMaxSeriesPerQuery sets the maximum number of series a query of the rule can return.
+func WithMaxSeriesPerQuery(limit int64) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.MaxSeriesPerQuery = limit
+	}
+}
+
+// WithDependsOn makes the rule depend on the rules with the given UIDs.
+func WithDependsOn(uids ...string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.DependsOn = uids
+	}
+}
+
+// WithRecord makes the rule a recording rule that writes the result of the node "from" to the metric.
+func WithRecord(metric, from string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.Record = &Record{
+			Metric: metric,
+			From:   from,
+		}
+		rule.Condition = from
+	}
+}
+
+// WithNotificationSettings sends the alerts of the rule with the notification settings.
+func WithNotificationSettings(settings NotificationSettings) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.NotificationSettings = &settings
+	}
+}
+
 func GenerateAlertLabels(count int, prefix string) data.Labels {
 	labels := make(data.Labels, count)
 	for i := 0; i < count; i++ {
@@ -242,20 +287,24 @@ func GenerateGroupKey(orgID int64) AlertRuleGroupKey {
 // CopyRule creates a deep copy of AlertRule
 func CopyRule(r *AlertRule) *AlertRule {
 	result := AlertRule{
-		ID:              r.ID,
-		OrgID:           r.OrgID,
-		Title:           r.Title,
-		Condition:       r.Condition,
-		Updated:         r.Updated,
-		IntervalSeconds: r.IntervalSeconds,
-		Version:         r.Version,
-		UID:             r.UID,
-		NamespaceUID:    r.NamespaceUID,
-		RuleGroup:       r.RuleGroup,
-		RuleGroupIndex:  r.RuleGroupIndex,
-		NoDataState:     r.NoDataState,
-		ExecErrState:    r.ExecErrState,
-		For:             r.For,
+		ID:                     r.ID,
+		OrgID:                  r.OrgID,
+		Title:                  r.Title,
+		Condition:              r.Condition,
+		Updated:                r.Updated,
+		IntervalSeconds:        r.IntervalSeconds,
+		Version:                r.Version,
+		UID:                    r.UID,
+		NamespaceUID:           r.NamespaceUID,
+		RuleGroup:              r.RuleGroup,
+		RuleGroupIndex:         r.RuleGroupIndex,
+		NoDataState:            r.NoDataState,
+		ExecErrState:           r.ExecErrState,
+		For:                    r.For,
+		KeepFiringFor:          r.KeepFiringFor,
+		EvaluationTimeout:      r.EvaluationTimeout,
+		GroupEvaluationTimeout: r.GroupEvaluationTimeout,
+		MaxSeriesPerQuery:      r.MaxSeriesPerQuery,
 	}
 
 	if r.DashboardUID != nil {
@@ -293,6 +342,21 @@ func CopyRule(r *AlertRule) *AlertRule {
 		}
 	}
 
+	if r.Record != nil {
+		record := *r.Record
+		result.Record = &record
+	}
+
+	if r.DependsOn != nil {
+		result.DependsOn = make([]string, len(r.DependsOn))
+		copy(result.DependsOn, r.DependsOn)
+	}
+
+	if r.NotificationSettings != nil {
+		settings := CopyNotificationSettings(*r.NotificationSettings)
+		result.NotificationSettings = &settings
+	}
+
 	return &result
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -37,11 +38,13 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func ProvideService(
@@ -67,6 +70,7 @@ func ProvideService(
 	annotationsRepo annotations.Repository,
 	pluginsStore plugins.Store,
 	tracer tracing.Tracer,
+	imageFiles *store.ImageFileStore,
 ) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:                  cfg,
@@ -92,6 +96,7 @@ func ProvideService(
 		annotationsRepo:      annotationsRepo,
 		pluginsStore:         pluginsStore,
 		tracer:               tracer,
+		imageFiles:           imageFiles,
 	}
 
 	if ng.IsDisabled() {
@@ -124,6 +129,7 @@ type AlertNG struct {
 	renderService       rendering.Service
 	imageService        image.ImageService
 	schedule            schedule.ScheduleService
+	replicaRing         *schedule.ReplicaRing
 	stateManager        *state.Manager
 	folderService       folder.Service
 	dashboardService    dashboards.DashboardService
@@ -139,6 +145,8 @@ type AlertNG struct {
 	bus          bus.Bus
 	pluginsStore plugins.Store
 	tracer       tracing.Tracer
+	// imageFiles stores the files of screenshots. It is nil if they are stored only on the local disk.
+	imageFiles *store.ImageFileStore
 }
 
 func (ng *AlertNG) init() error {
@@ -156,6 +164,7 @@ func (ng *AlertNG) init() error {
 		FolderService:    ng.folderService,
 		AccessControl:    ng.accesscontrol,
 		DashboardService: ng.dashboardService,
+		ImageFiles:       ng.imageFiles,
 	}
 	ng.store = store
 
@@ -196,6 +205,12 @@ func (ng *AlertNG) init() error {
 	ng.AlertsRouter = alertsRouter
 
 	evalFactory := eval.NewEvaluatorFactory(ng.Cfg.UnifiedAlerting, ng.DataSourceCache, ng.ExpressionService, ng.pluginsStore)
+
+	var recordingWriter schedule.RecordingWriter = writer.NewNoopWriter()
+	if ng.Cfg.UnifiedAlerting.RecordingRules.Enabled {
+		recordingWriter = writer.NewPrometheusWriter(ng.Cfg.UnifiedAlerting.RecordingRules, ng.DataSourceService, log.New("ngalert.writer"))
+	}
+
 	schedCfg := schedule.SchedulerCfg{
 		MaxAttempts:          ng.Cfg.UnifiedAlerting.MaxAttempts,
 		C:                    clk,
@@ -207,10 +222,18 @@ func (ng *AlertNG) init() error {
 		RuleStore:            store,
 		Metrics:              ng.Metrics.GetSchedulerMetrics(),
 		AlertSender:          alertsRouter,
+		RecordingWriter:      recordingWriter,
 		Tracer:               ng.tracer,
 	}
 
-	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store)
+	if ng.Cfg.UnifiedAlerting.HAShardingEnabled {
+		hostname, _ := os.Hostname()
+		replicaID := hostname + "-" + util.GenerateShortUID()
+		ng.replicaRing = schedule.NewReplicaRing(replicaID, store, clk, ng.Cfg.UnifiedAlerting.HAHeartbeatInterval, ng.Cfg.UnifiedAlerting.HAHeartbeatTimeout)
+		schedCfg.Sharder = ng.replicaRing
+	}
+
+	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.store)
 	if err != nil {
 		return err
 	}
@@ -222,8 +245,18 @@ func (ng *AlertNG) init() error {
 		Clock:                clk,
 		Historian:            history,
 		DoNotSaveNormalState: ng.FeatureToggles.IsEnabled(featuremgmt.FlagAlertingNoNormalState),
+
+		ImageStore:              store,
+		ImageRetention:          ng.Cfg.UnifiedAlerting.Screenshots.ImageRetention,
+		DoNotTakeImageOnResolve: !ng.Cfg.UnifiedAlerting.Screenshots.CaptureOnResolve,
+		RepeatImageInterval:     ng.Cfg.UnifiedAlerting.Screenshots.RepeatCaptureInterval,
 	}
 	stateManager := state.NewManager(cfg)
+	if ng.Cfg.UnifiedAlerting.StateMetrics.Enabled {
+		if err := ng.Metrics.Registerer.Register(stateManager.NewAlertsCollector(ng.Cfg.UnifiedAlerting.StateMetrics.MaxSeriesPerOrg)); err != nil {
+			return fmt.Errorf("failed to register the metrics of the alerts: %w", err)
+		}
+	}
 	scheduler := schedule.NewScheduler(schedCfg, stateManager)
 
 	// if it is required to include folder title to the alerts, we need to subscribe to changes of alert title
@@ -239,7 +272,7 @@ func (ng *AlertNG) init() error {
 	contactPointService := provisioning.NewContactPointService(store, ng.SecretsService, store, store, ng.Log)
 	templateService := provisioning.NewTemplateService(store, store, store, ng.Log)
 	muteTimingService := provisioning.NewMuteTimingService(store, store, store, ng.Log)
-	alertRuleService := provisioning.NewAlertRuleService(store, store, ng.dashboardService, ng.QuotaService, store,
+	alertRuleService := provisioning.NewAlertRuleService(store, store, store, ng.dashboardService, ng.QuotaService, store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ng.Log)
 
@@ -340,6 +373,11 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	})
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
+		if ng.replicaRing != nil {
+			children.Go(func() error {
+				return ng.replicaRing.Run(subCtx)
+			})
+		}
 		children.Go(func() error {
 			return ng.schedule.Run(subCtx)
 		})
@@ -389,7 +427,7 @@ type Historian interface {
 	state.Historian
 }
 
-func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore) (Historian, error) {
+func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, hs historian.StateHistoryStore) (Historian, error) {
 	if !cfg.Enabled {
 		return historian.NewNopHistorian(), nil
 	}
@@ -412,7 +450,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		return backend, nil
 	}
 	if cfg.Backend == "sql" {
-		return historian.NewSqlBackend(), nil
+		return historian.NewSqlBackend(hs), nil
 	}
 
 	return nil, fmt.Errorf("unrecognized state history backend: %s", cfg.Backend)
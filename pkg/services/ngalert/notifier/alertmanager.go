@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	alertingNotify "github.com/grafana/alerting/notify"
@@ -22,6 +23,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/localwebhook"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/setting"
@@ -43,6 +45,7 @@ var silenceMaintenanceInterval = 15 * time.Minute
 type AlertingStore interface {
 	store.AlertingStore
 	store.ImageStore
+	ListNotificationSettings(ctx context.Context, orgID int64) (map[ngmodels.AlertRuleKey]ngmodels.NotificationSettings, error)
 }
 
 type Alertmanager struct {
@@ -175,7 +178,7 @@ func (am *Alertmanager) SaveAndApplyDefaultConfig(ctx context.Context) error {
 		}
 
 		err = am.Store.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error {
-			_, err := am.applyConfig(cfg, []byte(am.Settings.UnifiedAlerting.DefaultConfiguration))
+			_, err := am.applyConfig(ctx, cfg, []byte(am.Settings.UnifiedAlerting.DefaultConfiguration))
 			return err
 		})
 		if err != nil {
@@ -188,8 +191,9 @@ func (am *Alertmanager) SaveAndApplyDefaultConfig(ctx context.Context) error {
 }
 
 // SaveAndApplyConfig saves the configuration the database and applies the configuration to the Alertmanager.
-// It rollbacks the save if we fail to apply the configuration.
-func (am *Alertmanager) SaveAndApplyConfig(ctx context.Context, cfg *apimodels.PostableUserConfig) error {
+// It rollbacks the save if we fail to apply the configuration. createdBy is the login of the user that saves the
+// configuration, or empty if Grafana saves it.
+func (am *Alertmanager) SaveAndApplyConfig(ctx context.Context, cfg *apimodels.PostableUserConfig, createdBy string) error {
 	rawConfig, err := json.Marshal(&cfg)
 	if err != nil {
 		return fmt.Errorf("failed to serialize to the Alertmanager configuration: %w", err)
@@ -202,10 +206,11 @@ func (am *Alertmanager) SaveAndApplyConfig(ctx context.Context, cfg *apimodels.P
 			ConfigurationVersion:      fmt.Sprintf("v%d", ngmodels.AlertConfigurationVersion),
 			OrgID:                     am.orgID,
 			LastApplied:               time.Now().UTC().Unix(),
+			CreatedBy:                 createdBy,
 		}
 
 		err = am.Store.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error {
-			_, err := am.applyConfig(cfg, rawConfig)
+			_, err := am.applyConfig(ctx, cfg, rawConfig)
 			return err
 		})
 		if err != nil {
@@ -239,7 +244,20 @@ func (am *Alertmanager) ApplyConfig(ctx context.Context, dbCfg *ngmodels.AlertCo
 // applyConfig applies a new configuration by re-initializing all components using the configuration provided.
 // It returns a boolean indicating whether the user config was changed and an error.
 // It is not safe to call concurrently.
-func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig []byte) (bool, error) {
+func (am *Alertmanager) applyConfig(ctx context.Context, cfg *apimodels.PostableUserConfig, rawConfig []byte) (bool, error) {
+	// The route for the notification settings of the alert rules is part of the applied configuration, so that
+	// the configuration is applied again when the settings change.
+	cfg, routeAdded, err := am.withAutogeneratedRoute(ctx, cfg)
+	if err != nil {
+		return false, err
+	}
+	if routeAdded && rawConfig != nil {
+		rawConfig, err = json.Marshal(cfg)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	// First, let's make sure this config is not already loaded
 	var amConfigChanged bool
 	if rawConfig == nil {
@@ -294,7 +312,7 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 
 // applyAndMarkConfig applies a configuration and marks it as applied if no errors occur.
 func (am *Alertmanager) applyAndMarkConfig(ctx context.Context, hash string, cfg *apimodels.PostableUserConfig, rawConfig []byte) error {
-	configChanged, err := am.applyConfig(cfg, rawConfig)
+	configChanged, err := am.applyConfig(ctx, cfg, rawConfig)
 	if err != nil {
 		return err
 	}
@@ -374,7 +392,7 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 			Err:      err,
 		}
 	}
-	receiverFactory, exists := alertingNotify.Factory(r.Type)
+	receiverFactory, exists := am.receiverFactory(r.Type)
 	if !exists {
 		return nil, InvalidReceiverError{
 			Receiver: r,
@@ -391,6 +409,15 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 	return n, nil
 }
 
+// receiverFactory returns the factory of the notifiers of the type. The local webhook is implemented by Grafana,
+// the other notifiers by the alerting package.
+func (am *Alertmanager) receiverFactory(receiverType string) (func(receivers.FactoryConfig) (alertingNotify.NotificationChannel, error), bool) {
+	if strings.EqualFold(receiverType, localwebhook.Type) {
+		return localwebhook.Factory(am.Settings.UnifiedAlerting.LocalWebhook.AllowedDirectories), true
+	}
+	return alertingNotify.Factory(receiverType)
+}
+
 // PutAlerts receives the alerts and then sends them through the corresponding route based on whenever the alert has a receiver embedded or not
 func (am *Alertmanager) PutAlerts(postableAlerts apimodels.PostableAlerts) error {
 	alerts := make(alertingNotify.PostableAlerts, 0, len(postableAlerts.PostableAlerts))
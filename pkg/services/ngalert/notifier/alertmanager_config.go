@@ -36,7 +36,23 @@ func (moa *MultiOrgAlertmanager) GetAlertmanagerConfiguration(ctx context.Contex
 	if err != nil {
 		return definitions.GettableUserConfig{}, fmt.Errorf("failed to get latest configuration: %w", err)
 	}
-	cfg, err := Load([]byte(query.Result.AlertmanagerConfiguration))
+	result, err := moa.gettableUserConfigFromRaw([]byte(query.Result.AlertmanagerConfiguration))
+	if err != nil {
+		return definitions.GettableUserConfig{}, err
+	}
+
+	result, err = moa.mergeProvenance(ctx, result, org)
+	if err != nil {
+		return definitions.GettableUserConfig{}, err
+	}
+
+	return result, nil
+}
+
+// gettableUserConfigFromRaw converts a stored configuration into a GettableUserConfig, which does not contain the
+// values of the secure settings.
+func (moa *MultiOrgAlertmanager) gettableUserConfigFromRaw(raw []byte) (definitions.GettableUserConfig, error) {
+	cfg, err := Load(raw)
 	if err != nil {
 		return definitions.GettableUserConfig{}, fmt.Errorf("failed to unmarshal alertmanager configuration: %w", err)
 	}
@@ -81,15 +97,12 @@ func (moa *MultiOrgAlertmanager) GetAlertmanagerConfiguration(ctx context.Contex
 		result.AlertmanagerConfig.Receivers = append(result.AlertmanagerConfig.Receivers, &gettableApiReceiver)
 	}
 
-	result, err = moa.mergeProvenance(ctx, result, org)
-	if err != nil {
-		return definitions.GettableUserConfig{}, err
-	}
-
 	return result, nil
 }
 
-func (moa *MultiOrgAlertmanager) ApplyAlertmanagerConfiguration(ctx context.Context, org int64, config definitions.PostableUserConfig) error {
+// ApplyAlertmanagerConfiguration saves the configuration of the organization and applies it to its Alertmanager.
+// The configuration is recorded in the history of configurations as created by the given user login.
+func (moa *MultiOrgAlertmanager) ApplyAlertmanagerConfiguration(ctx context.Context, org int64, config definitions.PostableUserConfig, createdBy string) error {
 	// Get the last known working configuration
 	query := models.GetLatestAlertmanagerConfigurationQuery{OrgID: org}
 	if err := moa.configStore.GetLatestAlertmanagerConfiguration(ctx, &query); err != nil {
@@ -115,7 +128,7 @@ func (moa *MultiOrgAlertmanager) ApplyAlertmanagerConfiguration(ctx context.Cont
 		}
 	}
 
-	if err := am.SaveAndApplyConfig(ctx, &config); err != nil {
+	if err := am.SaveAndApplyConfig(ctx, &config, createdBy); err != nil {
 		moa.logger.Error("unable to save and apply alertmanager configuration", "error", err)
 		return AlertmanagerConfigRejectedError{err}
 	}
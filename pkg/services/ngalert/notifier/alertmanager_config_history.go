@@ -0,0 +1,132 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// GetAlertmanagerConfigurationHistory returns the configurations that were saved for the organization, newest first.
+// If limit is greater than zero, at most limit configurations are returned.
+func (moa *MultiOrgAlertmanager) GetAlertmanagerConfigurationHistory(ctx context.Context, org int64, limit int) ([]definitions.GettableHistoricUserConfig, error) {
+	query := models.GetAlertmanagerConfigurationHistoryQuery{OrgID: org, Limit: limit}
+	if err := moa.configStore.GetAlertmanagerConfigurationHistory(ctx, &query); err != nil {
+		return nil, fmt.Errorf("failed to get configuration history: %w", err)
+	}
+
+	result := make([]definitions.GettableHistoricUserConfig, 0, len(query.Result))
+	for _, h := range query.Result {
+		cfg, err := moa.gettableHistoricUserConfig(h)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, cfg)
+	}
+	return result, nil
+}
+
+// DiffAlertmanagerConfigurations returns the unified diff between two historical configurations of the organization.
+// The configurations are compared as YAML without the values of the secure settings.
+func (moa *MultiOrgAlertmanager) DiffAlertmanagerConfigurations(ctx context.Context, org int64, from, to int64) (definitions.GettableHistoricUserConfigDiff, error) {
+	fromYAML, err := moa.historicalConfigurationYAML(ctx, org, from)
+	if err != nil {
+		return definitions.GettableHistoricUserConfigDiff{}, err
+	}
+	toYAML, err := moa.historicalConfigurationYAML(ctx, org, to)
+	if err != nil {
+		return definitions.GettableHistoricUserConfigDiff{}, err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(fromYAML),
+		B:        difflib.SplitLines(toYAML),
+		FromFile: strconv.FormatInt(from, 10),
+		ToFile:   strconv.FormatInt(to, 10),
+		Context:  3,
+	})
+	if err != nil {
+		return definitions.GettableHistoricUserConfigDiff{}, fmt.Errorf("failed to diff configurations: %w", err)
+	}
+	return definitions.GettableHistoricUserConfigDiff{From: from, To: to, Diff: diff}, nil
+}
+
+// ActivateHistoricalConfiguration saves and applies a historical configuration of the organization as its current
+// configuration. The configuration is saved like any other configuration, so the rollback is recorded in the history.
+func (moa *MultiOrgAlertmanager) ActivateHistoricalConfiguration(ctx context.Context, org int64, id int64, createdBy string) error {
+	historic, err := moa.GetHistoricalConfiguration(ctx, org, id)
+	if err != nil {
+		return err
+	}
+
+	am, err := moa.AlertmanagerFor(org)
+	if err != nil {
+		// It's okay if the alertmanager isn't ready yet, we're changing its config anyway.
+		if !errors.Is(err, ErrAlertmanagerNotReady) {
+			return err
+		}
+	}
+
+	if err := am.SaveAndApplyConfig(ctx, historic, createdBy); err != nil {
+		moa.logger.Error("unable to save and apply historical alertmanager configuration", "error", err, "id", id)
+		return AlertmanagerConfigRejectedError{err}
+	}
+	return nil
+}
+
+// GetHistoricalConfiguration returns a historical configuration of the organization. The secure settings of the
+// configuration are encrypted.
+func (moa *MultiOrgAlertmanager) GetHistoricalConfiguration(ctx context.Context, org int64, id int64) (*definitions.PostableUserConfig, error) {
+	query := models.GetHistoricalAlertmanagerConfigurationQuery{OrgID: org, ID: id}
+	if err := moa.configStore.GetHistoricalAlertmanagerConfiguration(ctx, &query); err != nil {
+		return nil, err
+	}
+	cfg, err := Load([]byte(query.Result.AlertmanagerConfiguration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal historical alertmanager configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+func (moa *MultiOrgAlertmanager) historicalConfigurationYAML(ctx context.Context, org int64, id int64) (string, error) {
+	query := models.GetHistoricalAlertmanagerConfigurationQuery{OrgID: org, ID: id}
+	if err := moa.configStore.GetHistoricalAlertmanagerConfiguration(ctx, &query); err != nil {
+		return "", err
+	}
+	cfg, err := moa.gettableUserConfigFromRaw([]byte(query.Result.AlertmanagerConfiguration))
+	if err != nil {
+		return "", err
+	}
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal configuration %d: %w", id, err)
+	}
+	return string(b), nil
+}
+
+func (moa *MultiOrgAlertmanager) gettableHistoricUserConfig(h *models.HistoricAlertConfiguration) (definitions.GettableHistoricUserConfig, error) {
+	cfg, err := moa.gettableUserConfigFromRaw([]byte(h.AlertmanagerConfiguration))
+	if err != nil {
+		return definitions.GettableHistoricUserConfig{}, fmt.Errorf("failed to convert configuration %d: %w", h.ID, err)
+	}
+	result := definitions.GettableHistoricUserConfig{
+		ID:                 h.ID,
+		TemplateFiles:      cfg.TemplateFiles,
+		AlertmanagerConfig: cfg.AlertmanagerConfig,
+		CreatedAt:          strfmt.DateTime(time.Unix(h.CreatedAt, 0).UTC()),
+		CreatedBy:          h.CreatedBy,
+	}
+	if h.LastApplied != 0 {
+		lastApplied := strfmt.DateTime(time.Unix(h.LastApplied, 0).UTC())
+		result.LastApplied = &lastApplied
+	}
+	return result, nil
+}
@@ -0,0 +1,116 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+// withAutogeneratedRoute returns a copy of the configuration with the route for the notification settings of the
+// rules of the organization. It returns the configuration unchanged and false if no rule has notification settings.
+// The route is never saved, it is generated every time the configuration is applied.
+func (am *Alertmanager) withAutogeneratedRoute(ctx context.Context, cfg *apimodels.PostableUserConfig) (*apimodels.PostableUserConfig, bool, error) {
+	settings, err := am.Store.ListNotificationSettings(ctx, am.orgID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get notification settings of the alert rules: %w", err)
+	}
+	return addAutogeneratedRoute(cfg, settings, am.logger)
+}
+
+// addAutogeneratedRoute returns a copy of the configuration where the first child of the root route matches the
+// alerts of the rules with notification settings. The route has a child per receiver, which has a child per distinct
+// settings. Settings that refer to a receiver or a mute timing that does not exist are skipped. The alerts that do
+// not match any child are sent to the receiver of the root route.
+func addAutogeneratedRoute(cfg *apimodels.PostableUserConfig, settings map[ngmodels.AlertRuleKey]ngmodels.NotificationSettings, logger log.Logger) (*apimodels.PostableUserConfig, bool, error) {
+	root := cfg.AlertmanagerConfig.Route
+	if root == nil || len(settings) == 0 {
+		return cfg, false, nil
+	}
+
+	validator := provisioning.NewNotificationSettingsValidator(cfg)
+	byReceiver := make(map[string]map[string]ngmodels.NotificationSettings)
+	for key, s := range settings {
+		if err := validator.Validate(s); err != nil {
+			logger.Warn("Skipping notification settings of the alert rule", "rule_uid", key.UID, "error", err)
+			continue
+		}
+		if byReceiver[s.Receiver] == nil {
+			byReceiver[s.Receiver] = make(map[string]ngmodels.NotificationSettings)
+		}
+		byReceiver[s.Receiver][strconv.FormatUint(s.Fingerprint(), 16)] = s
+	}
+
+	autogenMatcher, err := labels.NewMatcher(labels.MatchEqual, ngmodels.AutogeneratedRouteLabel, "true")
+	if err != nil {
+		return nil, false, err
+	}
+	autogenRoute := &apimodels.Route{
+		Receiver:       root.Receiver,
+		ObjectMatchers: apimodels.ObjectMatchers{autogenMatcher},
+	}
+	for _, receiver := range sortedKeys(byReceiver) {
+		receiverMatcher, err := labels.NewMatcher(labels.MatchEqual, ngmodels.AutogeneratedRouteReceiverNameLabel, receiver)
+		if err != nil {
+			return nil, false, err
+		}
+		receiverRoute := &apimodels.Route{
+			Receiver:       receiver,
+			ObjectMatchers: apimodels.ObjectMatchers{receiverMatcher},
+		}
+		for _, fingerprint := range sortedKeys(byReceiver[receiver]) {
+			s := byReceiver[receiver][fingerprint]
+			hashMatcher, err := labels.NewMatcher(labels.MatchEqual, ngmodels.AutogeneratedRouteSettingsHashLabel, fingerprint)
+			if err != nil {
+				return nil, false, err
+			}
+			receiverRoute.Routes = append(receiverRoute.Routes, newSettingsRoute(s, hashMatcher))
+		}
+		autogenRoute.Routes = append(autogenRoute.Routes, receiverRoute)
+	}
+
+	result := *cfg
+	rootCopy := *root
+	rootCopy.Routes = append([]*apimodels.Route{autogenRoute}, root.Routes...)
+	result.AlertmanagerConfig.Route = &rootCopy
+	return &result, true, nil
+}
+
+// newSettingsRoute creates the route for the settings. The group_by and the timings that are not set are inherited
+// from the root route.
+func newSettingsRoute(s ngmodels.NotificationSettings, matcher *labels.Matcher) *apimodels.Route {
+	route := &apimodels.Route{
+		Receiver:          s.Receiver,
+		ObjectMatchers:    apimodels.ObjectMatchers{matcher},
+		GroupByStr:        s.GroupBy,
+		GroupWait:         s.GroupWait,
+		GroupInterval:     s.GroupInterval,
+		RepeatInterval:    s.RepeatInterval,
+		MuteTimeIntervals: s.MuteTimeIntervals,
+	}
+	for _, l := range s.GroupBy {
+		if l == "..." {
+			route.GroupByAll = true
+		} else {
+			route.GroupBy = append(route.GroupBy, model.LabelName(l))
+		}
+	}
+	return route
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
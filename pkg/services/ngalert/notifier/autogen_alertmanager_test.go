@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const autogenTestConfig = `{
+	"alertmanager_config": {
+		"route": {
+			"receiver": "default",
+			"group_by": ["grafana_folder", "alertname"],
+			"routes": [{"receiver": "team-a", "object_matchers": [["team", "=", "a"]]}]
+		},
+		"mute_time_intervals": [{"name": "weekends", "time_intervals": [{"weekdays": ["saturday", "sunday"]}]}],
+		"receivers": [
+			{"name": "default", "grafana_managed_receiver_configs": [{"uid": "default", "name": "default", "type": "email", "settings": {"addresses": "default@grafana.com"}}]},
+			{"name": "team-a", "grafana_managed_receiver_configs": [{"uid": "team-a", "name": "team-a", "type": "email", "settings": {"addresses": "a@grafana.com"}}]}
+		]
+	}
+}`
+
+func TestAddAutogeneratedRoute(t *testing.T) {
+	cfg, err := Load([]byte(autogenTestConfig))
+	require.NoError(t, err)
+
+	groupWait := model.Duration(time.Minute)
+	withTimings := ngmodels.NotificationSettings{Receiver: "team-a", GroupBy: []string{"..."}, GroupWait: &groupWait, MuteTimeIntervals: []string{"weekends"}}
+	defaults := ngmodels.NotificationSettings{Receiver: "team-a"}
+	settings := map[ngmodels.AlertRuleKey]ngmodels.NotificationSettings{
+		{OrgID: 1, UID: "rule-1"}: withTimings,
+		{OrgID: 1, UID: "rule-2"}: withTimings,
+		{OrgID: 1, UID: "rule-3"}: defaults,
+		{OrgID: 1, UID: "rule-4"}: {Receiver: "unknown"},
+		{OrgID: 1, UID: "rule-5"}: {Receiver: "default", MuteTimeIntervals: []string{"unknown"}},
+	}
+
+	result, added, err := addAutogeneratedRoute(cfg, settings, log.NewNopLogger())
+	require.NoError(t, err)
+	require.True(t, added)
+
+	t.Run("should not change the configuration", func(t *testing.T) {
+		require.Len(t, cfg.AlertmanagerConfig.Route.Routes, 1)
+		require.Equal(t, "team-a", cfg.AlertmanagerConfig.Route.Routes[0].Receiver)
+	})
+
+	root := result.AlertmanagerConfig.Route
+	require.Len(t, root.Routes, 2)
+	autogen := root.Routes[0]
+
+	t.Run("should add a route per receiver and distinct settings", func(t *testing.T) {
+		require.Equal(t, "default", autogen.Receiver)
+		require.False(t, autogen.Continue)
+		require.Len(t, autogen.Routes, 1)
+		require.Equal(t, "team-a", autogen.Routes[0].Receiver)
+		require.Len(t, autogen.Routes[0].Routes, 2)
+	})
+
+	t.Run("should route alerts according to the settings", func(t *testing.T) {
+		amRoute := dispatch.NewRoute(root.AsAMRoute(), nil)
+
+		routes := amRoute.Match(toLabelSet(withTimings.ToLabels()))
+		require.Len(t, routes, 1)
+		require.Equal(t, "team-a", routes[0].RouteOpts.Receiver)
+		require.True(t, routes[0].RouteOpts.GroupByAll)
+		require.Equal(t, time.Minute, routes[0].RouteOpts.GroupWait)
+		require.Equal(t, []string{"weekends"}, routes[0].RouteOpts.MuteTimeIntervals)
+
+		routes = amRoute.Match(toLabelSet(defaults.ToLabels()))
+		require.Len(t, routes, 1)
+		require.Equal(t, "team-a", routes[0].RouteOpts.Receiver)
+		require.Contains(t, routes[0].RouteOpts.GroupBy, model.LabelName("alertname"))
+		require.Empty(t, routes[0].RouteOpts.MuteTimeIntervals)
+	})
+
+	t.Run("should send alerts of skipped settings to the root receiver", func(t *testing.T) {
+		amRoute := dispatch.NewRoute(root.AsAMRoute(), nil)
+		routes := amRoute.Match(toLabelSet((&ngmodels.NotificationSettings{Receiver: "unknown"}).ToLabels()))
+		require.Len(t, routes, 1)
+		require.Equal(t, "default", routes[0].RouteOpts.Receiver)
+	})
+
+	t.Run("should not change the configuration if there are no settings", func(t *testing.T) {
+		result, added, err := addAutogeneratedRoute(cfg, nil, log.NewNopLogger())
+		require.NoError(t, err)
+		require.False(t, added)
+		require.Same(t, cfg, result)
+	})
+}
+
+func toLabelSet(lbls map[string]string) model.LabelSet {
+	result := make(model.LabelSet, len(lbls))
+	for k, v := range lbls {
+		result[model.LabelName(k)] = model.LabelValue(v)
+	}
+	return result
+}
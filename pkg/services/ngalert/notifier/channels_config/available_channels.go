@@ -844,6 +844,98 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "localwebhook",
+			Name:        "Local webhook",
+			Description: "Writes the webhook payload to a local file or Unix domain socket",
+			Heading:     "Local webhook settings",
+			Info:        "The path must be in one of the directories of the allowed_directories setting of the [unified_alerting.local_webhook] section.",
+			Options: []NotifierOption{
+				{
+					Label:   "Destination",
+					Element: ElementTypeSelect,
+					SelectOptions: []SelectOption{
+						{
+							Value: "file",
+							Label: "File",
+						},
+						{
+							Value: "socket",
+							Label: "Unix socket",
+						},
+					},
+					Description:  "Append every notification as a line to a file, or write it to a Unix domain socket. Default is file.",
+					PropertyName: "destination",
+				},
+				{
+					Label:        "Path",
+					Description:  "Absolute path of the file or of the socket.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "/var/spool/grafana/notifications.log",
+					PropertyName: "path",
+					Required:     true,
+				},
+				{
+					Label:        "Max file size (MB)",
+					Description:  "Size above which the file is rotated. Default is 100.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "100",
+					PropertyName: "maxFileSizeMB",
+					ShowWhen: ShowWhen{
+						Field: "destination",
+						Is:    "file",
+					},
+				},
+				{
+					Label:        "Max backups",
+					Description:  "Number of rotated files to keep. Default is 5.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "5",
+					PropertyName: "maxBackups",
+					ShowWhen: ShowWhen{
+						Field: "destination",
+						Is:    "file",
+					},
+				},
+				{
+					Label:        "Socket timeout",
+					Description:  "Timeout for connecting and writing to the socket. Default is 10s.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "10s",
+					PropertyName: "socketTimeout",
+					ShowWhen: ShowWhen{
+						Field: "destination",
+						Is:    "socket",
+					},
+				},
+				{
+					Label:        "Max Alerts",
+					Description:  "Max alerts to include in a notification. Remaining alerts in the same batch will be ignored above this number. 0 means no limit.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "maxAlerts",
+				},
+				{
+					Label:        "Title",
+					Description:  "Templated title of the message.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "title",
+					Placeholder:  alertingTemplates.DefaultMessageTitleEmbed,
+				},
+				{
+					Label:        "Message",
+					Description:  "Custom message. You can use template variables.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "message",
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+				},
+			},
+		},
 		{
 			Type:        "wecom",
 			Name:        "WeCom",
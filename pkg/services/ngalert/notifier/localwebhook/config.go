@@ -0,0 +1,145 @@
+package localwebhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// Type is the type of the contact point.
+const Type = "localwebhook"
+
+const (
+	DestinationFile   = "file"
+	DestinationSocket = "socket"
+
+	defaultMaxFileSizeMB = 100
+	defaultMaxBackups    = 5
+	defaultSocketTimeout = 10 * time.Second
+)
+
+type Config struct {
+	Destination string
+	// Path is the path of the file or of the Unix domain socket.
+	Path string
+	// MaxFileSize is the size in bytes above which the file is rotated.
+	MaxFileSize int64
+	// MaxBackups is the number of rotated files that are kept.
+	MaxBackups    int
+	SocketTimeout time.Duration
+	MaxAlerts     int
+
+	Title   string
+	Message string
+}
+
+// ValidateConfig parses the settings of the contact point. It does not check that the path is allowed.
+func ValidateConfig(factoryConfig receivers.FactoryConfig) (Config, error) {
+	settings := Config{}
+	rawSettings := struct {
+		Destination   string      `json:"destination,omitempty" yaml:"destination,omitempty"`
+		Path          string      `json:"path,omitempty" yaml:"path,omitempty"`
+		MaxFileSizeMB json.Number `json:"maxFileSizeMB,omitempty" yaml:"maxFileSizeMB,omitempty"`
+		MaxBackups    json.Number `json:"maxBackups,omitempty" yaml:"maxBackups,omitempty"`
+		SocketTimeout string      `json:"socketTimeout,omitempty" yaml:"socketTimeout,omitempty"`
+		MaxAlerts     json.Number `json:"maxAlerts,omitempty" yaml:"maxAlerts,omitempty"`
+		Title         string      `json:"title,omitempty" yaml:"title,omitempty"`
+		Message       string      `json:"message,omitempty" yaml:"message,omitempty"`
+	}{}
+
+	err := json.Unmarshal(factoryConfig.Config.Settings, &rawSettings)
+	if err != nil {
+		return settings, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	settings.Destination = rawSettings.Destination
+	if settings.Destination == "" {
+		settings.Destination = DestinationFile
+	}
+	if settings.Destination != DestinationFile && settings.Destination != DestinationSocket {
+		return settings, fmt.Errorf("invalid destination '%s', must be either '%s' or '%s'", settings.Destination, DestinationFile, DestinationSocket)
+	}
+
+	if rawSettings.Path == "" {
+		return settings, errors.New("required field 'path' is not specified")
+	}
+	if !filepath.IsAbs(rawSettings.Path) {
+		return settings, fmt.Errorf("path '%s' must be absolute", rawSettings.Path)
+	}
+	settings.Path = filepath.Clean(rawSettings.Path)
+
+	maxFileSizeMB, err := parseNumber(rawSettings.MaxFileSizeMB, "maxFileSizeMB", defaultMaxFileSizeMB)
+	if err != nil {
+		return settings, err
+	}
+	if maxFileSizeMB <= 0 {
+		return settings, errors.New("field 'maxFileSizeMB' must be positive")
+	}
+	settings.MaxFileSize = int64(maxFileSizeMB) * 1024 * 1024
+
+	settings.MaxBackups, err = parseNumber(rawSettings.MaxBackups, "maxBackups", defaultMaxBackups)
+	if err != nil {
+		return settings, err
+	}
+	if settings.MaxBackups < 0 {
+		return settings, errors.New("field 'maxBackups' cannot be negative")
+	}
+
+	settings.SocketTimeout = defaultSocketTimeout
+	if rawSettings.SocketTimeout != "" {
+		settings.SocketTimeout, err = time.ParseDuration(rawSettings.SocketTimeout)
+		if err != nil {
+			return settings, fmt.Errorf("invalid socketTimeout: %w", err)
+		}
+		if settings.SocketTimeout <= 0 {
+			return settings, errors.New("field 'socketTimeout' must be positive")
+		}
+	}
+
+	settings.MaxAlerts, err = parseNumber(rawSettings.MaxAlerts, "maxAlerts", 0)
+	if err != nil {
+		return settings, err
+	}
+
+	settings.Title = rawSettings.Title
+	if settings.Title == "" {
+		settings.Title = templates.DefaultMessageTitleEmbed
+	}
+	settings.Message = rawSettings.Message
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+	return settings, nil
+}
+
+// IsPathAllowed returns true if the path is in one of the directories.
+func IsPathAllowed(path string, allowedDirectories []string) bool {
+	for _, dir := range allowedDirectories {
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+		prefix := strings.TrimSuffix(filepath.Clean(dir), string(filepath.Separator)) + string(filepath.Separator)
+		if strings.HasPrefix(path, prefix) && len(path) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseNumber(n json.Number, field string, defaultValue int) (int, error) {
+	if n == "" {
+		return defaultValue, nil
+	}
+	v, err := strconv.Atoi(n.String())
+	if err != nil {
+		return 0, fmt.Errorf("field '%s' must be an integer: %w", field, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,225 @@
+package localwebhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	alertingTemplates "github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+)
+
+// Notifier writes notifications to a local file or to a Unix domain socket, so that a local agent can forward them.
+// Every notification is a line with the JSON payload of the webhook contact point.
+type Notifier struct {
+	*receivers.Base
+	log      logging.Logger
+	images   images.ImageStore
+	tmpl     *template.Template
+	orgID    int64
+	settings Config
+}
+
+// New creates a notifier. It returns an error if the path of the settings is not in one of the allowed directories.
+func New(factoryConfig receivers.FactoryConfig, allowedDirectories []string) (*Notifier, error) {
+	settings, err := ValidateConfig(factoryConfig)
+	if err != nil {
+		return nil, err
+	}
+	if !IsPathAllowed(settings.Path, allowedDirectories) {
+		return nil, fmt.Errorf("path '%s' is not in one of the directories allowed for local webhooks", settings.Path)
+	}
+	return &Notifier{
+		Base:     receivers.NewBase(factoryConfig.Config),
+		orgID:    factoryConfig.Config.OrgID,
+		log:      factoryConfig.Logger,
+		images:   factoryConfig.ImageStore,
+		tmpl:     factoryConfig.Template,
+		settings: settings,
+	}, nil
+}
+
+// Factory returns a factory of notifiers that can write only to the allowed directories.
+func Factory(allowedDirectories []string) func(receivers.FactoryConfig) (alertingNotify.NotificationChannel, error) {
+	return func(fc receivers.FactoryConfig) (alertingNotify.NotificationChannel, error) {
+		n, err := New(fc, allowedDirectories)
+		if err != nil {
+			return nil, alertingNotify.ReceiverInitError{
+				Reason: err.Error(),
+				Cfg:    *fc.Config,
+			}
+		}
+		return n, nil
+	}
+}
+
+// message is the payload of the webhook contact point.
+type message struct {
+	*alertingTemplates.ExtendedData
+
+	// The protocol version.
+	Version         string `json:"version"`
+	GroupKey        string `json:"groupKey"`
+	TruncatedAlerts int    `json:"truncatedAlerts"`
+	OrgID           int64  `json:"orgId"`
+	Title           string `json:"title"`
+	State           string `json:"state"`
+	Message         string `json:"message"`
+}
+
+// Notify implements the Notifier interface. Failed writes are retried.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	as, numTruncated := truncateAlerts(n.settings.MaxAlerts, as)
+	var tmplErr error
+	tmpl, data := alertingTemplates.TmplText(ctx, n.tmpl, as, n.log, &tmplErr)
+
+	// Augment our Alert data with ImageURLs if available.
+	_ = images.WithStoredImages(ctx, n.log, n.images,
+		func(index int, image images.Image) error {
+			if len(image.URL) != 0 {
+				data.Alerts[index].ImageURL = image.URL
+			}
+			return nil
+		},
+		as...)
+
+	msg := &message{
+		Version:         "1",
+		ExtendedData:    data,
+		GroupKey:        groupKey.String(),
+		TruncatedAlerts: numTruncated,
+		OrgID:           n.orgID,
+		Title:           tmpl(n.settings.Title),
+		Message:         tmpl(n.settings.Message),
+	}
+	if types.Alerts(as...).Status() == model.AlertFiring {
+		msg.State = string(receivers.AlertStateAlerting)
+	} else {
+		msg.State = string(receivers.AlertStateOK)
+	}
+
+	if tmplErr != nil {
+		n.log.Warn("failed to template local webhook message", "error", tmplErr.Error())
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, err
+	}
+	body = append(body, '\n')
+
+	if n.settings.Destination == DestinationSocket {
+		err = writeToSocket(ctx, n.settings.Path, body, n.settings.SocketTimeout)
+	} else {
+		err = appendToFile(n.settings.Path, body, n.settings.MaxFileSize, n.settings.MaxBackups)
+	}
+	if err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+func (n *Notifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
+
+func truncateAlerts(maxAlerts int, alerts []*types.Alert) ([]*types.Alert, int) {
+	if maxAlerts > 0 && len(alerts) > maxAlerts {
+		return alerts[:maxAlerts], len(alerts) - maxAlerts
+	}
+
+	return alerts, 0
+}
+
+// fileLocks serializes the writes to a file, because several contact points can write to the same file.
+var fileLocks sync.Map
+
+// appendToFile appends the line to the file. If the file would exceed maxSize, it is rotated first: the file is
+// renamed to path.1, path.1 to path.2 and so on, and the files above maxBackups are removed.
+func appendToFile(path string, line []byte, maxSize int64, maxBackups int) error {
+	mtx, _ := fileLocks.LoadOrStore(path, &sync.Mutex{})
+	mtx.(*sync.Mutex).Lock()
+	defer mtx.(*sync.Mutex).Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil && info.Size() > 0 && info.Size()+int64(len(line)) > maxSize {
+		if err := rotate(path, maxBackups); err != nil {
+			return fmt.Errorf("failed to rotate file: %w", err)
+		}
+	}
+
+	// The path is checked against the allowed directories when the notifier is created.
+	// nolint:gosec
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func rotate(path string, maxBackups int) error {
+	if maxBackups == 0 {
+		return os.Truncate(path, 0)
+	}
+	if err := os.Remove(backupPath(path, maxBackups)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for i := maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(backupPath(path, i), backupPath(path, i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(path, backupPath(path, 1))
+}
+
+func backupPath(path string, i int) string {
+	return path + "." + strconv.Itoa(i)
+}
+
+func writeToSocket(ctx context.Context, path string, line []byte, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetWriteDeadline(deadline); err != nil {
+			_ = conn.Close()
+			return err
+		}
+	}
+	if _, err := conn.Write(line); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,238 @@
+package localwebhook
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/receivers/webhook"
+	"github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		settings string
+		expErr   string
+		assert   func(t *testing.T, cfg Config)
+	}{
+		{
+			name:     "defaults",
+			settings: `{"path": "/var/spool/grafana/notifications.log"}`,
+			assert: func(t *testing.T, cfg Config) {
+				require.Equal(t, DestinationFile, cfg.Destination)
+				require.Equal(t, int64(defaultMaxFileSizeMB*1024*1024), cfg.MaxFileSize)
+				require.Equal(t, defaultMaxBackups, cfg.MaxBackups)
+				require.Equal(t, defaultSocketTimeout, cfg.SocketTimeout)
+				require.Equal(t, templates.DefaultMessageEmbed, cfg.Message)
+			},
+		},
+		{
+			name:     "socket",
+			settings: `{"destination": "socket", "path": "/run/agent.sock", "socketTimeout": "1s", "maxAlerts": "10"}`,
+			assert: func(t *testing.T, cfg Config) {
+				require.Equal(t, DestinationSocket, cfg.Destination)
+				require.Equal(t, "/run/agent.sock", cfg.Path)
+				require.Equal(t, 10, cfg.MaxAlerts)
+			},
+		},
+		{
+			name:     "missing path",
+			settings: `{}`,
+			expErr:   "required field 'path' is not specified",
+		},
+		{
+			name:     "relative path",
+			settings: `{"path": "notifications.log"}`,
+			expErr:   "must be absolute",
+		},
+		{
+			name:     "invalid destination",
+			settings: `{"path": "/tmp/notifications.log", "destination": "http"}`,
+			expErr:   "invalid destination",
+		},
+		{
+			name:     "zero max file size",
+			settings: `{"path": "/tmp/notifications.log", "maxFileSizeMB": "0"}`,
+			expErr:   "field 'maxFileSizeMB' must be positive",
+		},
+		{
+			name:     "invalid socket timeout",
+			settings: `{"path": "/tmp/agent.sock", "destination": "socket", "socketTimeout": "soon"}`,
+			expErr:   "invalid socketTimeout",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := ValidateConfig(factoryConfig(t, tc.settings))
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			tc.assert(t, cfg)
+		})
+	}
+}
+
+func TestIsPathAllowed(t *testing.T) {
+	allowed := []string{"/var/spool/grafana/", "/run"}
+	require.True(t, IsPathAllowed("/var/spool/grafana/notifications.log", allowed))
+	require.True(t, IsPathAllowed("/run/agent.sock", allowed))
+	require.False(t, IsPathAllowed("/var/spool/grafana", allowed))
+	require.False(t, IsPathAllowed("/var/spool/grafana-other/notifications.log", allowed))
+	require.False(t, IsPathAllowed("/etc/passwd", allowed))
+	require.False(t, IsPathAllowed("/etc/passwd", nil))
+}
+
+func TestNotifier(t *testing.T) {
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		},
+	}
+
+	t.Run("should not create notifier if path is not allowed", func(t *testing.T) {
+		_, err := New(factoryConfig(t, `{"path": "/etc/notifications.log"}`), []string{t.TempDir()})
+		require.ErrorContains(t, err, "is not in one of the directories allowed")
+	})
+
+	t.Run("should append the payload of the webhook to the file", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "notifications.log")
+		n, err := New(factoryConfig(t, `{"path": "`+path+`", "message": "{{ len .Alerts }} alerts"}`), []string{dir})
+		require.NoError(t, err)
+
+		ok, err := n.Notify(notifyContext(), alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+		ok, err = n.Notify(notifyContext(), alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		lines := readLines(t, path)
+		require.Len(t, lines, 2)
+		require.JSONEq(t, webhookPayload(t, `{"url": "http://localhost", "message": "{{ len .Alerts }} alerts"}`, alerts), lines[0])
+
+		var msg map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &msg))
+		require.Equal(t, "1 alerts", msg["message"])
+	})
+
+	t.Run("should rotate the file", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "notifications.log")
+		line := []byte(strings.Repeat("a", 10) + "\n")
+		for i := 0; i < 4; i++ {
+			require.NoError(t, appendToFile(path, line, 15, 2))
+		}
+
+		require.Len(t, readLines(t, path), 1)
+		require.Len(t, readLines(t, path+".1"), 1)
+		require.Len(t, readLines(t, path+".2"), 1)
+		require.NoFileExists(t, path+".3")
+	})
+
+	t.Run("should write the payload of the webhook to the socket", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "localwebhook")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		path := filepath.Join(dir, "agent.sock")
+		listener, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = listener.Close() })
+		received := make(chan string, 1)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer func() { _ = conn.Close() }()
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			received <- line
+		}()
+
+		n, err := New(factoryConfig(t, `{"destination": "socket", "path": "`+path+`"}`), []string{dir})
+		require.NoError(t, err)
+
+		ok, err := n.Notify(notifyContext(), alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.JSONEq(t, webhookPayload(t, `{"url": "http://localhost"}`, alerts), <-received)
+	})
+
+	t.Run("should retry if socket is not available", func(t *testing.T) {
+		dir := t.TempDir()
+		n, err := New(factoryConfig(t, `{"destination": "socket", "path": "`+filepath.Join(dir, "missing.sock")+`"}`), []string{dir})
+		require.NoError(t, err)
+
+		ok, err := n.Notify(notifyContext(), alerts...)
+		require.Error(t, err)
+		require.True(t, ok)
+	})
+}
+
+// webhookPayload returns the payload the webhook contact point sends for the alerts.
+func webhookPayload(t *testing.T, settings string, alerts []*types.Alert) string {
+	t.Helper()
+	sender := receivers.MockNotificationService()
+	fc := factoryConfig(t, settings)
+	fc.NotificationService = sender
+	n, err := webhook.New(fc)
+	require.NoError(t, err)
+	_, err = n.Notify(notifyContext(), alerts...)
+	require.NoError(t, err)
+	return sender.Webhook.Body
+}
+
+func factoryConfig(t *testing.T, settings string) receivers.FactoryConfig {
+	t.Helper()
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+	return receivers.FactoryConfig{
+		Config: &receivers.NotificationChannelConfig{
+			Name:     "local",
+			Type:     Type,
+			OrgID:    1,
+			Settings: json.RawMessage(settings),
+		},
+		DecryptFunc: func(ctx context.Context, sjd map[string][]byte, key string, fallback string) string {
+			return fallback
+		},
+		ImageStore: &images.UnavailableImageStore{},
+		Template:   tmpl,
+		Logger:     &logging.FakeLogger{},
+	}
+}
+
+func notifyContext() context.Context {
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	return notify.WithReceiverName(ctx, "my_receiver")
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
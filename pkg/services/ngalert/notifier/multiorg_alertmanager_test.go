@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/setting"
@@ -297,6 +298,86 @@ func TestMultiOrgAlertmanager_AlertmanagerFor(t *testing.T) {
 	}
 }
 
+func TestMultiOrgAlertmanager_ConfigurationHistory(t *testing.T) {
+	configStore := NewFakeConfigStore(t, map[int64]*models.AlertConfiguration{})
+	orgStore := &FakeOrgStore{
+		orgs: []int64{1},
+	}
+	tmpDir := t.TempDir()
+	cfg := &setting.Cfg{
+		DataPath:        tmpDir,
+		UnifiedAlerting: setting.UnifiedAlertingSettings{AlertmanagerConfigPollInterval: 3 * time.Minute, DefaultConfiguration: setting.GetAlertmanagerDefaultConfiguration()}, // do not poll in tests.
+	}
+	kvStore := NewFakeKVStore(t)
+	provStore := provisioning.NewFakeProvisioningStore()
+	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+	decryptFn := secretsService.GetDecryptedValue
+	reg := prometheus.NewPedanticRegistry()
+	m := metrics.NewNGAlert(reg)
+	mam, err := NewMultiOrgAlertmanager(cfg, configStore, orgStore, kvStore, provStore, decryptFn, m.GetMultiOrgAlertmanagerMetrics(), nil, log.New("testlogger"), secretsService)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// The default configuration is saved when the Alertmanager of the org is created.
+	require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(ctx))
+
+	newConfig, err := Load([]byte(cfg.UnifiedAlerting.DefaultConfiguration))
+	require.NoError(t, err)
+	newConfig.TemplateFiles = map[string]string{"a": `{{ define "a" }}a{{ end }}`}
+	require.NoError(t, mam.ApplyAlertmanagerConfiguration(ctx, 1, *newConfig, "admin"))
+
+	// The history contains the configurations newest first.
+	{
+		history, err := mam.GetAlertmanagerConfigurationHistory(ctx, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, int64(2), history[0].ID)
+		require.Equal(t, "admin", history[0].CreatedBy)
+		require.Equal(t, map[string]string{"a": `{{ define "a" }}a{{ end }}`}, history[0].TemplateFiles)
+		require.NotNil(t, history[0].LastApplied)
+		require.Equal(t, int64(1), history[1].ID)
+		require.Empty(t, history[1].CreatedBy)
+
+		history, err = mam.GetAlertmanagerConfigurationHistory(ctx, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+	}
+
+	// The diff shows the changes between the configurations.
+	{
+		diff, err := mam.DiffAlertmanagerConfigurations(ctx, 1, 1, 2)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), diff.From)
+		require.Equal(t, int64(2), diff.To)
+		require.Contains(t, diff.Diff, "+++ 2")
+		require.Contains(t, diff.Diff, `+    a: '{{ define "a" }}a{{ end }}'`)
+
+		_, err = mam.DiffAlertmanagerConfigurations(ctx, 1, 1, 10)
+		require.ErrorIs(t, err, store.ErrHistoricalConfigurationNotFound)
+	}
+
+	// Activating a previous configuration saves it as the latest configuration and records it in the history.
+	{
+		require.NoError(t, mam.ActivateHistoricalConfiguration(ctx, 1, 1, "editor"))
+
+		current, err := mam.GetAlertmanagerConfiguration(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, current.TemplateFiles)
+
+		history, err := mam.GetAlertmanagerConfigurationHistory(ctx, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+		require.Equal(t, "editor", history[0].CreatedBy)
+
+		diff, err := mam.DiffAlertmanagerConfigurations(ctx, 1, 1, 3)
+		require.NoError(t, err)
+		require.Empty(t, diff.Diff)
+
+		err = mam.ActivateHistoricalConfiguration(ctx, 1, 10, "editor")
+		require.ErrorIs(t, err, store.ErrHistoricalConfigurationNotFound)
+	}
+}
+
 var brokenConfig = `
 	"alertmanager_config": {
 		"route": {
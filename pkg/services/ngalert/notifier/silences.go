@@ -1,6 +1,8 @@
 package notifier
 
 import (
+	"errors"
+
 	alertingNotify "github.com/grafana/alerting/notify"
 )
 
@@ -19,3 +21,65 @@ func (am *Alertmanager) CreateSilence(ps *alertingNotify.PostableSilence) (strin
 func (am *Alertmanager) DeleteSilence(silenceID string) error {
 	return am.Base.DeleteSilence(silenceID)
 }
+
+// GetSilencedAlerts returns the active alerts that are suppressed by the silence. It returns ErrSilenceNotFound if
+// the silence is not present.
+func (am *Alertmanager) GetSilencedAlerts(silenceID string) (alertingNotify.GettableAlerts, error) {
+	if _, err := am.Base.GetSilence(silenceID); err != nil {
+		return nil, err
+	}
+	alerts, err := am.Base.GetAlerts(true, true, true, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	result := alertingNotify.GettableAlerts{}
+	for _, alert := range alerts {
+		if alert.Status == nil {
+			continue
+		}
+		for _, id := range alert.Status.SilencedBy {
+			if id == silenceID {
+				result = append(result, alert)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// GetSilence returns the silence of the Alertmanager of the organization.
+func (moa *MultiOrgAlertmanager) GetSilence(orgID int64, silenceID string) (alertingNotify.GettableSilence, error) {
+	am, err := moa.silencesAlertmanagerFor(orgID)
+	if err != nil {
+		return alertingNotify.GettableSilence{}, err
+	}
+	return am.GetSilence(silenceID)
+}
+
+// CreateSilence creates or updates the silence in the Alertmanager of the organization, and returns the ID of the silence.
+func (moa *MultiOrgAlertmanager) CreateSilence(orgID int64, ps *alertingNotify.PostableSilence) (string, error) {
+	am, err := moa.silencesAlertmanagerFor(orgID)
+	if err != nil {
+		return "", err
+	}
+	return am.CreateSilence(ps)
+}
+
+// DeleteSilence expires the silence in the Alertmanager of the organization.
+func (moa *MultiOrgAlertmanager) DeleteSilence(orgID int64, silenceID string) error {
+	am, err := moa.silencesAlertmanagerFor(orgID)
+	if err != nil {
+		return err
+	}
+	return am.DeleteSilence(silenceID)
+}
+
+// silencesAlertmanagerFor returns the Alertmanager of the organization. Silences do not depend on the configuration,
+// so an Alertmanager that is not ready because its configuration is invalid is returned as well.
+func (moa *MultiOrgAlertmanager) silencesAlertmanagerFor(orgID int64) (*Alertmanager, error) {
+	am, err := moa.AlertmanagerFor(orgID)
+	if err != nil && !errors.Is(err, ErrAlertmanagerNotReady) {
+		return nil, err
+	}
+	return am, nil
+}
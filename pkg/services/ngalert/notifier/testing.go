@@ -19,6 +19,12 @@ type fakeConfigStore struct {
 
 	// appliedConfigs stores configs by orgID and config hash.
 	appliedConfigs map[int64]map[string]*models.AlertConfiguration
+
+	// history stores the saved configs by orgID, ordered oldest -> newest.
+	history map[int64][]*models.HistoricAlertConfiguration
+
+	// notificationSettings stores the notification settings of the alert rules by orgID.
+	notificationSettings map[int64]map[models.AlertRuleKey]models.NotificationSettings
 }
 
 // Saves the image or returns an error.
@@ -34,12 +40,17 @@ func (f *fakeConfigStore) GetImages(ctx context.Context, tokens []string) ([]mod
 	return nil, nil, models.ErrImageNotFound
 }
 
+func (f *fakeConfigStore) ListNotificationSettings(_ context.Context, orgID int64) (map[models.AlertRuleKey]models.NotificationSettings, error) {
+	return f.notificationSettings[orgID], nil
+}
+
 func NewFakeConfigStore(t *testing.T, configs map[int64]*models.AlertConfiguration) *fakeConfigStore {
 	t.Helper()
 
 	return &fakeConfigStore{
 		configs:        configs,
 		appliedConfigs: make(map[int64]map[string]*models.AlertConfiguration),
+		history:        make(map[int64][]*models.HistoricAlertConfiguration),
 	}
 }
 
@@ -79,6 +90,12 @@ func (f *fakeConfigStore) SaveAlertmanagerConfigurationWithCallback(_ context.Co
 		return err
 	}
 
+	historic := models.HistoricConfigFromAlertConfig(cfg)
+	historic.ID = int64(len(f.history[cmd.OrgID]) + 1)
+	historic.LastApplied = cmd.LastApplied
+	historic.CreatedBy = cmd.CreatedBy
+	f.history[cmd.OrgID] = append(f.history[cmd.OrgID], &historic)
+
 	if cmd.LastApplied != 0 {
 		if _, ok := f.appliedConfigs[cmd.OrgID]; !ok {
 			f.appliedConfigs[cmd.OrgID] = make(map[string]*models.AlertConfiguration)
@@ -117,6 +134,28 @@ func (f *fakeConfigStore) MarkConfigurationAsApplied(_ context.Context, cmd *mod
 	return errors.New("config not found")
 }
 
+func (f *fakeConfigStore) GetAlertmanagerConfigurationHistory(_ context.Context, query *models.GetAlertmanagerConfigurationHistoryQuery) error {
+	history := f.history[query.OrgID]
+	query.Result = make([]*models.HistoricAlertConfiguration, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		if query.Limit > 0 && len(query.Result) == query.Limit {
+			break
+		}
+		query.Result = append(query.Result, history[i])
+	}
+	return nil
+}
+
+func (f *fakeConfigStore) GetHistoricalAlertmanagerConfiguration(_ context.Context, query *models.GetHistoricalAlertmanagerConfigurationQuery) error {
+	for _, cfg := range f.history[query.OrgID] {
+		if cfg.ID == query.ID {
+			query.Result = cfg
+			return nil
+		}
+	}
+	return store.ErrHistoricalConfigurationNotFound
+}
+
 type FakeOrgStore struct {
 	orgs []int64
 }
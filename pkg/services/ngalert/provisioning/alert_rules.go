@@ -21,6 +21,7 @@ type AlertRuleService struct {
 	baseIntervalSeconds    int64
 	ruleStore              RuleStore
 	provenanceStore        ProvisioningStore
+	amConfigStore          AMConfigReader
 	dashboardService       dashboards.DashboardService
 	quotas                 QuotaChecker
 	xact                   TransactionManager
@@ -29,6 +30,7 @@ type AlertRuleService struct {
 
 func NewAlertRuleService(ruleStore RuleStore,
 	provenanceStore ProvisioningStore,
+	amConfigStore AMConfigReader,
 	dashboardService dashboards.DashboardService,
 	quotas QuotaChecker,
 	xact TransactionManager,
@@ -40,6 +42,7 @@ func NewAlertRuleService(ruleStore RuleStore,
 		baseIntervalSeconds:    baseIntervalSeconds,
 		ruleStore:              ruleStore,
 		provenanceStore:        provenanceStore,
+		amConfigStore:          amConfigStore,
 		dashboardService:       dashboardService,
 		quotas:                 quotas,
 		xact:                   xact,
@@ -108,26 +111,33 @@ func (service *AlertRuleService) GetAlertRuleWithFolderTitle(ctx context.Context
 }
 
 // CreateAlertRule creates a new alert rule. This function will ignore any
-// interval that is set in the rule struct and use the already existing group
-// interval or the default one.
+// interval and group evaluation timeout that are set in the rule struct and
+// use the ones of the already existing group or the default interval.
 func (service *AlertRuleService) CreateAlertRule(ctx context.Context, rule models.AlertRule, provenance models.Provenance, userID int64) (models.AlertRule, error) {
 	if rule.UID == "" {
 		rule.UID = util.GenerateShortUID()
 	}
-	interval, err := service.ruleStore.GetRuleGroupInterval(ctx, rule.OrgID, rule.NamespaceUID, rule.RuleGroup)
+	group, err := service.GetRuleGroup(ctx, rule.OrgID, rule.NamespaceUID, rule.RuleGroup)
 	// if the alert group does not exists we just use the default interval
 	if err != nil && errors.Is(err, store.ErrAlertRuleGroupNotFound) {
-		interval = service.defaultIntervalSeconds
+		group.Interval = service.defaultIntervalSeconds
 	} else if err != nil {
 		return models.AlertRule{}, err
 	}
-	rule.IntervalSeconds = interval
+	rule.IntervalSeconds = group.Interval
+	rule.GroupEvaluationTimeout = group.EvaluationTimeout
 	err = rule.SetDashboardAndPanelFromAnnotations()
 	if err != nil {
 		return models.AlertRule{}, err
 	}
 	rule.Updated = time.Now()
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := ValidateNotificationSettings(ctx, service.amConfigStore, rule.OrgID, []*models.AlertRule{&rule}); err != nil {
+			return err
+		}
+		if err := service.checkRuleDependencies(ctx, rule.OrgID, []*models.AlertRule{&rule}, nil); err != nil {
+			return err
+		}
 		ids, err := service.ruleStore.InsertAlertRules(ctx, []models.AlertRule{
 			rule,
 		})
@@ -165,10 +175,11 @@ func (service *AlertRuleService) GetRuleGroup(ctx context.Context, orgID int64,
 		return models.AlertRuleGroup{}, store.ErrAlertRuleGroupNotFound
 	}
 	res := models.AlertRuleGroup{
-		Title:     q.Result[0].RuleGroup,
-		FolderUID: q.Result[0].NamespaceUID,
-		Interval:  q.Result[0].IntervalSeconds,
-		Rules:     []models.AlertRule{},
+		Title:             q.Result[0].RuleGroup,
+		FolderUID:         q.Result[0].NamespaceUID,
+		Interval:          q.Result[0].IntervalSeconds,
+		EvaluationTimeout: q.Result[0].GroupEvaluationTimeout,
+		Rules:             []models.AlertRule{},
 	}
 	for _, r := range q.Result {
 		if r != nil {
@@ -178,8 +189,8 @@ func (service *AlertRuleService) GetRuleGroup(ctx context.Context, orgID int64,
 	return res, nil
 }
 
-// UpdateRuleGroup will update the interval for all rules in the group.
-func (service *AlertRuleService) UpdateRuleGroup(ctx context.Context, orgID int64, namespaceUID string, ruleGroup string, intervalSeconds int64) error {
+// UpdateRuleGroup will update the interval and the evaluation timeout of the group for all rules in the group.
+func (service *AlertRuleService) UpdateRuleGroup(ctx context.Context, orgID int64, namespaceUID string, ruleGroup string, intervalSeconds int64, evaluationTimeout time.Duration) error {
 	if err := models.ValidateRuleGroupInterval(intervalSeconds, service.baseIntervalSeconds); err != nil {
 		return err
 	}
@@ -195,11 +206,15 @@ func (service *AlertRuleService) UpdateRuleGroup(ctx context.Context, orgID int6
 		}
 		updateRules := make([]models.UpdateRule, 0, len(query.Result))
 		for _, rule := range query.Result {
-			if rule.IntervalSeconds == intervalSeconds {
+			if rule.IntervalSeconds == intervalSeconds && rule.GroupEvaluationTimeout == evaluationTimeout {
 				continue
 			}
 			newRule := *rule
 			newRule.IntervalSeconds = intervalSeconds
+			newRule.GroupEvaluationTimeout = evaluationTimeout
+			if err := newRule.ValidateEvaluationTimeouts(); err != nil {
+				return err
+			}
 			updateRules = append(updateRules, models.UpdateRule{
 				Existing: rule,
 				New:      newRule,
@@ -259,6 +274,18 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, orgID int
 	}
 
 	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		changed := make([]*models.AlertRule, 0, len(delta.New)+len(delta.Update))
+		changed = append(changed, delta.New...)
+		for _, update := range delta.Update {
+			changed = append(changed, update.New)
+		}
+		if err := ValidateNotificationSettings(ctx, service.amConfigStore, orgID, changed); err != nil {
+			return err
+		}
+		if err := service.checkRuleDependencies(ctx, orgID, changed, delta.Delete); err != nil {
+			return err
+		}
+
 		uids, err := service.ruleStore.InsertAlertRules(ctx, withoutNilAlertRules(delta.New))
 		if err != nil {
 			return fmt.Errorf("failed to insert alert rules: %w", err)
@@ -329,11 +356,18 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, rule model
 	rule.Updated = time.Now()
 	rule.ID = storedRule.ID
 	rule.IntervalSeconds = storedRule.IntervalSeconds
+	rule.GroupEvaluationTimeout = storedRule.GroupEvaluationTimeout
 	err = rule.SetDashboardAndPanelFromAnnotations()
 	if err != nil {
 		return models.AlertRule{}, err
 	}
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := ValidateNotificationSettings(ctx, service.amConfigStore, rule.OrgID, []*models.AlertRule{&rule}); err != nil {
+			return err
+		}
+		if err := service.checkRuleDependencies(ctx, rule.OrgID, []*models.AlertRule{&rule}, nil); err != nil {
+			return err
+		}
 		err := service.ruleStore.UpdateAlertRules(ctx, []models.UpdateRule{
 			{
 				Existing: &storedRule,
@@ -365,6 +399,9 @@ func (service *AlertRuleService) DeleteAlertRule(ctx context.Context, orgID int6
 		return fmt.Errorf("cannot delete with provided provenance '%s', needs '%s'", provenance, storedProvenance)
 	}
 	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := service.checkRuleDependencies(ctx, orgID, nil, []*models.AlertRule{rule}); err != nil {
+			return err
+		}
 		return service.deleteRules(ctx, orgID, rule)
 	})
 }
@@ -384,6 +421,40 @@ func (service *AlertRuleService) checkLimitsTransactionCtx(ctx context.Context,
 	return nil
 }
 
+// checkRuleDependencies checks that the rules the changed rules and the remaining rules depend on exist in the
+// organization after the rules are changed and deleted, and that the dependencies do not form a cycle.
+func (service *AlertRuleService) checkRuleDependencies(ctx context.Context, orgID int64, changed []*models.AlertRule, deleted []*models.AlertRule) error {
+	// the deleted rules can be dependencies of other rules
+	hasDependencies := len(deleted) > 0
+	for _, rule := range changed {
+		hasDependencies = hasDependencies || len(rule.DependsOn) > 0
+	}
+	if !hasDependencies {
+		return nil
+	}
+
+	q := models.ListAlertRulesQuery{OrgID: orgID}
+	if err := service.ruleStore.ListAlertRules(ctx, &q); err != nil {
+		return fmt.Errorf("failed to fetch alert rules of the organization: %w", err)
+	}
+	orgRules := make(map[string]*models.AlertRule, len(q.Result)+len(changed))
+	for _, rule := range q.Result {
+		orgRules[rule.UID] = rule
+	}
+	for _, rule := range deleted {
+		delete(orgRules, rule.UID)
+	}
+	for _, rule := range changed {
+		if rule.UID != "" {
+			orgRules[rule.UID] = rule
+		}
+	}
+	toValidate := make([]*models.AlertRule, 0, len(changed))
+	toValidate = append(toValidate, changed...)
+	toValidate = append(toValidate, models.DependentRules(orgRules, deleted)...)
+	return models.ValidateRuleDependencies(toValidate, orgRules)
+}
+
 // deleteRules deletes a set of target rules and associated data, while checking for database consistency.
 func (service *AlertRuleService) deleteRules(ctx context.Context, orgID int64, targets ...*models.AlertRule) error {
 	uids := make([]string, 0, len(targets))
@@ -429,10 +500,11 @@ func (service *AlertRuleService) GetAlertRuleGroupWithFolderTitle(ctx context.Co
 
 	res := file.AlertRuleGroupWithFolderTitle{
 		AlertRuleGroup: &models.AlertRuleGroup{
-			Title:     q.Result[0].RuleGroup,
-			FolderUID: q.Result[0].NamespaceUID,
-			Interval:  q.Result[0].IntervalSeconds,
-			Rules:     []models.AlertRule{},
+			Title:             q.Result[0].RuleGroup,
+			FolderUID:         q.Result[0].NamespaceUID,
+			Interval:          q.Result[0].IntervalSeconds,
+			EvaluationTimeout: q.Result[0].GroupEvaluationTimeout,
+			Rules:             []models.AlertRule{},
 		},
 		OrgID:       orgID,
 		FolderTitle: dash.Title,
@@ -491,10 +563,11 @@ func (service *AlertRuleService) GetAlertGroupsWithFolderTitle(ctx context.Conte
 		}
 		result = append(result, file.AlertRuleGroupWithFolderTitle{
 			AlertRuleGroup: &models.AlertRuleGroup{
-				Title:     rules[0].RuleGroup,
-				FolderUID: rules[0].NamespaceUID,
-				Interval:  rules[0].IntervalSeconds,
-				Rules:     rules,
+				Title:             rules[0].RuleGroup,
+				FolderUID:         rules[0].NamespaceUID,
+				Interval:          rules[0].IntervalSeconds,
+				EvaluationTimeout: rules[0].GroupEvaluationTimeout,
+				Rules:             rules,
 			},
 			OrgID:       orgID,
 			FolderTitle: title,
@@ -516,6 +589,7 @@ func (service *AlertRuleService) GetAlertGroupsWithFolderTitle(ctx context.Conte
 func syncGroupRuleFields(group *models.AlertRuleGroup, orgID int64) *models.AlertRuleGroup {
 	for i := range group.Rules {
 		group.Rules[i].IntervalSeconds = group.Interval
+		group.Rules[i].GroupEvaluationTimeout = group.EvaluationTimeout
 		group.Rules[i].RuleGroup = group.Title
 		group.Rules[i].NamespaceUID = group.FolderUID
 		group.Rules[i].OrgID = orgID
@@ -62,7 +62,7 @@ func TestAlertRuleService(t *testing.T) {
 		require.Equal(t, int64(60), rule.IntervalSeconds)
 
 		var interval int64 = 120
-		err = ruleService.UpdateRuleGroup(context.Background(), orgID, rule.NamespaceUID, rule.RuleGroup, 120)
+		err = ruleService.UpdateRuleGroup(context.Background(), orgID, rule.NamespaceUID, rule.RuleGroup, 120, 0)
 		require.NoError(t, err)
 
 		rule, _, err = ruleService.GetAlertRule(context.Background(), orgID, rule.UID)
@@ -107,7 +107,7 @@ func TestAlertRuleService(t *testing.T) {
 		require.NoError(t, err)
 
 		var interval int64 = 120
-		err = ruleService.UpdateRuleGroup(context.Background(), orgID, rule.NamespaceUID, rule.RuleGroup, 120)
+		err = ruleService.UpdateRuleGroup(context.Background(), orgID, rule.NamespaceUID, rule.RuleGroup, 120, 0)
 		require.NoError(t, err)
 
 		rule = dummyRule("test#4-1", orgID)
@@ -137,7 +137,7 @@ func TestAlertRuleService(t *testing.T) {
 		require.Equal(t, int64(1), rule.Version)
 		require.Equal(t, int64(60), rule.IntervalSeconds)
 
-		err = ruleService.UpdateRuleGroup(context.Background(), orgID, namespaceUID, ruleGroup, newInterval)
+		err = ruleService.UpdateRuleGroup(context.Background(), orgID, namespaceUID, ruleGroup, newInterval, 0)
 		require.NoError(t, err)
 
 		rule, _, err = ruleService.GetAlertRule(context.Background(), orgID, ruleUID)
@@ -310,6 +310,98 @@ func TestAlertRuleService(t *testing.T) {
 		}
 	})
 
+	t.Run("alert rule creation and update should reject notification settings with unknown contact point", func(t *testing.T) {
+		var orgID int64 = 2
+		err := ruleService.amConfigStore.(*store.DBstore).SaveAlertmanagerConfiguration(context.Background(), &models.SaveAlertmanagerConfigurationCmd{
+			AlertmanagerConfiguration: `{"alertmanager_config": {"route": {"receiver": "default"}, "receivers": [{"name": "default"}]}}`,
+			OrgID:                     orgID,
+		})
+		require.NoError(t, err)
+
+		rule := dummyRule("test#notification-settings", orgID)
+		rule.NotificationSettings = &models.NotificationSettings{Receiver: "unknown"}
+		_, err = ruleService.CreateAlertRule(context.Background(), rule, models.ProvenanceAPI, 0)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		rule.NotificationSettings = &models.NotificationSettings{Receiver: "default"}
+		rule, err = ruleService.CreateAlertRule(context.Background(), rule, models.ProvenanceAPI, 0)
+		require.NoError(t, err)
+
+		rule.NotificationSettings = &models.NotificationSettings{Receiver: "unknown"}
+		_, err = ruleService.UpdateAlertRule(context.Background(), rule, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		group := createDummyGroup("notification-settings", orgID)
+		group.Rules[0].NotificationSettings = &models.NotificationSettings{Receiver: "unknown"}
+		err = ruleService.ReplaceRuleGroup(context.Background(), orgID, group, 0, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("alert rule creation should reject dependencies on rules that do not exist", func(t *testing.T) {
+		rule := dummyRule("test#dependency-missing", 1)
+		rule.DependsOn = []string{"missing"}
+		_, err := ruleService.CreateAlertRule(context.Background(), rule, models.ProvenanceAPI, 0)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("alert rule update should reject dependencies that form a cycle", func(t *testing.T) {
+		upstream, err := ruleService.CreateAlertRule(context.Background(), dummyRule("test#dependency-upstream", 1), models.ProvenanceAPI, 0)
+		require.NoError(t, err)
+		dependent := dummyRule("test#dependency-dependent", 1)
+		dependent.DependsOn = []string{upstream.UID}
+		dependent, err = ruleService.CreateAlertRule(context.Background(), dependent, models.ProvenanceAPI, 0)
+		require.NoError(t, err)
+
+		upstream.DependsOn = []string{dependent.UID}
+		_, err = ruleService.UpdateAlertRule(context.Background(), upstream, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("alert rule deletion should reject deleting a rule other rules depend on", func(t *testing.T) {
+		upstream, err := ruleService.CreateAlertRule(context.Background(), dummyRule("test#dependency-deleted-upstream", 1), models.ProvenanceAPI, 0)
+		require.NoError(t, err)
+		dependent := dummyRule("test#dependency-deleted-dependent", 1)
+		dependent.DependsOn = []string{upstream.UID}
+		_, err = ruleService.CreateAlertRule(context.Background(), dependent, models.ProvenanceAPI, 0)
+		require.NoError(t, err)
+
+		err = ruleService.DeleteAlertRule(context.Background(), 1, upstream.UID, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		_, _, err = ruleService.GetAlertRule(context.Background(), 1, upstream.UID)
+		require.NoError(t, err)
+	})
+
+	t.Run("group write should reject removing a rule other rules depend on", func(t *testing.T) {
+		group := createDummyGroup("dependency-removed", 1)
+		err := ruleService.ReplaceRuleGroup(context.Background(), 1, group, 0, models.ProvenanceAPI)
+		require.NoError(t, err)
+		group, err = ruleService.GetRuleGroup(context.Background(), 1, group.FolderUID, group.Title)
+		require.NoError(t, err)
+		dependent := dummyRule("test#dependency-removed-dependent", 1)
+		dependent.DependsOn = []string{group.Rules[0].UID}
+		_, err = ruleService.CreateAlertRule(context.Background(), dependent, models.ProvenanceAPI, 0)
+		require.NoError(t, err)
+
+		group.Rules = []models.AlertRule{}
+		err = ruleService.ReplaceRuleGroup(context.Background(), 1, group, 0, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("group write should reject dependencies that form a cycle", func(t *testing.T) {
+		group := createDummyGroup("dependency-cycle", 1)
+		group.Rules = append(group.Rules, dummyRule("dependency-cycle-rule-2", 1))
+		err := ruleService.ReplaceRuleGroup(context.Background(), 1, group, 0, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		group, err = ruleService.GetRuleGroup(context.Background(), 1, group.FolderUID, group.Title)
+		require.NoError(t, err)
+		require.Len(t, group.Rules, 2)
+		group.Rules[0].DependsOn = []string{group.Rules[1].UID}
+		group.Rules[1].DependsOn = []string{group.Rules[0].UID}
+		err = ruleService.ReplaceRuleGroup(context.Background(), 1, group, 0, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
 	t.Run("quota met causes create to be rejected", func(t *testing.T) {
 		ruleService := createAlertRuleService(t)
 		checker := &MockQuotaChecker{}
@@ -349,6 +441,7 @@ func createAlertRuleService(t *testing.T) AlertRuleService {
 	return AlertRuleService{
 		ruleStore:              store,
 		provenanceStore:        store,
+		amConfigStore:          &store,
 		quotas:                 &quotas,
 		xact:                   sqlStore,
 		log:                    log.New("testing"),
@@ -40,6 +40,8 @@ type ContactPointQuery struct {
 	// Optionally filter by name.
 	Name  string
 	OrgID int64
+	// Optionally decrypt secure settings, by default they are redacted.
+	Decrypt bool
 }
 
 func (ecp *ContactPointService) GetContactPoints(ctx context.Context, q ContactPointQuery) ([]apimodels.EmbeddedContactPoint, error) {
@@ -80,13 +82,20 @@ func (ecp *ContactPointService) GetContactPoints(ctx context.Context, q ContactP
 			if decryptedValue == "" {
 				continue
 			}
-			embeddedContactPoint.Settings.Set(k, apimodels.RedactedValue)
+			if q.Decrypt {
+				embeddedContactPoint.Settings.Set(k, decryptedValue)
+			} else {
+				embeddedContactPoint.Settings.Set(k, apimodels.RedactedValue)
+			}
 		}
 
 		contactPoints = append(contactPoints, embeddedContactPoint)
 	}
 	sort.SliceStable(contactPoints, func(i, j int) bool {
-		return contactPoints[i].Name < contactPoints[j].Name
+		if contactPoints[i].Name != contactPoints[j].Name {
+			return contactPoints[i].Name < contactPoints[j].Name
+		}
+		return contactPoints[i].UID < contactPoints[j].UID
 	})
 	return contactPoints, nil
 }
@@ -61,6 +61,24 @@ func TestContactPointService(t *testing.T) {
 		require.Equal(t, "slack", cps[1].Type)
 	})
 
+	t.Run("service sorts contact points with the same name by uid", func(t *testing.T) {
+		sut := createContactPointServiceSut(secretsService)
+		for _, uid := range []string{"c", "a", "b"} {
+			newCp := createTestContactPoint()
+			newCp.UID = uid
+			_, err := sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+			require.NoError(t, err)
+		}
+
+		for i := 0; i < 5; i++ {
+			cps, err := sut.GetContactPoints(context.Background(), cpsQuery(1))
+			require.NoError(t, err)
+			require.Len(t, cps, 4)
+			require.Equal(t, "email receiver", cps[0].Name)
+			require.Equal(t, []string{"a", "b", "c"}, []string{cps[1].UID, cps[2].UID, cps[3].UID})
+		}
+	})
+
 	t.Run("it's possible to use a custom uid", func(t *testing.T) {
 		customUID := "1337"
 		sut := createContactPointServiceSut(secretsService)
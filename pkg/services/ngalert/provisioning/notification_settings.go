@@ -0,0 +1,58 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// AMConfigReader reads the Alertmanager configurations.
+type AMConfigReader interface {
+	GetLatestAlertmanagerConfiguration(ctx context.Context, query *models.GetLatestAlertmanagerConfigurationQuery) error
+}
+
+// NewNotificationSettingsValidator creates a validator for the receivers and the mute timings of the configuration.
+func NewNotificationSettingsValidator(cfg *definitions.PostableUserConfig) models.NotificationSettingsValidator {
+	receivers := make([]string, 0, len(cfg.AlertmanagerConfig.Receivers))
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		receivers = append(receivers, r.Name)
+	}
+	muteTimes := make([]string, 0, len(cfg.AlertmanagerConfig.MuteTimeIntervals))
+	for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+		muteTimes = append(muteTimes, mt.Name)
+	}
+	return models.NewNotificationSettingsValidator(receivers, muteTimes)
+}
+
+// ValidateNotificationSettings checks that the notification settings of the rules are valid and that their
+// receivers and mute timings exist in the latest Alertmanager configuration of the organization. The configuration
+// is read only if a rule has notification settings.
+func ValidateNotificationSettings(ctx context.Context, store AMConfigReader, orgID int64, rules []*models.AlertRule) error {
+	var validator *models.NotificationSettingsValidator
+	for _, rule := range rules {
+		if rule.NotificationSettings == nil {
+			continue
+		}
+		if err := rule.NotificationSettings.Validate(); err != nil {
+			return err
+		}
+		if validator == nil {
+			query := models.GetLatestAlertmanagerConfigurationQuery{OrgID: orgID}
+			if err := store.GetLatestAlertmanagerConfiguration(ctx, &query); err != nil {
+				return fmt.Errorf("failed to get the Alertmanager configuration: %w", err)
+			}
+			cfg, err := deserializeAlertmanagerConfig([]byte(query.Result.AlertmanagerConfiguration))
+			if err != nil {
+				return err
+			}
+			v := NewNotificationSettingsValidator(cfg)
+			validator = &v
+		}
+		if err := validator.Validate(*rule.NotificationSettings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestValidateNotificationSettings(t *testing.T) {
+	var orgID int64 = 1
+	configs := &MockAMConfigStore{}
+	configs.EXPECT().GetsConfig(models.AlertConfiguration{
+		OrgID: orgID,
+		AlertmanagerConfiguration: `{
+			"alertmanager_config": {
+				"route": {"receiver": "default"},
+				"mute_time_intervals": [{"name": "weekends", "time_intervals": [{"weekdays": ["saturday", "sunday"]}]}],
+				"receivers": [{"name": "default", "grafana_managed_receiver_configs": [{"uid": "default", "name": "default", "type": "email", "settings": {"addresses": "default@grafana.com"}}]}]
+			}
+		}`,
+	})
+	rulesWithSettings := func(settings models.NotificationSettings) []*models.AlertRule {
+		return []*models.AlertRule{models.AlertRuleGen(models.WithNotificationSettings(settings))()}
+	}
+
+	t.Run("accepts existing contact point and mute timings", func(t *testing.T) {
+		rules := rulesWithSettings(models.NotificationSettings{Receiver: "default", MuteTimeIntervals: []string{"weekends"}})
+		require.NoError(t, ValidateNotificationSettings(context.Background(), configs, orgID, rules))
+	})
+
+	t.Run("rejects unknown contact point", func(t *testing.T) {
+		rules := rulesWithSettings(models.NotificationSettings{Receiver: "unknown"})
+		err := ValidateNotificationSettings(context.Background(), configs, orgID, rules)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("rejects unknown mute timing", func(t *testing.T) {
+		rules := rulesWithSettings(models.NotificationSettings{Receiver: "default", MuteTimeIntervals: []string{"unknown"}})
+		err := ValidateNotificationSettings(context.Background(), configs, orgID, rules)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("rejects invalid settings", func(t *testing.T) {
+		rules := rulesWithSettings(models.NotificationSettings{Receiver: "default", GroupBy: []string{"...", "alertname"}})
+		err := ValidateNotificationSettings(context.Background(), configs, orgID, rules)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("does not read configuration if rules have no settings", func(t *testing.T) {
+		rule := models.AlertRuleGen()()
+		rule.NotificationSettings = nil
+		err := ValidateNotificationSettings(context.Background(), nil, orgID, []*models.AlertRule{rule})
+		require.NoError(t, err)
+	})
+}
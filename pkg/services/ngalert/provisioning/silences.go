@@ -0,0 +1,139 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	alertingNotify "github.com/grafana/alerting/notify"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// provisionedSilencesNamespace is the namespace of the key-value store that maps the UIDs of provisioned silences to
+// the IDs of the silences in the Alertmanager, which change when a silence is updated.
+const provisionedSilencesNamespace = "alertmanager.provisioned-silences"
+
+// SilenceStore manages the silences of the Alertmanagers of the organizations.
+type SilenceStore interface {
+	GetSilence(orgID int64, silenceID string) (definitions.GettableSilence, error)
+	CreateSilence(orgID int64, ps *definitions.PostableSilence) (string, error)
+	DeleteSilence(orgID int64, silenceID string) error
+}
+
+type SilenceService struct {
+	silences SilenceStore
+	kv       kvstore.KVStore
+	log      log.Logger
+}
+
+func NewSilenceService(silences SilenceStore, kv kvstore.KVStore, log log.Logger) *SilenceService {
+	return &SilenceService{
+		silences: silences,
+		kv:       kv,
+		log:      log,
+	}
+}
+
+// ProvisionSilence creates the silence with the UID, or updates it if it was provisioned before and it has not
+// expired yet. A silence without start keeps the start of the silence it updates, or starts now.
+// It returns the ID of the silence in the Alertmanager.
+func (svc *SilenceService) ProvisionSilence(ctx context.Context, orgID int64, uid string, silence amv2.Silence) (string, error) {
+	if silence.EndsAt != nil && time.Time(*silence.EndsAt).Before(time.Now()) {
+		return "", fmt.Errorf("%w: silence '%s' has already ended", ErrValidation, uid)
+	}
+
+	existing, err := svc.activeSilence(ctx, orgID, uid)
+	if err != nil {
+		return "", err
+	}
+	var existingID string
+	if existing != nil {
+		existingID = *existing.ID
+	}
+	if silence.StartsAt == nil {
+		now := strfmt.DateTime(time.Now())
+		silence.StartsAt = &now
+		if existing != nil {
+			silence.StartsAt = existing.StartsAt
+		}
+	}
+	ps := &definitions.PostableSilence{ID: existingID, Silence: silence}
+	id, err := svc.silences.CreateSilence(orgID, ps)
+	if err != nil {
+		if errors.Is(err, alertingNotify.ErrCreateSilenceBadPayload) {
+			return "", fmt.Errorf("%w: %s", ErrValidation, err.Error())
+		}
+		return "", err
+	}
+	if id != existingID {
+		if err := svc.kv.Set(ctx, orgID, provisionedSilencesNamespace, uid, id); err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+// DeleteProvisionedSilence expires the silence with the UID. It does nothing if the silence was not provisioned.
+func (svc *SilenceService) DeleteProvisionedSilence(ctx context.Context, orgID int64, uid string) error {
+	existing, err := svc.activeSilence(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if err := svc.silences.DeleteSilence(orgID, *existing.ID); err != nil && !errors.Is(err, alertingNotify.ErrSilenceNotFound) {
+			return err
+		}
+	}
+	return svc.kv.Del(ctx, orgID, provisionedSilencesNamespace, uid)
+}
+
+// PruneProvisionedSilences expires the provisioned silences that are not in uids, which maps the IDs of the
+// organizations to the UIDs of their silences in the provisioning files. This way the silences removed from the
+// files do not stay active.
+func (svc *SilenceService) PruneProvisionedSilences(ctx context.Context, uids map[int64]map[string]struct{}) error {
+	provisioned, err := svc.kv.GetAll(ctx, kvstore.AllOrganizations, provisionedSilencesNamespace)
+	if err != nil {
+		return fmt.Errorf("failed to get the provisioned silences: %w", err)
+	}
+	for orgID, silences := range provisioned {
+		for uid := range silences {
+			if _, ok := uids[orgID][uid]; ok {
+				continue
+			}
+			svc.log.Info("Expiring provisioned silence that is not in the provisioning files anymore", "uid", uid, "org", orgID)
+			if err := svc.DeleteProvisionedSilence(ctx, orgID, uid); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// activeSilence returns the silence with the UID, or nil if the silence was not provisioned or it has already expired.
+func (svc *SilenceService) activeSilence(ctx context.Context, orgID int64, uid string) (*definitions.GettableSilence, error) {
+	id, ok, err := svc.kv.Get(ctx, orgID, provisionedSilencesNamespace, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the ID of provisioned silence '%s': %w", uid, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	silence, err := svc.silences.GetSilence(orgID, id)
+	if err != nil {
+		// expired silences are deleted after the retention period
+		if errors.Is(err, alertingNotify.ErrSilenceNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if silence.Status != nil && silence.Status.State != nil && *silence.Status.State == amv2.SilenceStatusStateExpired {
+		return nil, nil
+	}
+	return &silence, nil
+}
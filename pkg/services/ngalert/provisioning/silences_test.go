@@ -0,0 +1,237 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	alertingNotify "github.com/grafana/alerting/notify"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestSilenceService(t *testing.T) {
+	t.Run("service creates silence and maps it to the UID", func(t *testing.T) {
+		sut, silences, _ := createSilenceServiceSut()
+
+		id, err := sut.ProvisionSilence(context.Background(), 1, "maintenance", silenceEndingIn(time.Hour))
+
+		require.NoError(t, err)
+		require.Contains(t, silences.silences[1], id)
+		require.Equal(t, "maintenance", *silences.silences[1][id].Comment)
+	})
+
+	t.Run("service updates provisioned silence and keeps its start", func(t *testing.T) {
+		sut, silences, _ := createSilenceServiceSut()
+		first, err := sut.ProvisionSilence(context.Background(), 1, "maintenance", silenceEndingIn(time.Hour))
+		require.NoError(t, err)
+		startsAt := *silences.silences[1][first].StartsAt
+
+		second, err := sut.ProvisionSilence(context.Background(), 1, "maintenance", silenceEndingIn(2*time.Hour))
+
+		require.NoError(t, err)
+		require.Equal(t, first, second)
+		require.Len(t, silences.silences[1], 1)
+		require.Equal(t, startsAt, *silences.silences[1][second].StartsAt)
+	})
+
+	t.Run("service remembers the new ID when the Alertmanager replaces the silence", func(t *testing.T) {
+		sut, silences, _ := createSilenceServiceSut()
+		first, err := sut.ProvisionSilence(context.Background(), 1, "maintenance", silenceEndingIn(time.Hour))
+		require.NoError(t, err)
+		silences.replace = true
+
+		second, err := sut.ProvisionSilence(context.Background(), 1, "maintenance", silenceEndingIn(time.Hour))
+		require.NoError(t, err)
+		require.NotEqual(t, first, second)
+		silences.replace = false
+
+		third, err := sut.ProvisionSilence(context.Background(), 1, "maintenance", silenceEndingIn(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, second, third)
+	})
+
+	t.Run("service creates new silence when provisioned silence expired", func(t *testing.T) {
+		sut, silences, _ := createSilenceServiceSut()
+		first, err := sut.ProvisionSilence(context.Background(), 1, "maintenance", silenceEndingIn(time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, silences.DeleteSilence(1, first))
+
+		second, err := sut.ProvisionSilence(context.Background(), 1, "maintenance", silenceEndingIn(time.Hour))
+
+		require.NoError(t, err)
+		require.NotEqual(t, first, second)
+	})
+
+	t.Run("service rejects silence that has already ended", func(t *testing.T) {
+		sut, _, _ := createSilenceServiceSut()
+
+		_, err := sut.ProvisionSilence(context.Background(), 1, "maintenance", silenceEndingIn(-time.Hour))
+
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("service rejects invalid silence", func(t *testing.T) {
+		sut, silences, _ := createSilenceServiceSut()
+		silences.createErr = alertingNotify.ErrCreateSilenceBadPayload
+
+		_, err := sut.ProvisionSilence(context.Background(), 1, "maintenance", silenceEndingIn(time.Hour))
+
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("service expires provisioned silence on delete", func(t *testing.T) {
+		sut, silences, kv := createSilenceServiceSut()
+		id, err := sut.ProvisionSilence(context.Background(), 1, "maintenance", silenceEndingIn(time.Hour))
+		require.NoError(t, err)
+
+		err = sut.DeleteProvisionedSilence(context.Background(), 1, "maintenance")
+
+		require.NoError(t, err)
+		require.Equal(t, amv2.SilenceStatusStateExpired, *silences.silences[1][id].Status.State)
+		_, ok, err := kv.Get(context.Background(), 1, provisionedSilencesNamespace, "maintenance")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("service does nothing on delete of unknown silence", func(t *testing.T) {
+		sut, _, _ := createSilenceServiceSut()
+
+		err := sut.DeleteProvisionedSilence(context.Background(), 1, "maintenance")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("service expires provisioned silences that are not in the files anymore", func(t *testing.T) {
+		sut, silences, kv := createSilenceServiceSut()
+		kept, err := sut.ProvisionSilence(context.Background(), 1, "kept", silenceEndingIn(time.Hour))
+		require.NoError(t, err)
+		removed, err := sut.ProvisionSilence(context.Background(), 1, "removed", silenceEndingIn(time.Hour))
+		require.NoError(t, err)
+		otherOrg, err := sut.ProvisionSilence(context.Background(), 2, "kept", silenceEndingIn(time.Hour))
+		require.NoError(t, err)
+
+		err = sut.PruneProvisionedSilences(context.Background(), map[int64]map[string]struct{}{
+			1: {"kept": {}},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, amv2.SilenceStatusStateActive, *silences.silences[1][kept].Status.State)
+		require.Equal(t, amv2.SilenceStatusStateExpired, *silences.silences[1][removed].Status.State)
+		require.Equal(t, amv2.SilenceStatusStateExpired, *silences.silences[2][otherOrg].Status.State)
+		all, err := kv.GetAll(context.Background(), kvstore.AllOrganizations, provisionedSilencesNamespace)
+		require.NoError(t, err)
+		require.Equal(t, map[int64]map[string]string{1: {"kept": kept}}, all)
+	})
+}
+
+func createSilenceServiceSut() (*SilenceService, *fakeSilenceStore, *fakeSilenceKVStore) {
+	silences := &fakeSilenceStore{silences: map[int64]map[string]*definitions.GettableSilence{}}
+	kv := &fakeSilenceKVStore{store: map[string]string{}}
+	return NewSilenceService(silences, kv, log.NewNopLogger()), silences, kv
+}
+
+func silenceEndingIn(d time.Duration) amv2.Silence {
+	endsAt := strfmt.DateTime(time.Now().Add(d))
+	name, value, isEqual, isRegex := "service", "db", true, false
+	comment, createdBy := "maintenance", "provisioning"
+	return amv2.Silence{
+		Matchers:  amv2.Matchers{{Name: &name, Value: &value, IsEqual: &isEqual, IsRegex: &isRegex}},
+		EndsAt:    &endsAt,
+		Comment:   &comment,
+		CreatedBy: &createdBy,
+	}
+}
+
+type fakeSilenceStore struct {
+	silences  map[int64]map[string]*definitions.GettableSilence
+	lastID    int
+	replace   bool
+	createErr error
+}
+
+func (f *fakeSilenceStore) GetSilence(orgID int64, silenceID string) (definitions.GettableSilence, error) {
+	s, ok := f.silences[orgID][silenceID]
+	if !ok {
+		return definitions.GettableSilence{}, alertingNotify.ErrSilenceNotFound
+	}
+	return *s, nil
+}
+
+func (f *fakeSilenceStore) CreateSilence(orgID int64, ps *definitions.PostableSilence) (string, error) {
+	if f.createErr != nil {
+		return "", f.createErr
+	}
+	id := ps.ID
+	if _, ok := f.silences[orgID][id]; !ok || f.replace {
+		f.lastID++
+		id = fmt.Sprintf("silence-%d", f.lastID)
+	}
+	if f.silences[orgID] == nil {
+		f.silences[orgID] = map[string]*definitions.GettableSilence{}
+	}
+	state := amv2.SilenceStatusStateActive
+	f.silences[orgID][id] = &definitions.GettableSilence{
+		ID:      &id,
+		Silence: ps.Silence,
+		Status:  &amv2.SilenceStatus{State: &state},
+	}
+	return id, nil
+}
+
+func (f *fakeSilenceStore) DeleteSilence(orgID int64, silenceID string) error {
+	s, ok := f.silences[orgID][silenceID]
+	if !ok {
+		return alertingNotify.ErrSilenceNotFound
+	}
+	state := amv2.SilenceStatusStateExpired
+	s.Status.State = &state
+	return nil
+}
+
+type fakeSilenceKVStore struct {
+	store map[string]string
+}
+
+func (f *fakeSilenceKVStore) Get(_ context.Context, orgID int64, namespace string, key string) (string, bool, error) {
+	v, ok := f.store[fmt.Sprintf("%d/%s/%s", orgID, namespace, key)]
+	return v, ok, nil
+}
+
+func (f *fakeSilenceKVStore) Set(_ context.Context, orgID int64, namespace string, key string, value string) error {
+	f.store[fmt.Sprintf("%d/%s/%s", orgID, namespace, key)] = value
+	return nil
+}
+
+func (f *fakeSilenceKVStore) Del(_ context.Context, orgID int64, namespace string, key string) error {
+	delete(f.store, fmt.Sprintf("%d/%s/%s", orgID, namespace, key))
+	return nil
+}
+
+func (f *fakeSilenceKVStore) Keys(_ context.Context, _ int64, _ string, _ string) ([]kvstore.Key, error) {
+	return nil, nil
+}
+
+func (f *fakeSilenceKVStore) GetAll(_ context.Context, orgID int64, namespace string) (map[int64]map[string]string, error) {
+	result := map[int64]map[string]string{}
+	for k, v := range f.store {
+		parts := strings.SplitN(k, "/", 3)
+		org, _ := strconv.ParseInt(parts[0], 10, 64)
+		if parts[1] != namespace || (orgID != kvstore.AllOrganizations && org != orgID) {
+			continue
+		}
+		if result[org] == nil {
+			result[org] = map[string]string{}
+		}
+		result[org][parts[2]] = v
+	}
+	return result, nil
+}
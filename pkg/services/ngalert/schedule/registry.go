@@ -144,6 +144,15 @@ type evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// done is closed when the evaluation is completed or skipped. It is nil if nothing waits for the evaluation.
+	done chan struct{}
+}
+
+// complete signals the ones that wait for the evaluation that it is completed or skipped.
+func (e *evaluation) complete() {
+	if e.done != nil {
+		close(e.done)
+	}
 }
 
 type alertRulesRegistry struct {
@@ -5,10 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"time"
 
 	"github.com/benbjohnson/clock"
 	alertingModels "github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/hashicorp/go-multierror"
 	prometheusModel "github.com/prometheus/common/model"
 	"go.opentelemetry.io/otel/attribute"
@@ -48,6 +51,11 @@ type AlertsSender interface {
 	Send(key ngmodels.AlertRuleKey, alerts definitions.PostableAlerts)
 }
 
+// RecordingWriter is an interface for a service that writes the results of recording rules to their target.
+type RecordingWriter interface {
+	Write(ctx context.Context, rule *ngmodels.AlertRule, t time.Time, frames data.Frames, extraLabels map[string]string) error
+}
+
 // RulesStore is a store that provides alert rules for scheduling
 type RulesStore interface {
 	GetAlertRulesKeysForScheduling(ctx context.Context) ([]ngmodels.AlertRuleKeyWithVersion, error)
@@ -89,6 +97,7 @@ type schedule struct {
 	metrics *metrics.Scheduler
 
 	alertsSender    AlertsSender
+	recordingWriter RecordingWriter
 	minRuleInterval time.Duration
 
 	// schedulableAlertRules contains the alert rules that are considered for
@@ -97,6 +106,13 @@ type schedule struct {
 	// last evaluated.
 	schedulableAlertRules alertRulesRegistry
 
+	// sharder decides which alert rules are evaluated by this replica. If it is nil, all rules are evaluated.
+	sharder RuleSharder
+	// notOwnedRules contains the alert rules that were evaluated by other replicas in the previous tick.
+	notOwnedRules map[ngmodels.AlertRuleKey]struct{}
+	// notOwnedStateRefreshedAt is the tick at which the states of notOwnedRules were last read from the database.
+	notOwnedStateRefreshedAt time.Time
+
 	tracer tracing.Tracer
 }
 
@@ -112,7 +128,9 @@ type SchedulerCfg struct {
 	RuleStore            RulesStore
 	Metrics              *metrics.Scheduler
 	AlertSender          AlertsSender
+	RecordingWriter      RecordingWriter
 	Tracer               tracing.Tracer
+	Sharder              RuleSharder
 }
 
 // NewScheduler returns a new schedule.
@@ -132,7 +150,10 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		minRuleInterval:       cfg.MinRuleInterval,
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
+		recordingWriter:       cfg.RecordingWriter,
 		tracer:                cfg.Tracer,
+		sharder:               cfg.Sharder,
+		notOwnedRules:         make(map[ngmodels.AlertRuleKey]struct{}),
 	}
 
 	return &sch
@@ -180,6 +201,19 @@ func (sch *schedule) DeleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 	sch.updateRulesMetrics(alertRules)
 }
 
+// handOverAlertRule stops the evaluation of the alert rule that is evaluated by another replica. The state is kept in
+// the database, so the replica that takes over the rule can continue from it.
+// notOwnedStateRefreshInterval is how often the states of the alert rules evaluated by other replicas are read from
+// the database when the rules that the replica evaluates do not change.
+const notOwnedStateRefreshInterval = time.Minute
+
+func (sch *schedule) handOverAlertRule(key ngmodels.AlertRuleKey) {
+	if ruleInfo, ok := sch.registry.del(key); ok {
+		sch.log.Info("Handing over alert rule to another replica", key.LogContext()...)
+		ruleInfo.stop(errRuleHandedOver)
+	}
+}
+
 func (sch *schedule) schedulePeriodic(ctx context.Context, t *ticker.T) error {
 	dispatcherGroup, ctx := errgroup.WithContext(ctx)
 	for {
@@ -203,6 +237,39 @@ func (sch *schedule) schedulePeriodic(ctx context.Context, t *ticker.T) error {
 	}
 }
 
+// refreshNotOwnedState reads the state of the rules evaluated by other replicas from the database, so that the state
+// cache of every replica contains the state of all rules: the API, and the rules that depend on them, read it from
+// the cache. The state is read when the rules evaluated by other replicas change, and otherwise every
+// notOwnedStateRefreshInterval. The states of deleted rules that were evaluated by other replicas are removed from
+// the cache.
+func (sch *schedule) refreshNotOwnedState(ctx context.Context, tick time.Time, alertRules []*ngmodels.AlertRule, notOwned map[ngmodels.AlertRuleKey]struct{}, notOwnedRules []*ngmodels.AlertRule) {
+	changed := len(notOwned) != len(sch.notOwnedRules)
+	if len(sch.notOwnedRules) > 0 {
+		existing := make(map[ngmodels.AlertRuleKey]struct{}, len(alertRules))
+		for _, rule := range alertRules {
+			existing[rule.GetKey()] = struct{}{}
+		}
+		for key := range sch.notOwnedRules {
+			if _, ok := notOwned[key]; !ok {
+				changed = true
+			}
+			if _, ok := existing[key]; !ok {
+				sch.stateManager.ForgetStateByRuleUID(key)
+			}
+		}
+	}
+	sch.notOwnedRules = notOwned
+
+	if !changed && tick.Sub(sch.notOwnedStateRefreshedAt) < notOwnedStateRefreshInterval {
+		return
+	}
+	if err := sch.stateManager.RefreshStateByRules(ctx, notOwnedRules); err != nil {
+		sch.log.Error("Failed to refresh state of alert rules evaluated by other replicas", "error", err)
+		return
+	}
+	sch.notOwnedStateRefreshedAt = tick
+}
+
 type readyToRunItem struct {
 	ruleInfo *alertRuleInfo
 	evaluation
@@ -248,8 +315,23 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 	readyToRun := make([]readyToRunItem, 0)
 	missingFolder := make(map[string][]string)
+	notOwned := make(map[ngmodels.AlertRuleKey]struct{})
+	var notOwnedRules []*ngmodels.AlertRule
 	for _, item := range alertRules {
 		key := item.GetKey()
+		if sch.sharder != nil && !sch.sharder.Owns(key) {
+			sch.handOverAlertRule(key)
+			notOwned[key] = struct{}{}
+			notOwnedRules = append(notOwnedRules, item)
+			delete(registeredDefinitions, key)
+			continue
+		}
+		if _, ok := sch.notOwnedRules[key]; ok {
+			sch.log.Info("Taking over alert rule from another replica", key.LogContext()...)
+			if err := sch.stateManager.LoadStateByRule(ctx, item); err != nil {
+				sch.log.Error("Failed to load state of alert rule", append(key.LogContext(), "error", err)...)
+			}
+		}
 		ruleInfo, newRoutine := sch.registry.getOrCreateInfo(ctx, key)
 
 		// enforce minimum evaluation interval
@@ -295,10 +377,21 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		delete(registeredDefinitions, key)
 	}
 
+	sch.refreshNotOwnedState(ctx, tick, alertRules, notOwned, notOwnedRules)
+
 	if len(missingFolder) > 0 { // if this happens then there can be problems with fetching folders from the database.
 		sch.log.Warn("Unable to obtain folder titles for some rules", "missingFolderUIDToRuleUID", missingFolder)
 	}
 
+	sortByDependencies(readyToRun)
+
+	// The evaluation of a rule that depends on rules evaluated in the same tick waits for their evaluations.
+	done := make(map[ngmodels.AlertRuleKey]chan struct{}, len(readyToRun))
+	for i := range readyToRun {
+		readyToRun[i].done = make(chan struct{})
+		done[readyToRun[i].rule.GetKey()] = readyToRun[i].done
+	}
+
 	var step int64 = 0
 	if len(readyToRun) > 0 {
 		step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
@@ -306,15 +399,26 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 	for i := range readyToRun {
 		item := readyToRun[i]
+		var upstream []chan struct{}
+		for _, uid := range item.rule.DependsOn {
+			if ch, ok := done[ngmodels.AlertRuleKey{OrgID: item.rule.OrgID, UID: uid}]; ok {
+				upstream = append(upstream, ch)
+			}
+		}
 
 		time.AfterFunc(time.Duration(int64(i)*step), func() {
 			key := item.rule.GetKey()
+			if !waitForUpstream(item.ruleInfo.ctx, time.Duration(item.rule.IntervalSeconds)*time.Second, upstream) {
+				sch.log.Debug("Waiting for the evaluation of upstream rules timed out", append(key.LogContext(), "time", tick)...)
+			}
 			success, dropped := item.ruleInfo.eval(&item.evaluation)
 			if !success {
+				item.complete()
 				sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
 				return
 			}
 			if dropped != nil {
+				dropped.complete()
 				sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
 				orgID := fmt.Sprint(key.OrgID)
 				sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
@@ -362,21 +466,11 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 		logger := logger.New("version", e.rule.Version, "attempt", attempt, "now", e.scheduledAt)
 		start := sch.clock.Now()
 
-		schedulerUser := &user.SignedInUser{
-			UserID:           -1,
-			IsServiceAccount: true,
-			Login:            "grafana_scheduler",
-			OrgID:            e.rule.OrgID,
-			OrgRole:          org.RoleAdmin,
-			Permissions: map[int64]map[string][]string{
-				e.rule.OrgID: {
-					datasources.ActionQuery: []string{
-						datasources.ScopeAll,
-					},
-				},
-			},
-		}
-		evalCtx := eval.Context(ctx, schedulerUser)
+		evalCtx := eval.NewContextWithPreviousResults(ctx, schedulerUserForRule(e.rule), &state.AlertingResultsFromRuleState{
+			Manager: sch.stateManager,
+			Rule:    e.rule,
+		})
+		evalCtx.Limits = eval.LimitsFromRule(e.rule)
 		ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
 		var results eval.Results
 		var dur time.Duration
@@ -392,6 +486,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 
 		evalTotal.Inc()
 		evalDuration.Observe(dur.Seconds())
+		if limit := exceededEvaluationLimit(err); limit != "" {
+			sch.metrics.EvaluationLimitsExceeded.WithLabelValues(orgID, e.rule.Title, limit).Inc()
+		}
 
 		if err != nil || results.HasErrors() {
 			evalTotalFailures.Inc()
@@ -439,6 +536,62 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 		}
 	}
 
+	record := func(ctx context.Context, attempt int64, e *evaluation, span tracing.Span) error {
+		logger := logger.New("version", e.rule.Version, "attempt", attempt, "now", e.scheduledAt)
+		start := sch.clock.Now()
+
+		evalCtx := eval.Context(ctx, schedulerUserForRule(e.rule))
+		evalCtx.Limits = eval.LimitsFromRule(e.rule)
+		ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
+		var resp *backend.QueryDataResponse
+		if err == nil {
+			resp, err = ruleEval.EvaluateRaw(ctx, e.scheduledAt)
+		}
+		var frames data.Frames
+		if err == nil {
+			res, ok := resp.Responses[e.rule.Record.From]
+			switch {
+			case !ok:
+				err = fmt.Errorf("no results for the recorded query or expression %s", e.rule.Record.From)
+			case res.Error != nil:
+				err = res.Error
+			default:
+				frames = res.Frames
+			}
+		}
+		if err == nil {
+			err = sch.recordingWriter.Write(ctx, e.rule, e.scheduledAt, frames, e.rule.Labels)
+		}
+		dur := sch.clock.Now().Sub(start)
+
+		evalTotal.Inc()
+		evalDuration.Observe(dur.Seconds())
+		if limit := exceededEvaluationLimit(err); limit != "" {
+			sch.metrics.EvaluationLimitsExceeded.WithLabelValues(orgID, e.rule.Title, limit).Inc()
+		}
+
+		if err != nil {
+			evalTotalFailures.Inc()
+			logger.Error("Failed to evaluate recording rule", "error", err, "duration", dur)
+			span.RecordError(err)
+			span.AddEvents(
+				[]string{"error", "message"},
+				[]tracing.EventValue{
+					{Str: fmt.Sprintf("%v", err)},
+					{Str: "recording rule evaluation failed"},
+				})
+			return err
+		}
+		logger.Debug("Recording rule evaluated", "frames", len(frames), "duration", dur)
+		span.AddEvents(
+			[]string{"message", "frames"},
+			[]tracing.EventValue{
+				{Str: "recording rule evaluated"},
+				{Num: int64(len(frames))},
+			})
+		return nil
+	}
+
 	retryIfError := func(f func(attempt int64) error) error {
 		var attempt int64
 		var err error
@@ -477,6 +630,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				return nil
 			}
 			if evalRunning {
+				ctx.complete()
 				continue
 			}
 
@@ -485,6 +639,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				defer func() {
 					evalRunning = false
 					sch.evalApplied(key, ctx.scheduledAt)
+					ctx.complete()
 				}()
 
 				err := retryIfError(func(attempt int64) error {
@@ -505,6 +660,12 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 					if isPaused {
 						return nil
 					}
+					if upstream := sch.firingUpstreamRule(ctx.rule); upstream != "" {
+						logger.Debug("Skip evaluation because an upstream rule is firing", "upstreamRuleUID", upstream)
+						states := sch.stateManager.ResetStateByRuleUID(grafanaCtx, ctx.rule, ngmodels.StateReasonUpstreamFiring)
+						notify(states)
+						return nil
+					}
 					tracingCtx, span := sch.tracer.Start(grafanaCtx, "alert rule execution")
 					defer span.End()
 
@@ -514,6 +675,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 					utcTick := ctx.scheduledAt.UTC().Format(time.RFC3339Nano)
 					span.SetAttributes("tick", utcTick, attribute.String("tick", utcTick))
 
+					if ctx.rule.Type() == ngmodels.RuleTypeRecording {
+						return record(tracingCtx, attempt, ctx, span)
+					}
 					evaluate(tracingCtx, attempt, ctx, span)
 					return nil
 				})
@@ -538,6 +702,94 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 	}
 }
 
+// firingUpstreamRule returns the UID of the first rule the given rule depends on that has at least one firing alert.
+// Returns an empty string if none of them is firing. Rules that do not exist are considered not firing.
+func (sch *schedule) firingUpstreamRule(rule *ngmodels.AlertRule) string {
+	for _, uid := range rule.DependsOn {
+		for _, s := range sch.stateManager.GetStatesForRuleUID(rule.OrgID, uid) {
+			if s.State == eval.Alerting {
+				return uid
+			}
+		}
+	}
+	return ""
+}
+
+// waitForUpstream waits until the evaluations of the upstream rules are completed or skipped, at most for the timeout.
+// Returns false if the timeout elapsed. Returns true without waiting if the context is done, because the evaluation is
+// canceled anyway.
+func waitForUpstream(ctx context.Context, timeout time.Duration, upstream []chan struct{}) bool {
+	if len(upstream) == 0 {
+		return true
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for _, done := range upstream {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return true
+		case <-timer.C:
+			return false
+		}
+	}
+	return true
+}
+
+// sortByDependencies sorts items so that rules are dispatched after the rules they depend on.
+// Rules with the same dependency depth are ordered by their rule group and position in the group.
+// The order only decides when the evaluations are dispatched: the evaluations run concurrently, and a rule waits for
+// the rules it depends on in processTick.
+func sortByDependencies(items []readyToRunItem) {
+	byKey := make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule, len(items))
+	for _, item := range items {
+		byKey[item.rule.GetKey()] = item.rule
+	}
+	depths := make(map[ngmodels.AlertRuleKey]int, len(items))
+	var depth func(key ngmodels.AlertRuleKey) int
+	depth = func(key ngmodels.AlertRuleKey) int {
+		if d, ok := depths[key]; ok {
+			return d
+		}
+		depths[key] = 0 // protects from cycles, which are rejected by the API but can still be created by other means
+		d := 0
+		for _, uid := range byKey[key].DependsOn {
+			upstream := ngmodels.AlertRuleKey{OrgID: key.OrgID, UID: uid}
+			if _, ok := byKey[upstream]; !ok {
+				continue
+			}
+			if ud := depth(upstream) + 1; ud > d {
+				d = ud
+			}
+		}
+		depths[key] = d
+		return d
+	}
+	for key := range byKey {
+		depth(key)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].rule, items[j].rule
+		if da, db := depths[a.GetKey()], depths[b.GetKey()]; da != db {
+			return da < db
+		}
+		if a.OrgID != b.OrgID {
+			return a.OrgID < b.OrgID
+		}
+		if a.NamespaceUID != b.NamespaceUID {
+			return a.NamespaceUID < b.NamespaceUID
+		}
+		if a.RuleGroup != b.RuleGroup {
+			return a.RuleGroup < b.RuleGroup
+		}
+		if a.RuleGroupIndex != b.RuleGroupIndex {
+			return a.RuleGroupIndex < b.RuleGroupIndex
+		}
+		return a.UID < b.UID
+	})
+}
+
 // evalApplied is only used on tests.
 func (sch *schedule) evalApplied(alertDefKey ngmodels.AlertRuleKey, now time.Time) {
 	if sch.evalAppliedFunc == nil {
@@ -556,6 +808,24 @@ func (sch *schedule) stopApplied(alertDefKey ngmodels.AlertRuleKey) {
 	sch.stopAppliedFunc(alertDefKey)
 }
 
+// schedulerUserForRule returns the user on behalf of which the scheduler evaluates the rule.
+func schedulerUserForRule(rule *ngmodels.AlertRule) *user.SignedInUser {
+	return &user.SignedInUser{
+		UserID:           -1,
+		IsServiceAccount: true,
+		Login:            "grafana_scheduler",
+		OrgID:            rule.OrgID,
+		OrgRole:          org.RoleAdmin,
+		Permissions: map[int64]map[string][]string{
+			rule.OrgID: {
+				datasources.ActionQuery: []string{
+					datasources.ScopeAll,
+				},
+			},
+		},
+	}
+}
+
 func (sch *schedule) getRuleExtraLabels(evalCtx *evaluation) map[string]string {
 	extraLabels := make(map[string]string, 4)
 
@@ -566,5 +836,23 @@ func (sch *schedule) getRuleExtraLabels(evalCtx *evaluation) map[string]string {
 	if !sch.disableGrafanaFolder {
 		extraLabels[ngmodels.FolderTitleLabel] = evalCtx.folderTitle
 	}
+	if evalCtx.rule.NotificationSettings != nil {
+		for k, v := range evalCtx.rule.NotificationSettings.ToLabels() {
+			extraLabels[k] = v
+		}
+	}
 	return extraLabels
 }
+
+// exceededEvaluationLimit returns the name of the limit that the evaluation exceeded,
+// or an empty string if the error is not caused by a limit.
+func exceededEvaluationLimit(err error) string {
+	switch {
+	case errors.Is(err, eval.ErrEvaluationTimeout):
+		return "timeout"
+	case errors.Is(err, eval.ErrSeriesLimitExceeded):
+		return "max_series_per_query"
+	default:
+		return ""
+	}
+}
@@ -717,6 +717,200 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 
 		require.NotEmpty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
 	})
+
+	t.Run("when rule is a recording rule", func(t *testing.T) {
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithRecord("test_metric", "A"))()
+
+		evalChan := make(chan *evaluation)
+		evalAppliedChan := make(chan time.Time)
+
+		sender := AlertsSenderMock{}
+		sender.EXPECT().Send(rule.GetKey(), mock.Anything).Return()
+
+		sch, ruleStore, _, reg := createSchedule(evalAppliedChan, &sender)
+		ruleStore.PutRule(context.Background(), rule)
+		writer := sch.recordingWriter.(*fakeRecordingWriter)
+
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
+		}()
+
+		evalChan <- &evaluation{
+			scheduledAt: sch.clock.Now(),
+			rule:        rule,
+		}
+
+		waitForTimeChannel(t, evalAppliedChan)
+
+		t.Run("it should write the result of the recorded node", func(t *testing.T) {
+			written := writer.getWritten()
+			require.Len(t, written, 1)
+			require.Len(t, written[0], 1)
+			v, ok := written[0][0].Fields[0].ConcreteAt(0)
+			require.True(t, ok)
+			require.Equal(t, 1.0, v)
+		})
+
+		t.Run("it should not create alert instances or send alerts", func(t *testing.T) {
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+		})
+
+		t.Run("it should increase evaluation counter", func(t *testing.T) {
+			expectedMetric := fmt.Sprintf(
+				`# HELP grafana_alerting_rule_evaluations_total The total number of rule evaluations.
+        	            	# TYPE grafana_alerting_rule_evaluations_total counter
+        	            	grafana_alerting_rule_evaluations_total{org="%[1]d"} 1
+				`, rule.OrgID)
+
+			err := testutil.GatherAndCompare(reg, bytes.NewBufferString(expectedMetric), "grafana_alerting_rule_evaluations_total")
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("when rule depends on another rule", func(t *testing.T) {
+		upstream := models.AlertRuleGen(withQueryForState(t, eval.Alerting))()
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithOrgID(upstream.OrgID), models.WithDependsOn(upstream.UID))()
+
+		evalChan := make(chan *evaluation)
+		evalAppliedChan := make(chan time.Time)
+
+		sender := AlertsSenderMock{}
+		sender.EXPECT().Send(rule.GetKey(), mock.Anything).Return()
+
+		sch, ruleStore, _, _ := createSchedule(evalAppliedChan, &sender)
+		ruleStore.PutRule(context.Background(), upstream, rule)
+
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
+		}()
+
+		t.Run("it should evaluate the rule when upstream rule is not firing", func(t *testing.T) {
+			evalChan <- &evaluation{
+				scheduledAt: sch.clock.Now(),
+				rule:        rule,
+			}
+			waitForTimeChannel(t, evalAppliedChan)
+
+			states := sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+			require.Len(t, states, 1)
+			require.Equal(t, eval.Alerting, states[0].State)
+			sender.AssertNumberOfCalls(t, "Send", 1)
+		})
+
+		t.Run("it should skip evaluation and resolve alerts when upstream rule is firing", func(t *testing.T) {
+			now := sch.clock.Now()
+			sch.stateManager.ProcessEvalResults(context.Background(), now, upstream, eval.Results{
+				{Instance: data.Labels{}, State: eval.Alerting, EvaluatedAt: now},
+			}, nil)
+
+			evalChan <- &evaluation{
+				scheduledAt: now,
+				rule:        rule,
+			}
+			waitForTimeChannel(t, evalAppliedChan)
+
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			sender.AssertNumberOfCalls(t, "Send", 2)
+			args, ok := sender.Calls[1].Arguments[1].(definitions.PostableAlerts)
+			require.Truef(t, ok, fmt.Sprintf("expected argument of function was supposed to be 'definitions.PostableAlerts' but got %T", sender.Calls[1].Arguments[1]))
+			require.Len(t, args.PostableAlerts, 1)
+			require.Equal(t, models.StateReasonUpstreamFiring, args.PostableAlerts[0].Annotations[models.StateReasonAnnotation])
+		})
+	})
+}
+
+func TestSortByDependencies(t *testing.T) {
+	gen := func(mutators ...models.AlertRuleMutator) *models.AlertRule {
+		return models.AlertRuleGen(append([]models.AlertRuleMutator{models.WithOrgID(1), func(rule *models.AlertRule) {
+			rule.NamespaceUID = "folder"
+			rule.RuleGroup = "group"
+		}}, mutators...)...)()
+	}
+	upstream := gen(models.WithGroupIndex(3))
+	dependent := gen(models.WithGroupIndex(1), models.WithDependsOn(upstream.UID))
+	other := gen(models.WithGroupIndex(2))
+	otherGroup := gen(models.WithGroupIndex(1), func(rule *models.AlertRule) {
+		rule.RuleGroup = "another-group"
+	})
+
+	items := []readyToRunItem{
+		{evaluation: evaluation{rule: dependent}},
+		{evaluation: evaluation{rule: other}},
+		{evaluation: evaluation{rule: otherGroup}},
+		{evaluation: evaluation{rule: upstream}},
+	}
+	sortByDependencies(items)
+
+	actual := make([]string, 0, len(items))
+	for _, item := range items {
+		actual = append(actual, item.rule.UID)
+	}
+	require.Equal(t, []string{otherGroup.UID, other.UID, upstream.UID, dependent.UID}, actual)
+}
+
+func TestProcessTicksWithDependencies(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+
+	upstream := models.AlertRuleGen(withQueryForState(t, eval.Normal), models.WithOrgID(1), models.WithInterval(10*time.Second))()
+	dependent := models.AlertRuleGen(withQueryForState(t, eval.Normal), models.WithOrgID(1), models.WithInterval(10*time.Second), models.WithDependsOn(upstream.UID))()
+	ruleStore.PutRule(ctx, upstream, dependent)
+
+	release := make(chan struct{})
+	applied := make(chan models.AlertRuleKey, 2)
+	sch.evalAppliedFunc = func(key models.AlertRuleKey, _ time.Time) {
+		if key == upstream.GetKey() {
+			<-release
+		}
+		applied <- key
+	}
+
+	scheduled, _ := sch.processTick(ctx, dispatcherGroup, time.Unix(100, 0))
+	require.Len(t, scheduled, 2)
+
+	// The dependent rule is dispatched half a base interval after the upstream rule, but its evaluation waits for the
+	// evaluation of the upstream rule to complete.
+	select {
+	case key := <-applied:
+		t.Fatalf("rule %s was evaluated before the evaluation of the upstream rule completed", key.UID)
+	case <-time.After(2 * time.Second):
+	}
+
+	close(release)
+	require.Equal(t, upstream.GetKey(), <-applied)
+	select {
+	case key := <-applied:
+		require.Equal(t, dependent.GetKey(), key)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the dependent rule was not evaluated after the evaluation of the upstream rule completed")
+	}
+}
+
+func TestWaitForUpstream(t *testing.T) {
+	t.Run("should return true when upstream evaluations are completed", func(t *testing.T) {
+		done := make(chan struct{})
+		close(done)
+		require.True(t, waitForUpstream(context.Background(), time.Minute, []chan struct{}{done}))
+	})
+
+	t.Run("should return false when timeout elapses", func(t *testing.T) {
+		require.False(t, waitForUpstream(context.Background(), time.Millisecond, []chan struct{}{make(chan struct{})}))
+	})
+
+	t.Run("should not wait when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.True(t, waitForUpstream(ctx, time.Minute, []chan struct{}{make(chan struct{})}))
+	})
 }
 
 func TestSchedule_UpdateAlertRule(t *testing.T) {
@@ -823,6 +1017,7 @@ func setupScheduler(t *testing.T, rs *fakeRulesStore, is *state.FakeInstanceStor
 		RuleStore:        rs,
 		Metrics:          m.GetSchedulerMetrics(),
 		AlertSender:      senderMock,
+		RecordingWriter:  &fakeRecordingWriter{},
 		Tracer:           testTracer,
 	}
 	managerCfg := state.ManagerCfg{
@@ -940,3 +1135,28 @@ func assertStopRun(t *testing.T, ch <-chan models.AlertRuleKey, keys ...models.A
 		}
 	}
 }
+
+func TestSchedule_getRuleExtraLabels(t *testing.T) {
+	sch := &schedule{}
+
+	t.Run("should add routing labels of notification settings", func(t *testing.T) {
+		settings := models.NotificationSettings{Receiver: "team-a"}
+		rule := models.AlertRuleGen(models.WithNotificationSettings(settings))()
+
+		lbls := sch.getRuleExtraLabels(&evaluation{rule: rule, folderTitle: "folder"})
+
+		for k, v := range settings.ToLabels() {
+			require.Equal(t, v, lbls[k])
+		}
+		require.Equal(t, "folder", lbls[models.FolderTitleLabel])
+	})
+
+	t.Run("should not add routing labels if rule has no notification settings", func(t *testing.T) {
+		rule := models.AlertRuleGen()()
+		rule.NotificationSettings = nil
+
+		lbls := sch.getRuleExtraLabels(&evaluation{rule: rule})
+
+		require.NotContains(t, lbls, models.AutogeneratedRouteLabel)
+	})
+}
@@ -0,0 +1,199 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// errRuleHandedOver is the reason to stop the routine of a rule that is evaluated by another replica.
+var errRuleHandedOver = errors.New("rule handed over to another replica")
+
+// ruleRingTokensPerReplica is the number of tokens of every replica in the hash ring. More tokens spread the rules more
+// evenly across the replicas.
+const ruleRingTokensPerReplica = 128
+
+// RuleSharder decides which alert rules are evaluated by the scheduler of this replica.
+type RuleSharder interface {
+	// Owns returns true if the rule must be evaluated by this replica.
+	Owns(key ngmodels.AlertRuleKey) bool
+}
+
+// ReplicaStore stores the heartbeats of the replicas.
+type ReplicaStore interface {
+	UpdateReplicaHeartbeat(ctx context.Context, replicaID string, at time.Time) error
+	GetActiveReplicas(ctx context.Context, since time.Time) ([]string, error)
+	DeleteReplica(ctx context.Context, replicaID string) error
+	DeleteInactiveReplicas(ctx context.Context, before time.Time) (int64, error)
+}
+
+// hashRing is a consistent hash ring of replicas. When a replica joins or leaves the ring, only the rules of the
+// tokens next to the tokens of the replica change the owner.
+type hashRing struct {
+	tokens   []uint64
+	replicas map[uint64]string
+	members  []string
+}
+
+func newHashRing(members []string) *hashRing {
+	r := &hashRing{
+		tokens:   make([]uint64, 0, len(members)*ruleRingTokensPerReplica),
+		replicas: make(map[uint64]string, len(members)*ruleRingTokensPerReplica),
+		members:  members,
+	}
+	for _, m := range members {
+		for i := 0; i < ruleRingTokensPerReplica; i++ {
+			token := hashString(m + "#" + strconv.Itoa(i))
+			if _, ok := r.replicas[token]; ok {
+				continue
+			}
+			r.replicas[token] = m
+			r.tokens = append(r.tokens, token)
+		}
+	}
+	sort.Slice(r.tokens, func(i, j int) bool { return r.tokens[i] < r.tokens[j] })
+	return r
+}
+
+// owner returns the replica that owns the rule, or an empty string if the ring is empty.
+func (r *hashRing) owner(key ngmodels.AlertRuleKey) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	h := hashString(strconv.FormatInt(key.OrgID, 10) + "/" + key.UID)
+	idx := sort.Search(len(r.tokens), func(i int) bool { return r.tokens[i] >= h })
+	if idx == len(r.tokens) {
+		idx = 0
+	}
+	return r.replicas[r.tokens[idx]]
+}
+
+// hashString returns the FNV-1a hash of the string mixed with the finalizer of MurmurHash3, because FNV-1a alone
+// spreads similar strings such as rule UIDs with a common prefix poorly over the ring.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	k := h.Sum64()
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return k
+}
+
+// ReplicaRing is a RuleSharder that spreads the rules across the replicas that send heartbeats to the database.
+type ReplicaRing struct {
+	replicaID string
+	store     ReplicaStore
+	clock     clock.Clock
+	interval  time.Duration
+	timeout   time.Duration
+	log       log.Logger
+
+	mtx  sync.RWMutex
+	ring *hashRing
+}
+
+// NewReplicaRing creates a ReplicaRing for the replica. The replica sends a heartbeat every interval,
+// and replicas that did not send a heartbeat for longer than the timeout are removed from the ring.
+func NewReplicaRing(replicaID string, store ReplicaStore, clk clock.Clock, interval, timeout time.Duration) *ReplicaRing {
+	return &ReplicaRing{
+		replicaID: replicaID,
+		store:     store,
+		clock:     clk,
+		interval:  interval,
+		timeout:   timeout,
+		log:       log.New("ngalert.scheduler.ring", "replica", replicaID),
+	}
+}
+
+// Owns returns true if the rule is assigned to this replica. Until the replica knows the members of the ring,
+// it owns all rules, which is the behavior without sharding.
+func (r *ReplicaRing) Owns(key ngmodels.AlertRuleKey) bool {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if r.ring == nil {
+		return true
+	}
+	return r.ring.owner(key) == r.replicaID
+}
+
+// Members returns the IDs of the replicas in the ring.
+func (r *ReplicaRing) Members() []string {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if r.ring == nil {
+		return nil
+	}
+	return r.ring.members
+}
+
+// Run sends heartbeats and updates the members of the ring until the context is canceled.
+// The replica leaves the ring when Run returns.
+func (r *ReplicaRing) Run(ctx context.Context) error {
+	ticker := r.clock.Ticker(r.interval)
+	defer ticker.Stop()
+	r.heartbeat(ctx)
+	for {
+		select {
+		case <-ticker.C:
+			r.heartbeat(ctx)
+		case <-ctx.Done():
+			leaveCtx, cancel := context.WithTimeout(context.Background(), r.interval)
+			defer cancel()
+			if err := r.store.DeleteReplica(leaveCtx, r.replicaID); err != nil {
+				r.log.Warn("Failed to leave the ring", "error", err)
+			}
+			return nil
+		}
+	}
+}
+
+func (r *ReplicaRing) heartbeat(ctx context.Context) {
+	now := r.clock.Now()
+	if err := r.store.UpdateReplicaHeartbeat(ctx, r.replicaID, now); err != nil {
+		// the other replicas take over the rules of this replica if it cannot send heartbeats until the timeout
+		r.log.Error("Failed to send heartbeat", "error", err)
+	}
+	members, err := r.store.GetActiveReplicas(ctx, now.Add(-r.timeout))
+	if err != nil {
+		r.log.Error("Failed to get the members of the ring", "error", err)
+		return
+	}
+	r.setMembers(members)
+	if _, err := r.store.DeleteInactiveReplicas(ctx, now.Add(-10*r.timeout)); err != nil {
+		r.log.Warn("Failed to delete inactive replicas", "error", err)
+	}
+}
+
+func (r *ReplicaRing) setMembers(members []string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.ring != nil && equalMembers(r.ring.members, members) {
+		return
+	}
+	r.log.Info("Members of the ring changed, rebalancing alert rules", "members", members)
+	r.ring = newHashRing(members)
+}
+
+func equalMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
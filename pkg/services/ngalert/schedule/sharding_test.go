@@ -0,0 +1,350 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestHashRing(t *testing.T) {
+	keys := make([]models.AlertRuleKey, 0, 3000)
+	for i := 0; i < 3000; i++ {
+		keys = append(keys, models.AlertRuleKey{OrgID: int64(i%3 + 1), UID: fmt.Sprintf("rule-%d", i)})
+	}
+
+	t.Run("should spread rules evenly across replicas", func(t *testing.T) {
+		ring := newHashRing([]string{"replica-1", "replica-2", "replica-3"})
+		counts := map[string]int{}
+		for _, key := range keys {
+			counts[ring.owner(key)]++
+		}
+		require.Len(t, counts, 3)
+		for replica, count := range counts {
+			require.InDeltaf(t, len(keys)/3, count, float64(len(keys))/10, "replica %s owns %d rules", replica, count)
+		}
+	})
+
+	t.Run("should move only rules of the replica that left", func(t *testing.T) {
+		before := newHashRing([]string{"replica-1", "replica-2", "replica-3"})
+		after := newHashRing([]string{"replica-1", "replica-3"})
+		for _, key := range keys {
+			owner := before.owner(key)
+			if owner == "replica-2" {
+				require.NotEqual(t, "replica-2", after.owner(key))
+				continue
+			}
+			require.Equal(t, owner, after.owner(key))
+		}
+	})
+
+	t.Run("should not have owner if empty", func(t *testing.T) {
+		require.Equal(t, "", newHashRing(nil).owner(keys[0]))
+	})
+}
+
+func TestReplicaRing(t *testing.T) {
+	key := models.AlertRuleKey{OrgID: 1, UID: "test"}
+	store := newFakeReplicaStore()
+	clk := clock.NewMock()
+	ring := NewReplicaRing("replica-1", store, clk, time.Second, 5*time.Second)
+
+	require.True(t, ring.Owns(key), "replica should own all rules until it knows the members of the ring")
+
+	ring.heartbeat(context.Background())
+	require.Equal(t, []string{"replica-1"}, ring.Members())
+	require.True(t, ring.Owns(key))
+
+	owner := newHashRing([]string{"replica-1", "replica-2"}).owner(key)
+	store.heartbeats["replica-2"] = clk.Now()
+	clk.Add(time.Second)
+	ring.heartbeat(context.Background())
+	require.Equal(t, []string{"replica-1", "replica-2"}, ring.Members())
+	require.Equal(t, owner == "replica-1", ring.Owns(key))
+
+	// replica-2 stops sending heartbeats
+	clk.Add(10 * time.Second)
+	ring.heartbeat(context.Background())
+	require.Equal(t, []string{"replica-1"}, ring.Members())
+	require.True(t, ring.Owns(key))
+}
+
+func TestProcessTicksWithSharder(t *testing.T) {
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+	ruleStore := newFakeRulesStore()
+	instanceStore := &state.FakeInstanceStore{}
+	sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+	sharder := &fakeSharder{owned: map[models.AlertRuleKey]bool{}}
+	sch.sharder = sharder
+
+	// the rules are not evaluated in the ticks of the test
+	owned := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Hour))()
+	handedOver := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Hour))()
+	ruleStore.PutRule(ctx, owned, handedOver)
+	sharder.owned[owned.GetKey()] = true
+
+	tick := time.Unix(1, 0)
+	sch.processTick(ctx, dispatcherGroup, tick)
+	require.True(t, sch.registry.exists(owned.GetKey()))
+	require.False(t, sch.registry.exists(handedOver.GetKey()))
+
+	t.Run("should load state of rule that was taken over", func(t *testing.T) {
+		sharder.owned[handedOver.GetKey()] = true
+		tick = tick.Add(time.Second)
+		sch.processTick(ctx, dispatcherGroup, tick)
+		require.True(t, sch.registry.exists(handedOver.GetKey()))
+		require.Contains(t, instanceStore.RecordedOps, models.ListAlertInstancesQuery{RuleOrgID: handedOver.OrgID, RuleUID: handedOver.UID})
+	})
+
+	t.Run("should stop routine of rule that was handed over without deleting it", func(t *testing.T) {
+		info, err := sch.registry.get(owned.GetKey())
+		require.NoError(t, err)
+		sharder.owned[owned.GetKey()] = false
+		tick = tick.Add(time.Second)
+		_, stopped := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Empty(t, stopped)
+		require.False(t, sch.registry.exists(owned.GetKey()))
+		require.ErrorIs(t, info.ctx.Err(), errRuleHandedOver)
+		require.NotNil(t, sch.schedulableAlertRules.get(owned.GetKey()))
+		require.Contains(t, instanceStore.RecordedOps, models.ListAlertInstancesQuery{RuleOrgID: owned.OrgID, RuleUIDs: []string{owned.UID}})
+	})
+}
+
+func TestProcessTicksWithSharderAcrossReplicas(t *testing.T) {
+	ctx := context.Background()
+	ruleStore := newFakeRulesStore()
+	instanceStore := newMemoryInstanceStore()
+
+	upstream := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Hour), models.WithFor(0))()
+	dependent := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Hour), models.WithDependsOn(upstream.UID))()
+	ruleStore.PutRule(ctx, upstream, dependent)
+
+	// replica1 evaluates the upstream rule and replica2 the rule that depends on it
+	replica1 := setupReplica(t, ruleStore, instanceStore, upstream.GetKey())
+	replica2 := setupReplica(t, ruleStore, instanceStore, dependent.GetKey())
+
+	tick := time.Unix(1, 0)
+	replica1.processTick(ctx, new(errgroup.Group), tick)
+	replica2.processTick(ctx, new(errgroup.Group), tick)
+	require.Empty(t, replica2.firingUpstreamRule(dependent))
+
+	replica1.stateManager.ProcessEvalResults(ctx, tick, upstream, eval.Results{{
+		Instance:    data.Labels{"instance": "a"},
+		State:       eval.Alerting,
+		EvaluatedAt: tick,
+	}}, nil)
+
+	t.Run("should not read the state again before the refresh interval", func(t *testing.T) {
+		queries := instanceStore.queries
+		tick = tick.Add(time.Second)
+		replica2.processTick(ctx, new(errgroup.Group), tick)
+		require.Equal(t, queries, instanceStore.queries)
+		require.Empty(t, replica2.stateManager.GetStatesForRuleUID(upstream.OrgID, upstream.UID))
+	})
+
+	tick = tick.Add(notOwnedStateRefreshInterval)
+	replica1.processTick(ctx, new(errgroup.Group), tick)
+	replica2.processTick(ctx, new(errgroup.Group), tick)
+
+	t.Run("should read the state of rules evaluated by other replicas", func(t *testing.T) {
+		states := replica2.stateManager.GetStatesForRuleUID(upstream.OrgID, upstream.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Alerting, states[0].State)
+		require.Len(t, replica1.stateManager.GetStatesForRuleUID(upstream.OrgID, upstream.UID), 1)
+	})
+
+	t.Run("should skip rules whose upstream rule fires on another replica", func(t *testing.T) {
+		require.Equal(t, upstream.UID, replica2.firingUpstreamRule(dependent))
+	})
+
+	t.Run("should remove states that were deleted by the other replica", func(t *testing.T) {
+		replica1.stateManager.DeleteStateByRuleUID(ctx, upstream.GetKey(), models.StateReasonRuleDeleted)
+		tick = tick.Add(notOwnedStateRefreshInterval)
+		replica2.processTick(ctx, new(errgroup.Group), tick)
+		require.Empty(t, replica2.stateManager.GetStatesForRuleUID(upstream.OrgID, upstream.UID))
+		require.Empty(t, replica2.firingUpstreamRule(dependent))
+	})
+
+	t.Run("should remove the states of deleted rules evaluated by other replicas from the cache", func(t *testing.T) {
+		replica1.stateManager.ProcessEvalResults(ctx, tick, upstream, eval.Results{{
+			Instance:    data.Labels{"instance": "a"},
+			State:       eval.Alerting,
+			EvaluatedAt: tick,
+		}}, nil)
+		tick = tick.Add(notOwnedStateRefreshInterval)
+		replica2.processTick(ctx, new(errgroup.Group), tick)
+		require.Len(t, replica2.stateManager.GetStatesForRuleUID(upstream.OrgID, upstream.UID), 1)
+
+		ruleStore.DeleteRule(upstream)
+		tick = tick.Add(time.Second)
+		replica2.processTick(ctx, new(errgroup.Group), tick)
+		require.Empty(t, replica2.stateManager.GetStatesForRuleUID(upstream.OrgID, upstream.UID))
+	})
+}
+
+// setupReplica returns a scheduler that owns the given rules and stores the state in instanceStore.
+func setupReplica(t *testing.T, ruleStore *fakeRulesStore, instanceStore state.InstanceStore, owned ...models.AlertRuleKey) *schedule {
+	t.Helper()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+	sch.stateManager = state.NewManager(state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore: instanceStore,
+		Images:        &state.NoopImageService{},
+		Clock:         sch.clock,
+		Historian:     &state.FakeHistorian{},
+	})
+	sharder := &fakeSharder{owned: map[models.AlertRuleKey]bool{}}
+	for _, key := range owned {
+		sharder.owned[key] = true
+	}
+	sch.sharder = sharder
+	return sch
+}
+
+type fakeSharder struct {
+	owned map[models.AlertRuleKey]bool
+}
+
+func (f *fakeSharder) Owns(key models.AlertRuleKey) bool {
+	return f.owned[key]
+}
+
+// memoryInstanceStore is an instance store that keeps the alert instances in memory, so that they can be shared by
+// the state managers of several replicas.
+type memoryInstanceStore struct {
+	mtx       sync.Mutex
+	instances map[models.AlertInstanceKey]models.AlertInstance
+	queries   int
+}
+
+func newMemoryInstanceStore() *memoryInstanceStore {
+	return &memoryInstanceStore{instances: map[models.AlertInstanceKey]models.AlertInstance{}}
+}
+
+func (f *memoryInstanceStore) FetchOrgIds(_ context.Context) ([]int64, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	orgs := map[int64]struct{}{}
+	var result []int64
+	for key := range f.instances {
+		if _, ok := orgs[key.RuleOrgID]; !ok {
+			orgs[key.RuleOrgID] = struct{}{}
+			result = append(result, key.RuleOrgID)
+		}
+	}
+	return result, nil
+}
+
+func (f *memoryInstanceStore) ListAlertInstances(_ context.Context, q *models.ListAlertInstancesQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.queries++
+	q.Result = nil
+	uids := make(map[string]struct{}, len(q.RuleUIDs))
+	for _, uid := range q.RuleUIDs {
+		uids[uid] = struct{}{}
+	}
+	for key, instance := range f.instances {
+		if key.RuleOrgID != q.RuleOrgID || (q.RuleUID != "" && key.RuleUID != q.RuleUID) {
+			continue
+		}
+		if _, ok := uids[key.RuleUID]; len(uids) > 0 && !ok {
+			continue
+		}
+		instance := instance
+		q.Result = append(q.Result, &instance)
+	}
+	return nil
+}
+
+func (f *memoryInstanceStore) SaveAlertInstances(_ context.Context, instances ...models.AlertInstance) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, instance := range instances {
+		f.instances[instance.AlertInstanceKey] = instance
+	}
+	return nil
+}
+
+func (f *memoryInstanceStore) DeleteAlertInstances(_ context.Context, keys ...models.AlertInstanceKey) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, key := range keys {
+		delete(f.instances, key)
+	}
+	return nil
+}
+
+func (f *memoryInstanceStore) DeleteAlertInstancesByRule(_ context.Context, ruleKey models.AlertRuleKey) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for key := range f.instances {
+		if key.RuleOrgID == ruleKey.OrgID && key.RuleUID == ruleKey.UID {
+			delete(f.instances, key)
+		}
+	}
+	return nil
+}
+
+type fakeReplicaStore struct {
+	mtx        sync.Mutex
+	heartbeats map[string]time.Time
+}
+
+func newFakeReplicaStore() *fakeReplicaStore {
+	return &fakeReplicaStore{heartbeats: map[string]time.Time{}}
+}
+
+func (f *fakeReplicaStore) UpdateReplicaHeartbeat(_ context.Context, replicaID string, at time.Time) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.heartbeats[replicaID] = at
+	return nil
+}
+
+func (f *fakeReplicaStore) GetActiveReplicas(_ context.Context, since time.Time) ([]string, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var result []string
+	for id, at := range f.heartbeats {
+		if !at.Before(since) {
+			result = append(result, id)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (f *fakeReplicaStore) DeleteReplica(_ context.Context, replicaID string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	delete(f.heartbeats, replicaID)
+	return nil
+}
+
+func (f *fakeReplicaStore) DeleteInactiveReplicas(_ context.Context, before time.Time) (int64, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var deleted int64
+	for id, at := range f.heartbeats {
+		if at.Before(before) {
+			delete(f.heartbeats, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -2,9 +2,12 @@ package schedule
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
@@ -80,3 +83,22 @@ func (f *fakeRulesStore) DeleteRule(rules ...*models.AlertRule) {
 func (f *fakeRulesStore) getNamespaceTitle(uid string) string {
 	return "TEST-FOLDER-" + uid
 }
+
+type fakeRecordingWriter struct {
+	mtx     sync.Mutex
+	written []data.Frames
+	err     error
+}
+
+func (f *fakeRecordingWriter) Write(_ context.Context, _ *models.AlertRule, _ time.Time, frames data.Frames, _ map[string]string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.written = append(f.written, frames)
+	return f.err
+}
+
+func (f *fakeRecordingWriter) getWritten() []data.Frames {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return append([]data.Frames(nil), f.written...)
+}
@@ -0,0 +1,151 @@
+package state
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+const (
+	alertsMetricName         = "ALERTS"
+	alertsForStateMetricName = "ALERTS_FOR_STATE"
+
+	alertStateLabel = "alertstate"
+	orgIDLabel      = "grafana_org_id"
+	ruleUIDLabel    = "grafana_rule_uid"
+)
+
+var alertsSeriesDroppedDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(metrics.Namespace, metrics.Subsystem, "alerts_metrics_series_dropped"),
+	"The number of series of the ALERTS metric that were not exported because the organization exceeded the limit of series.",
+	[]string{"org"}, nil,
+)
+
+// alertsCollector exports the pending and firing alerts of the state cache like Prometheus exports the alerts of its
+// rules: ALERTS has the value 1 and the labels of the alert plus the alertstate label, ALERTS_FOR_STATE has the time
+// the alert became active as value. The series of every organization are limited to maxSeriesPerOrg, unless it is 0.
+type alertsCollector struct {
+	cache           *cache
+	maxSeriesPerOrg int
+}
+
+// NewAlertsCollector returns a collector of the ALERTS and ALERTS_FOR_STATE metrics of the alerts of the manager.
+func (st *Manager) NewAlertsCollector(maxSeriesPerOrg int) prometheus.Collector {
+	return &alertsCollector{cache: st.cache, maxSeriesPerOrg: maxSeriesPerOrg}
+}
+
+// Describe sends no descriptors because the labels of the ALERTS metrics depend on the alerts. This makes the
+// collector unchecked.
+func (c *alertsCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (c *alertsCollector) Collect(ch chan<- prometheus.Metric) {
+	// The metrics are sent after the cache is unlocked, so a slow scrape does not block the evaluations.
+	for _, m := range c.snapshot() {
+		ch <- m
+	}
+}
+
+// snapshot returns the metrics of the alerts that are in the cache at the moment.
+func (c *alertsCollector) snapshot() []prometheus.Metric {
+	c.cache.mtxStates.RLock()
+	defer c.cache.mtxStates.RUnlock()
+
+	var result []prometheus.Metric
+	for orgID, orgStates := range c.cache.states {
+		exported := make(map[string]struct{})
+		dropped := 0
+		for _, ruleUID := range sortedRuleUIDs(orgStates) {
+			rule := orgStates[ruleUID]
+			for _, cacheID := range sortedCacheIDs(rule) {
+				s := rule.states[cacheID]
+				alertState, ok := toAlertState(s.State)
+				if !ok {
+					continue
+				}
+				lbls := alertsMetricLabels(s)
+				key := lbls.String()
+				if _, ok := exported[key]; ok {
+					// Labels that are not valid in Prometheus are dropped, so different alerts can have the same labels.
+					continue
+				}
+				if c.maxSeriesPerOrg > 0 && len(exported) >= c.maxSeriesPerOrg {
+					dropped++
+					continue
+				}
+				forState, err := prometheus.NewConstMetric(
+					prometheus.NewDesc(alertsForStateMetricName, "The time at which the Grafana-managed alert became active.", nil, prometheus.Labels(lbls)),
+					prometheus.GaugeValue, float64(s.StartsAt.Unix()),
+				)
+				if err != nil {
+					// The values of the labels are not valid UTF-8.
+					continue
+				}
+				lbls[alertStateLabel] = alertState
+				alerts, err := prometheus.NewConstMetric(
+					prometheus.NewDesc(alertsMetricName, "Pending and firing Grafana-managed alerts.", nil, prometheus.Labels(lbls)),
+					prometheus.GaugeValue, 1,
+				)
+				if err != nil {
+					continue
+				}
+				exported[key] = struct{}{}
+				result = append(result, forState, alerts)
+			}
+		}
+		result = append(result, prometheus.MustNewConstMetric(alertsSeriesDroppedDesc, prometheus.GaugeValue, float64(dropped), strconv.FormatInt(orgID, 10)))
+	}
+	return result
+}
+
+// toAlertState returns the alertstate label of the alerts in the state, or false if the state is not an alert.
+// Alerts in the NoData and Error states are sent to the Alertmanager, so they are firing.
+func toAlertState(s eval.State) (string, bool) {
+	switch s {
+	case eval.Pending:
+		return "pending", true
+	case eval.Alerting, eval.NoData, eval.Error:
+		return "firing", true
+	default:
+		return "", false
+	}
+}
+
+// alertsMetricLabels returns the labels of the state that are valid in Prometheus, and the organization and the rule
+// of the state. Labels that start with __ are reserved in Prometheus.
+func alertsMetricLabels(s *State) data.Labels {
+	lbls := make(data.Labels, len(s.Labels)+2)
+	for k, v := range s.Labels {
+		if k == alertStateLabel || strings.HasPrefix(k, "__") || !model.LabelName(k).IsValid() {
+			continue
+		}
+		lbls[k] = v
+	}
+	lbls[orgIDLabel] = strconv.FormatInt(s.OrgID, 10)
+	lbls[ruleUIDLabel] = s.AlertRuleUID
+	return lbls
+}
+
+func sortedRuleUIDs(orgStates map[string]*ruleStates) []string {
+	uids := make([]string, 0, len(orgStates))
+	for uid := range orgStates {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+	return uids
+}
+
+func sortedCacheIDs(rule *ruleStates) []string {
+	ids := make([]string, 0, len(rule.states))
+	for id := range rule.states {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
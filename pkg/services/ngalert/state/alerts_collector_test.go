@@ -0,0 +1,138 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+func TestAlertsCollector(t *testing.T) {
+	startsAt := time.Unix(1000, 0)
+	newState := func(orgID int64, ruleUID string, s eval.State, lbls data.Labels) *State {
+		return &State{
+			OrgID:        orgID,
+			AlertRuleUID: ruleUID,
+			CacheID:      lbls.String(),
+			State:        s,
+			Labels:       lbls,
+			StartsAt:     startsAt,
+		}
+	}
+	gather := func(t *testing.T, c *cache, maxSeriesPerOrg int) map[string][]*dto.Metric {
+		t.Helper()
+		reg := prometheus.NewPedanticRegistry()
+		require.NoError(t, reg.Register(&alertsCollector{cache: c, maxSeriesPerOrg: maxSeriesPerOrg}))
+		families, err := reg.Gather()
+		require.NoError(t, err)
+		result := make(map[string][]*dto.Metric)
+		for _, f := range families {
+			result[f.GetName()] = f.GetMetric()
+		}
+		return result
+	}
+	labelsOf := func(m *dto.Metric) map[string]string {
+		result := make(map[string]string)
+		for _, l := range m.GetLabel() {
+			result[l.GetName()] = l.GetValue()
+		}
+		return result
+	}
+
+	t.Run("should export pending and firing alerts", func(t *testing.T) {
+		c := newCache()
+		c.set(newState(1, "rule-1", eval.Normal, data.Labels{"alertname": "normal"}))
+		c.set(newState(1, "rule-2", eval.Pending, data.Labels{"alertname": "pending", "__alert_rule_uid__": "rule-2"}))
+		c.set(newState(1, "rule-3", eval.Alerting, data.Labels{"alertname": "alerting", "invalid-label": "value"}))
+		c.set(newState(2, "rule-4", eval.NoData, data.Labels{"alertname": "nodata", "alertstate": "custom"}))
+
+		metrics := gather(t, c, 0)
+
+		require.Len(t, metrics[alertsMetricName], 3)
+		byName := make(map[string]map[string]string)
+		for _, m := range metrics[alertsMetricName] {
+			require.Equal(t, 1.0, m.GetGauge().GetValue())
+			lbls := labelsOf(m)
+			byName[lbls["alertname"]] = lbls
+		}
+		require.Equal(t, map[string]string{"alertname": "pending", "alertstate": "pending", "grafana_org_id": "1", "grafana_rule_uid": "rule-2"}, byName["pending"])
+		require.Equal(t, map[string]string{"alertname": "alerting", "alertstate": "firing", "grafana_org_id": "1", "grafana_rule_uid": "rule-3"}, byName["alerting"])
+		require.Equal(t, map[string]string{"alertname": "nodata", "alertstate": "firing", "grafana_org_id": "2", "grafana_rule_uid": "rule-4"}, byName["nodata"])
+
+		require.Len(t, metrics[alertsForStateMetricName], 3)
+		for _, m := range metrics[alertsForStateMetricName] {
+			require.Equal(t, float64(startsAt.Unix()), m.GetGauge().GetValue())
+			require.NotContains(t, labelsOf(m), "alertstate")
+		}
+	})
+
+	t.Run("should limit series per organization", func(t *testing.T) {
+		c := newCache()
+		for i := 0; i < 5; i++ {
+			c.set(newState(1, "rule-1", eval.Alerting, data.Labels{"instance": fmt.Sprintf("instance-%d", i)}))
+		}
+		c.set(newState(2, "rule-2", eval.Alerting, data.Labels{"instance": "instance"}))
+
+		metrics := gather(t, c, 3)
+
+		perOrg := make(map[string]int)
+		for _, m := range metrics[alertsMetricName] {
+			perOrg[labelsOf(m)["grafana_org_id"]]++
+		}
+		require.Equal(t, map[string]int{"1": 3, "2": 1}, perOrg)
+
+		dropped := make(map[string]float64)
+		for _, m := range metrics["grafana_alerting_alerts_metrics_series_dropped"] {
+			dropped[labelsOf(m)["org"]] = m.GetGauge().GetValue()
+		}
+		require.Equal(t, map[string]float64{"1": 2, "2": 0}, dropped)
+	})
+
+	t.Run("should export alerts with the same valid labels once", func(t *testing.T) {
+		c := newCache()
+		c.set(newState(1, "rule-1", eval.Alerting, data.Labels{"instance": "a", "invalid-1": "x"}))
+		c.set(newState(1, "rule-1", eval.Alerting, data.Labels{"instance": "a", "invalid-2": "y"}))
+
+		metrics := gather(t, c, 0)
+
+		require.Len(t, metrics[alertsMetricName], 1)
+	})
+
+	t.Run("should not lock the cache while sending metrics", func(t *testing.T) {
+		c := newCache()
+		c.set(newState(1, "rule-1", eval.Alerting, data.Labels{"instance": "a"}))
+
+		ch := make(chan prometheus.Metric)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			(&alertsCollector{cache: c}).Collect(ch)
+		}()
+		<-ch // the collector is blocked on sending the next metric
+
+		updated := make(chan struct{})
+		go func() {
+			c.set(newState(1, "rule-1", eval.Alerting, data.Labels{"instance": "b"}))
+			close(updated)
+		}()
+		select {
+		case <-updated:
+		case <-time.After(time.Second):
+			require.Fail(t, "the cache is locked while metrics are sent")
+		}
+
+		for {
+			select {
+			case <-ch:
+			case <-done:
+				return
+			}
+		}
+	})
+}
@@ -2,31 +2,269 @@ package historian
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 )
 
+const (
+	// defaultQueryLimit is the number of entries returned by a query without a limit, and maxQueryLimit is the largest limit of a query.
+	defaultQueryLimit = 1000
+	maxQueryLimit     = 5000
+	// maxQueryRange is the longest time range of a query. Longer ranges are shortened to end at the end of the query.
+	maxQueryRange = 30 * 24 * time.Hour
+	// queryBatchSize is the number of entries read at once from the store when the labels of the entries are matched.
+	queryBatchSize = 1000
+)
+
+type StateHistoryStore interface {
+	SaveStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error
+	GetStateHistory(ctx context.Context, query models.HistoryQuery) ([]models.StateHistoryEntry, error)
+}
+
+// SqlBackend is an implementation of state.Historian that uses the Grafana database as the backing datastore.
 type SqlBackend struct {
-	log log.Logger
+	store StateHistoryStore
+	log   log.Logger
 }
 
-func NewSqlBackend() *SqlBackend {
+func NewSqlBackend(store StateHistoryStore) *SqlBackend {
 	return &SqlBackend{
-		log: log.New("ngalert.state.historian"),
+		store: store,
+		log:   log.New("ngalert.state.historian", "backend", "sql"),
 	}
 }
 
-func (h *SqlBackend) RecordStatesAsync(ctx context.Context, _ history_model.RuleMeta, _ []state.StateTransition) <-chan error {
-	errCh := make(chan error)
-	close(errCh)
+// RecordStatesAsync writes a number of state transitions for a given rule to state history.
+func (h *SqlBackend) RecordStatesAsync(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	// Build entries before starting goroutine, to make sure all data is copied and won't mutate underneath us.
+	entries := statesToEntries(rule, states, logger)
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+	go func() {
+		defer close(errCh)
+		if err := h.store.SaveStateHistory(ctx, entries); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch", "entries", len(entries))
+	}()
 	return errCh
 }
 
+// QueryStates returns a page of the state transitions that match the query, ordered by time. The rule UID and
+// the time range are matched by the database, the other labels are matched while the entries are read in batches.
 func (h *SqlBackend) QueryStates(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
-	return data.NewFrame("states"), nil
+	logger := h.log.FromContext(ctx)
+
+	now := time.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	if query.To.Sub(query.From) > maxQueryRange {
+		query.From = query.To.Add(-maxQueryRange)
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultQueryLimit
+	}
+	if query.Limit > maxQueryLimit {
+		query.Limit = maxQueryLimit
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	matchers := make(map[string]string, len(query.Labels))
+	for k, v := range query.Labels {
+		matchers[k] = v
+	}
+	if ruleUID, ok := matchers[RuleUIDLabel]; ok && (query.RuleUID == "" || query.RuleUID == ruleUID) {
+		query.RuleUID = ruleUID
+		delete(matchers, RuleUIDLabel)
+	}
+
+	entries, err := h.queryEntries(ctx, query, matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	// The frame has the same shape as the one produced by the Loki backend, so that clients do not need to know which backend is used:
+	//   1. `time` - timestamp - when the transition happened
+	//   2. `line` - JSON - the full data of the transition
+	//   3. `labels` - JSON - the labels associated with that state transition
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+	for _, entry := range entries {
+		lbls := entryLabels(entry)
+		lblsJson, err := json.Marshal(lbls)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize entry labels: %w", err)
+		}
+		line, err := entryToLine(entry)
+		if err != nil {
+			logger.Error("Stored state history entry is in an invalid format, skipping", "id", entry.ID, "error", err)
+			continue
+		}
+		times = append(times, time.UnixMilli(entry.Epoch))
+		lines = append(lines, line)
+		labels = append(labels, lblsJson)
+	}
+
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+
+	return frame, nil
+}
+
+// queryEntries returns the page of the query of the entries that match the labels. Without labels to match,
+// the page is read from the store at once.
+func (h *SqlBackend) queryEntries(ctx context.Context, query models.HistoryQuery, matchers map[string]string) ([]models.StateHistoryEntry, error) {
+	if len(matchers) == 0 {
+		return h.store.GetStateHistory(ctx, query)
+	}
+
+	batch := query
+	batch.Limit = queryBatchSize
+	batch.Offset = 0
+	skip := query.Offset
+	result := make([]models.StateHistoryEntry, 0)
+	for {
+		entries, err := h.store.GetStateHistory(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !matchLabels(entryLabels(entry), matchers) {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			result = append(result, entry)
+			if len(result) == query.Limit {
+				return result, nil
+			}
+		}
+		if len(entries) < batch.Limit {
+			return result, nil
+		}
+		batch.Offset += batch.Limit
+	}
+}
+
+func statesToEntries(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []models.StateHistoryEntry {
+	entries := make([]models.StateHistoryEntry, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+
+		var values []byte
+		if blob := valuesAsDataBlob(state.State); blob != nil {
+			var err error
+			if values, err = blob.Encode(); err != nil {
+				logger.Error("Failed to serialize values of state, skipping", "error", err)
+				continue
+			}
+		}
+
+		entry := models.StateHistoryEntry{
+			OrgID:         rule.OrgID,
+			RuleUID:       rule.UID,
+			NamespaceUID:  rule.NamespaceUID,
+			RuleGroup:     rule.Group,
+			Labels:        removePrivateLabels(state.State.Labels),
+			PreviousState: state.PreviousFormatted(),
+			CurrentState:  state.Formatted(),
+			Values:        string(values),
+			DashboardUID:  rule.DashboardUID,
+			PanelID:       rule.PanelID,
+			Epoch:         state.State.LastEvaluationTime.UnixMilli(),
+		}
+		if state.State.State == eval.Error && state.Error != nil {
+			entry.Error = state.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// entryLabels returns the labels of the alert instance together with the labels that identify the rule.
+// These are the same labels the Loki backend attaches to its streams.
+func entryLabels(entry models.StateHistoryEntry) data.Labels {
+	lbls := make(data.Labels, len(entry.Labels)+5)
+	for k, v := range entry.Labels {
+		lbls[k] = v
+	}
+	lbls[StateHistoryLabelKey] = StateHistoryLabelValue
+	lbls[OrgIDLabel] = fmt.Sprint(entry.OrgID)
+	lbls[RuleUIDLabel] = entry.RuleUID
+	lbls[GroupLabel] = entry.RuleGroup
+	lbls[FolderUIDLabel] = entry.NamespaceUID
+	return lbls
+}
+
+// matchLabels returns true if all matchers are equal to the corresponding label.
+func matchLabels(lbls data.Labels, matchers map[string]string) bool {
+	for k, v := range matchers {
+		if lbls[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func entryToLine(entry models.StateHistoryEntry) (json.RawMessage, error) {
+	values := simplejson.New()
+	if entry.Values != "" {
+		var err error
+		if values, err = simplejson.NewJson([]byte(entry.Values)); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(lokiEntry{
+		SchemaVersion: 1,
+		Previous:      entry.PreviousState,
+		Current:       entry.CurrentState,
+		Error:         entry.Error,
+		Values:        values,
+		DashboardUID:  entry.DashboardUID,
+		PanelID:       entry.PanelID,
+	})
+}
+
+// DeleteExpiredService is a service to delete state history that is older than the configured retention period.
+type DeleteExpiredService struct {
+	store store.StateHistoryAdminStore
+}
+
+func (s *DeleteExpiredService) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.store.DeleteExpiredStateHistory(ctx)
+}
+
+func ProvideDeleteExpiredService(store *store.DBstore) *DeleteExpiredService {
+	return &DeleteExpiredService{store: store}
 }
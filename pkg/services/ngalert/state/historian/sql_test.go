@@ -0,0 +1,256 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestSqlBackend(t *testing.T) {
+	t.Run("statesToEntries", func(t *testing.T) {
+		t.Run("skips non-transitory states", func(t *testing.T) {
+			rule := createTestRule()
+			states := singleFromNormal(&state.State{State: eval.Normal})
+
+			res := statesToEntries(rule, states, log.NewNopLogger())
+
+			require.Empty(t, res)
+		})
+
+		t.Run("maps evaluation errors", func(t *testing.T) {
+			rule := createTestRule()
+			states := singleFromNormal(&state.State{State: eval.Error, Error: fmt.Errorf("oh no")})
+
+			res := statesToEntries(rule, states, log.NewNopLogger())
+
+			require.Len(t, res, 1)
+			require.Contains(t, res[0].Error, "oh no")
+		})
+
+		t.Run("maps rule and excludes private labels", func(t *testing.T) {
+			rule := createTestRule()
+			now := time.Now()
+			states := singleFromNormal(&state.State{
+				State:              eval.Alerting,
+				Labels:             data.Labels{"a": "b", "__private__": "c"},
+				Values:             map[string]float64{"A": 2.0},
+				LastEvaluationTime: now,
+			})
+
+			res := statesToEntries(rule, states, log.NewNopLogger())
+
+			require.Len(t, res, 1)
+			require.Equal(t, models.StateHistoryEntry{
+				OrgID:         rule.OrgID,
+				RuleUID:       rule.UID,
+				NamespaceUID:  rule.NamespaceUID,
+				RuleGroup:     rule.Group,
+				Labels:        map[string]string{"a": "b"},
+				PreviousState: "Normal",
+				CurrentState:  "Alerting",
+				Values:        `{"A":2}`,
+				DashboardUID:  rule.DashboardUID,
+				PanelID:       rule.PanelID,
+				Epoch:         now.UnixMilli(),
+			}, res[0])
+		})
+	})
+
+	t.Run("RecordStatesAsync saves entries to the store", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		sut := NewSqlBackend(store)
+		states := singleFromNormal(&state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}})
+
+		err := <-sut.RecordStatesAsync(context.Background(), createTestRule(), states)
+
+		require.NoError(t, err)
+		require.Len(t, store.entries, 1)
+	})
+
+	t.Run("RecordStatesAsync returns store errors", func(t *testing.T) {
+		store := &fakeStateHistoryStore{err: fmt.Errorf("oh no")}
+		sut := NewSqlBackend(store)
+		states := singleFromNormal(&state.State{State: eval.Alerting})
+
+		err := <-sut.RecordStatesAsync(context.Background(), createTestRule(), states)
+
+		require.ErrorContains(t, err, "oh no")
+	})
+
+	t.Run("QueryStates", func(t *testing.T) {
+		rule := createTestRule()
+		now := time.Now()
+		store := &fakeStateHistoryStore{}
+		sut := NewSqlBackend(store)
+		states := []state.StateTransition{
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}, LastEvaluationTime: now.Add(-2 * time.Second)},
+			},
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Pending, Labels: data.Labels{"c": "d"}, LastEvaluationTime: now.Add(-time.Second)},
+			},
+		}
+		require.NoError(t, <-sut.RecordStatesAsync(context.Background(), rule, states))
+
+		t.Run("returns all entries in the same shape as loki", func(t *testing.T) {
+			frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, RuleUID: rule.UID})
+			require.NoError(t, err)
+
+			require.Len(t, frame.Fields, 3)
+			require.Equal(t, dfTime, frame.Fields[0].Name)
+			require.Equal(t, dfLine, frame.Fields[1].Name)
+			require.Equal(t, dfLabels, frame.Fields[2].Name)
+			require.Equal(t, 2, frame.Rows())
+
+			var entry lokiEntry
+			require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+			require.Equal(t, "Normal", entry.Previous)
+			require.Equal(t, "Alerting", entry.Current)
+
+			var lbls map[string]string
+			require.NoError(t, json.Unmarshal(frame.Fields[2].At(0).(json.RawMessage), &lbls))
+			require.Equal(t, map[string]string{
+				StateHistoryLabelKey: StateHistoryLabelValue,
+				"folderUID":          rule.NamespaceUID,
+				"group":              rule.Group,
+				"orgID":              fmt.Sprint(rule.OrgID),
+				"ruleUID":            rule.UID,
+				"a":                  "b",
+			}, lbls)
+		})
+
+		t.Run("filters entries by labels", func(t *testing.T) {
+			frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, Labels: map[string]string{"c": "d"}})
+			require.NoError(t, err)
+
+			require.Equal(t, 1, frame.Rows())
+			var entry lokiEntry
+			require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+			require.Equal(t, "Pending", entry.Current)
+		})
+
+		t.Run("defaults the time range", func(t *testing.T) {
+			_, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID})
+			require.NoError(t, err)
+
+			require.False(t, store.lastQuery.From.IsZero())
+			require.False(t, store.lastQuery.To.IsZero())
+			require.Equal(t, defaultQueryRange, store.lastQuery.To.Sub(store.lastQuery.From))
+			require.Equal(t, defaultQueryLimit, store.lastQuery.Limit)
+		})
+
+		t.Run("caps the time range and the limit", func(t *testing.T) {
+			_, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, From: now.Add(-365 * 24 * time.Hour), To: now, Limit: 2 * maxQueryLimit})
+			require.NoError(t, err)
+
+			require.Equal(t, now, store.lastQuery.To)
+			require.Equal(t, maxQueryRange, store.lastQuery.To.Sub(store.lastQuery.From))
+			require.Equal(t, maxQueryLimit, store.lastQuery.Limit)
+		})
+
+		t.Run("matches the rule UID label in the store", func(t *testing.T) {
+			frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, Labels: map[string]string{RuleUIDLabel: rule.UID}})
+			require.NoError(t, err)
+
+			require.Equal(t, 2, frame.Rows())
+			require.Equal(t, rule.UID, store.lastQuery.RuleUID)
+		})
+
+		t.Run("returns a page of the entries", func(t *testing.T) {
+			frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, Limit: 1, Offset: 1})
+			require.NoError(t, err)
+
+			require.Equal(t, 1, frame.Rows())
+			var entry lokiEntry
+			require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+			require.Equal(t, "Pending", entry.Current)
+		})
+	})
+
+	t.Run("QueryStates reads entries in batches when matching labels", func(t *testing.T) {
+		rule := createTestRule()
+		now := time.Now()
+		store := &fakeStateHistoryStore{}
+		sut := NewSqlBackend(store)
+		states := make([]state.StateTransition, 0, 3*queryBatchSize)
+		for i := 0; i < 3*queryBatchSize; i++ {
+			states = append(states, state.StateTransition{
+				PreviousState: eval.Normal,
+				State: &state.State{
+					State:              eval.Alerting,
+					Labels:             data.Labels{"i": fmt.Sprint(i), "even": fmt.Sprint(i%2 == 0)},
+					LastEvaluationTime: now.Add(time.Duration(i-3*queryBatchSize) * time.Millisecond),
+				},
+			})
+		}
+		require.NoError(t, <-sut.RecordStatesAsync(context.Background(), rule, states))
+
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, Labels: map[string]string{"even": "true"}, Limit: 3, Offset: queryBatchSize})
+		require.NoError(t, err)
+
+		require.Equal(t, 3, frame.Rows())
+		for i, want := range []int{2 * queryBatchSize, 2*queryBatchSize + 2, 2*queryBatchSize + 4} {
+			var lbls map[string]string
+			require.NoError(t, json.Unmarshal(frame.Fields[2].At(i).(json.RawMessage), &lbls))
+			require.Equal(t, fmt.Sprint(want), lbls["i"])
+		}
+		require.Equal(t, queryBatchSize, store.lastQuery.Limit)
+		require.Equal(t, 2*queryBatchSize, store.lastQuery.Offset)
+	})
+}
+
+type fakeStateHistoryStore struct {
+	mtx       sync.Mutex
+	entries   []models.StateHistoryEntry
+	lastQuery models.HistoryQuery
+	err       error
+}
+
+func (f *fakeStateHistoryStore) SaveStateHistory(_ context.Context, entries []models.StateHistoryEntry) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.entries = append(f.entries, entries...)
+	return nil
+}
+
+func (f *fakeStateHistoryStore) GetStateHistory(_ context.Context, query models.HistoryQuery) ([]models.StateHistoryEntry, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.lastQuery = query
+	result := make([]models.StateHistoryEntry, 0, len(f.entries))
+	for _, e := range f.entries {
+		if e.OrgID != query.OrgID || (query.RuleUID != "" && e.RuleUID != query.RuleUID) {
+			continue
+		}
+		if (!query.From.IsZero() && e.Epoch < query.From.UnixMilli()) || (!query.To.IsZero() && e.Epoch > query.To.UnixMilli()) {
+			continue
+		}
+		result = append(result, e)
+	}
+	if query.Limit > 0 {
+		if query.Offset >= len(result) {
+			return nil, nil
+		}
+		result = result[query.Offset:]
+		if len(result) > query.Limit {
+			result = result[:query.Limit]
+		}
+	}
+	return result, nil
+}
@@ -2,12 +2,14 @@ package state
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"time"
 
 	"github.com/benbjohnson/clock"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
@@ -40,6 +42,11 @@ type Manager struct {
 	externalURL   *url.URL
 
 	doNotSaveNormalState bool
+
+	imageStore              ImageStore
+	imageRetention          time.Duration
+	doNotTakeImageOnResolve bool
+	repeatImageInterval     time.Duration
 }
 
 type ManagerCfg struct {
@@ -51,6 +58,17 @@ type ManagerCfg struct {
 	Historian     Historian
 	// DoNotSaveNormalState controls whether eval.Normal state is persisted to the database and returned by get methods
 	DoNotSaveNormalState bool
+
+	// ImageStore is used to keep the images of active alerts until the alerts are resolved. If nil, images expire
+	// regardless of the alerts.
+	ImageStore ImageStore
+	// ImageRetention is how long images are kept once the alerts that use them are resolved.
+	ImageRetention time.Duration
+	// DoNotTakeImageOnResolve controls whether an image is taken when an alert is resolved
+	DoNotTakeImageOnResolve bool
+	// RepeatImageInterval is the age of the image of a firing alert after which a new image is taken, so that
+	// repeated notifications show the current state. Zero disables it.
+	RepeatImageInterval time.Duration
 }
 
 func NewManager(cfg ManagerCfg) *Manager {
@@ -65,6 +83,11 @@ func NewManager(cfg ManagerCfg) *Manager {
 		clock:                cfg.Clock,
 		externalURL:          cfg.ExternalURL,
 		doNotSaveNormalState: cfg.DoNotSaveNormalState,
+
+		imageStore:              cfg.ImageStore,
+		imageRetention:          cfg.ImageRetention,
+		doNotTakeImageOnResolve: cfg.DoNotTakeImageOnResolve,
+		repeatImageInterval:     cfg.RepeatImageInterval,
 	}
 }
 
@@ -136,24 +159,8 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("Error getting cacheId for entry", "error", err)
-			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-			}
+			s := st.stateFromInstance(entry, ruleForEntry)
+			rulesStates.states[s.CacheID] = s
 			statesCount++
 		}
 	}
@@ -161,6 +168,125 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// LoadStateByRule replaces the states of the rule in the cache with the states stored in the database. It is used
+// when another replica hands the evaluation of the rule over to this one.
+func (st *Manager) LoadStateByRule(ctx context.Context, rule *ngModels.AlertRule) error {
+	if st.instanceStore == nil {
+		return nil
+	}
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	}
+	if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
+		return fmt.Errorf("failed to fetch state of the rule: %w", err)
+	}
+	rs := &ruleStates{states: make(map[string]*State, len(cmd.Result))}
+	for _, entry := range cmd.Result {
+		s := st.stateFromInstance(entry, rule)
+		rs.states[s.CacheID] = s
+	}
+	st.cache.setRuleStates(rule.GetKey(), rs)
+	return nil
+}
+
+// refreshBatchSize is the maximum number of rules whose states are fetched by a single query.
+const refreshBatchSize = 100
+
+// RefreshStateByRules replaces the states of the rules in the cache with the states stored in the database. It is used
+// for the rules evaluated by other replicas, so that every replica can serve the state of all rules. The states are
+// fetched by queries restricted to the UIDs of the rules, in batches of rules of the same organization.
+func (st *Manager) RefreshStateByRules(ctx context.Context, rules []*ngModels.AlertRule) error {
+	if st.instanceStore == nil || len(rules) == 0 {
+		return nil
+	}
+	byOrg := make(map[int64][]*ngModels.AlertRule)
+	for _, rule := range rules {
+		byOrg[rule.OrgID] = append(byOrg[rule.OrgID], rule)
+	}
+	for orgID, orgRules := range byOrg {
+		for start := 0; start < len(orgRules); start += refreshBatchSize {
+			end := start + refreshBatchSize
+			if end > len(orgRules) {
+				end = len(orgRules)
+			}
+			if err := st.refreshStateByRules(ctx, orgID, orgRules[start:end]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (st *Manager) refreshStateByRules(ctx context.Context, orgID int64, rules []*ngModels.AlertRule) error {
+	byUID := make(map[string]*ngModels.AlertRule, len(rules))
+	cmd := ngModels.ListAlertInstancesQuery{RuleOrgID: orgID, RuleUIDs: make([]string, 0, len(rules))}
+	for _, rule := range rules {
+		byUID[rule.UID] = rule
+		cmd.RuleUIDs = append(cmd.RuleUIDs, rule.UID)
+	}
+	if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
+		return fmt.Errorf("failed to fetch state of the rules of organization %d: %w", orgID, err)
+	}
+	states := make(map[string]*ruleStates, len(rules))
+	for uid := range byUID {
+		states[uid] = &ruleStates{states: make(map[string]*State)}
+	}
+	for _, entry := range cmd.Result {
+		rs, ok := states[entry.RuleUID]
+		if !ok {
+			continue
+		}
+		s := st.stateFromInstance(entry, byUID[entry.RuleUID])
+		rs.states[s.CacheID] = s
+	}
+	for uid, rs := range states {
+		st.cache.setRuleStates(byUID[uid].GetKey(), rs)
+	}
+	return nil
+}
+
+// ForgetStateByRuleUID removes the states of the rule from the cache but, unlike DeleteStateByRuleUID, does not
+// delete them from the database or resolve them. It is used for rules that were deleted while another replica
+// evaluated them.
+func (st *Manager) ForgetStateByRuleUID(ruleKey ngModels.AlertRuleKey) {
+	st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, rule *ngModels.AlertRule) *State {
+	cacheID, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("Error getting cacheId for entry", "error", err)
+	}
+	var resultFp expr.Fingerprint
+	if entry.ResultFingerprint != "" {
+		resultFp, err = expr.ParseFingerprint(entry.ResultFingerprint)
+		if err != nil {
+			st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
+		}
+	}
+	// The time is read from Unix seconds, 0 means that the alert is not kept firing.
+	var keepFiringSince time.Time
+	if entry.KeepFiringSince.Unix() > 0 {
+		keepFiringSince = entry.KeepFiringSince
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              cacheID,
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          rule.Annotations,
+		ResultFingerprint:    resultFp,
+		KeepFiringSince:      keepFiringSince,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID, stateId string) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -301,11 +427,18 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.StateReason = result.State.String()
 	}
 
+	// The alert is still firing although its condition is no longer met because of the rule's KeepFiringFor
+	if !currentState.KeepFiringSince.IsZero() {
+		currentState.StateReason = ngModels.StateReasonKeepFiring
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
 
-	if shouldTakeImage(currentState.State, oldState, currentState.Image, currentState.Resolved) {
+	resolved := currentState.Resolved && !st.doNotTakeImageOnResolve
+	if shouldTakeImage(currentState.State, oldState, currentState.Image, resolved) ||
+		shouldRetakeImage(currentState.State, currentState.Image, st.repeatImageInterval, st.clock.Now()) {
 		image, err := takeImage(ctx, st.images, alertRule)
 		if err != nil {
 			logger.Warn("Failed to take an image",
@@ -316,6 +449,7 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 			currentState.Image = image
 		}
 	}
+	st.retainImage(ctx, currentState, logger)
 
 	st.cache.set(currentState)
 
@@ -336,6 +470,25 @@ func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*Stat
 	return st.cache.getStatesForRuleUID(orgID, alertRuleUID, st.doNotSaveNormalState)
 }
 
+// AlertingResultsFromRuleState implements eval.AlertingResultsReader. It returns the fingerprints of the results
+// of the rule whose states are alerting.
+type AlertingResultsFromRuleState struct {
+	Manager *Manager
+	Rule    *ngModels.AlertRule
+}
+
+// Read returns the fingerprints of the results of the alerting states of the rule.
+func (a *AlertingResultsFromRuleState) Read() expr.Fingerprints {
+	states := a.Manager.GetStatesForRuleUID(a.Rule.OrgID, a.Rule.UID)
+	result := make(expr.Fingerprints)
+	for _, s := range states {
+		if s.State == eval.Alerting {
+			result[s.ResultFingerprint] = struct{}{}
+		}
+	}
+	return result
+}
+
 func (st *Manager) Put(states []*State) {
 	for _, s := range states {
 		st.cache.set(s)
@@ -370,6 +523,8 @@ func (st *Manager) saveAlertStates(ctx context.Context, logger log.Logger, state
 			LastEvalTime:      s.LastEvaluationTime,
 			CurrentStateSince: s.StartsAt,
 			CurrentStateEnd:   s.EndsAt,
+			ResultFingerprint: s.ResultFingerprint.String(),
+			KeepFiringSince:   s.KeepFiringSince,
 		}
 		instances = append(instances, fields)
 	}
@@ -454,7 +609,7 @@ func (st *Manager) deleteStaleStatesFromCache(ctx context.Context, logger log.Lo
 		if oldState == eval.Alerting {
 			s.Resolved = true
 			// If there is no resolved image for this rule then take one
-			if resolvedImage == nil {
+			if resolvedImage == nil && !st.doNotTakeImageOnResolve {
 				image, err := takeImage(ctx, st.images, alertRule)
 				if err != nil {
 					logger.Warn("Failed to take an image",
@@ -478,6 +633,27 @@ func (st *Manager) deleteStaleStatesFromCache(ctx context.Context, logger log.Lo
 	return resolvedStates
 }
 
+// retainImage extends the expiration of the image of an active alert, so that the image is kept until the alert is
+// resolved. The image expires at least imageRetention after the alert is resolved. The expiration is extended to
+// twice the retention so that the image is saved at most once per retention.
+func (st *Manager) retainImage(ctx context.Context, s *State, logger log.Logger) {
+	if st.imageStore == nil || st.imageRetention <= 0 || s.Image == nil || s.Image.ID == 0 || s.State == eval.Normal {
+		return
+	}
+	now := st.clock.Now()
+	if s.Image.ExpiresAt.After(now.Add(st.imageRetention)) {
+		return
+	}
+	// The image can be shared with other states.
+	image := *s.Image
+	image.ExpiresAt = now.Add(2 * st.imageRetention)
+	if err := st.imageStore.SaveImage(ctx, &image); err != nil {
+		logger.Warn("Failed to extend the expiration of the image", "token", image.Token, "error", err)
+		return
+	}
+	s.Image = &image
+}
+
 func stateIsStale(evaluatedAt time.Time, lastEval time.Time, intervalSeconds int64) bool {
 	return !lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).After(evaluatedAt)
 }
@@ -7,11 +7,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/log/logtest"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -147,3 +151,100 @@ func TestManager_saveAlertStates(t *testing.T) {
 		}
 	})
 }
+
+type fakeImageCapturer struct {
+	clock  clock.Clock
+	called int
+}
+
+func (c *fakeImageCapturer) NewImage(_ context.Context, _ *ngmodels.AlertRule) (*ngmodels.Image, error) {
+	c.called++
+	return &ngmodels.Image{
+		ID:        int64(c.called),
+		Token:     fmt.Sprint(c.called),
+		CreatedAt: c.clock.Now(),
+		ExpiresAt: c.clock.Now().Add(time.Hour),
+	}, nil
+}
+
+type fakeImageStore struct {
+	saved []ngmodels.Image
+}
+
+func (s *fakeImageStore) SaveImage(_ context.Context, img *ngmodels.Image) error {
+	s.saved = append(s.saved, *img)
+	return nil
+}
+
+func TestManager_images(t *testing.T) {
+	rule := ngmodels.AlertRuleGen(ngmodels.WithFor(0))()
+	process := func(st *Manager, clk *clock.Mock, s eval.State) {
+		st.ProcessEvalResults(context.Background(), clk.Now(), rule, eval.Results{{
+			Instance:    data.Labels{"instance": "a"},
+			State:       s,
+			EvaluatedAt: clk.Now(),
+		}}, nil)
+	}
+	newManager := func(cfg ManagerCfg) (*Manager, *clock.Mock, *fakeImageCapturer) {
+		clk := clock.NewMock()
+		images := &fakeImageCapturer{clock: clk}
+		cfg.Metrics = metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics()
+		cfg.Images = images
+		cfg.Clock = clk
+		return NewManager(cfg), clk, images
+	}
+
+	t.Run("should take image when alert is resolved", func(t *testing.T) {
+		st, clk, images := newManager(ManagerCfg{})
+		process(st, clk, eval.Alerting)
+		require.Equal(t, 1, images.called)
+		clk.Add(10 * time.Second)
+		process(st, clk, eval.Normal)
+		require.Equal(t, 2, images.called)
+	})
+
+	t.Run("should not take image when alert is resolved if DoNotTakeImageOnResolve is true", func(t *testing.T) {
+		st, clk, images := newManager(ManagerCfg{DoNotTakeImageOnResolve: true})
+		process(st, clk, eval.Alerting)
+		require.Equal(t, 1, images.called)
+		clk.Add(10 * time.Second)
+		process(st, clk, eval.Normal)
+		require.Equal(t, 1, images.called)
+	})
+
+	t.Run("should take new image of firing alert after RepeatImageInterval", func(t *testing.T) {
+		st, clk, images := newManager(ManagerCfg{RepeatImageInterval: time.Minute})
+		process(st, clk, eval.Alerting)
+		require.Equal(t, 1, images.called)
+		clk.Add(30 * time.Second)
+		process(st, clk, eval.Alerting)
+		require.Equal(t, 1, images.called)
+		clk.Add(30 * time.Second)
+		process(st, clk, eval.Alerting)
+		require.Equal(t, 2, images.called)
+		require.Equal(t, "2", st.GetStatesForRuleUID(rule.OrgID, rule.UID)[0].Image.Token)
+	})
+
+	t.Run("should extend expiration of image of firing alert", func(t *testing.T) {
+		store := &fakeImageStore{}
+		st, clk, _ := newManager(ManagerCfg{ImageStore: store, ImageRetention: time.Hour})
+		start := clk.Now()
+		process(st, clk, eval.Alerting)
+		require.Len(t, store.saved, 1)
+		require.Equal(t, start.Add(2*time.Hour), store.saved[0].ExpiresAt)
+
+		clk.Add(30 * time.Minute)
+		process(st, clk, eval.Alerting)
+		require.Len(t, store.saved, 1)
+
+		clk.Add(31 * time.Minute)
+		process(st, clk, eval.Alerting)
+		require.Len(t, store.saved, 2)
+		require.Equal(t, clk.Now().Add(2*time.Hour), store.saved[1].ExpiresAt)
+
+		// the images of resolved alerts are not extended
+		clk.Add(2 * time.Hour)
+		process(st, clk, eval.Normal)
+		require.Len(t, store.saved, 2)
+	})
+}
@@ -61,12 +61,14 @@ func TestWarmStateCache(t *testing.T) {
 			CacheID:      `[["test2","testValue2"]]`,
 			Labels:       data.Labels{"test2": "testValue2"},
 			State:        eval.Alerting,
+			StateReason:  models.StateReasonKeepFiring,
 			Results: []state.Evaluation{
 				{EvaluationTime: evaluationTime, EvaluationState: eval.Alerting},
 			},
 			StartsAt:           evaluationTime.Add(-1 * time.Minute),
 			EndsAt:             evaluationTime.Add(1 * time.Minute),
 			LastEvaluationTime: evaluationTime,
+			KeepFiringSince:    evaluationTime.Add(-30 * time.Second),
 			Annotations:        map[string]string{"testAnnoKey": "testAnnoValue"},
 		},
 		{
@@ -137,9 +139,11 @@ func TestWarmStateCache(t *testing.T) {
 			LabelsHash: hash,
 		},
 		CurrentState:      models.InstanceStateFiring,
+		CurrentReason:     models.StateReasonKeepFiring,
 		LastEvalTime:      evaluationTime,
 		CurrentStateSince: evaluationTime.Add(-1 * time.Minute),
 		CurrentStateEnd:   evaluationTime.Add(1 * time.Minute),
+		KeepFiringSince:   evaluationTime.Add(-30 * time.Second),
 		Labels:            labels,
 	}
 
@@ -2232,6 +2236,9 @@ func TestProcessEvalResults(t *testing.T) {
 			assert.Len(t, states, len(tc.expectedStates))
 
 			for _, s := range tc.expectedStates {
+				if s.ResultFingerprint == 0 {
+					s.ResultFingerprint = expectedResultFingerprint(tc.evalResults, s.Labels)
+				}
 				cachedState := st.Get(s.OrgID, s.AlertRuleUID, s.CacheID)
 				assert.Equal(t, s, cachedState)
 			}
@@ -2275,6 +2282,45 @@ func TestProcessEvalResults(t *testing.T) {
 			require.Contains(t, savedStates, s.CacheID)
 		}
 	})
+
+	t.Run("should keep alert firing for KeepFiringFor", func(t *testing.T) {
+		cfg := state.ManagerCfg{
+			Metrics:       testMetrics.GetStateMetrics(),
+			ExternalURL:   nil,
+			InstanceStore: &state.FakeInstanceStore{},
+			Images:        &state.NotAvailableImageService{},
+			Clock:         clock.New(),
+			Historian:     &state.FakeHistorian{},
+		}
+		st := state.NewManager(cfg)
+		rule := models.AlertRuleGen(models.WithFor(0), models.WithKeepFiringFor(time.Minute), models.WithInterval(10*time.Second))()
+		labels := data.Labels{"instance": "test"}
+		type transitionSummary struct {
+			state    eval.State
+			reason   string
+			resolved bool
+		}
+		process := func(evaluatedAt time.Time, s eval.State) transitionSummary {
+			results := eval.Results{eval.ResultGen(eval.WithEvaluatedAt(evaluatedAt), eval.WithState(s), eval.WithLabels(labels))()}
+			transitions := st.ProcessEvalResults(context.Background(), evaluatedAt, rule, results, make(data.Labels))
+			require.Len(t, transitions, 1)
+			return transitionSummary{
+				state:    transitions[0].State.State,
+				reason:   transitions[0].StateReason,
+				resolved: transitions[0].Resolved,
+			}
+		}
+
+		require.Equal(t, transitionSummary{state: eval.Alerting}, process(evaluationTime, eval.Alerting))
+		// the condition is no longer met but the alert is kept firing
+		require.Equal(t, transitionSummary{state: eval.Alerting, reason: models.StateReasonKeepFiring}, process(evaluationTime.Add(10*time.Second), eval.Normal))
+		require.Equal(t, transitionSummary{state: eval.Alerting, reason: models.StateReasonKeepFiring}, process(evaluationTime.Add(50*time.Second), eval.Normal))
+		// the condition is met again, so the alert keeps firing without the reason
+		require.Equal(t, transitionSummary{state: eval.Alerting}, process(evaluationTime.Add(60*time.Second), eval.Alerting))
+		require.Equal(t, transitionSummary{state: eval.Alerting, reason: models.StateReasonKeepFiring}, process(evaluationTime.Add(70*time.Second), eval.Normal))
+		// KeepFiringFor has elapsed since the condition stopped being met
+		require.Equal(t, transitionSummary{state: eval.Normal, resolved: true}, process(evaluationTime.Add(130*time.Second), eval.Normal))
+	})
 }
 
 func printAllAnnotations(annos map[int64]annotations.Item) string {
@@ -2287,6 +2333,31 @@ func printAllAnnotations(annos map[int64]annotations.Item) string {
 	return str
 }
 
+// expectedResultFingerprint returns the fingerprint of the labels of the most specific result instance
+// that the state labels contain.
+func expectedResultFingerprint(results []eval.Results, stateLabels data.Labels) expr.Fingerprint {
+	var instance data.Labels
+	found := false
+	for _, res := range results {
+		for _, r := range res {
+			if found && len(r.Instance) <= len(instance) {
+				continue
+			}
+			matches := true
+			for k, v := range r.Instance {
+				if stateLabels[k] != v {
+					matches = false
+					break
+				}
+			}
+			if matches {
+				instance, found = r.Instance, true
+			}
+		}
+	}
+	return expr.FingerprintOf(instance)
+}
+
 func TestStaleResultsHandler(t *testing.T) {
 	evaluationTime := time.Now()
 	interval := time.Minute
@@ -2360,8 +2431,9 @@ func TestStaleResultsHandler(t *testing.T) {
 						"alertname":                    rule.Title,
 						"test1":                        "testValue1",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					Values:            make(map[string]float64),
+					ResultFingerprint: expr.FingerprintOf(data.Labels{"test1": "testValue1"}),
+					State:             eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
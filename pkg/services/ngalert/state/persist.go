@@ -35,3 +35,8 @@ type Historian interface {
 type ImageCapturer interface {
 	NewImage(ctx context.Context, r *models.AlertRule) (*models.Image, error)
 }
+
+// ImageStore saves images. It is used to extend the expiration of the images of active alerts.
+type ImageStore interface {
+	SaveImage(ctx context.Context, img *models.Image) error
+}
@@ -58,6 +58,15 @@ type State struct {
 	// conditions.
 	Values map[string]float64
 
+	// ResultFingerprint is the fingerprint of the labels of the evaluation result, that is the labels
+	// without the labels of the rule. It is used to find the state of the dimensions of the result
+	// in the next evaluation.
+	ResultFingerprint expr.Fingerprint
+
+	// KeepFiringSince is the time the condition of a firing alert stopped being met while the rule keeps
+	// the alert firing. It is zero if the alert is not being kept firing.
+	KeepFiringSince time.Time
+
 	StartsAt             time.Time
 	EndsAt               time.Time
 	LastSentAt           time.Time
@@ -89,6 +98,7 @@ func (a *State) SetAlerting(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = time.Time{}
 }
 
 // SetPending the state to Pending. It changes both the start and end time.
@@ -98,6 +108,7 @@ func (a *State) SetPending(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = time.Time{}
 }
 
 // SetNoData sets the state to NoData. It changes both the start and end time.
@@ -107,15 +118,20 @@ func (a *State) SetNoData(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = time.Time{}
 }
 
 // SetError sets the state to Error. It changes both the start and end time.
 func (a *State) SetError(err error, startsAt, endsAt time.Time) {
 	a.State = eval.Error
 	a.StateReason = models.StateReasonError
+	if errors.Is(err, eval.ErrSeriesLimitExceeded) {
+		a.StateReason = models.StateReasonSeriesLimitExceeded
+	}
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = err
+	a.KeepFiringSince = time.Time{}
 }
 
 // SetNormal sets the state to Normal. It changes both the start and end time.
@@ -125,6 +141,7 @@ func (a *State) SetNormal(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = time.Time{}
 }
 
 // Resolve sets the State to Normal. It updates the StateReason, the end time, and sets Resolved to true.
@@ -184,14 +201,25 @@ func NewEvaluationValues(m map[string]eval.NumberValueCapture) map[string]*float
 	return result
 }
 
-func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger log.Logger) {
+func resultNormal(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger) {
 	if state.State == eval.Normal {
 		logger.Debug("Keeping state", "state", state.State)
-	} else {
-		logger.Debug("Changing state", "previous_state", state.State, "next_state", eval.Normal)
-		// Normal states have the same start and end timestamps
-		state.SetNormal("", result.EvaluatedAt, result.EvaluatedAt)
+		return
 	}
+	if state.State == eval.Alerting && rule.KeepFiringFor > 0 {
+		// If the alert rule has a KeepFiringFor duration then the alert is resolved only after it has been observed
+		if state.KeepFiringSince.IsZero() {
+			state.KeepFiringSince = result.EvaluatedAt
+		}
+		if result.EvaluatedAt.Sub(state.KeepFiringSince) < rule.KeepFiringFor {
+			logger.Debug("Keeping state firing", "state", state.State, "keep_firing_since", state.KeepFiringSince)
+			state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
+			return
+		}
+	}
+	logger.Debug("Changing state", "previous_state", state.State, "next_state", eval.Normal)
+	// Normal states have the same start and end timestamps
+	state.SetNormal("", result.EvaluatedAt, result.EvaluatedAt)
 }
 
 func resultAlerting(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger) {
@@ -199,6 +227,8 @@ func resultAlerting(state *State, rule *models.AlertRule, result eval.Result, lo
 	case eval.Alerting:
 		logger.Debug("Keeping state", "state", state.State)
 		state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
+		// The condition is met again so the alert is no longer kept firing
+		state.KeepFiringSince = time.Time{}
 	case eval.Pending:
 		// If the previous state is Pending then check if the For duration has been observed
 		if result.EvaluatedAt.Sub(state.StartsAt) >= rule.For {
@@ -217,7 +247,13 @@ func resultAlerting(state *State, rule *models.AlertRule, result eval.Result, lo
 	}
 }
 func resultError(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger) {
-	switch rule.ExecErrState {
+	execErrState := rule.ExecErrState
+	if errors.Is(result.Error, eval.ErrSeriesLimitExceeded) {
+		// the results of the rule are incomplete, so it goes to Error regardless of its execution error state
+		// instead of firing or resolving all of its alerts.
+		execErrState = models.ErrorErrState
+	}
+	switch execErrState {
 	case models.AlertingErrState:
 		logger.Debug("Execution error state is Alerting", "handler", "resultAlerting", "previous_handler", "resultError")
 		resultAlerting(state, rule, result, logger)
@@ -261,6 +297,7 @@ func resultError(state *State, rule *models.AlertRule, result eval.Result, logge
 
 func resultNoData(state *State, rule *models.AlertRule, result eval.Result, _ log.Logger) {
 	state.Error = result.Error
+	state.KeepFiringSince = time.Time{}
 
 	if state.StartsAt.IsZero() {
 		state.StartsAt = result.EvaluatedAt
@@ -367,6 +404,12 @@ func shouldTakeImage(state, previousState eval.State, previousImage *models.Imag
 		state == eval.Alerting && previousImage == nil
 }
 
+// shouldRetakeImage returns true if the state is alerting and its image is older than interval, so that the
+// notifications that are repeated while the alert is firing show a recent image. It returns false if interval is 0.
+func shouldRetakeImage(state eval.State, image *models.Image, interval time.Duration, now time.Time) bool {
+	return interval > 0 && state == eval.Alerting && image != nil && !image.CreatedAt.Add(interval).After(now)
+}
+
 // takeImage takes an image for the alert rule. It returns nil if screenshots are disabled or
 // the rule is not associated with a dashboard panel.
 func takeImage(ctx context.Context, s ImageCapturer, r *models.AlertRule) (*models.Image, error) {
@@ -235,6 +235,18 @@ func TestSetError(t *testing.T) {
 			StartsAt:    mock.Now(),
 			EndsAt:      mock.Now().Add(time.Minute),
 		},
+	}, {
+		name:     "reason is set when series limit is exceeded",
+		startsAt: mock.Now(),
+		endsAt:   mock.Now().Add(time.Minute),
+		error:    eval.ErrSeriesLimitExceeded,
+		expected: State{
+			State:       eval.Error,
+			StateReason: ngmodels.StateReasonSeriesLimitExceeded,
+			Error:       eval.ErrSeriesLimitExceeded,
+			StartsAt:    mock.Now(),
+			EndsAt:      mock.Now().Add(time.Minute),
+		},
 	}}
 
 	for _, test := range tests {
@@ -594,6 +606,47 @@ func TestShouldTakeImage(t *testing.T) {
 	}
 }
 
+func TestShouldRetakeImage(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		state    eval.State
+		image    *ngmodels.Image
+		interval time.Duration
+		expected bool
+	}{{
+		name:     "should take image for alerting state with image older than interval",
+		state:    eval.Alerting,
+		image:    &ngmodels.Image{CreatedAt: now.Add(-time.Hour)},
+		interval: time.Hour,
+		expected: true,
+	}, {
+		name:     "should not take image for alerting state with image newer than interval",
+		state:    eval.Alerting,
+		image:    &ngmodels.Image{CreatedAt: now.Add(-time.Minute)},
+		interval: time.Hour,
+	}, {
+		name:  "should not take image if interval is zero",
+		state: eval.Alerting,
+		image: &ngmodels.Image{CreatedAt: now.Add(-time.Hour)},
+	}, {
+		name:     "should not take image for alerting state without image",
+		state:    eval.Alerting,
+		interval: time.Hour,
+	}, {
+		name:     "should not take image for pending state",
+		state:    eval.Pending,
+		image:    &ngmodels.Image{CreatedAt: now.Add(-time.Hour)},
+		interval: time.Hour,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, shouldRetakeImage(test.state, test.image, test.interval, now))
+		})
+	}
+}
+
 func TestTakeImage(t *testing.T) {
 	t.Run("ErrNoDashboard should return nil", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -134,22 +134,29 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 			}
 			newRules = append(newRules, r)
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleUID:          r.UID,
-				RuleOrgID:        r.OrgID,
-				RuleNamespaceUID: r.NamespaceUID,
-				RuleGroup:        r.RuleGroup,
-				ParentVersion:    0,
-				Version:          r.Version,
-				Created:          r.Updated,
-				Condition:        r.Condition,
-				Title:            r.Title,
-				Data:             r.Data,
-				IntervalSeconds:  r.IntervalSeconds,
-				NoDataState:      r.NoDataState,
-				ExecErrState:     r.ExecErrState,
-				For:              r.For,
-				Annotations:      r.Annotations,
-				Labels:           r.Labels,
+				RuleUID:                r.UID,
+				RuleOrgID:              r.OrgID,
+				RuleNamespaceUID:       r.NamespaceUID,
+				RuleGroup:              r.RuleGroup,
+				ParentVersion:          0,
+				Version:                r.Version,
+				Created:                r.Updated,
+				Condition:              r.Condition,
+				Title:                  r.Title,
+				Data:                   r.Data,
+				IntervalSeconds:        r.IntervalSeconds,
+				NoDataState:            r.NoDataState,
+				ExecErrState:           r.ExecErrState,
+				For:                    r.For,
+				KeepFiringFor:          r.KeepFiringFor,
+				Annotations:            r.Annotations,
+				Labels:                 r.Labels,
+				Record:                 r.Record,
+				DependsOn:              r.DependsOn,
+				EvaluationTimeout:      r.EvaluationTimeout,
+				GroupEvaluationTimeout: r.GroupEvaluationTimeout,
+				MaxSeriesPerQuery:      r.MaxSeriesPerQuery,
+				NotificationSettings:   r.NotificationSettings,
 			})
 		}
 		if len(newRules) > 0 {
@@ -201,23 +208,30 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 			}
 			parentVersion = r.Existing.Version
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				RuleGroupIndex:   r.New.RuleGroupIndex,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version + 1,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
+				RuleOrgID:              r.New.OrgID,
+				RuleUID:                r.New.UID,
+				RuleNamespaceUID:       r.New.NamespaceUID,
+				RuleGroup:              r.New.RuleGroup,
+				RuleGroupIndex:         r.New.RuleGroupIndex,
+				ParentVersion:          parentVersion,
+				Version:                r.New.Version + 1,
+				Created:                r.New.Updated,
+				Condition:              r.New.Condition,
+				Title:                  r.New.Title,
+				Data:                   r.New.Data,
+				IntervalSeconds:        r.New.IntervalSeconds,
+				NoDataState:            r.New.NoDataState,
+				ExecErrState:           r.New.ExecErrState,
+				For:                    r.New.For,
+				KeepFiringFor:          r.New.KeepFiringFor,
+				Annotations:            r.New.Annotations,
+				Labels:                 r.New.Labels,
+				Record:                 r.New.Record,
+				DependsOn:              r.New.DependsOn,
+				EvaluationTimeout:      r.New.EvaluationTimeout,
+				GroupEvaluationTimeout: r.New.GroupEvaluationTimeout,
+				MaxSeriesPerQuery:      r.New.MaxSeriesPerQuery,
+				NotificationSettings:   r.New.NotificationSettings,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -300,6 +314,24 @@ func (st DBstore) ListAlertRules(ctx context.Context, query *ngmodels.ListAlertR
 	})
 }
 
+// ListNotificationSettings returns the notification settings of the alert rules of the organization that have them.
+func (st DBstore) ListNotificationSettings(ctx context.Context, orgID int64) (map[ngmodels.AlertRuleKey]ngmodels.NotificationSettings, error) {
+	var rules []ngmodels.AlertRule
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("alert_rule").Cols("org_id", "uid", "notification_settings").Where("org_id = ?", orgID).Find(&rules)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[ngmodels.AlertRuleKey]ngmodels.NotificationSettings)
+	for _, rule := range rules {
+		if rule.NotificationSettings != nil {
+			result[rule.GetKey()] = *rule.NotificationSettings
+		}
+	}
+	return result, nil
+}
+
 // Count returns either the number of the alert rules under a specific org (if orgID is not zero)
 // or the number of all the alert rules
 func (st DBstore) Count(ctx context.Context, orgID int64) (int64, error) {
@@ -568,5 +600,36 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 	if alertRule.For < 0 {
 		return fmt.Errorf("%w: field `for` cannot be negative", ngmodels.ErrAlertRuleFailedValidation)
 	}
+
+	if alertRule.KeepFiringFor < 0 {
+		return fmt.Errorf("%w: field `keep_firing_for` cannot be negative", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
+	for _, uid := range alertRule.DependsOn {
+		if uid == alertRule.UID {
+			return fmt.Errorf("%w: rule cannot depend on itself", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+
+	if alertRule.EvaluationTimeout < 0 {
+		return fmt.Errorf("%w: field `evaluation_timeout` cannot be negative", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
+	if alertRule.GroupEvaluationTimeout < 0 {
+		return fmt.Errorf("%w: evaluation timeout of the rule group cannot be negative", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
+	if alertRule.MaxSeriesPerQuery < 0 {
+		return fmt.Errorf("%w: field `max_series_per_query` cannot be negative", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
+	if alertRule.NotificationSettings != nil {
+		if alertRule.Type() == ngmodels.RuleTypeRecording {
+			return fmt.Errorf("%w: notification settings are not supported by recording rules", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if err := alertRule.NotificationSettings.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -175,8 +175,27 @@ func TestIntegration_CountAlertRules(t *testing.T) {
 	}
 }
 
-func createRule(t *testing.T, store *DBstore) *models.AlertRule {
-	rule := models.AlertRuleGen(withIntervalMatching(store.Cfg.BaseInterval))()
+func TestIntegrationListNotificationSettings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	store := &DBstore{SQLStore: sqlStore}
+	settings := models.NotificationSettings{Receiver: "team-a", GroupBy: []string{"alertname"}}
+	withSettings := createRule(t, store, models.WithNotificationSettings(settings))
+	createRule(t, store, models.WithOrgID(withSettings.OrgID))
+	createRule(t, store, models.WithOrgID(withSettings.OrgID+1), models.WithNotificationSettings(settings))
+
+	result, err := store.ListNotificationSettings(context.Background(), withSettings.OrgID)
+	require.NoError(t, err)
+	require.Equal(t, map[models.AlertRuleKey]models.NotificationSettings{
+		withSettings.GetKey(): settings,
+	}, result)
+}
+
+func createRule(t *testing.T, store *DBstore, mutators ...models.AlertRuleMutator) *models.AlertRule {
+	rule := models.AlertRuleGen(append([]models.AlertRuleMutator{withIntervalMatching(store.Cfg.BaseInterval)}, mutators...)...)()
 	err := store.SQLStore.WithDbSession(context.Background(), func(sess *db.Session) error {
 		_, err := sess.Table(models.AlertRule{}).InsertOne(rule)
 		if err != nil {
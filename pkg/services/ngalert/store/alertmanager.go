@@ -16,6 +16,8 @@ var (
 	// ErrVersionLockedObjectNotFound is returned when an object is not
 	// found using the current hash.
 	ErrVersionLockedObjectNotFound = fmt.Errorf("could not find object using provided id and hash")
+	// ErrHistoricalConfigurationNotFound is an error for when a previous alertmanager configuration is not found.
+	ErrHistoricalConfigurationNotFound = fmt.Errorf("could not find the previous Alertmanager configuration")
 	// ConfigRecordsLimit defines the limit of how many alertmanager configuration versions
 	// should be stored in the database for each organization including the current one.
 	// Has to be > 0
@@ -90,6 +92,7 @@ func (st DBstore) SaveAlertmanagerConfigurationWithCallback(ctx context.Context,
 
 		historicConfig := models.HistoricConfigFromAlertConfig(config)
 		historicConfig.LastApplied = cmd.LastApplied
+		historicConfig.CreatedBy = cmd.CreatedBy
 		if _, err := sess.Table("alert_configuration_history").Insert(historicConfig); err != nil {
 			return err
 		}
@@ -128,6 +131,7 @@ func (st *DBstore) UpdateAlertmanagerConfiguration(ctx context.Context, cmd *mod
 		}
 
 		historicConfig := models.HistoricConfigFromAlertConfig(config)
+		historicConfig.CreatedBy = cmd.CreatedBy
 		if _, err := sess.Table("alert_configuration_history").Insert(historicConfig); err != nil {
 			return err
 		}
@@ -179,6 +183,45 @@ func (st *DBstore) GetAppliedConfigurations(ctx context.Context, query *models.G
 	})
 }
 
+// GetAlertmanagerConfigurationHistory returns the configurations that have been saved for the organization, ordered newest -> oldest by id.
+func (st *DBstore) GetAlertmanagerConfigurationHistory(ctx context.Context, query *models.GetAlertmanagerConfigurationHistoryQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		cfgs := []*models.HistoricAlertConfiguration{}
+		q := sess.Table("alert_configuration_history").
+			Desc("id").
+			Where("org_id = ?", query.OrgID)
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+		if err := q.Find(&cfgs); err != nil {
+			return err
+		}
+
+		query.Result = cfgs
+		return nil
+	})
+}
+
+// GetHistoricalAlertmanagerConfiguration returns a configuration that has been saved for the organization.
+// It returns ErrHistoricalConfigurationNotFound if the configuration does not exist or it has already been deleted.
+func (st *DBstore) GetHistoricalAlertmanagerConfiguration(ctx context.Context, query *models.GetHistoricalAlertmanagerConfigurationQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		cfg := &models.HistoricAlertConfiguration{}
+		ok, err := sess.Table("alert_configuration_history").
+			Where("org_id = ? AND id = ?", query.OrgID, query.ID).
+			Get(cfg)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrHistoricalConfigurationNotFound
+		}
+
+		query.Result = cfg
+		return nil
+	})
+}
+
 func (st *DBstore) deleteOldConfigurations(ctx context.Context, orgID int64, limit int) (int64, error) {
 	if limit < 1 {
 		return 0, fmt.Errorf("failed to delete old configurations: limit is set to '%d' but needs to be > 0", limit)
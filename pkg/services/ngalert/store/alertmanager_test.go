@@ -338,6 +338,66 @@ func TestIntegrationMarkConfigurationAsApplied(t *testing.T) {
 	})
 }
 
+func TestIntegrationAlertmanagerConfigurationHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	store := &DBstore{
+		SQLStore: sqlStore,
+		Logger:   log.NewNopLogger(),
+	}
+	ctx := context.Background()
+
+	const orgID = 1
+	for i, author := range []string{"", "admin", "editor"} {
+		cmd := buildSaveConfigCmd(t, fmt.Sprintf("config-%d", i), orgID)
+		cmd.CreatedBy = author
+		require.NoError(t, store.SaveAlertmanagerConfiguration(ctx, &cmd))
+	}
+	_, _ = setupConfigInOrg(t, "other-org", orgID+1, store)
+
+	t.Run("GetAlertmanagerConfigurationHistory returns configurations of the org newest first", func(t *testing.T) {
+		query := models.GetAlertmanagerConfigurationHistoryQuery{OrgID: orgID}
+		err := store.GetAlertmanagerConfigurationHistory(ctx, &query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 3)
+		require.Equal(t, "config-2", query.Result[0].AlertmanagerConfiguration)
+		require.Equal(t, "editor", query.Result[0].CreatedBy)
+		require.Equal(t, "config-0", query.Result[2].AlertmanagerConfiguration)
+		require.Empty(t, query.Result[2].CreatedBy)
+	})
+
+	t.Run("GetAlertmanagerConfigurationHistory respects limit", func(t *testing.T) {
+		query := models.GetAlertmanagerConfigurationHistoryQuery{OrgID: orgID, Limit: 2}
+		err := store.GetAlertmanagerConfigurationHistory(ctx, &query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 2)
+		require.Equal(t, "config-2", query.Result[0].AlertmanagerConfiguration)
+	})
+
+	t.Run("GetHistoricalAlertmanagerConfiguration returns the configuration", func(t *testing.T) {
+		history := models.GetAlertmanagerConfigurationHistoryQuery{OrgID: orgID}
+		require.NoError(t, store.GetAlertmanagerConfigurationHistory(ctx, &history))
+
+		query := models.GetHistoricalAlertmanagerConfigurationQuery{OrgID: orgID, ID: history.Result[1].ID}
+		err := store.GetHistoricalAlertmanagerConfiguration(ctx, &query)
+		require.NoError(t, err)
+		require.Equal(t, "config-1", query.Result.AlertmanagerConfiguration)
+		require.Equal(t, "admin", query.Result.CreatedBy)
+	})
+
+	t.Run("GetHistoricalAlertmanagerConfiguration returns error for configuration of another org", func(t *testing.T) {
+		history := models.GetAlertmanagerConfigurationHistoryQuery{OrgID: orgID + 1}
+		require.NoError(t, store.GetAlertmanagerConfigurationHistory(ctx, &history))
+		require.Len(t, history.Result, 1)
+
+		query := models.GetHistoricalAlertmanagerConfigurationQuery{OrgID: orgID, ID: history.Result[0].ID}
+		err := store.GetHistoricalAlertmanagerConfiguration(ctx, &query)
+		require.ErrorIs(t, err, ErrHistoricalConfigurationNotFound)
+	})
+}
+
 func setupConfig(t *testing.T, config string, store *DBstore) (string, string) {
 	t.Helper()
 	return setupConfigInOrg(t, config, 1, store)
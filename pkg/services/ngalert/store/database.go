@@ -28,6 +28,8 @@ type AlertingStore interface {
 	SaveAlertmanagerConfigurationWithCallback(ctx context.Context, cmd *models.SaveAlertmanagerConfigurationCmd, callback SaveCallback) error
 	UpdateAlertmanagerConfiguration(ctx context.Context, cmd *models.SaveAlertmanagerConfigurationCmd) error
 	MarkConfigurationAsApplied(ctx context.Context, cmd *models.MarkConfigurationAsAppliedCmd) error
+	GetAlertmanagerConfigurationHistory(ctx context.Context, query *models.GetAlertmanagerConfigurationHistoryQuery) error
+	GetHistoricalAlertmanagerConfiguration(ctx context.Context, query *models.GetHistoricalAlertmanagerConfigurationQuery) error
 }
 
 // DBstore stores the alert definitions and instances in the database.
@@ -39,11 +41,13 @@ type DBstore struct {
 	FolderService    folder.Service
 	AccessControl    accesscontrol.AccessControl
 	DashboardService dashboards.DashboardService
+	// ImageFiles stores the files of images. It is nil if the files are only on the local disk.
+	ImageFiles *ImageFileStore
 }
 
 func ProvideDBStore(
 	cfg *setting.Cfg, featureToggles featuremgmt.FeatureToggles, sqlstore db.DB, folderService folder.Service,
-	access accesscontrol.AccessControl, dashboards dashboards.DashboardService) *DBstore {
+	access accesscontrol.AccessControl, dashboards dashboards.DashboardService, imageFiles *ImageFileStore) *DBstore {
 	return &DBstore{
 		Cfg:              cfg.UnifiedAlerting,
 		FeatureToggles:   featureToggles,
@@ -52,5 +56,6 @@ func ProvideDBStore(
 		FolderService:    folderService,
 		AccessControl:    access,
 		DashboardService: dashboards,
+		ImageFiles:       imageFiles,
 	}
 }
@@ -93,11 +93,18 @@ func CalculateChanges(ctx context.Context, ruleReader RuleReader, groupKey model
 		}
 
 		if existing == nil {
+			if err := r.ValidateEvaluationTimeouts(); err != nil {
+				return nil, err
+			}
 			toAdd = append(toAdd, &r.AlertRule)
 			continue
 		}
 
 		models.PatchPartialAlertRule(existing, r)
+		// the patched evaluation timeout could be longer than the submitted interval
+		if err := r.ValidateEvaluationTimeouts(); err != nil {
+			return nil, err
+		}
 
 		diff := existing.Diff(&r.AlertRule, AlertRuleFieldsToIgnoreInDiff[:]...)
 		if len(diff) == 0 {
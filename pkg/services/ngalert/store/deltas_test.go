@@ -234,6 +234,22 @@ func TestCalculateChanges(t *testing.T) {
 		require.Len(t, changes.AffectedGroups[sourceGroupKey], len(inDatabase))
 	})
 
+	t.Run("should fail if patched evaluation timeout is longer than the submitted interval", func(t *testing.T) {
+		groupKey := models.GenerateGroupKey(orgId)
+		existing := models.AlertRuleGen(withGroupKey(groupKey), models.WithInterval(2*time.Minute), models.WithEvaluationTimeout(time.Minute))()
+
+		fakeStore := fakes.NewRuleStore(t)
+		fakeStore.PutRule(context.Background(), existing)
+
+		submitted := models.CopyRule(existing)
+		simulateSubmitted(submitted)
+		submitted.IntervalSeconds = 30
+		submitted.EvaluationTimeout = -1
+
+		_, err := CalculateChanges(context.Background(), fakeStore, groupKey, []*models.AlertRuleWithOptionals{{AlertRule: *submitted}})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
 	t.Run("should fail when submitted rule has UID that does not exist in db", func(t *testing.T) {
 		fakeStore := fakes.NewRuleStore(t)
 		groupKey := models.GenerateGroupKey(orgId)
@@ -51,6 +51,7 @@ func (st DBstore) GetImage(ctx context.Context, token string) (*models.Image, er
 	}); err != nil {
 		return nil, err
 	}
+	st.getImageFile(ctx, &image)
 	return &image, nil
 }
 
@@ -61,6 +62,9 @@ func (st DBstore) GetImages(ctx context.Context, tokens []string) ([]models.Imag
 	}); err != nil {
 		return nil, nil, err
 	}
+	for i := range images {
+		st.getImageFile(ctx, &images[i])
+	}
 	if len(images) < len(tokens) {
 		return images, unmatchedTokens(tokens, images), models.ErrImageNotFound
 	}
@@ -68,8 +72,9 @@ func (st DBstore) GetImages(ctx context.Context, tokens []string) ([]models.Imag
 }
 
 func (st DBstore) SaveImage(ctx context.Context, img *models.Image) error {
-	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		if img.ID == 0 {
+	isNew := img.ID == 0
+	if err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if isNew {
 			// If the ID is zero then this is a new image. It needs a token, a created timestamp
 			// and an expiration time. The expiration time of the image is derived from the created
 			// timestamp rather than the current time as it helps assert that the expiration time
@@ -80,7 +85,7 @@ func (st DBstore) SaveImage(ctx context.Context, img *models.Image) error {
 			}
 			img.Token = token.String()
 			img.CreatedAt = TimeNow().UTC()
-			img.ExpiresAt = img.CreatedAt.Add(imageExpirationDuration)
+			img.ExpiresAt = img.CreatedAt.Add(st.imageRetention())
 			if _, err := sess.Insert(img); err != nil {
 				return fmt.Errorf("failed to insert image: %w", err)
 			}
@@ -99,13 +104,30 @@ func (st DBstore) SaveImage(ctx context.Context, img *models.Image) error {
 			}
 		}
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+
+	if isNew && st.ImageFiles != nil {
+		if err := st.ImageFiles.SaveFile(ctx, img); err != nil {
+			// The image can still be sent by this instance.
+			st.Logger.Warn("Failed to save the file of the image", "token", img.Token, "error", err)
+		}
+	}
+	return nil
 }
 
 func (st DBstore) DeleteExpiredImages(ctx context.Context) (int64, error) {
 	var n int64
+	var expired []models.Image
 	if err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		rows, err := sess.Where("expires_at < ?", TimeNow().UTC()).Delete(&models.Image{})
+		now := TimeNow().UTC()
+		if st.ImageFiles != nil {
+			if err := sess.Where("expires_at < ?", now).Find(&expired); err != nil {
+				return fmt.Errorf("failed to find expired images: %w", err)
+			}
+		}
+		rows, err := sess.Where("expires_at < ?", now).Delete(&models.Image{})
 		if err != nil {
 			return fmt.Errorf("failed to delete expired images: %w", err)
 		}
@@ -114,9 +136,34 @@ func (st DBstore) DeleteExpiredImages(ctx context.Context) (int64, error) {
 	}); err != nil {
 		return -1, err
 	}
+
+	for i := range expired {
+		if err := st.ImageFiles.DeleteFile(ctx, &expired[i]); err != nil {
+			st.Logger.Warn("Failed to delete the file of the expired image", "token", expired[i].Token, "error", err)
+		}
+	}
 	return n, nil
 }
 
+// imageRetention returns how long new images are kept.
+func (st DBstore) imageRetention() time.Duration {
+	if st.Cfg.Screenshots.ImageRetention > 0 {
+		return st.Cfg.Screenshots.ImageRetention
+	}
+	return imageExpirationDuration
+}
+
+// getImageFile makes sure the file of the image is on the local disk if the files of images are in a file storage.
+// The image is returned even if its file is missing, because it can have a URL.
+func (st DBstore) getImageFile(ctx context.Context, img *models.Image) {
+	if st.ImageFiles == nil {
+		return
+	}
+	if err := st.ImageFiles.GetFile(ctx, img); err != nil {
+		st.Logger.Warn("Failed to get the file of the image", "token", img.Token, "error", err)
+	}
+}
+
 // unmatchedTokens returns the tokens that were not matched to an image.
 func unmatchedTokens(tokens []string, images []models.Image) []string {
 	matched := make(map[string]struct{})
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gocloud.dev/blob"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// imageFilesRoot is the folder of the files of images in the file storage.
+const imageFilesRoot = "/ngalert/images/"
+
+// ImageFileStore stores the files of images in a file storage shared by all Grafana instances. This way the images
+// can be sent in notifications by any instance, even if the file is not on its local disk.
+type ImageFileStore struct {
+	files  filestorage.FileStorage
+	dir    string
+	logger log.Logger
+}
+
+// NewImageFileStore returns a store that saves the files of images in files, and downloads them into dir.
+func NewImageFileStore(files filestorage.FileStorage, dir string, logger log.Logger) *ImageFileStore {
+	return &ImageFileStore{
+		files:  files,
+		dir:    dir,
+		logger: logger,
+	}
+}
+
+// ProvideImageFileStore returns the store of the storage of the screenshots settings. It returns nil if the files
+// of images are stored only on the local disk.
+func ProvideImageFileStore(cfg *setting.Cfg, sqlStore db.DB) (*ImageFileStore, error) {
+	logger := log.New("ngalert.image.files")
+	var files filestorage.FileStorage
+	switch cfg.UnifiedAlerting.Screenshots.Storage {
+	case setting.ScreenshotsStorageDatabase:
+		files = filestorage.NewDbStorage(logger, sqlStore, nil, imageFilesRoot)
+	case setting.ScreenshotsStorageBlob:
+		// The drivers of object storage services are not registered, so only directories of file systems can be opened.
+		bucket, err := blob.OpenBucket(context.Background(), cfg.UnifiedAlerting.Screenshots.BlobURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the blob storage of images: %w", err)
+		}
+		files = filestorage.NewCdkBlobStorage(logger, bucket, imageFilesRoot, nil)
+	default:
+		return nil, nil
+	}
+	return NewImageFileStore(files, cfg.ImagesDir, logger), nil
+}
+
+// SaveFile saves the file of the image in the file storage. Images without a path are ignored.
+func (s *ImageFileStore) SaveFile(ctx context.Context, img *models.Image) error {
+	if !img.HasPath() {
+		return nil
+	}
+	// The path of the image is set by the screenshot service.
+	// nolint:gosec
+	contents, err := os.ReadFile(img.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read the file of the image: %w", err)
+	}
+	return s.files.Upsert(ctx, &filestorage.UpsertFileCommand{
+		Path:     imageFilePath(img),
+		Contents: contents,
+	})
+}
+
+// GetFile makes sure the file of the image is on the local disk. If the file is missing, it is downloaded from the
+// file storage into the directory of images, and the path of the image is updated.
+func (s *ImageFileStore) GetFile(ctx context.Context, img *models.Image) error {
+	if !img.HasPath() {
+		return nil
+	}
+	if _, err := os.Stat(img.Path); err == nil {
+		return nil
+	}
+
+	localPath := filepath.Join(s.dir, filepath.Base(imageFilePath(img)))
+	if _, err := os.Stat(localPath); err == nil {
+		img.Path = localPath
+		return nil
+	}
+
+	file, ok, err := s.files.Get(ctx, imageFilePath(img), nil)
+	if err != nil {
+		return fmt.Errorf("failed to get the file of the image: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("the file of the image %s does not exist", img.Token)
+	}
+
+	if err := os.MkdirAll(s.dir, 0750); err != nil {
+		return err
+	}
+	// Write to a temporary file first so that concurrent notifications never read a partial file.
+	tmp, err := os.CreateTemp(s.dir, img.Token+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(file.Contents); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), localPath); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	s.logger.Debug("Downloaded the file of the image", "token", img.Token, "path", localPath)
+	img.Path = localPath
+	return nil
+}
+
+// DeleteFile deletes the file of the image from the file storage, and the file downloaded on the local disk if any.
+func (s *ImageFileStore) DeleteFile(ctx context.Context, img *models.Image) error {
+	if !img.HasPath() {
+		return nil
+	}
+	if err := s.files.Delete(ctx, imageFilePath(img)); err != nil {
+		return err
+	}
+	localPath := filepath.Join(s.dir, filepath.Base(imageFilePath(img)))
+	if err := os.Remove(localPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// imageFilePath returns the path of the file of the image in the file storage.
+func imageFilePath(img *models.Image) string {
+	return filestorage.Join(img.Token + filepath.Ext(img.Path))
+}
@@ -2,13 +2,18 @@ package store_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests"
@@ -198,3 +203,81 @@ func TestIntegrationDeleteExpiredImages(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestIntegrationImageRetention(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	// our database schema uses second precision for timestamps
+	store.TimeNow = func() time.Time {
+		return time.Now().Truncate(time.Second)
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	dbstore.Cfg.Screenshots.ImageRetention = time.Hour
+
+	image := models.Image{Path: "example.png"}
+	require.NoError(t, dbstore.SaveImage(ctx, &image))
+	assert.Equal(t, image.ExpiresAt, image.CreatedAt.Add(time.Hour))
+}
+
+func TestIntegrationImageFiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	// our database schema uses second precision for timestamps
+	store.TimeNow = func() time.Time {
+		return time.Now().Truncate(time.Second)
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	bucket, err := blob.OpenBucket(ctx, "mem://")
+	require.NoError(t, err)
+	files := filestorage.NewCdkBlobStorage(log.NewNopLogger(), bucket, "", nil)
+	downloadDir := t.TempDir()
+	dbstore.ImageFiles = store.NewImageFileStore(files, downloadDir, log.NewNopLogger())
+
+	path := filepath.Join(t.TempDir(), "example.png")
+	require.NoError(t, os.WriteFile(path, []byte("image"), 0600))
+
+	// the file should be saved in the file storage
+	image := models.Image{Path: path}
+	require.NoError(t, dbstore.SaveImage(ctx, &image))
+	_, ok, err := files.Get(ctx, "/"+image.Token+".png", nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// the file should not be downloaded if it is on the local disk
+	result, err := dbstore.GetImage(ctx, image.Token)
+	require.NoError(t, err)
+	assert.Equal(t, path, result.Path)
+
+	// the file should be downloaded if it is not on the local disk
+	require.NoError(t, os.Remove(path))
+	result, err = dbstore.GetImage(ctx, image.Token)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(downloadDir, image.Token+".png"), result.Path)
+	b, err := os.ReadFile(result.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "image", string(b))
+
+	images, _, err := dbstore.GetImages(ctx, []string{image.Token})
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, result.Path, images[0].Path)
+
+	// the file should be deleted when the image expires
+	image.ExpiresAt = time.Now().Add(-time.Second)
+	require.NoError(t, dbstore.SaveImage(ctx, &image))
+	n, err := dbstore.DeleteExpiredImages(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, ok, err = files.Get(ctx, "/"+image.Token+".png", nil)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoFileExists(t, result.Path)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -160,9 +161,10 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 				RuleUID:    alertRule1.UID,
 				LabelsHash: hash,
 			},
-			CurrentState:  models.InstanceStateFiring,
-			CurrentReason: string(models.InstanceStateError),
-			Labels:        labels,
+			CurrentState:    models.InstanceStateFiring,
+			CurrentReason:   string(models.InstanceStateError),
+			Labels:          labels,
+			KeepFiringSince: time.Unix(1700000000, 0),
 		}
 		err := dbstore.SaveAlertInstances(ctx, instance)
 		require.NoError(t, err)
@@ -179,6 +181,7 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, alertRule1.OrgID, listCmd.Result[0].RuleOrgID)
 		require.Equal(t, alertRule1.UID, listCmd.Result[0].RuleUID)
 		require.Equal(t, instance.CurrentReason, listCmd.Result[0].CurrentReason)
+		require.Equal(t, instance.KeepFiringSince.Unix(), listCmd.Result[0].KeepFiringSince.Unix())
 	})
 
 	t.Run("can save and read new alert instance with no labels", func(t *testing.T) {
@@ -208,6 +211,7 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, alertRule2.OrgID, listCmd.Result[0].RuleOrgID)
 		require.Equal(t, alertRule2.UID, listCmd.Result[0].RuleUID)
 		require.Equal(t, instance.Labels, listCmd.Result[0].Labels)
+		require.Equal(t, int64(0), listCmd.Result[0].KeepFiringSince.Unix())
 	})
 
 	t.Run("can save two instances with same org_id, uid and different labels", func(t *testing.T) {
@@ -262,6 +266,21 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 		require.Len(t, listQuery.Result, 4)
 	})
 
+	t.Run("can list the instances of several rules", func(t *testing.T) {
+		listQuery := &models.ListAlertInstancesQuery{
+			RuleOrgID: orgID,
+			RuleUIDs:  []string{alertRule1.UID, alertRule3.UID},
+		}
+
+		err := dbstore.ListAlertInstances(ctx, listQuery)
+		require.NoError(t, err)
+
+		require.Len(t, listQuery.Result, 3)
+		for _, instance := range listQuery.Result {
+			require.Contains(t, []string{alertRule1.UID, alertRule3.UID}, instance.RuleUID)
+		}
+	})
+
 	t.Run("should ignore Normal state with no reason if feature flag is enabled", func(t *testing.T) {
 		labels := models.InstanceLabels{"test": util.GenerateShortUID()}
 		instance1 := models.AlertInstance{
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ReplicaStore stores the heartbeats of the replicas that shard the evaluation of alert rules.
+type ReplicaStore interface {
+	// UpdateReplicaHeartbeat inserts the replica or updates the time of its last heartbeat.
+	UpdateReplicaHeartbeat(ctx context.Context, replicaID string, at time.Time) error
+	// GetActiveReplicas returns the sorted IDs of the replicas whose last heartbeat is not before the given time.
+	GetActiveReplicas(ctx context.Context, since time.Time) ([]string, error)
+	// DeleteReplica removes the replica.
+	DeleteReplica(ctx context.Context, replicaID string) error
+	// DeleteInactiveReplicas removes the replicas whose last heartbeat is before the given time.
+	DeleteInactiveReplicas(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (st DBstore) UpdateReplicaHeartbeat(ctx context.Context, replicaID string, at time.Time) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		replica := models.SchedulerReplica{ReplicaID: replicaID, LastHeartbeat: at.Unix()}
+		exists, err := sess.Where("replica_id = ?", replicaID).Exist(&models.SchedulerReplica{})
+		if err != nil {
+			return fmt.Errorf("failed to get replica %s: %w", replicaID, err)
+		}
+		if exists {
+			if _, err := sess.Where("replica_id = ?", replicaID).Cols("last_heartbeat").Update(&replica); err != nil {
+				return fmt.Errorf("failed to update heartbeat of replica %s: %w", replicaID, err)
+			}
+			return nil
+		}
+		if _, err := sess.Insert(&replica); err != nil {
+			return fmt.Errorf("failed to insert replica %s: %w", replicaID, err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetActiveReplicas(ctx context.Context, since time.Time) ([]string, error) {
+	replicas := make([]models.SchedulerReplica, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("last_heartbeat >= ?", since.Unix()).Find(&replicas)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active replicas: %w", err)
+	}
+	result := make([]string, 0, len(replicas))
+	for _, r := range replicas {
+		result = append(result, r.ReplicaID)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (st DBstore) DeleteReplica(ctx context.Context, replicaID string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("replica_id = ?", replicaID).Delete(&models.SchedulerReplica{}); err != nil {
+			return fmt.Errorf("failed to delete replica %s: %w", replicaID, err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) DeleteInactiveReplicas(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		rows, err := sess.Where("last_heartbeat < ?", before.Unix()).Delete(&models.SchedulerReplica{})
+		if err != nil {
+			return fmt.Errorf("failed to delete inactive replicas: %w", err)
+		}
+		n = rows
+		return nil
+	})
+	return n, err
+}
@@ -0,0 +1,47 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationReplicaStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	now := time.Unix(1000, 0)
+
+	require.NoError(t, dbstore.UpdateReplicaHeartbeat(ctx, "replica-b", now))
+	require.NoError(t, dbstore.UpdateReplicaHeartbeat(ctx, "replica-a", now.Add(-time.Minute)))
+	require.NoError(t, dbstore.UpdateReplicaHeartbeat(ctx, "replica-c", now.Add(-time.Hour)))
+
+	active, err := dbstore.GetActiveReplicas(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, []string{"replica-a", "replica-b"}, active)
+
+	t.Run("should update heartbeat of existing replica", func(t *testing.T) {
+		require.NoError(t, dbstore.UpdateReplicaHeartbeat(ctx, "replica-c", now))
+		active, err := dbstore.GetActiveReplicas(ctx, now.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []string{"replica-a", "replica-b", "replica-c"}, active)
+	})
+
+	t.Run("should delete replicas", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteReplica(ctx, "replica-b"))
+		deleted, err := dbstore.DeleteInactiveReplicas(ctx, now)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		active, err := dbstore.GetActiveReplicas(ctx, time.Unix(0, 0))
+		require.NoError(t, err)
+		require.Equal(t, []string{"replica-c"}, active)
+	})
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+type StateHistoryStore interface {
+	// SaveStateHistory inserts the entries in batches sized for the database dialect.
+	SaveStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error
+	// GetStateHistory returns the entries of the organization that happened within the time range of the query,
+	// ordered by time. The query is restricted to a single rule if the rule UID is set, and to a page of the entries
+	// if the limit is set. Labels are not matched.
+	GetStateHistory(ctx context.Context, query models.HistoryQuery) ([]models.StateHistoryEntry, error)
+}
+
+type StateHistoryAdminStore interface {
+	StateHistoryStore
+
+	// DeleteExpiredStateHistory deletes entries older than the configured retention period. It returns
+	// the number of deleted entries or an error.
+	DeleteExpiredStateHistory(ctx context.Context) (int64, error)
+}
+
+func (st DBstore) SaveStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		opts := sqlstore.NativeSettingsForDialect(st.SQLStore.GetDialect())
+		if _, err := sess.BulkInsert(&models.StateHistoryEntry{}, entries, opts); err != nil {
+			return fmt.Errorf("failed to save state history: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetStateHistory(ctx context.Context, query models.HistoryQuery) ([]models.StateHistoryEntry, error) {
+	entries := make([]models.StateHistoryEntry, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ?", query.OrgID)
+		if query.RuleUID != "" {
+			q = q.And("rule_uid = ?", query.RuleUID)
+		}
+		if !query.From.IsZero() {
+			q = q.And("epoch >= ?", query.From.UnixMilli())
+		}
+		if !query.To.IsZero() {
+			q = q.And("epoch <= ?", query.To.UnixMilli())
+		}
+		q = q.Asc("epoch", "id")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit, query.Offset)
+		}
+		return q.Find(&entries)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state history: %w", err)
+	}
+	return entries, nil
+}
+
+func (st DBstore) DeleteExpiredStateHistory(ctx context.Context) (int64, error) {
+	if st.Cfg.StateHistory.SQLRetentionPeriod <= 0 {
+		return 0, nil
+	}
+	before := TimeNow().Add(-st.Cfg.StateHistory.SQLRetentionPeriod).UnixMilli()
+	var n int64
+	if err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rows, err := sess.Where("epoch < ?", before).Delete(&models.StateHistoryEntry{})
+		if err != nil {
+			return fmt.Errorf("failed to delete expired state history: %w", err)
+		}
+		n = rows
+		return nil
+	}); err != nil {
+		return -1, err
+	}
+	return n, nil
+}
@@ -0,0 +1,93 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationSaveAndGetStateHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now()
+	entry := func(orgID int64, ruleUID string, ts time.Time) models.StateHistoryEntry {
+		return models.StateHistoryEntry{
+			OrgID:         orgID,
+			RuleUID:       ruleUID,
+			NamespaceUID:  "folder",
+			RuleGroup:     "group",
+			Labels:        map[string]string{"a": "b"},
+			PreviousState: "Normal",
+			CurrentState:  "Alerting",
+			Values:        `{"A":1}`,
+			Epoch:         ts.UnixMilli(),
+		}
+	}
+	require.NoError(t, dbstore.SaveStateHistory(ctx, []models.StateHistoryEntry{
+		entry(1, "rule1", now.Add(-time.Minute)),
+		entry(1, "rule2", now.Add(-2*time.Minute)),
+		entry(1, "rule1", now.Add(-2*time.Hour)),
+		entry(2, "rule1", now.Add(-time.Minute)),
+	}))
+
+	// should return the entries of the org within the time range ordered by time
+	result, err := dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1, From: now.Add(-time.Hour), To: now})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "rule2", result[0].RuleUID)
+	assert.Equal(t, "rule1", result[1].RuleUID)
+	assert.Equal(t, map[string]string{"a": "b"}, result[1].Labels)
+	assert.Equal(t, `{"A":1}`, result[1].Values)
+
+	// should return the entries of a single rule
+	result, err = dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1, RuleUID: "rule1"})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	for _, r := range result {
+		assert.Equal(t, "rule1", r.RuleUID)
+		assert.Equal(t, int64(1), r.OrgID)
+	}
+
+	// should return a page of the entries
+	result, err = dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1, Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "rule2", result[0].RuleUID)
+}
+
+func TestIntegrationDeleteExpiredStateHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	dbstore.Cfg.StateHistory.SQLRetentionPeriod = time.Hour
+
+	now := time.Now()
+	require.NoError(t, dbstore.SaveStateHistory(ctx, []models.StateHistoryEntry{
+		{OrgID: 1, RuleUID: "rule1", Epoch: now.Add(-2 * time.Hour).UnixMilli()},
+		{OrgID: 1, RuleUID: "rule1", Epoch: now.Add(-time.Minute).UnixMilli()},
+	}))
+
+	// should delete the entry older than the retention period
+	n, err := dbstore.DeleteExpiredStateHistory(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	result, err := dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, now.Add(-time.Minute).UnixMilli(), result[0].Epoch)
+}
@@ -0,0 +1,144 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const remoteWritePath = "/api/v1/write"
+
+var ErrNoTarget = errors.New("no remote write target is configured")
+
+type dataSourceGetter interface {
+	GetDataSource(ctx context.Context, query *datasources.GetDataSourceQuery) (*datasources.DataSource, error)
+	DecryptedBasicAuthPassword(ctx context.Context, ds *datasources.DataSource) (string, error)
+}
+
+// target is a remote write endpoint with optional basic auth credentials.
+type target struct {
+	url               string
+	basicAuthUser     string
+	basicAuthPassword string
+}
+
+// PrometheusWriter writes the results of recording rules to Prometheus compatible remote write endpoints.
+// A rule is written either to the Prometheus data source it targets or to the endpoint configured in the settings.
+type PrometheusWriter struct {
+	cfg         setting.RecordingRuleSettings
+	client      *http.Client
+	dataSources dataSourceGetter
+	log         log.Logger
+}
+
+func NewPrometheusWriter(cfg setting.RecordingRuleSettings, dataSources dataSourceGetter, logger log.Logger) *PrometheusWriter {
+	return &PrometheusWriter{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		dataSources: dataSources,
+		log:         logger,
+	}
+}
+
+// Write converts the frames produced by the recording rule to Prometheus time series named after the rule's metric,
+// adds the extra labels to each series and sends them to the rule's target.
+func (w *PrometheusWriter) Write(ctx context.Context, rule *models.AlertRule, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	if rule.Record == nil {
+		return fmt.Errorf("rule %s is not a recording rule", rule.UID)
+	}
+	ts, err := remotewrite.TimeSeriesFromFramesWithName(rule.Record.Metric, t, extraLabels, frames...)
+	if err != nil {
+		return err
+	}
+	if len(ts) == 0 {
+		w.log.Debug("No series to write", "rule_uid", rule.UID)
+		return nil
+	}
+	body, err := remotewrite.TimeSeriesToBytes(ts)
+	if err != nil {
+		return err
+	}
+
+	tgt, err := w.target(ctx, rule)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tgt.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error constructing remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if tgt.basicAuthUser != "" || tgt.basicAuthPassword != "" {
+		req.SetBasicAuth(tgt.basicAuthUser, tgt.basicAuthPassword)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("remote write endpoint returned a non-200 status code: %d", resp.StatusCode)
+	}
+	w.log.Debug("Recording rule results written", "rule_uid", rule.UID, "series", len(ts))
+	return nil
+}
+
+// target returns the remote write endpoint of the data source the rule targets or the default one from the settings.
+func (w *PrometheusWriter) target(ctx context.Context, rule *models.AlertRule) (target, error) {
+	if rule.Record.TargetDatasourceUID == "" {
+		if w.cfg.URL == "" {
+			return target{}, ErrNoTarget
+		}
+		return target{
+			url:               w.cfg.URL,
+			basicAuthUser:     w.cfg.BasicAuthUsername,
+			basicAuthPassword: w.cfg.BasicAuthPassword,
+		}, nil
+	}
+
+	ds, err := w.dataSources.GetDataSource(ctx, &datasources.GetDataSourceQuery{
+		UID:   rule.Record.TargetDatasourceUID,
+		OrgID: rule.OrgID,
+	})
+	if err != nil {
+		return target{}, fmt.Errorf("failed to get target data source %s: %w", rule.Record.TargetDatasourceUID, err)
+	}
+	if ds.Type != datasources.DS_PROMETHEUS {
+		return target{}, fmt.Errorf("target data source %s is of type %s but only %s is supported", ds.UID, ds.Type, datasources.DS_PROMETHEUS)
+	}
+	u, err := url.Parse(ds.URL)
+	if err != nil {
+		return target{}, fmt.Errorf("failed to parse URL of the target data source %s: %w", ds.UID, err)
+	}
+	result := target{url: u.JoinPath(remoteWritePath).String()}
+	if ds.BasicAuth {
+		result.basicAuthUser = ds.BasicAuthUser
+		result.basicAuthPassword, err = w.dataSources.DecryptedBasicAuthPassword(ctx, ds)
+		if err != nil {
+			return target{}, fmt.Errorf("failed to decrypt basic auth password of the target data source %s: %w", ds.UID, err)
+		}
+	}
+	return result, nil
+}
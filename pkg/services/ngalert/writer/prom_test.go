@@ -0,0 +1,87 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestPrometheusWriter_Write(t *testing.T) {
+	var (
+		gotPath    string
+		gotRequest prompb.WriteRequest
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		decoded, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		require.NoError(t, proto.Unmarshal(decoded, &gotRequest))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	dsService := &fakes.FakeDataSourceService{DataSources: []*datasources.DataSource{
+		{UID: "prom", OrgID: 1, Type: datasources.DS_PROMETHEUS, URL: srv.URL + "/prometheus"},
+		{UID: "loki", OrgID: 1, Type: datasources.DS_LOKI, URL: srv.URL},
+	}}
+	cfg := setting.RecordingRuleSettings{Enabled: true, URL: srv.URL + "/push", Timeout: time.Second}
+	w := NewPrometheusWriter(cfg, dsService, log.NewNopLogger())
+
+	now := time.Now()
+	frames := data.Frames{data.NewFrame("",
+		data.NewField("", data.Labels{"host": "a"}, []float64{42}),
+	)}
+
+	t.Run("writes to the configured endpoint", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithOrgID(1), models.WithRecord("test_metric", "A"))()
+		err := w.Write(context.Background(), rule, now, frames, map[string]string{"rule": "x"})
+		require.NoError(t, err)
+		require.Equal(t, "/push", gotPath)
+		require.Len(t, gotRequest.Timeseries, 1)
+		require.Equal(t, []prompb.Label{
+			{Name: "host", Value: "a"},
+			{Name: "rule", Value: "x"},
+			{Name: "__name__", Value: "test_metric"},
+		}, gotRequest.Timeseries[0].Labels)
+		require.Equal(t, 42.0, gotRequest.Timeseries[0].Samples[0].Value)
+	})
+
+	t.Run("writes to the target data source", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithOrgID(1), models.WithRecord("test_metric", "A"))()
+		rule.Record.TargetDatasourceUID = "prom"
+		err := w.Write(context.Background(), rule, now, frames, nil)
+		require.NoError(t, err)
+		require.Equal(t, "/prometheus/api/v1/write", gotPath)
+	})
+
+	t.Run("fails if the target data source is not Prometheus", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithOrgID(1), models.WithRecord("test_metric", "A"))()
+		rule.Record.TargetDatasourceUID = "loki"
+		err := w.Write(context.Background(), rule, now, frames, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("fails if no target is configured", func(t *testing.T) {
+		w := NewPrometheusWriter(setting.RecordingRuleSettings{Enabled: true}, dsService, log.NewNopLogger())
+		rule := models.AlertRuleGen(models.WithOrgID(1), models.WithRecord("test_metric", "A"))()
+		err := w.Write(context.Background(), rule, now, frames, nil)
+		require.ErrorIs(t, err, ErrNoTarget)
+	})
+}
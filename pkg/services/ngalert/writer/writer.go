@@ -0,0 +1,22 @@
+// Package writer writes the results of recording rules to their target data sources.
+package writer
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// NoopWriter is a writer that discards the results of recording rules, to be used when recording rules are disabled.
+type NoopWriter struct{}
+
+func NewNoopWriter() *NoopWriter {
+	return &NoopWriter{}
+}
+
+func (w *NoopWriter) Write(_ context.Context, _ *models.AlertRule, _ time.Time, _ data.Frames, _ map[string]string) error {
+	return nil
+}
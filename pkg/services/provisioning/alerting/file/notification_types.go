@@ -0,0 +1,57 @@
+package file
+
+import (
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+)
+
+// ContactPointExport is the provisioned file export of alerting.ContactPointV1.
+type ContactPointExport struct {
+	OrgID     int64            `json:"orgId" yaml:"orgId"`
+	Name      string           `json:"name" yaml:"name"`
+	Receivers []ReceiverExport `json:"receivers" yaml:"receivers"`
+}
+
+// ReceiverExport is the provisioned file export of alerting.ReceiverV1.
+type ReceiverExport struct {
+	UID                   string         `json:"uid" yaml:"uid"`
+	Type                  string         `json:"type" yaml:"type"`
+	Settings              map[string]any `json:"settings" yaml:"settings"`
+	DisableResolveMessage bool           `json:"disableResolveMessage" yaml:"disableResolveMessage"`
+}
+
+// NotificationPolicyExport is the provisioned file export of alerting.NotificiationPolicyV1.
+type NotificationPolicyExport struct {
+	OrgID       int64 `json:"orgId" yaml:"orgId"`
+	RouteExport `json:",inline" yaml:",inline"`
+}
+
+// RouteExport is the provisioned file export of definitions.Route. The fields and their names match the
+// ones that the provisioning of notification policies accepts.
+type RouteExport struct {
+	Receiver          string              `json:"receiver,omitempty" yaml:"receiver,omitempty"`
+	GroupByStr        []string            `json:"group_by,omitempty" yaml:"group_by,omitempty"`
+	Match             map[string]string   `json:"match,omitempty" yaml:"match,omitempty"`
+	MatchRE           config.MatchRegexps `json:"match_re,omitempty" yaml:"match_re,omitempty"`
+	Matchers          config.Matchers     `json:"matchers,omitempty" yaml:"matchers,omitempty"`
+	ObjectMatchers    [][3]string         `json:"object_matchers,omitempty" yaml:"object_matchers,omitempty"`
+	MuteTimeIntervals []string            `json:"mute_time_intervals,omitempty" yaml:"mute_time_intervals,omitempty"`
+	Continue          bool                `json:"continue,omitempty" yaml:"continue,omitempty"`
+	Routes            []*RouteExport      `json:"routes,omitempty" yaml:"routes,omitempty"`
+	GroupWait         *model.Duration     `json:"group_wait,omitempty" yaml:"group_wait,omitempty"`
+	GroupInterval     *model.Duration     `json:"group_interval,omitempty" yaml:"group_interval,omitempty"`
+	RepeatInterval    *model.Duration     `json:"repeat_interval,omitempty" yaml:"repeat_interval,omitempty"`
+}
+
+// MuteTimeIntervalExport is the provisioned file export of alerting.MuteTimeV1.
+type MuteTimeIntervalExport struct {
+	OrgID                   int64 `json:"orgId" yaml:"orgId"`
+	config.MuteTimeInterval `json:",inline" yaml:",inline"`
+}
+
+// NotificationTemplateExport is the provisioned file export of alerting.TemplateV1.
+type NotificationTemplateExport struct {
+	OrgID    int64  `json:"orgId" yaml:"orgId"`
+	Name     string `json:"name" yaml:"name"`
+	Template string `json:"template" yaml:"template"`
+}
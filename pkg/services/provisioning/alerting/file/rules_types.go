@@ -24,11 +24,12 @@ type RuleDeleteV1 struct {
 }
 
 type AlertRuleGroupV1 struct {
-	OrgID    values.Int64Value  `json:"orgId" yaml:"orgId"`
-	Name     values.StringValue `json:"name" yaml:"name"`
-	Folder   values.StringValue `json:"folder" yaml:"folder"`
-	Interval values.StringValue `json:"interval" yaml:"interval"`
-	Rules    []AlertRuleV1      `json:"rules" yaml:"rules"`
+	OrgID             values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name              values.StringValue `json:"name" yaml:"name"`
+	Folder            values.StringValue `json:"folder" yaml:"folder"`
+	Interval          values.StringValue `json:"interval" yaml:"interval"`
+	EvaluationTimeout values.StringValue `json:"evaluationTimeout" yaml:"evaluationTimeout"`
+	Rules             []AlertRuleV1      `json:"rules" yaml:"rules"`
 }
 
 func (ruleGroupV1 *AlertRuleGroupV1) MapToModel() (AlertRuleGroupWithFolderTitle, error) {
@@ -50,11 +51,26 @@ func (ruleGroupV1 *AlertRuleGroupV1) MapToModel() (AlertRuleGroupWithFolderTitle
 	if strings.TrimSpace(ruleGroup.FolderTitle) == "" {
 		return AlertRuleGroupWithFolderTitle{}, errors.New("rule group has no folder set")
 	}
+	if ruleGroupV1.EvaluationTimeout.Value() != "" {
+		timeout, err := model.ParseDuration(ruleGroupV1.EvaluationTimeout.Value())
+		if err != nil {
+			return AlertRuleGroupWithFolderTitle{}, fmt.Errorf("rule group '%s' has invalid evaluation timeout: %w", ruleGroup.Title, err)
+		}
+		ruleGroup.EvaluationTimeout = time.Duration(timeout)
+		if ruleGroup.EvaluationTimeout < 0 || ruleGroup.EvaluationTimeout > time.Duration(interval) {
+			return AlertRuleGroupWithFolderTitle{}, fmt.Errorf("rule group '%s' has evaluation timeout that is negative or longer than the interval", ruleGroup.Title)
+		}
+	}
 	for _, ruleV1 := range ruleGroupV1.Rules {
 		rule, err := ruleV1.mapToModel(ruleGroup.OrgID)
 		if err != nil {
 			return AlertRuleGroupWithFolderTitle{}, err
 		}
+		rule.IntervalSeconds = ruleGroup.Interval
+		rule.GroupEvaluationTimeout = ruleGroup.EvaluationTimeout
+		if err := rule.ValidateEvaluationTimeouts(); err != nil {
+			return AlertRuleGroupWithFolderTitle{}, err
+		}
 		ruleGroup.Rules = append(ruleGroup.Rules, rule)
 	}
 	return ruleGroup, nil
@@ -67,18 +83,87 @@ type AlertRuleGroupWithFolderTitle struct {
 }
 
 type AlertRuleV1 struct {
-	UID          values.StringValue    `json:"uid" yaml:"uid"`
-	Title        values.StringValue    `json:"title" yaml:"title"`
-	Condition    values.StringValue    `json:"condition" yaml:"condition"`
-	Data         []QueryV1             `json:"data" yaml:"data"`
-	DashboardUID values.StringValue    `json:"dasboardUid" yaml:"dashboardUid"`
-	PanelID      values.Int64Value     `json:"panelId" yaml:"panelId"`
-	NoDataState  values.StringValue    `json:"noDataState" yaml:"noDataState"`
-	ExecErrState values.StringValue    `json:"execErrState" yaml:"execErrState"`
-	For          values.StringValue    `json:"for" yaml:"for"`
-	Annotations  values.StringMapValue `json:"annotations" yaml:"annotations"`
-	Labels       values.StringMapValue `json:"labels" yaml:"labels"`
-	IsPaused     values.BoolValue      `json:"isPaused" yaml:"isPaused"`
+	UID                  values.StringValue      `json:"uid" yaml:"uid"`
+	Title                values.StringValue      `json:"title" yaml:"title"`
+	Condition            values.StringValue      `json:"condition" yaml:"condition"`
+	Data                 []QueryV1               `json:"data" yaml:"data"`
+	DashboardUID         values.StringValue      `json:"dasboardUid" yaml:"dashboardUid"`
+	PanelID              values.Int64Value       `json:"panelId" yaml:"panelId"`
+	NoDataState          values.StringValue      `json:"noDataState" yaml:"noDataState"`
+	ExecErrState         values.StringValue      `json:"execErrState" yaml:"execErrState"`
+	For                  values.StringValue      `json:"for" yaml:"for"`
+	KeepFiringFor        values.StringValue      `json:"keepFiringFor" yaml:"keepFiringFor"`
+	Annotations          values.StringMapValue   `json:"annotations" yaml:"annotations"`
+	Labels               values.StringMapValue   `json:"labels" yaml:"labels"`
+	IsPaused             values.BoolValue        `json:"isPaused" yaml:"isPaused"`
+	Record               *RecordV1               `json:"record" yaml:"record"`
+	EvaluationTimeout    values.StringValue      `json:"evaluationTimeout" yaml:"evaluationTimeout"`
+	MaxSeriesPerQuery    values.Int64Value       `json:"maxSeriesPerQuery" yaml:"maxSeriesPerQuery"`
+	NotificationSettings *NotificationSettingsV1 `json:"notificationSettings" yaml:"notificationSettings"`
+	DependsOn            []values.StringValue    `json:"dependsOn" yaml:"dependsOn"`
+}
+
+type RecordV1 struct {
+	Metric              values.StringValue `json:"metric" yaml:"metric"`
+	From                values.StringValue `json:"from" yaml:"from"`
+	TargetDatasourceUID values.StringValue `json:"targetDatasourceUid" yaml:"targetDatasourceUid"`
+}
+
+func (record *RecordV1) mapToModel() (*models.Record, error) {
+	r := &models.Record{
+		Metric:              record.Metric.Value(),
+		From:                record.From.Value(),
+		TargetDatasourceUID: record.TargetDatasourceUID.Value(),
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type NotificationSettingsV1 struct {
+	Receiver          values.StringValue   `json:"receiver" yaml:"receiver"`
+	GroupBy           []values.StringValue `json:"groupBy" yaml:"groupBy"`
+	GroupWait         values.StringValue   `json:"groupWait" yaml:"groupWait"`
+	GroupInterval     values.StringValue   `json:"groupInterval" yaml:"groupInterval"`
+	RepeatInterval    values.StringValue   `json:"repeatInterval" yaml:"repeatInterval"`
+	MuteTimeIntervals []values.StringValue `json:"muteTimeIntervals" yaml:"muteTimeIntervals"`
+}
+
+func (settings *NotificationSettingsV1) mapToModel() (*models.NotificationSettings, error) {
+	parseDuration := func(v values.StringValue) (*model.Duration, error) {
+		if v.Value() == "" {
+			return nil, nil
+		}
+		d, err := model.ParseDuration(v.Value())
+		if err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}
+	s := &models.NotificationSettings{
+		Receiver: settings.Receiver.Value(),
+	}
+	for _, l := range settings.GroupBy {
+		s.GroupBy = append(s.GroupBy, l.Value())
+	}
+	for _, mt := range settings.MuteTimeIntervals {
+		s.MuteTimeIntervals = append(s.MuteTimeIntervals, mt.Value())
+	}
+	var err error
+	if s.GroupWait, err = parseDuration(settings.GroupWait); err != nil {
+		return nil, err
+	}
+	if s.GroupInterval, err = parseDuration(settings.GroupInterval); err != nil {
+		return nil, err
+	}
+	if s.RepeatInterval, err = parseDuration(settings.RepeatInterval); err != nil {
+		return nil, err
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -92,11 +177,38 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no UID set", alertRule.Title)
 	}
 	alertRule.OrgID = orgID
-	duration, err := model.ParseDuration(rule.For.Value())
-	if err != nil {
-		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+	// recording rules do not have a pending period, so the field can be omitted for them.
+	if rule.Record == nil || rule.For.Value() != "" {
+		duration, err := model.ParseDuration(rule.For.Value())
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.For = time.Duration(duration)
+	}
+	if rule.KeepFiringFor.Value() != "" {
+		duration, err := model.ParseDuration(rule.KeepFiringFor.Value())
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.KeepFiringFor = time.Duration(duration)
+	}
+	if rule.EvaluationTimeout.Value() != "" {
+		timeout, err := model.ParseDuration(rule.EvaluationTimeout.Value())
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.EvaluationTimeout = time.Duration(timeout)
+	}
+	alertRule.MaxSeriesPerQuery = rule.MaxSeriesPerQuery.Value()
+	if alertRule.MaxSeriesPerQuery < 0 {
+		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: maxSeriesPerQuery cannot be negative", alertRule.Title)
+	}
+	for _, uid := range rule.DependsOn {
+		if uid.Value() == "" {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: dependsOn cannot contain an empty UID", alertRule.Title)
+		}
+		alertRule.DependsOn = append(alertRule.DependsOn, uid.Value())
 	}
-	alertRule.For = time.Duration(duration)
 	dashboardUID := rule.DashboardUID.Value()
 	alertRule.DashboardUID = &dashboardUID
 	panelID := rule.PanelID.Value()
@@ -120,9 +232,30 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 	}
 	alertRule.NoDataState = noDataState
 	alertRule.Condition = rule.Condition.Value()
+	if rule.Record != nil {
+		alertRule.Record, err = rule.Record.mapToModel()
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		if alertRule.Condition == "" {
+			alertRule.Condition = alertRule.Record.From
+		}
+		if alertRule.Condition != alertRule.Record.From {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: condition of a recording rule must refer to the recorded query or expression", alertRule.Title)
+		}
+	}
 	if alertRule.Condition == "" {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no condition set", alertRule.Title)
 	}
+	if rule.NotificationSettings != nil {
+		if rule.Record != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: notification settings are not supported by recording rules", alertRule.Title)
+		}
+		alertRule.NotificationSettings, err = rule.NotificationSettings.mapToModel()
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+	}
 	alertRule.Annotations = rule.Annotations.Raw
 	alertRule.Labels = rule.Labels.Value()
 	for _, queryV1 := range rule.Data {
@@ -181,33 +314,61 @@ func (queryV1 *QueryV1) mapToModel() (models.AlertQuery, error) {
 // AlertingFileExport is the full provisioned file export.
 // swagger:model
 type AlertingFileExport struct {
-	APIVersion int64                  `json:"apiVersion" yaml:"apiVersion"`
-	Groups     []AlertRuleGroupExport `json:"groups" yaml:"groups"`
+	APIVersion    int64                        `json:"apiVersion" yaml:"apiVersion"`
+	Groups        []AlertRuleGroupExport       `json:"groups,omitempty" yaml:"groups,omitempty"`
+	ContactPoints []ContactPointExport         `json:"contactPoints,omitempty" yaml:"contactPoints,omitempty"`
+	Policies      []NotificationPolicyExport   `json:"policies,omitempty" yaml:"policies,omitempty"`
+	MuteTimes     []MuteTimeIntervalExport     `json:"muteTimes,omitempty" yaml:"muteTimes,omitempty"`
+	Templates     []NotificationTemplateExport `json:"templates,omitempty" yaml:"templates,omitempty"`
 }
 
 // AlertRuleGroupExport is the provisioned file export of AlertRuleGroupV1.
 type AlertRuleGroupExport struct {
-	OrgID    int64             `json:"orgId" yaml:"orgId"`
-	Name     string            `json:"name" yaml:"name"`
-	Folder   string            `json:"folder" yaml:"folder"`
-	Interval model.Duration    `json:"interval" yaml:"interval"`
-	Rules    []AlertRuleExport `json:"rules" yaml:"rules"`
+	OrgID             int64             `json:"orgId" yaml:"orgId"`
+	Name              string            `json:"name" yaml:"name"`
+	Folder            string            `json:"folder" yaml:"folder"`
+	Interval          model.Duration    `json:"interval" yaml:"interval"`
+	EvaluationTimeout model.Duration    `json:"evaluationTimeout,omitempty" yaml:"evaluationTimeout,omitempty"`
+	Rules             []AlertRuleExport `json:"rules" yaml:"rules"`
 }
 
 // AlertRuleExport is the provisioned file export of models.AlertRule.
 type AlertRuleExport struct {
-	UID          string                     `json:"uid" yaml:"uid"`
-	Title        string                     `json:"title" yaml:"title"`
-	Condition    string                     `json:"condition" yaml:"condition"`
-	Data         []AlertQueryExport         `json:"data" yaml:"data"`
-	DashboardUID string                     `json:"dasboardUid,omitempty" yaml:"dashboardUid,omitempty"`
-	PanelID      int64                      `json:"panelId,omitempty" yaml:"panelId,omitempty"`
-	NoDataState  models.NoDataState         `json:"noDataState" yaml:"noDataState"`
-	ExecErrState models.ExecutionErrorState `json:"execErrState" yaml:"execErrState"`
-	For          model.Duration             `json:"for" yaml:"for"`
-	Annotations  map[string]string          `json:"annotations,omitempty" yaml:"annotations,omitempty"`
-	Labels       map[string]string          `json:"labels,omitempty" yaml:"labels,omitempty"`
-	IsPaused     bool                       `json:"isPaused" yaml:"isPaused"`
+	UID                  string                               `json:"uid" yaml:"uid"`
+	Title                string                               `json:"title" yaml:"title"`
+	Condition            string                               `json:"condition" yaml:"condition"`
+	Data                 []AlertQueryExport                   `json:"data" yaml:"data"`
+	DashboardUID         string                               `json:"dasboardUid,omitempty" yaml:"dashboardUid,omitempty"`
+	PanelID              int64                                `json:"panelId,omitempty" yaml:"panelId,omitempty"`
+	NoDataState          models.NoDataState                   `json:"noDataState" yaml:"noDataState"`
+	ExecErrState         models.ExecutionErrorState           `json:"execErrState" yaml:"execErrState"`
+	For                  model.Duration                       `json:"for" yaml:"for"`
+	KeepFiringFor        model.Duration                       `json:"keepFiringFor,omitempty" yaml:"keepFiringFor,omitempty"`
+	Annotations          map[string]string                    `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Labels               map[string]string                    `json:"labels,omitempty" yaml:"labels,omitempty"`
+	IsPaused             bool                                 `json:"isPaused" yaml:"isPaused"`
+	Record               *AlertRuleRecordExport               `json:"record,omitempty" yaml:"record,omitempty"`
+	EvaluationTimeout    model.Duration                       `json:"evaluationTimeout,omitempty" yaml:"evaluationTimeout,omitempty"`
+	MaxSeriesPerQuery    int64                                `json:"maxSeriesPerQuery,omitempty" yaml:"maxSeriesPerQuery,omitempty"`
+	NotificationSettings *AlertRuleNotificationSettingsExport `json:"notificationSettings,omitempty" yaml:"notificationSettings,omitempty"`
+	DependsOn            []string                             `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+}
+
+// AlertRuleRecordExport is the provisioned export of models.Record.
+type AlertRuleRecordExport struct {
+	Metric              string `json:"metric" yaml:"metric"`
+	From                string `json:"from" yaml:"from"`
+	TargetDatasourceUID string `json:"targetDatasourceUid,omitempty" yaml:"targetDatasourceUid,omitempty"`
+}
+
+// AlertRuleNotificationSettingsExport is the provisioned export of models.NotificationSettings.
+type AlertRuleNotificationSettingsExport struct {
+	Receiver          string          `json:"receiver" yaml:"receiver"`
+	GroupBy           []string        `json:"groupBy,omitempty" yaml:"groupBy,omitempty"`
+	GroupWait         *model.Duration `json:"groupWait,omitempty" yaml:"groupWait,omitempty"`
+	GroupInterval     *model.Duration `json:"groupInterval,omitempty" yaml:"groupInterval,omitempty"`
+	RepeatInterval    *model.Duration `json:"repeatInterval,omitempty" yaml:"repeatInterval,omitempty"`
+	MuteTimeIntervals []string        `json:"muteTimeIntervals,omitempty" yaml:"muteTimeIntervals,omitempty"`
 }
 
 // AlertQueryExport is the provisioned export of models.AlertQuery.
@@ -243,11 +404,12 @@ func newAlertRuleGroupExport(d AlertRuleGroupWithFolderTitle) (AlertRuleGroupExp
 		rules = append(rules, alert)
 	}
 	return AlertRuleGroupExport{
-		OrgID:    d.OrgID,
-		Name:     d.Title,
-		Folder:   d.FolderTitle,
-		Interval: model.Duration(time.Duration(d.Interval) * time.Second),
-		Rules:    rules,
+		OrgID:             d.OrgID,
+		Name:              d.Title,
+		Folder:            d.FolderTitle,
+		Interval:          model.Duration(time.Duration(d.Interval) * time.Second),
+		EvaluationTimeout: model.Duration(d.EvaluationTimeout),
+		Rules:             rules,
 	}, nil
 }
 
@@ -272,19 +434,46 @@ func newAlertRuleExport(rule models.AlertRule) (AlertRuleExport, error) {
 		panelID = *rule.PanelID
 	}
 
+	var record *AlertRuleRecordExport
+	if rule.Record != nil {
+		record = &AlertRuleRecordExport{
+			Metric:              rule.Record.Metric,
+			From:                rule.Record.From,
+			TargetDatasourceUID: rule.Record.TargetDatasourceUID,
+		}
+	}
+
+	var notificationSettings *AlertRuleNotificationSettingsExport
+	if rule.NotificationSettings != nil {
+		notificationSettings = &AlertRuleNotificationSettingsExport{
+			Receiver:          rule.NotificationSettings.Receiver,
+			GroupBy:           rule.NotificationSettings.GroupBy,
+			GroupWait:         rule.NotificationSettings.GroupWait,
+			GroupInterval:     rule.NotificationSettings.GroupInterval,
+			RepeatInterval:    rule.NotificationSettings.RepeatInterval,
+			MuteTimeIntervals: rule.NotificationSettings.MuteTimeIntervals,
+		}
+	}
+
 	return AlertRuleExport{
-		UID:          rule.UID,
-		Title:        rule.Title,
-		For:          model.Duration(rule.For),
-		Condition:    rule.Condition,
-		Data:         data,
-		DashboardUID: dashboardUID,
-		PanelID:      panelID,
-		NoDataState:  rule.NoDataState,
-		ExecErrState: rule.ExecErrState,
-		Annotations:  rule.Annotations,
-		Labels:       rule.Labels,
-		IsPaused:     rule.IsPaused,
+		UID:                  rule.UID,
+		Title:                rule.Title,
+		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
+		Condition:            rule.Condition,
+		Data:                 data,
+		DashboardUID:         dashboardUID,
+		PanelID:              panelID,
+		NoDataState:          rule.NoDataState,
+		ExecErrState:         rule.ExecErrState,
+		Annotations:          rule.Annotations,
+		Labels:               rule.Labels,
+		IsPaused:             rule.IsPaused,
+		Record:               record,
+		EvaluationTimeout:    model.Duration(rule.EvaluationTimeout),
+		MaxSeriesPerQuery:    rule.MaxSeriesPerQuery,
+		NotificationSettings: notificationSettings,
+		DependsOn:            rule.DependsOn,
 	}, nil
 }
 
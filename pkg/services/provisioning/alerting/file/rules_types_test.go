@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
@@ -80,6 +81,36 @@ func TestRuleGroup(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, int64(1), rgMapped.OrgID)
 	})
+	t.Run("a rule group evaluation timeout should be set to all rules", func(t *testing.T) {
+		rg := validRuleGroupV1(t)
+		err := yaml.Unmarshal([]byte("5s"), &rg.EvaluationTimeout)
+		require.NoError(t, err)
+		withTimeout := validRuleV1(t)
+		err = yaml.Unmarshal([]byte("1s"), &withTimeout.EvaluationTimeout)
+		require.NoError(t, err)
+		rg.Rules = []AlertRuleV1{validRuleV1(t), withTimeout}
+		rgMapped, err := rg.MapToModel()
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Second, rgMapped.EvaluationTimeout)
+		require.Equal(t, 5*time.Second, rgMapped.Rules[0].GroupEvaluationTimeout)
+		require.Equal(t, 5*time.Second, rgMapped.Rules[1].GroupEvaluationTimeout)
+		require.Zero(t, rgMapped.Rules[0].EvaluationTimeout)
+		require.Equal(t, time.Second, rgMapped.Rules[1].EvaluationTimeout)
+	})
+	t.Run("a rule group evaluation timeout longer than the interval should error", func(t *testing.T) {
+		rg := validRuleGroupV1(t)
+		err := yaml.Unmarshal([]byte("2h"), &rg.EvaluationTimeout)
+		require.NoError(t, err)
+		_, err = rg.MapToModel()
+		require.Error(t, err)
+	})
+	t.Run("a rule group with an invalid evaluation timeout should error", func(t *testing.T) {
+		rg := validRuleGroupV1(t)
+		err := yaml.Unmarshal([]byte("10x"), &rg.EvaluationTimeout)
+		require.NoError(t, err)
+		_, err = rg.MapToModel()
+		require.Error(t, err)
+	})
 }
 
 func TestRules(t *testing.T) {
@@ -125,6 +156,83 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 48*time.Hour, ruleMapped.For)
 	})
+	t.Run("a rule with a keepFiringFor duration should map it correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		keepFiringFor := values.StringValue{}
+		err := yaml.Unmarshal([]byte("5m"), &keepFiringFor)
+		require.NoError(t, err)
+		rule.KeepFiringFor = keepFiringFor
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Minute, ruleMapped.KeepFiringFor)
+	})
+	t.Run("a rule with evaluation limits should map them correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		err := yaml.Unmarshal([]byte("30s"), &rule.EvaluationTimeout)
+		require.NoError(t, err)
+		err = yaml.Unmarshal([]byte("1000"), &rule.MaxSeriesPerQuery)
+		require.NoError(t, err)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, 30*time.Second, ruleMapped.EvaluationTimeout)
+		require.Equal(t, int64(1000), ruleMapped.MaxSeriesPerQuery)
+	})
+	t.Run("a rule with a negative maxSeriesPerQuery should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		err := yaml.Unmarshal([]byte("-1"), &rule.MaxSeriesPerQuery)
+		require.NoError(t, err)
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a rule with dependencies should map them correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		err := yaml.Unmarshal([]byte("[upstream_1, upstream_2]"), &rule.DependsOn)
+		require.NoError(t, err)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"upstream_1", "upstream_2"}, ruleMapped.DependsOn)
+	})
+	t.Run("a rule with an empty dependency should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		err := yaml.Unmarshal([]byte("['']"), &rule.DependsOn)
+		require.NoError(t, err)
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a rule with notification settings should map them correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		var settings NotificationSettingsV1
+		err := yaml.Unmarshal([]byte("receiver: team-a\ngroupBy: [alertname]\ngroupWait: 1m\nmuteTimeIntervals: [weekends]\n"), &settings)
+		require.NoError(t, err)
+		rule.NotificationSettings = &settings
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		groupWait := model.Duration(time.Minute)
+		require.Equal(t, &models.NotificationSettings{
+			Receiver:          "team-a",
+			GroupBy:           []string{"alertname"},
+			GroupWait:         &groupWait,
+			MuteTimeIntervals: []string{"weekends"},
+		}, ruleMapped.NotificationSettings)
+	})
+	t.Run("a rule with notification settings without receiver should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		var settings NotificationSettingsV1
+		err := yaml.Unmarshal([]byte("groupWait: 1m\n"), &settings)
+		require.NoError(t, err)
+		rule.NotificationSettings = &settings
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a rule with an invalid keepFiringFor duration should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		keepFiringFor := values.StringValue{}
+		err := yaml.Unmarshal([]byte("10x"), &keepFiringFor)
+		require.NoError(t, err)
+		rule.KeepFiringFor = keepFiringFor
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
 	t.Run("a rule with out a condition should error", func(t *testing.T) {
 		rule := validRuleV1(t)
 		rule.Condition = values.StringValue{}
@@ -187,6 +295,38 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, ruleMapped.NoDataState, models.NoData)
 	})
+	t.Run("a recording rule should map record and use it as condition", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Condition = values.StringValue{}
+		rule.For = values.StringValue{}
+		rule.Record = validRecordV1(t)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, models.RuleTypeRecording, ruleMapped.Type())
+		require.Equal(t, &models.Record{Metric: "test_metric", From: "A"}, ruleMapped.Record)
+		require.Equal(t, "A", ruleMapped.Condition)
+		require.Equal(t, time.Duration(0), ruleMapped.For)
+	})
+	t.Run("a recording rule with invalid metric name should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Record = validRecordV1(t)
+		metric := values.StringValue{}
+		err := yaml.Unmarshal([]byte("test-metric"), &metric)
+		require.NoError(t, err)
+		rule.Record.Metric = metric
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a recording rule with condition different from the recorded node should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Record = validRecordV1(t)
+		condition := values.StringValue{}
+		err := yaml.Unmarshal([]byte("B"), &condition)
+		require.NoError(t, err)
+		rule.Condition = condition
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
 }
 
 func validRuleGroupV1(t *testing.T) AlertRuleGroupV1 {
@@ -238,3 +378,19 @@ func validRuleV1(t *testing.T) AlertRuleV1 {
 		Data:      []QueryV1{{}},
 	}
 }
+
+func validRecordV1(t *testing.T) *RecordV1 {
+	t.Helper()
+	var (
+		metric values.StringValue
+		from   values.StringValue
+	)
+	err := yaml.Unmarshal([]byte("test_metric"), &metric)
+	require.NoError(t, err)
+	err = yaml.Unmarshal([]byte("A"), &from)
+	require.NoError(t, err)
+	return &RecordV1{
+		Metric: metric,
+		From:   from,
+	}
+}
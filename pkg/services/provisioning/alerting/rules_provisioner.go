@@ -40,7 +40,9 @@ type defaultAlertRuleProvisioner struct {
 func (prov *defaultAlertRuleProvisioner) Provision(ctx context.Context,
 	files []*AlertingFile) error {
 	for _, file := range files {
-		for _, group := range file.Groups {
+		folderUIDs := make([]string, len(file.Groups))
+		var rules []alert_models.AlertRule
+		for i, group := range file.Groups {
 			folderUID, err := prov.getOrCreateFolderUID(ctx, group.FolderTitle, group.OrgID)
 			if err != nil {
 				return err
@@ -50,15 +52,22 @@ func (prov *defaultAlertRuleProvisioner) Provision(ctx context.Context,
 				"folder", group.FolderTitle,
 				"folderUID", folderUID,
 				"name", group.Title)
+			folderUIDs[i] = folderUID
 			for _, rule := range group.Rules {
 				rule.NamespaceUID = folderUID
 				rule.RuleGroup = group.Title
-				err = prov.provisionRule(ctx, group.OrgID, rule)
-				if err != nil {
-					return err
-				}
+				rules = append(rules, rule)
 			}
-			err = prov.ruleService.UpdateRuleGroup(ctx, group.OrgID, folderUID, group.Title, group.Interval)
+		}
+		// the dependencies of a rule are validated when it is provisioned, so the rules it depends on are provisioned first.
+		for _, rule := range orderByDependencies(rules) {
+			err := prov.provisionRule(ctx, rule.OrgID, rule)
+			if err != nil {
+				return err
+			}
+		}
+		for i, group := range file.Groups {
+			err := prov.ruleService.UpdateRuleGroup(ctx, group.OrgID, folderUIDs[i], group.Title, group.Interval, group.EvaluationTimeout)
 			if err != nil {
 				return err
 			}
@@ -94,6 +103,35 @@ func (prov *defaultAlertRuleProvisioner) provisionRule(
 	return err
 }
 
+// orderByDependencies returns the rules ordered so that every rule comes after the rules of the same organization
+// it depends on. The order of the rules is kept otherwise.
+func orderByDependencies(rules []alert_models.AlertRule) []alert_models.AlertRule {
+	indexes := make(map[alert_models.AlertRuleKey]int, len(rules))
+	for i, rule := range rules {
+		indexes[rule.GetKey()] = i
+	}
+	visited := make([]bool, len(rules))
+	result := make([]alert_models.AlertRule, 0, len(rules))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		// marking the rule before its dependencies protects from cycles, which are rejected when the rules are provisioned.
+		visited[i] = true
+		for _, uid := range rules[i].DependsOn {
+			if j, ok := indexes[alert_models.AlertRuleKey{OrgID: rules[i].OrgID, UID: uid}]; ok {
+				visit(j)
+			}
+		}
+		result = append(result, rules[i])
+	}
+	for i := range rules {
+		visit(i)
+	}
+	return result
+}
+
 func (prov *defaultAlertRuleProvisioner) getOrCreateFolderUID(
 	ctx context.Context, folderName string, orgID int64) (string, error) {
 	cmd := &dashboards.GetDashboardQuery{
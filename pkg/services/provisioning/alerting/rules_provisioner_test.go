@@ -0,0 +1,40 @@
+package alerting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestOrderByDependencies(t *testing.T) {
+	rule := func(uid string, dependsOn ...string) models.AlertRule {
+		return models.AlertRule{OrgID: 1, UID: uid, DependsOn: dependsOn}
+	}
+
+	t.Run("rules come after the rules they depend on", func(t *testing.T) {
+		rules := []models.AlertRule{rule("a", "c"), rule("b"), rule("c", "b"), rule("d")}
+		require.Equal(t, []string{"b", "c", "a", "d"}, ruleUIDs(orderByDependencies(rules)))
+	})
+
+	t.Run("dependencies on rules of other organizations and other files are ignored", func(t *testing.T) {
+		other := rule("b")
+		other.OrgID = 2
+		rules := []models.AlertRule{rule("a", "b", "missing"), other}
+		require.Equal(t, []string{"a", "b"}, ruleUIDs(orderByDependencies(rules)))
+	})
+
+	t.Run("cycles do not prevent ordering", func(t *testing.T) {
+		rules := []models.AlertRule{rule("a", "b"), rule("b", "a")}
+		require.Equal(t, []string{"b", "a"}, ruleUIDs(orderByDependencies(rules)))
+	})
+}
+
+func ruleUIDs(rules []models.AlertRule) []string {
+	result := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, rule.UID)
+	}
+	return result
+}
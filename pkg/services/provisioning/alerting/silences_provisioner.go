@@ -0,0 +1,79 @@
+package alerting
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+type SilencesProvisioner interface {
+	Provision(ctx context.Context, files []*AlertingFile) error
+	Unprovision(ctx context.Context, files []*AlertingFile) error
+}
+
+type defaultSilencesProvisioner struct {
+	logger         log.Logger
+	silenceService *provisioning.SilenceService
+}
+
+// NewSilencesProvisioner creates a provisioner of silences. The silence service is nil if the Alertmanagers of
+// Grafana do not run, and then silences are not provisioned.
+func NewSilencesProvisioner(logger log.Logger,
+	silenceService *provisioning.SilenceService) SilencesProvisioner {
+	return &defaultSilencesProvisioner{
+		logger:         logger,
+		silenceService: silenceService,
+	}
+}
+
+// Provision creates or updates the silences of the files, and expires the provisioned silences that were removed
+// from the files.
+func (c *defaultSilencesProvisioner) Provision(ctx context.Context,
+	files []*AlertingFile) error {
+	uids := map[int64]map[string]struct{}{}
+	for _, file := range files {
+		for _, silence := range file.Silences {
+			if c.silenceService == nil {
+				c.logger.Warn("Silences cannot be provisioned because unified alerting is disabled", "file", file.Filename)
+				return nil
+			}
+			if uids[silence.OrgID] == nil {
+				uids[silence.OrgID] = map[string]struct{}{}
+			}
+			uids[silence.OrgID][silence.UID] = struct{}{}
+			// planned maintenance windows stay in the files after they end, which must not fail the provisioning
+			if time.Time(*silence.Silence.EndsAt).Before(time.Now()) {
+				c.logger.Debug("Skipping silence that has already ended", "uid", silence.UID, "org", silence.OrgID)
+				continue
+			}
+			id, err := c.silenceService.ProvisionSilence(ctx, silence.OrgID, silence.UID, silence.Silence)
+			if err != nil {
+				return err
+			}
+			c.logger.Debug("Provisioned silence", "uid", silence.UID, "org", silence.OrgID, "id", id)
+		}
+	}
+	if c.silenceService == nil {
+		return nil
+	}
+	return c.silenceService.PruneProvisionedSilences(ctx, uids)
+}
+
+func (c *defaultSilencesProvisioner) Unprovision(ctx context.Context,
+	files []*AlertingFile) error {
+	for _, file := range files {
+		for _, deleteSilence := range file.DeleteSilences {
+			if c.silenceService == nil {
+				c.logger.Warn("Silences cannot be deleted because unified alerting is disabled", "file", file.Filename)
+				return nil
+			}
+			err := c.silenceService.DeleteProvisionedSilence(ctx, deleteSilence.OrgID, deleteSilence.UID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// defaultSilenceCreatedBy is the author of provisioned silences that do not have one.
+const defaultSilenceCreatedBy = "provisioning"
+
+type SilenceV1 struct {
+	OrgID     values.Int64Value    `json:"orgId" yaml:"orgId"`
+	UID       values.StringValue   `json:"uid" yaml:"uid"`
+	StartsAt  values.StringValue   `json:"startsAt" yaml:"startsAt"`
+	EndsAt    values.StringValue   `json:"endsAt" yaml:"endsAt"`
+	Matchers  []values.StringValue `json:"matchers" yaml:"matchers"`
+	Comment   values.StringValue   `json:"comment" yaml:"comment"`
+	CreatedBy values.StringValue   `json:"createdBy" yaml:"createdBy"`
+}
+
+func (v1 *SilenceV1) mapToModel() (Silence, error) {
+	uid := strings.TrimSpace(v1.UID.Value())
+	if uid == "" {
+		return Silence{}, errors.New("silence missing uid")
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+
+	raw := strings.TrimSpace(v1.EndsAt.Value())
+	if raw == "" {
+		return Silence{}, fmt.Errorf("silence '%s' missing endsAt", uid)
+	}
+	endsAt, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return Silence{}, fmt.Errorf("silence '%s' has invalid endsAt: %w", uid, err)
+	}
+	ends := strfmt.DateTime(endsAt)
+	// the silence starts when it is provisioned if it does not have a start
+	var starts *strfmt.DateTime
+	if raw := strings.TrimSpace(v1.StartsAt.Value()); raw != "" {
+		startsAt, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return Silence{}, fmt.Errorf("silence '%s' has invalid startsAt: %w", uid, err)
+		}
+		if !endsAt.After(startsAt) {
+			return Silence{}, fmt.Errorf("silence '%s' must end after it starts", uid)
+		}
+		dt := strfmt.DateTime(startsAt)
+		starts = &dt
+	}
+
+	if len(v1.Matchers) == 0 {
+		return Silence{}, fmt.Errorf("silence '%s' missing matchers", uid)
+	}
+	matchers := make(amv2.Matchers, 0, len(v1.Matchers))
+	for _, m := range v1.Matchers {
+		matcher, err := labels.ParseMatcher(m.Value())
+		if err != nil {
+			return Silence{}, fmt.Errorf("silence '%s' has invalid matcher '%s': %w", uid, m.Value(), err)
+		}
+		isEqual := matcher.Type == labels.MatchEqual || matcher.Type == labels.MatchRegexp
+		isRegex := matcher.Type == labels.MatchRegexp || matcher.Type == labels.MatchNotRegexp
+		name, value := matcher.Name, matcher.Value
+		matchers = append(matchers, &amv2.Matcher{Name: &name, Value: &value, IsEqual: &isEqual, IsRegex: &isRegex})
+	}
+
+	comment := v1.Comment.Value()
+	createdBy := strings.TrimSpace(v1.CreatedBy.Value())
+	if createdBy == "" {
+		createdBy = defaultSilenceCreatedBy
+	}
+	return Silence{
+		OrgID: orgID,
+		UID:   uid,
+		Silence: amv2.Silence{
+			Matchers:  matchers,
+			StartsAt:  starts,
+			EndsAt:    &ends,
+			Comment:   &comment,
+			CreatedBy: &createdBy,
+		},
+	}, nil
+}
+
+type Silence struct {
+	OrgID   int64
+	UID     string
+	Silence amv2.Silence
+}
+
+type DeleteSilenceV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	UID   values.StringValue `json:"uid" yaml:"uid"`
+}
+
+func (v1 *DeleteSilenceV1) mapToModel() (DeleteSilence, error) {
+	uid := strings.TrimSpace(v1.UID.Value())
+	if uid == "" {
+		return DeleteSilence{}, errors.New("delete silence missing uid")
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	return DeleteSilence{
+		OrgID: orgID,
+		UID:   uid,
+	}, nil
+}
+
+type DeleteSilence struct {
+	OrgID int64
+	UID   string
+}
@@ -0,0 +1,120 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSilences(t *testing.T) {
+	t.Run("Valid config should not error on mapping", func(t *testing.T) {
+		s := silenceV1(t, validSilence)
+		silence, err := s.mapToModel()
+		require.NoError(t, err)
+		require.Equal(t, int64(1), silence.OrgID)
+		require.Equal(t, "db-maintenance", silence.UID)
+		require.Equal(t, time.Date(2023, 5, 1, 2, 0, 0, 0, time.UTC), time.Time(*silence.Silence.EndsAt).UTC())
+		require.Equal(t, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), time.Time(*silence.Silence.StartsAt).UTC())
+		require.Equal(t, defaultSilenceCreatedBy, *silence.Silence.CreatedBy)
+		require.Len(t, silence.Silence.Matchers, 2)
+		require.Equal(t, "service", *silence.Silence.Matchers[0].Name)
+		require.True(t, *silence.Silence.Matchers[0].IsEqual)
+		require.False(t, *silence.Silence.Matchers[0].IsRegex)
+		require.Equal(t, "instance", *silence.Silence.Matchers[1].Name)
+		require.False(t, *silence.Silence.Matchers[1].IsEqual)
+		require.True(t, *silence.Silence.Matchers[1].IsRegex)
+	})
+	t.Run("Missing start should start when provisioned", func(t *testing.T) {
+		s := silenceV1(t, `
+uid: db-maintenance
+endsAt: 2023-05-01T02:00:00Z
+matchers:
+  - service=db
+`)
+		silence, err := s.mapToModel()
+		require.NoError(t, err)
+		require.Nil(t, silence.Silence.StartsAt)
+	})
+	t.Run("Missing UID should error on mapping", func(t *testing.T) {
+		s := silenceV1(t, `
+endsAt: 2023-05-01T02:00:00Z
+matchers:
+  - service=db
+`)
+		_, err := s.mapToModel()
+		require.Error(t, err)
+	})
+	t.Run("Missing end should error on mapping", func(t *testing.T) {
+		s := silenceV1(t, `
+uid: db-maintenance
+matchers:
+  - service=db
+`)
+		_, err := s.mapToModel()
+		require.Error(t, err)
+	})
+	t.Run("End before start should error on mapping", func(t *testing.T) {
+		s := silenceV1(t, `
+uid: db-maintenance
+startsAt: 2023-05-01T02:00:00Z
+endsAt: 2023-05-01T00:00:00Z
+matchers:
+  - service=db
+`)
+		_, err := s.mapToModel()
+		require.Error(t, err)
+	})
+	t.Run("Missing matchers should error on mapping", func(t *testing.T) {
+		s := silenceV1(t, `
+uid: db-maintenance
+endsAt: 2023-05-01T02:00:00Z
+`)
+		_, err := s.mapToModel()
+		require.Error(t, err)
+	})
+	t.Run("Invalid matcher should error on mapping", func(t *testing.T) {
+		s := silenceV1(t, `
+uid: db-maintenance
+endsAt: 2023-05-01T02:00:00Z
+matchers:
+  - service
+`)
+		_, err := s.mapToModel()
+		require.Error(t, err)
+	})
+}
+
+func TestDeleteSilences(t *testing.T) {
+	t.Run("Valid config should not error on mapping", func(t *testing.T) {
+		var s DeleteSilenceV1
+		require.NoError(t, yaml.Unmarshal([]byte("orgId: 2\nuid: db-maintenance\n"), &s))
+		silence, err := s.mapToModel()
+		require.NoError(t, err)
+		require.Equal(t, DeleteSilence{OrgID: 2, UID: "db-maintenance"}, silence)
+	})
+	t.Run("Missing UID should error on mapping", func(t *testing.T) {
+		var s DeleteSilenceV1
+		require.NoError(t, yaml.Unmarshal([]byte("orgId: 2\n"), &s))
+		_, err := s.mapToModel()
+		require.Error(t, err)
+	})
+}
+
+const validSilence = `
+uid: db-maintenance
+startsAt: 2023-05-01T00:00:00Z
+endsAt: 2023-05-01T02:00:00Z
+comment: Database upgrade
+matchers:
+  - service=db
+  - instance!~db-[0-9]+
+`
+
+func silenceV1(t *testing.T, raw string) SilenceV1 {
+	t.Helper()
+	var s SilenceV1
+	require.NoError(t, yaml.Unmarshal([]byte(raw), &s))
+	return s
+}
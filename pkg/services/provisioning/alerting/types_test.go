@@ -0,0 +1,93 @@
+package alerting
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting/file"
+)
+
+func TestAlertingFileExportCanBeProvisioned(t *testing.T) {
+	duration := model.Duration(time.Minute)
+	teamA, err := labels.NewMatcher(labels.MatchEqual, "team", "a")
+	require.NoError(t, err)
+	tree := definitions.Route{
+		Receiver:   "webhook",
+		GroupByStr: []string{"alertname"},
+		GroupWait:  &duration,
+		Routes: []*definitions.Route{{
+			Receiver:          "webhook",
+			ObjectMatchers:    definitions.ObjectMatchers{teamA},
+			MuteTimeIntervals: []string{"weekends"},
+			Continue:          true,
+		}},
+	}
+	var intervals []timeinterval.TimeInterval
+	require.NoError(t, yaml.Unmarshal([]byte(`[{weekdays: ['saturday', 'sunday'], times: [{start_time: '06:00', end_time: '23:59'}]}]`), &intervals))
+	muteTiming := definitions.MuteTimeInterval{MuteTimeInterval: config.MuteTimeInterval{Name: "weekends", TimeIntervals: intervals}}
+	contactPoints, err := definitions.NewContactPointExports(2, []definitions.EmbeddedContactPoint{
+		{UID: "webhook-1", Name: "webhook", Type: "webhook", Settings: simplejson.NewFromAny(map[string]any{"url": "http://localhost/1"})},
+		{UID: "webhook-2", Name: "webhook", Type: "webhook", Settings: simplejson.NewFromAny(map[string]any{"url": "http://localhost/2"}), DisableResolveMessage: true},
+	})
+	require.NoError(t, err)
+
+	export := file.AlertingFileExport{
+		APIVersion:    1,
+		ContactPoints: contactPoints,
+		Policies:      []file.NotificationPolicyExport{definitions.NewNotificationPolicyExport(2, tree)},
+		MuteTimes:     []file.MuteTimeIntervalExport{definitions.NewMuteTimeIntervalExport(2, muteTiming)},
+		Templates: []file.NotificationTemplateExport{definitions.NewNotificationTemplateExport(2, definitions.NotificationTemplate{
+			Name:     "message",
+			Template: `{{ define "message" }}{{ .Status }}{{ end }}`,
+		})},
+	}
+
+	for name, marshal := range map[string]func(any) ([]byte, error){"yaml": yaml.Marshal, "json": json.Marshal} {
+		t.Run(name, func(t *testing.T) {
+			b, err := marshal(export)
+			require.NoError(t, err)
+			var fileV1 AlertingFileV1
+			require.NoError(t, yaml.Unmarshal(b, &fileV1))
+			result, err := fileV1.MapToModel()
+			require.NoError(t, err)
+
+			require.Len(t, result.ContactPoints, 1)
+			require.Equal(t, int64(2), result.ContactPoints[0].OrgID)
+			require.Len(t, result.ContactPoints[0].ContactPoints, 2)
+			require.Equal(t, "webhook-2", result.ContactPoints[0].ContactPoints[1].UID)
+			require.Equal(t, "http://localhost/2", result.ContactPoints[0].ContactPoints[1].Settings.Get("url").MustString())
+			require.True(t, result.ContactPoints[0].ContactPoints[1].DisableResolveMessage)
+
+			require.Len(t, result.Policies, 1)
+			require.Equal(t, int64(2), result.Policies[0].OrgID)
+			policy := result.Policies[0].Policy
+			require.Equal(t, tree.Receiver, policy.Receiver)
+			require.Equal(t, tree.GroupByStr, policy.GroupByStr)
+			require.Equal(t, tree.GroupWait, policy.GroupWait)
+			require.Len(t, policy.Routes, 1)
+			require.Len(t, policy.Routes[0].ObjectMatchers, 1)
+			require.Equal(t, teamA.String(), policy.Routes[0].ObjectMatchers[0].String())
+			require.Equal(t, tree.Routes[0].MuteTimeIntervals, policy.Routes[0].MuteTimeIntervals)
+			require.True(t, policy.Routes[0].Continue)
+
+			require.Len(t, result.MuteTimes, 1)
+			require.Equal(t, int64(2), result.MuteTimes[0].OrgID)
+			require.Equal(t, muteTiming.MuteTimeInterval, result.MuteTimes[0].MuteTime.MuteTimeInterval)
+
+			require.Len(t, result.Templates, 1)
+			require.Equal(t, int64(2), result.Templates[0].OrgID)
+			require.Equal(t, "message", result.Templates[0].Data.Name)
+			require.Equal(t, `{{ define "message" }}{{ .Status }}{{ end }}`, result.Templates[0].Data.Template)
+		})
+	}
+}
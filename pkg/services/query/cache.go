@@ -0,0 +1,190 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const cacheKeyPrefix = "query-result:"
+
+var (
+	queryCacheHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "query_cache_hits_total",
+		Help:      "The number of queries whose response was found in the query result cache.",
+	}, []string{"datasource_type"})
+	queryCacheMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "query_cache_misses_total",
+		Help:      "The number of queries whose response was not found in the query result cache.",
+	}, []string{"datasource_type"})
+)
+
+// ignoredQueryFields are the fields of the JSON of queries that do not change their responses.
+var ignoredQueryFields = []string{"refId", "requestId", "datasource", "datasourceId"}
+
+// ResultCache caches the responses of queries.
+type ResultCache interface {
+	// Get returns the cached response of the key, or false if there is none.
+	Get(ctx context.Context, key string) (backend.DataResponse, bool, error)
+	// Set caches the response of the key for ttl.
+	Set(ctx context.Context, key string, resp backend.DataResponse, ttl time.Duration) error
+}
+
+// RemoteResultCache caches the responses of queries in the remote cache, which is the database, Redis or Memcached.
+type RemoteResultCache struct {
+	storage remotecache.CacheStorage
+}
+
+func NewRemoteResultCache(storage remotecache.CacheStorage) *RemoteResultCache {
+	return &RemoteResultCache{storage: storage}
+}
+
+// cachedResponse is a response in the cache. Frames are encoded with Arrow.
+type cachedResponse struct {
+	Frames [][]byte
+	Status int
+}
+
+func (c *RemoteResultCache) Get(ctx context.Context, key string) (backend.DataResponse, bool, error) {
+	b, err := c.storage.GetByteArray(ctx, key)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return backend.DataResponse{}, false, nil
+		}
+		return backend.DataResponse{}, false, err
+	}
+	var cached cachedResponse
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&cached); err != nil {
+		return backend.DataResponse{}, false, fmt.Errorf("failed to decode cached response: %w", err)
+	}
+	frames, err := data.UnmarshalArrowFrames(cached.Frames)
+	if err != nil {
+		return backend.DataResponse{}, false, fmt.Errorf("failed to decode frames of cached response: %w", err)
+	}
+	return backend.DataResponse{Frames: frames, Status: backend.Status(cached.Status)}, true, nil
+}
+
+func (c *RemoteResultCache) Set(ctx context.Context, key string, resp backend.DataResponse, ttl time.Duration) error {
+	frames, err := resp.Frames.MarshalArrow()
+	if err != nil {
+		return fmt.Errorf("failed to encode frames: %w", err)
+	}
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(cachedResponse{Frames: frames, Status: int(resp.Status)}); err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	return c.storage.SetByteArray(ctx, key, b.Bytes(), ttl)
+}
+
+// queryDataWithCache returns the cached responses of the queries of the request, and queries the data source for the
+// others. Successful responses are cached for ttl. If skipCache is true, the data source is queried for all queries.
+func (s *Service) queryDataWithCache(ctx context.Context, user *user.SignedInUser, ds *datasources.DataSource, req *backend.QueryDataRequest, skipCache bool, ttl time.Duration) (*backend.QueryDataResponse, error) {
+	scope := s.cacheScope(ds, user)
+	resp := backend.NewQueryDataResponse()
+	keys := make(map[string]string, len(req.Queries))
+	misses := make([]backend.DataQuery, 0, len(req.Queries))
+	for _, q := range req.Queries {
+		key, err := cacheKey(ds, q, scope, s.cfg.QueryCaching.TimeRangeAlignment)
+		if err != nil {
+			s.log.Warn("Failed to create the cache key of the query", "datasource", ds.UID, "refId", q.RefID, "error", err)
+			misses = append(misses, q)
+			continue
+		}
+		keys[q.RefID] = key
+		if !skipCache {
+			cached, ok, err := s.resultCache.Get(ctx, key)
+			if err != nil {
+				s.log.Warn("Failed to get the response of the query from the cache", "datasource", ds.UID, "refId", q.RefID, "error", err)
+			} else if ok {
+				queryCacheHits.WithLabelValues(ds.Type).Inc()
+				for _, frame := range cached.Frames {
+					frame.RefID = q.RefID
+				}
+				resp.Responses[q.RefID] = cached
+				continue
+			}
+		}
+		queryCacheMisses.WithLabelValues(ds.Type).Inc()
+		misses = append(misses, q)
+	}
+
+	if len(misses) == 0 {
+		return resp, nil
+	}
+
+	missReq := *req
+	missReq.Queries = misses
+	missResp, err := s.queryDataSource(ctx, user, ds, &missReq)
+	if err != nil {
+		return nil, err
+	}
+	for refID, r := range missResp.Responses {
+		resp.Responses[refID] = r
+		key, ok := keys[refID]
+		if !ok || r.Error != nil {
+			continue
+		}
+		if err := s.resultCache.Set(ctx, key, r, ttl); err != nil {
+			s.log.Warn("Failed to cache the response of the query", "datasource", ds.UID, "refId", refID, "error", err)
+		}
+	}
+	return resp, nil
+}
+
+// cacheScope returns the scope of the permissions of cached responses. Responses are shared by the users of the
+// organization, unless the data source receives the identity of the user, in which case they are cached per user.
+func (s *Service) cacheScope(ds *datasources.DataSource, user *user.SignedInUser) string {
+	perUser := s.cfg.SendUserHeader
+	if ds.JsonData != nil {
+		perUser = perUser || ds.JsonData.Get("oauthPassThru").MustBool() || len(ds.JsonData.Get("keepCookies").MustStringArray()) > 0
+	}
+	if perUser && user != nil {
+		return fmt.Sprintf("org:%d:user:%d", ds.OrgID, user.UserID)
+	}
+	return fmt.Sprintf("org:%d", ds.OrgID)
+}
+
+// cacheKey returns the key of the response of the query. The key depends on the data source and its version, the
+// scope, the JSON of the query without the fields that do not change the response, and the time range of the query
+// aligned to alignment.
+func cacheKey(ds *datasources.DataSource, q backend.DataQuery, scope string, alignment time.Duration) (string, error) {
+	var model map[string]interface{}
+	if err := json.Unmarshal(q.JSON, &model); err != nil {
+		return "", err
+	}
+	for _, field := range ignoredQueryFields {
+		delete(model, field)
+	}
+	// The keys of maps are sorted, so equal queries have the same JSON.
+	normalized, err := json.Marshal(model)
+	if err != nil {
+		return "", err
+	}
+
+	from, to := q.TimeRange.From, q.TimeRange.To
+	if alignment > 0 {
+		from, to = from.Truncate(alignment), to.Truncate(alignment)
+	}
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\n%d\n%s\n%d\n%d\n%d\n%d\n%s\n%s", ds.UID, ds.Version, scope, from.UnixMilli(), to.UnixMilli(),
+		q.MaxDataPoints, q.Interval.Milliseconds(), q.QueryType, normalized)
+	return cacheKeyPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,224 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestQueryDataWithCache(t *testing.T) {
+	ds := &datasources.DataSource{UID: "ds1", Type: "prometheus", OrgID: 1}
+	user1 := &user.SignedInUser{OrgID: 1, UserID: 1}
+	user2 := &user.SignedInUser{OrgID: 1, UserID: 2}
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	query := func(refID string, from time.Time, model string) backend.DataQuery {
+		return backend.DataQuery{
+			RefID:     refID,
+			JSON:      json.RawMessage(model),
+			TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+		}
+	}
+
+	t.Run("should return the cached response of equal queries", func(t *testing.T) {
+		s, pc, _ := setupCache(t, &setting.Cfg{})
+		q := query("A", now, `{"refId": "A", "expr": "up"}`)
+		resp, err := s.queryDataWithCache(context.Background(), user1, ds, &backend.QueryDataRequest{Queries: []backend.DataQuery{q}}, false, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, "A", resp.Responses["A"].Frames[0].RefID)
+		require.Equal(t, 1, pc.queries)
+
+		// The same query with another refId and a close time range.
+		q = query("B", now.Add(2*time.Second), `{"expr": "up", "refId": "B", "datasource": {"uid": "ds1"}}`)
+		resp, err = s.queryDataWithCache(context.Background(), user1, ds, &backend.QueryDataRequest{Queries: []backend.DataQuery{q}}, false, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 1, pc.queries)
+		require.Equal(t, "B", resp.Responses["B"].Frames[0].RefID)
+	})
+
+	t.Run("should query only the queries that are not cached", func(t *testing.T) {
+		s, pc, _ := setupCache(t, &setting.Cfg{})
+		a := query("A", now, `{"expr": "up"}`)
+		_, err := s.queryDataWithCache(context.Background(), user1, ds, &backend.QueryDataRequest{Queries: []backend.DataQuery{a}}, false, time.Minute)
+		require.NoError(t, err)
+
+		b := query("B", now, `{"expr": "down"}`)
+		resp, err := s.queryDataWithCache(context.Background(), user1, ds, &backend.QueryDataRequest{Queries: []backend.DataQuery{a, b}}, false, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, resp.Responses, 2)
+		require.Equal(t, 2, pc.queries)
+		require.Len(t, pc.req.Queries, 1)
+		require.Equal(t, "B", pc.req.Queries[0].RefID)
+	})
+
+	t.Run("should query the data source if skipCache is true", func(t *testing.T) {
+		s, pc, _ := setupCache(t, &setting.Cfg{})
+		req := &backend.QueryDataRequest{Queries: []backend.DataQuery{query("A", now, `{"expr": "up"}`)}}
+		_, err := s.queryDataWithCache(context.Background(), user1, ds, req, false, time.Minute)
+		require.NoError(t, err)
+		_, err = s.queryDataWithCache(context.Background(), user1, ds, req, true, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 2, pc.queries)
+	})
+
+	t.Run("should not cache errors", func(t *testing.T) {
+		s, pc, cache := setupCache(t, &setting.Cfg{})
+		pc.err = true
+		req := &backend.QueryDataRequest{Queries: []backend.DataQuery{query("A", now, `{"expr": "up"}`)}}
+		_, err := s.queryDataWithCache(context.Background(), user1, ds, req, false, time.Minute)
+		require.NoError(t, err)
+		require.Empty(t, cache.items)
+	})
+
+	t.Run("should cache responses per user if the data source receives the identity of users", func(t *testing.T) {
+		s, pc, _ := setupCache(t, &setting.Cfg{})
+		oauthDS := &datasources.DataSource{UID: "ds1", Type: "prometheus", OrgID: 1, JsonData: simplejson.NewFromAny(map[string]interface{}{"oauthPassThru": true})}
+		req := &backend.QueryDataRequest{Queries: []backend.DataQuery{query("A", now, `{"expr": "up"}`)}}
+		_, err := s.queryDataWithCache(context.Background(), user1, oauthDS, req, false, time.Minute)
+		require.NoError(t, err)
+		_, err = s.queryDataWithCache(context.Background(), user2, oauthDS, req, false, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 2, pc.queries)
+
+		_, err = s.queryDataWithCache(context.Background(), user2, ds, req, false, time.Minute)
+		require.NoError(t, err)
+		_, err = s.queryDataWithCache(context.Background(), user1, ds, req, false, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 3, pc.queries)
+	})
+}
+
+func TestCacheKey(t *testing.T) {
+	ds := &datasources.DataSource{UID: "ds1"}
+	from := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	q := backend.DataQuery{
+		RefID:     "A",
+		JSON:      json.RawMessage(`{"refId": "A", "expr": "up", "legendFormat": "{{ job }}"}`),
+		TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+	}
+	key, err := cacheKey(ds, q, "org:1", 10*time.Second)
+	require.NoError(t, err)
+
+	same := q
+	same.RefID = "B"
+	same.JSON = json.RawMessage(`{"legendFormat": "{{ job }}", "expr": "up", "refId": "B", "requestId": "1"}`)
+	same.TimeRange = backend.TimeRange{From: from.Add(5 * time.Second), To: from.Add(time.Hour + 5*time.Second)}
+	sameKey, err := cacheKey(ds, same, "org:1", 10*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, key, sameKey)
+
+	other := q
+	other.JSON = json.RawMessage(`{"expr": "down"}`)
+	otherKey, err := cacheKey(ds, other, "org:1", 10*time.Second)
+	require.NoError(t, err)
+	require.NotEqual(t, key, otherKey)
+
+	other = q
+	other.TimeRange = backend.TimeRange{From: from.Add(time.Minute), To: from.Add(time.Hour + time.Minute)}
+	otherKey, err = cacheKey(ds, other, "org:1", 10*time.Second)
+	require.NoError(t, err)
+	require.NotEqual(t, key, otherKey)
+
+	otherKey, err = cacheKey(ds, q, "org:2", 10*time.Second)
+	require.NoError(t, err)
+	require.NotEqual(t, key, otherKey)
+
+	otherKey, err = cacheKey(&datasources.DataSource{UID: "ds1", Version: 2}, q, "org:1", 10*time.Second)
+	require.NoError(t, err)
+	require.NotEqual(t, key, otherKey)
+}
+
+func TestIntegrationRemoteResultCache(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	cache := NewRemoteResultCache(remotecache.NewFakeStore(t))
+
+	_, ok, err := cache.Get(context.Background(), "missing")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	frame := data.NewFrame("up", data.NewField("value", nil, []float64{1, 2}))
+	require.NoError(t, cache.Set(context.Background(), "key", backend.DataResponse{Frames: data.Frames{frame}, Status: backend.StatusOK}, time.Minute))
+
+	resp, ok, err := cache.Get(context.Background(), "key")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, backend.StatusOK, resp.Status)
+	require.Len(t, resp.Frames, 1)
+	require.Equal(t, "up", resp.Frames[0].Name)
+	require.Equal(t, 2, resp.Frames[0].Rows())
+}
+
+func setupCache(t *testing.T, cfg *setting.Cfg) (*Service, *countingPluginClient, *fakeResultCache) {
+	t.Helper()
+	cfg.QueryCaching = setting.QueryCachingSettings{Enabled: true, TTL: time.Minute, TimeRangeAlignment: 10 * time.Second}
+	pc := &countingPluginClient{}
+	cache := &fakeResultCache{items: map[string]backend.DataResponse{}}
+	return &Service{
+		cfg:          cfg,
+		pluginClient: pc,
+		resultCache:  cache,
+		log:          log.New("test.logger"),
+	}, pc, cache
+}
+
+// countingPluginClient counts the queries and responds with a frame per query.
+type countingPluginClient struct {
+	plugins.Client
+	req     *backend.QueryDataRequest
+	queries int
+	err     bool
+}
+
+func (c *countingPluginClient) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	c.req = req
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		c.queries++
+		if c.err {
+			resp.Responses[q.RefID] = backend.DataResponse{Error: context.DeadlineExceeded}
+			continue
+		}
+		frame := data.NewFrame("", data.NewField("value", nil, []float64{1}))
+		frame.RefID = q.RefID
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{frame}}
+	}
+	return resp, nil
+}
+
+type fakeResultCache struct {
+	items map[string]backend.DataResponse
+}
+
+func (c *fakeResultCache) Get(ctx context.Context, key string) (backend.DataResponse, bool, error) {
+	resp, ok := c.items[key]
+	if !ok {
+		return backend.DataResponse{}, false, nil
+	}
+	// Copy the frames, as the service sets their refId.
+	frames := make(data.Frames, 0, len(resp.Frames))
+	for _, f := range resp.Frames {
+		copied := *f
+		frames = append(frames, &copied)
+	}
+	return backend.DataResponse{Frames: frames, Status: resp.Status}, true, nil
+}
+
+func (c *fakeResultCache) Set(ctx context.Context, key string, resp backend.DataResponse, ttl time.Duration) error {
+	c.items[key] = resp
+	return nil
+}
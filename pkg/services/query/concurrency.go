@@ -0,0 +1,232 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var (
+	queryCoalesced = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "query_coalesced_total",
+		Help:      "The number of requests that shared the response of an identical request in flight to the data source.",
+	}, []string{"datasource_type"})
+	queryInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "query_datasource_in_flight",
+		Help:      "The number of requests in flight to data sources with a limit of concurrent queries.",
+	}, []string{"datasource_type"})
+	queryQueued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Name:      "query_datasource_queued",
+		Help:      "The number of requests waiting for data sources with a limit of concurrent queries.",
+	}, []string{"datasource_type"})
+	queryQueueWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Name:      "query_datasource_queue_wait_seconds",
+		Help:      "How long requests waited for data sources with a limit of concurrent queries.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+	}, []string{"datasource_type"})
+	queryRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "query_datasource_rejected_total",
+		Help:      "The number of requests rejected because the queue of the data source was full or they waited too long.",
+	}, []string{"datasource_type", "reason"})
+)
+
+// queryDataSource sends the request to the data source. Identical requests in flight at the same time share one
+// request to the data source, and the requests are limited to the maximum number of concurrent queries of the data
+// source. Requests with expressions, and the queries of alert rules, are sent by the expression service through the
+// plugin client directly, so they are neither coalesced nor limited.
+func (s *Service) queryDataSource(ctx context.Context, user *user.SignedInUser, ds *datasources.DataSource, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if !s.cfg.QueryConcurrency.CoalesceRequests {
+		return s.queryDataSourceWithLimit(ctx, ds, req)
+	}
+
+	key, err := coalescingKey(ds, req, s.cacheScope(ds, user))
+	if err != nil {
+		s.log.Warn("Failed to create the coalescing key of the request", "datasource", ds.UID, "error", err)
+		return s.queryDataSourceWithLimit(ctx, ds, req)
+	}
+
+	leader := false
+	ch := s.flights.DoChan(key, func() (interface{}, error) {
+		leader = true
+		// The request is not canceled when the caller that started it goes away, as other callers can wait for it.
+		return s.queryDataSourceWithLimit(detachedContext{ctx}, ds, req)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if !leader {
+			queryCoalesced.WithLabelValues(ds.Type).Inc()
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		shared, _ := res.Val.(*backend.QueryDataResponse)
+		if !res.Shared || shared == nil {
+			return shared, nil
+		}
+		// Callers modify the frames of their responses, e.g. to remove the metadata of public dashboards, so each
+		// caller of a shared response gets its own copy of the frames.
+		resp := backend.NewQueryDataResponse()
+		for refID, r := range shared.Responses {
+			r.Frames = copyFrames(r.Frames)
+			resp.Responses[refID] = r
+		}
+		return resp, nil
+	}
+}
+
+// queryDataSourceWithLimit sends the request to the data source once the number of requests in flight to the data
+// source is below its limit.
+func (s *Service) queryDataSourceWithLimit(ctx context.Context, ds *datasources.DataSource, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	l := s.limiter(ds)
+	if l == nil {
+		return s.pluginClient.QueryData(ctx, req)
+	}
+	if err := l.acquire(ctx, s.cfg.QueryConcurrency.MaxQueuedQueries, s.cfg.QueryConcurrency.QueueTimeout, ds.Type); err != nil {
+		return nil, err
+	}
+	queryInFlight.WithLabelValues(ds.Type).Inc()
+	defer func() {
+		queryInFlight.WithLabelValues(ds.Type).Dec()
+		l.release()
+	}()
+	return s.pluginClient.QueryData(ctx, req)
+}
+
+// limiter returns the limiter of the data source, or nil if its number of concurrent queries is unlimited.
+func (s *Service) limiter(ds *datasources.DataSource) *concurrencyLimiter {
+	limit := s.cfg.QueryConcurrency.MaxConcurrentQueriesForDataSource(ds.UID)
+	if limit <= 0 {
+		return nil
+	}
+	s.limitersMtx.Lock()
+	defer s.limitersMtx.Unlock()
+	if s.limiters == nil {
+		s.limiters = make(map[string]*concurrencyLimiter)
+	}
+	l, ok := s.limiters[ds.UID]
+	if !ok {
+		l = &concurrencyLimiter{slots: make(chan struct{}, limit)}
+		s.limiters[ds.UID] = l
+	}
+	return l
+}
+
+// concurrencyLimiter limits the number of requests in flight to a data source.
+type concurrencyLimiter struct {
+	slots  chan struct{}
+	queued int64
+}
+
+// acquire waits until a request can be sent to the data source. It returns ErrQueryQueueFull if maxQueued requests
+// are already waiting, and ErrQueryQueueTimeout if the request waits longer than timeout.
+func (l *concurrencyLimiter) acquire(ctx context.Context, maxQueued int, timeout time.Duration, dsType string) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if atomic.AddInt64(&l.queued, 1) > int64(maxQueued) {
+		atomic.AddInt64(&l.queued, -1)
+		queryRejected.WithLabelValues(dsType, "queue_full").Inc()
+		return ErrQueryQueueFull
+	}
+	queryQueued.WithLabelValues(dsType).Inc()
+	defer func() {
+		atomic.AddInt64(&l.queued, -1)
+		queryQueued.WithLabelValues(dsType).Dec()
+	}()
+
+	start := time.Now()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		queryQueueWait.WithLabelValues(dsType).Observe(time.Since(start).Seconds())
+		return nil
+	case <-timer.C:
+		queryRejected.WithLabelValues(dsType, "timeout").Inc()
+		return ErrQueryQueueTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *concurrencyLimiter) release() {
+	<-l.slots
+}
+
+// coalescingKey returns the key of the request. Requests with the same key get the same response from the data
+// source: they have the same queries with the same refIds and time ranges, and the same scope of permissions.
+func coalescingKey(ds *datasources.DataSource, req *backend.QueryDataRequest, scope string) (string, error) {
+	h := sha256.New()
+	for _, q := range req.Queries {
+		key, err := cacheKey(ds, q, scope, 0)
+		if err != nil {
+			return "", err
+		}
+		_, _ = fmt.Fprintf(h, "%s\n%s\n", q.RefID, key)
+	}
+	return ds.UID + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// copyFrames returns a deep copy of the frames.
+func copyFrames(frames data.Frames) data.Frames {
+	if frames == nil {
+		return nil
+	}
+	result := make(data.Frames, len(frames))
+	for i, frame := range frames {
+		if frame == nil {
+			continue
+		}
+		c := frame.EmptyCopy()
+		for j, field := range frame.Fields {
+			f := c.Fields[j]
+			f.Extend(field.Len())
+			for row := 0; row < field.Len(); row++ {
+				f.Set(row, field.CopyAt(row))
+			}
+			if field.Config != nil {
+				config := *field.Config
+				f.Config = &config
+			}
+		}
+		if frame.Meta != nil {
+			meta := *frame.Meta
+			meta.Stats = append([]data.QueryStat(nil), frame.Meta.Stats...)
+			meta.Notices = append([]data.Notice(nil), frame.Meta.Notices...)
+			c.Meta = &meta
+		}
+		result[i] = c
+	}
+	return result
+}
+
+// detachedContext keeps the values of the context, but is never canceled.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (c detachedContext) Done() <-chan struct{}             { return nil }
+func (c detachedContext) Err() error                        { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
@@ -0,0 +1,234 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestQueryDataSource(t *testing.T) {
+	ds := &datasources.DataSource{UID: "ds1", Type: "prometheus", OrgID: 1}
+	signedInUser := &user.SignedInUser{OrgID: 1, UserID: 1}
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	request := func(model string) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{Queries: []backend.DataQuery{{
+			RefID:     "A",
+			JSON:      json.RawMessage(model),
+			TimeRange: backend.TimeRange{From: now, To: now.Add(time.Hour)},
+		}}}
+	}
+
+	t.Run("should coalesce identical requests in flight", func(t *testing.T) {
+		s, pc := setupConcurrency(t, setting.QueryConcurrencySettings{CoalesceRequests: true})
+
+		var wg sync.WaitGroup
+		responses := make([]*backend.QueryDataResponse, 3)
+		for i := range responses {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "up"}`))
+				require.NoError(t, err)
+				responses[i] = resp
+			}()
+		}
+		// Wait for the first request to reach the data source, and the others to wait for it.
+		<-pc.started
+		require.Eventually(t, func() bool { return atomic.LoadInt64(&pc.calls) == 1 }, time.Second, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		close(pc.unblock)
+		wg.Wait()
+
+		require.Equal(t, int64(1), atomic.LoadInt64(&pc.calls))
+		for _, resp := range responses {
+			require.Contains(t, resp.Responses, "A")
+		}
+	})
+
+	t.Run("should give a copy of the frames to each caller of a coalesced request", func(t *testing.T) {
+		s, pc := setupConcurrency(t, setting.QueryConcurrencySettings{CoalesceRequests: true})
+
+		var wg sync.WaitGroup
+		responses := make([]*backend.QueryDataResponse, 2)
+		for i := range responses {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "up"}`))
+				require.NoError(t, err)
+				responses[i] = resp
+				// The first caller removes the metadata like public dashboards do, while the second one reads it.
+				frame := resp.Responses["A"].Frames[0]
+				if i == 0 {
+					frame.Meta.ExecutedQueryString = ""
+					frame.Fields[0].Set(0, float64(2))
+				} else {
+					_ = frame.Meta.ExecutedQueryString
+					_ = frame.Fields[0].At(0)
+				}
+			}()
+		}
+		<-pc.started
+		require.Eventually(t, func() bool { return atomic.LoadInt64(&pc.calls) == 1 }, time.Second, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		close(pc.unblock)
+		wg.Wait()
+
+		require.Equal(t, int64(1), atomic.LoadInt64(&pc.calls))
+		frame := responses[1].Responses["A"].Frames[0]
+		require.Equal(t, "up", frame.Meta.ExecutedQueryString)
+		require.Equal(t, float64(1), frame.Fields[0].At(0))
+	})
+
+	t.Run("should not coalesce different requests", func(t *testing.T) {
+		s, pc := setupConcurrency(t, setting.QueryConcurrencySettings{CoalesceRequests: true})
+		close(pc.unblock)
+		_, err := s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "up"}`))
+		require.NoError(t, err)
+		_, err = s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "down"}`))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), atomic.LoadInt64(&pc.calls))
+	})
+
+	t.Run("should reject requests if the queue is full", func(t *testing.T) {
+		s, pc := setupConcurrency(t, setting.QueryConcurrencySettings{
+			MaxConcurrentQueries: 1,
+			MaxQueuedQueries:     0,
+			QueueTimeout:         time.Second,
+		})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "up"}`))
+			require.NoError(t, err)
+		}()
+		<-pc.started
+
+		_, err := s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "down"}`))
+		require.ErrorIs(t, err, ErrQueryQueueFull)
+
+		close(pc.unblock)
+		<-done
+		_, err = s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "down"}`))
+		require.NoError(t, err)
+	})
+
+	t.Run("should reject requests that wait too long", func(t *testing.T) {
+		s, pc := setupConcurrency(t, setting.QueryConcurrencySettings{
+			MaxConcurrentQueries: 1,
+			MaxQueuedQueries:     1,
+			QueueTimeout:         50 * time.Millisecond,
+		})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "up"}`))
+			require.NoError(t, err)
+		}()
+		<-pc.started
+
+		_, err := s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "down"}`))
+		require.ErrorIs(t, err, ErrQueryQueueTimeout)
+		close(pc.unblock)
+		<-done
+	})
+
+	t.Run("should send queued requests once a request completes", func(t *testing.T) {
+		s, pc := setupConcurrency(t, setting.QueryConcurrencySettings{
+			MaxConcurrentQueries: 1,
+			MaxQueuedQueries:     1,
+			QueueTimeout:         time.Second,
+		})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "up"}`))
+			require.NoError(t, err)
+		}()
+		<-pc.started
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			close(pc.unblock)
+		}()
+		_, err := s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "down"}`))
+		require.NoError(t, err)
+		<-done
+		require.Equal(t, int64(2), atomic.LoadInt64(&pc.calls))
+	})
+
+	t.Run("should not limit data sources without a limit", func(t *testing.T) {
+		s, pc := setupConcurrency(t, setting.QueryConcurrencySettings{
+			MaxConcurrentQueries:           1,
+			QueueTimeout:                   time.Second,
+			DataSourceMaxConcurrentQueries: map[string]int{"ds1": 0},
+		})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "up"}`))
+			require.NoError(t, err)
+		}()
+		<-pc.started
+
+		go func() {
+			<-pc.started
+			close(pc.unblock)
+		}()
+		_, err := s.queryDataSource(context.Background(), signedInUser, ds, request(`{"expr": "down"}`))
+		require.NoError(t, err)
+		<-done
+	})
+}
+
+func setupConcurrency(t *testing.T, settings setting.QueryConcurrencySettings) (*Service, *blockingPluginClient) {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.QueryConcurrency = settings
+	pc := &blockingPluginClient{
+		started: make(chan struct{}, 10),
+		unblock: make(chan struct{}),
+	}
+	return &Service{
+		cfg:          cfg,
+		pluginClient: pc,
+		log:          log.New("test.logger"),
+	}, pc
+}
+
+// blockingPluginClient blocks the requests until unblock is closed.
+type blockingPluginClient struct {
+	plugins.Client
+	calls   int64
+	started chan struct{}
+	unblock chan struct{}
+}
+
+func (c *blockingPluginClient) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	atomic.AddInt64(&c.calls, 1)
+	c.started <- struct{}{}
+	<-c.unblock
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		frame := data.NewFrame("", data.NewField("value", nil, []float64{1}))
+		frame.RefID = q.RefID
+		frame.Meta = &data.FrameMeta{ExecutedQueryString: "up"}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{frame}}
+	}
+	return resp, nil
+}
@@ -10,4 +10,6 @@ var (
 	ErrMissingDataSourceInfo = errutil.NewBase(errutil.StatusBadRequest, "query.missingDataSourceInfo").MustTemplate("query missing datasource info: {{ .Public.RefId }}", errutil.WithPublic("Query {{ .Public.RefId }} is missing datasource information"))
 	ErrQueryParamMismatch    = errutil.NewBase(errutil.StatusBadRequest, "query.headerMismatch", errutil.WithPublicMessage("The request headers point to a different plugin than is defined in the request body")).Errorf("plugin header/body mismatch")
 	ErrDuplicateRefId        = errutil.NewBase(errutil.StatusBadRequest, "query.duplicateRefId", errutil.WithPublicMessage("Multiple queries using the same RefId is not allowed ")).Errorf("multiple queries using the same RefId is not allowed")
+	ErrQueryQueueFull        = errutil.NewBase(errutil.StatusTooManyRequests, "query.queueFull", errutil.WithPublicMessage("Too many queries are waiting for the data source, try again later")).Errorf("too many queries are waiting for the data source")
+	ErrQueryQueueTimeout     = errutil.NewBase(errutil.StatusTooManyRequests, "query.queueTimeout", errutil.WithPublicMessage("The query waited too long for the data source, try again later")).Errorf("timed out waiting for the data source")
 )
@@ -2,16 +2,20 @@ package query
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -37,6 +41,7 @@ func ProvideService(
 	pluginRequestValidator validations.PluginRequestValidator,
 	dataSourceService datasources.DataSourceService,
 	pluginClient plugins.Client,
+	remoteCache remotecache.CacheStorage,
 ) *Service {
 	g := &Service{
 		cfg:                    cfg,
@@ -47,6 +52,9 @@ func ProvideService(
 		pluginClient:           pluginClient,
 		log:                    log.New("query_data"),
 	}
+	if remoteCache != nil {
+		g.resultCache = NewRemoteResultCache(remoteCache)
+	}
 	g.log.Info("Query Service initialization")
 	return g
 }
@@ -58,6 +66,10 @@ type Service struct {
 	pluginRequestValidator validations.PluginRequestValidator
 	dataSourceService      datasources.DataSourceService
 	pluginClient           plugins.Client
+	resultCache            ResultCache
+	flights                singleflight.Group
+	limitersMtx            sync.Mutex
+	limiters               map[string]*concurrencyLimiter
 	log                    log.Logger
 }
 
@@ -81,7 +93,7 @@ func (s *Service) QueryData(ctx context.Context, user *user.SignedInUser, skipCa
 	}
 	// If there is only one datasource, query it and return
 	if len(parsedReq.parsedQueries) == 1 {
-		return s.handleQuerySingleDatasource(ctx, user, parsedReq)
+		return s.handleQuerySingleDatasource(ctx, user, skipCache, parsedReq)
 	}
 	// If there are multiple datasources, handle their queries concurrently and return the aggregate result
 	return s.executeConcurrentQueries(ctx, user, skipCache, reqDTO, parsedReq.parsedQueries)
@@ -122,6 +134,10 @@ func (s *Service) executeConcurrentQueries(ctx context.Context, user *user.Signe
 			defer recoveryFn(subDTO.Queries)
 
 			subResp, err := s.QueryData(ctx, user, skipCache, subDTO)
+			if errors.Is(err, ErrQueryQueueFull) || errors.Is(err, ErrQueryQueueTimeout) {
+				// The client should retry the whole request later, so it gets the status of the error.
+				return err
+			}
 			if err == nil {
 				rchan <- subResp.Responses
 			} else {
@@ -157,7 +173,8 @@ func buildErrorResponses(err error, queries []*simplejson.Json) backend.Response
 	return er
 }
 
-// handleExpressions handles POST /api/ds/query when there is an expression.
+// handleExpressions handles POST /api/ds/query when there is an expression. The queries are sent by the expression
+// service, so they are neither coalesced nor limited.
 func (s *Service) handleExpressions(ctx context.Context, user *user.SignedInUser, parsedReq *parsedRequest) (*backend.QueryDataResponse, error) {
 	exprReq := expr.Request{
 		Queries: []expr.Query{},
@@ -198,8 +215,9 @@ func (s *Service) handleExpressions(ctx context.Context, user *user.SignedInUser
 	return qdr, nil
 }
 
-// handleQuerySingleDatasource handles one or more queries to a single datasource
-func (s *Service) handleQuerySingleDatasource(ctx context.Context, user *user.SignedInUser, parsedReq *parsedRequest) (*backend.QueryDataResponse, error) {
+// handleQuerySingleDatasource handles one or more queries to a single datasource. The responses are cached if query
+// caching is enabled for the datasource.
+func (s *Service) handleQuerySingleDatasource(ctx context.Context, user *user.SignedInUser, skipCache bool, parsedReq *parsedRequest) (*backend.QueryDataResponse, error) {
 	queries := parsedReq.getFlattenedQueries()
 	ds := queries[0].datasource
 	if err := s.pluginRequestValidator.Validate(ds.URL, nil); err != nil {
@@ -233,7 +251,10 @@ func (s *Service) handleQuerySingleDatasource(ctx context.Context, user *user.Si
 		req.Queries = append(req.Queries, q.query)
 	}
 
-	return s.pluginClient.QueryData(ctx, req)
+	if ttl := s.cfg.QueryCaching.TTLForDataSource(ds.UID); ttl > 0 && s.resultCache != nil {
+		return s.queryDataWithCache(ctx, user, ds, req, skipCache, ttl)
+	}
+	return s.queryDataSource(ctx, user, ds, req)
 }
 
 // parseRequest parses a request into parsed queries grouped by datasource uid
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/stretchr/testify/assert"
@@ -401,6 +402,25 @@ func TestQueryDataMultipleSources(t *testing.T) {
 		require.NotContains(t, res.Responses, "A")
 	})
 
+	t.Run("error is returned when the queue of one of the data sources is full", func(t *testing.T) {
+		tc := setup(t)
+		tc.queryService.cfg.QueryConcurrency = setting.QueryConcurrencySettings{
+			DataSourceMaxConcurrentQueries: map[string]int{"ds2": 1},
+			QueueTimeout:                   time.Second,
+		}
+		// The only request that can be sent to ds2 is in flight.
+		limiter := tc.queryService.limiter(&datasources.DataSource{UID: "ds2"})
+		require.NoError(t, limiter.acquire(context.Background(), 0, time.Second, "mysql"))
+		defer limiter.release()
+
+		reqDTO := metricRequestWithQueries(t,
+			`{"datasource": {"type": "mysql", "uid": "ds1"}, "refId": "A"}`,
+			`{"datasource": {"type": "mysql", "uid": "ds2"}, "refId": "B"}`,
+		)
+		_, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, true, reqDTO)
+		require.ErrorIs(t, err, ErrQueryQueueFull)
+	})
+
 	t.Run("ignores a deprecated datasourceID", func(t *testing.T) {
 		tc := setup(t)
 		query1, err := simplejson.NewJson([]byte(`
@@ -447,7 +467,7 @@ func setup(t *testing.T) *testContext {
 		SimulatePluginFailure: false,
 	}
 	exprService := expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, pc, fakeDatasourceService)
-	queryService := ProvideService(setting.NewCfg(), dc, exprService, rv, ds, pc) // provider belonging to this package
+	queryService := ProvideService(setting.NewCfg(), dc, exprService, rv, ds, pc, nil) // provider belonging to this package
 	return &testContext{
 		pluginContext:          pc,
 		secretStore:            ss,
@@ -466,6 +466,15 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveDurableOutputPath is the directory of the log of the durable frame output.
+	LiveDurableOutputPath string
+	// LiveDurableOutputSegmentSize is the size in bytes above which the log of a channel starts a new segment.
+	LiveDurableOutputSegmentSize int64
+	// LiveDurableOutputMaxChannelSize is the size in bytes above which the oldest segments of a channel are removed.
+	// 0 means unlimited.
+	LiveDurableOutputMaxChannelSize int64
+	// LiveDurableOutputRetention is how long segments are kept after their last write. 0 means forever.
+	LiveDurableOutputRetention time.Duration
 
 	// Github OAuth
 	GithubSkipOrgRoleSync bool
@@ -497,6 +506,10 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	QueryCaching QueryCachingSettings
+
+	QueryConcurrency QueryConcurrencySettings
+
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
 	// SAML Auth
@@ -1114,6 +1127,12 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	cfg.DashboardPreviews = readDashboardPreviewsSettings(iniFile)
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	if cfg.QueryCaching, err = readQueryCachingSettings(iniFile); err != nil {
+		return err
+	}
+	if cfg.QueryConcurrency, err = readQueryConcurrencySettings(iniFile); err != nil {
+		return err
+	}
 
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
 	if err != nil {
@@ -1871,5 +1890,21 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		return err
 	}
 	cfg.LiveAllowedOrigins = originPatterns
+
+	cfg.LiveDurableOutputPath = makeAbsolute(section.Key("durable_output_path").MustString(filepath.Join(cfg.DataPath, "live", "framelog")), HomePath)
+	segmentSizeMB := section.Key("durable_output_segment_size_mb").MustInt64(16)
+	if segmentSizeMB <= 0 {
+		return fmt.Errorf("unexpected value %d for [live] durable_output_segment_size_mb", segmentSizeMB)
+	}
+	cfg.LiveDurableOutputSegmentSize = segmentSizeMB * 1024 * 1024
+	maxChannelSizeMB := section.Key("durable_output_max_channel_size_mb").MustInt64(256)
+	if maxChannelSizeMB < 0 {
+		return fmt.Errorf("unexpected value %d for [live] durable_output_max_channel_size_mb", maxChannelSizeMB)
+	}
+	cfg.LiveDurableOutputMaxChannelSize = maxChannelSizeMB * 1024 * 1024
+	cfg.LiveDurableOutputRetention = section.Key("durable_output_retention").MustDuration(24 * time.Hour)
+	if cfg.LiveDurableOutputRetention < 0 {
+		return fmt.Errorf("unexpected value %s for [live] durable_output_retention", cfg.LiveDurableOutputRetention)
+	}
 	return nil
 }
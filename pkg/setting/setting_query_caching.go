@@ -0,0 +1,57 @@
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type QueryCachingSettings struct {
+	Enabled bool
+	// TTL is how long the responses of queries are cached.
+	TTL time.Duration
+	// TimeRangeAlignment is the duration to which the time ranges of queries are aligned in the keys of the cache,
+	// so that queries whose time ranges are in the same interval share the cached response.
+	TimeRangeAlignment time.Duration
+	// DataSourceTTLs overrides TTL for the data sources with the UIDs. A TTL of zero disables caching.
+	DataSourceTTLs map[string]time.Duration
+}
+
+// TTLForDataSource returns how long the responses of the data source are cached. It returns 0 if they are not cached.
+func (s QueryCachingSettings) TTLForDataSource(uid string) time.Duration {
+	if !s.Enabled {
+		return 0
+	}
+	if ttl, ok := s.DataSourceTTLs[uid]; ok {
+		return ttl
+	}
+	return s.TTL
+}
+
+func readQueryCachingSettings(iniFile *ini.File) (QueryCachingSettings, error) {
+	s := QueryCachingSettings{
+		DataSourceTTLs: make(map[string]time.Duration),
+	}
+
+	section := iniFile.Section("query_caching")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.TTL = section.Key("ttl").MustDuration(time.Minute)
+	if s.TTL <= 0 {
+		return s, errors.New("value of setting 'ttl' in section 'query_caching' must be positive")
+	}
+	s.TimeRangeAlignment = section.Key("time_range_alignment").MustDuration(10 * time.Second)
+	if s.TimeRangeAlignment < 0 {
+		return s, errors.New("value of setting 'time_range_alignment' in section 'query_caching' cannot be negative")
+	}
+
+	for _, key := range iniFile.Section("query_caching.datasource_ttls").Keys() {
+		ttl, err := key.Duration()
+		if err != nil || ttl < 0 {
+			return s, fmt.Errorf("invalid TTL '%s' of data source '%s' in section 'query_caching.datasource_ttls'", key.Value(), key.Name())
+		}
+		s.DataSourceTTLs[key.Name()] = ttl
+	}
+	return s, nil
+}
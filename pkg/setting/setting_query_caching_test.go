@@ -0,0 +1,54 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadQueryCachingSettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		s, err := readQueryCachingSettings(ini.Empty())
+		require.NoError(t, err)
+		require.False(t, s.Enabled)
+		require.Equal(t, time.Minute, s.TTL)
+		require.Equal(t, 10*time.Second, s.TimeRangeAlignment)
+		require.Empty(t, s.DataSourceTTLs)
+	})
+
+	t.Run("data source TTLs", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[query_caching]
+enabled = true
+ttl = 5m
+
+[query_caching.datasource_ttls]
+ds1 = 1h
+ds2 = 0
+`))
+		require.NoError(t, err)
+		s, err := readQueryCachingSettings(f)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Minute, s.TTLForDataSource("ds"))
+		require.Equal(t, time.Hour, s.TTLForDataSource("ds1"))
+		require.Equal(t, time.Duration(0), s.TTLForDataSource("ds2"))
+
+		s.Enabled = false
+		require.Equal(t, time.Duration(0), s.TTLForDataSource("ds1"))
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		for _, conf := range []string{
+			"[query_caching]\nttl = 0s",
+			"[query_caching]\ntime_range_alignment = -1s",
+			"[query_caching.datasource_ttls]\nds1 = soon",
+		} {
+			f, err := ini.Load([]byte(conf))
+			require.NoError(t, err)
+			_, err = readQueryCachingSettings(f)
+			require.Error(t, err, conf)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type QueryConcurrencySettings struct {
+	// CoalesceRequests makes identical requests that are in flight at the same time share one request to the data
+	// source.
+	CoalesceRequests bool
+	// MaxConcurrentQueries is the number of requests that can be sent to a data source at the same time. Zero means
+	// unlimited.
+	MaxConcurrentQueries int
+	// MaxQueuedQueries is the number of requests that can wait for a data source. Requests above it are rejected.
+	MaxQueuedQueries int
+	// QueueTimeout is how long requests wait for a data source before they are rejected.
+	QueueTimeout time.Duration
+	// DataSourceMaxConcurrentQueries overrides MaxConcurrentQueries for the data sources with the UIDs.
+	DataSourceMaxConcurrentQueries map[string]int
+}
+
+// MaxConcurrentQueriesForDataSource returns the number of requests that can be sent to the data source at the same
+// time. It returns 0 if the number is unlimited.
+func (s QueryConcurrencySettings) MaxConcurrentQueriesForDataSource(uid string) int {
+	if limit, ok := s.DataSourceMaxConcurrentQueries[uid]; ok {
+		return limit
+	}
+	return s.MaxConcurrentQueries
+}
+
+func readQueryConcurrencySettings(iniFile *ini.File) (QueryConcurrencySettings, error) {
+	s := QueryConcurrencySettings{
+		DataSourceMaxConcurrentQueries: make(map[string]int),
+	}
+
+	section := iniFile.Section("query_concurrency")
+	s.CoalesceRequests = section.Key("coalesce_requests").MustBool(true)
+	s.MaxConcurrentQueries = section.Key("max_concurrent_queries_per_datasource").MustInt(0)
+	if s.MaxConcurrentQueries < 0 {
+		return s, errors.New("value of setting 'max_concurrent_queries_per_datasource' in section 'query_concurrency' cannot be negative")
+	}
+	s.MaxQueuedQueries = section.Key("max_queued_queries_per_datasource").MustInt(100)
+	if s.MaxQueuedQueries < 0 {
+		return s, errors.New("value of setting 'max_queued_queries_per_datasource' in section 'query_concurrency' cannot be negative")
+	}
+	s.QueueTimeout = section.Key("queue_timeout").MustDuration(30 * time.Second)
+	if s.QueueTimeout <= 0 {
+		return s, errors.New("value of setting 'queue_timeout' in section 'query_concurrency' must be positive")
+	}
+
+	for _, key := range iniFile.Section("query_concurrency.datasource_max_concurrent_queries").Keys() {
+		limit, err := key.Int()
+		if err != nil || limit < 0 {
+			return s, fmt.Errorf("invalid limit '%s' of data source '%s' in section 'query_concurrency.datasource_max_concurrent_queries'", key.Value(), key.Name())
+		}
+		s.DataSourceMaxConcurrentQueries[key.Name()] = limit
+	}
+	return s, nil
+}
@@ -0,0 +1,52 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadQueryConcurrencySettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		s, err := readQueryConcurrencySettings(ini.Empty())
+		require.NoError(t, err)
+		require.True(t, s.CoalesceRequests)
+		require.Equal(t, 0, s.MaxConcurrentQueries)
+		require.Equal(t, 100, s.MaxQueuedQueries)
+		require.Equal(t, 30*time.Second, s.QueueTimeout)
+		require.Equal(t, 0, s.MaxConcurrentQueriesForDataSource("ds1"))
+	})
+
+	t.Run("data source limits", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[query_concurrency]
+max_concurrent_queries_per_datasource = 10
+
+[query_concurrency.datasource_max_concurrent_queries]
+ds1 = 2
+ds2 = 0
+`))
+		require.NoError(t, err)
+		s, err := readQueryConcurrencySettings(f)
+		require.NoError(t, err)
+		require.Equal(t, 10, s.MaxConcurrentQueriesForDataSource("ds"))
+		require.Equal(t, 2, s.MaxConcurrentQueriesForDataSource("ds1"))
+		require.Equal(t, 0, s.MaxConcurrentQueriesForDataSource("ds2"))
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		for _, conf := range []string{
+			"[query_concurrency]\nmax_concurrent_queries_per_datasource = -1",
+			"[query_concurrency]\nmax_queued_queries_per_datasource = -1",
+			"[query_concurrency]\nqueue_timeout = 0s",
+			"[query_concurrency.datasource_max_concurrent_queries]\nds1 = many",
+		} {
+			f, err := ini.Load([]byte(conf))
+			require.NoError(t, err)
+			_, err = readQueryConcurrencySettings(f)
+			require.Error(t, err, conf)
+		}
+	})
+}
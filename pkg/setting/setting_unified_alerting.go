@@ -3,6 +3,8 @@ package setting
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -20,6 +22,8 @@ const (
 	alertmanagerDefaultGossipInterval     = cluster.DefaultGossipInterval
 	alertmanagerDefaultPushPullInterval   = cluster.DefaultPushPullInterval
 	alertmanagerDefaultConfigPollInterval = time.Minute
+	haDefaultHeartbeatInterval            = 15 * time.Second
+	haDefaultHeartbeatTimeout             = time.Minute
 	// To start, the alertmanager needs at least one route defined.
 	// TODO: we should move this to Grafana settings and define this as the default.
 	alertmanagerDefaultConfiguration = `{
@@ -53,13 +57,18 @@ const (
 	screenshotsMaxCaptureTimeout            = 30 * time.Second
 	screenshotsDefaultMaxConcurrent         = 5
 	screenshotsDefaultUploadImageStorage    = false
+	screenshotsDefaultCaptureOnResolve      = true
+	screenshotsDefaultImageRetention        = 24 * time.Hour
 	// SchedulerBaseInterval base interval of the scheduler. Controls how often the scheduler fetches database for new changes as well as schedules evaluation of a rule
 	// changing this value is discouraged because this could cause existing alert definition
 	// with intervals that are not exactly divided by this number not to be evaluated
 	SchedulerBaseInterval = 10 * time.Second
 	// DefaultRuleEvaluationInterval indicates a default interval of for how long a rule should be evaluated to change state from Pending to Alerting
-	DefaultRuleEvaluationInterval = SchedulerBaseInterval * 6 // == 60 seconds
-	stateHistoryDefaultEnabled    = true
+	DefaultRuleEvaluationInterval      = SchedulerBaseInterval * 6 // == 60 seconds
+	stateHistoryDefaultEnabled         = true
+	stateHistoryDefaultSQLRetention    = 30 * 24 * time.Hour
+	recordingRulesDefaultTimeout       = 10 * time.Second
+	stateMetricsDefaultMaxSeriesPerOrg = 1000
 )
 
 type UnifiedAlertingSettings struct {
@@ -71,9 +80,13 @@ type UnifiedAlertingSettings struct {
 	HAPeerTimeout                  time.Duration
 	HAGossipInterval               time.Duration
 	HAPushPullInterval             time.Duration
+	HAShardingEnabled              bool
+	HAHeartbeatInterval            time.Duration
+	HAHeartbeatTimeout             time.Duration
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
+	MaxSeriesPerQuery              int64
 	ExecuteAlerts                  bool
 	DefaultConfiguration           string
 	Enabled                        *bool // determines whether unified alerting is enabled. If it is nil then user did not define it and therefore its value will be determined during migration. Services should not use it directly.
@@ -86,13 +99,37 @@ type UnifiedAlertingSettings struct {
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	StateHistory                  UnifiedAlertingStateHistorySettings
+	RecordingRules                RecordingRuleSettings
+	StateMetrics                  UnifiedAlertingStateMetricsSettings
+	LocalWebhook                  UnifiedAlertingLocalWebhookSettings
 }
 
+const (
+	// ScreenshotsStorageDisk stores the files of screenshots only on the local disk.
+	ScreenshotsStorageDisk = "disk"
+	// ScreenshotsStorageDatabase also stores the files of screenshots in the database.
+	ScreenshotsStorageDatabase = "database"
+	// ScreenshotsStorageBlob also stores the files of screenshots in a directory of a file system, usually a network file
+	// system shared by all instances.
+	ScreenshotsStorageBlob = "blob"
+)
+
 type UnifiedAlertingScreenshotSettings struct {
 	Capture                    bool
 	CaptureTimeout             time.Duration
 	MaxConcurrentScreenshots   int64
 	UploadExternalImageStorage bool
+	// CaptureOnResolve controls whether a screenshot is taken when an alert is resolved.
+	CaptureOnResolve bool
+	// RepeatCaptureInterval is the age of the screenshot of a firing alert after which a new screenshot is taken.
+	// Zero disables it.
+	RepeatCaptureInterval time.Duration
+	// ImageRetention is how long images are kept once the alerts that use them are resolved.
+	ImageRetention time.Duration
+	// Storage is where the files of screenshots are stored in addition to the local disk.
+	Storage string
+	// BlobURL is the file:// URL of the directory when Storage is ScreenshotsStorageBlob.
+	BlobURL string
 }
 
 type UnifiedAlertingReservedLabelSettings struct {
@@ -111,6 +148,33 @@ type UnifiedAlertingStateHistorySettings struct {
 	LokiBasicAuthPassword string
 	LokiBasicAuthUsername string
 	ExternalLabels        map[string]string
+	// SQLRetentionPeriod is how long state history is kept in the database when the sql backend is used.
+	SQLRetentionPeriod time.Duration
+}
+
+// RecordingRuleSettings configures evaluation of recording rules and the default target their results are written to.
+type RecordingRuleSettings struct {
+	Enabled bool
+	// URL is the Prometheus remote write endpoint used for recording rules that do not specify a target data source.
+	URL string
+	// BasicAuthUsername and BasicAuthPassword are used for basic auth
+	// if one of them is set.
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
+}
+
+// UnifiedAlertingStateMetricsSettings configures the export of the alerts of Grafana-managed rules as metrics.
+type UnifiedAlertingStateMetricsSettings struct {
+	Enabled bool
+	// MaxSeriesPerOrg limits the number of series of the ALERTS metric of every organization. 0 means no limit.
+	MaxSeriesPerOrg int
+}
+
+// UnifiedAlertingLocalWebhookSettings configures the contact points that write notifications to local files and Unix sockets.
+type UnifiedAlertingLocalWebhookSettings struct {
+	// AllowedDirectories are the directories of the files and sockets. The contact points are disabled if it is empty.
+	AllowedDirectories []string
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -220,6 +284,21 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	if err != nil {
 		return err
 	}
+	uaCfg.HAShardingEnabled = ua.Key("ha_sharding_enabled").MustBool(false)
+	uaCfg.HAHeartbeatInterval, err = gtime.ParseDuration(valueAsString(ua, "ha_heartbeat_interval", (haDefaultHeartbeatInterval).String()))
+	if err != nil {
+		return err
+	}
+	uaCfg.HAHeartbeatTimeout, err = gtime.ParseDuration(valueAsString(ua, "ha_heartbeat_timeout", (haDefaultHeartbeatTimeout).String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.HAHeartbeatInterval <= 0 {
+		return fmt.Errorf("value of setting 'ha_heartbeat_interval' must be greater than 0")
+	}
+	if uaCfg.HAHeartbeatTimeout <= uaCfg.HAHeartbeatInterval {
+		return fmt.Errorf("value of setting 'ha_heartbeat_timeout' must be greater than 'ha_heartbeat_interval'")
+	}
 	uaCfg.HAListenAddr = ua.Key("ha_listen_address").MustString(alertmanagerDefaultClusterAddr)
 	uaCfg.HAAdvertiseAddr = ua.Key("ha_advertise_address").MustString("")
 	peers := ua.Key("ha_peers").MustString("")
@@ -257,6 +336,11 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.EvaluationTimeout = uaEvaluationTimeout
 
+	uaCfg.MaxSeriesPerQuery = ua.Key("max_series_per_query").MustInt64(0)
+	if uaCfg.MaxSeriesPerQuery < 0 {
+		return errors.New("value of setting 'max_series_per_query' cannot be negative")
+	}
+
 	uaMaxAttempts := ua.Key("max_attempts").MustInt64(schedulerDefaultMaxAttempts)
 	if uaMaxAttempts == schedulerDefaultMaxAttempts { // unified option or equals the default
 		legacyMaxAttempts := alerting.Key("max_attempts").MustInt64(schedulerDefaultMaxAttempts)
@@ -308,6 +392,34 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 
 	uaCfgScreenshots.MaxConcurrentScreenshots = screenshots.Key("max_concurrent_screenshots").MustInt64(screenshotsDefaultMaxConcurrent)
 	uaCfgScreenshots.UploadExternalImageStorage = screenshots.Key("upload_external_image_storage").MustBool(screenshotsDefaultUploadImageStorage)
+	uaCfgScreenshots.CaptureOnResolve = screenshots.Key("capture_on_resolve").MustBool(screenshotsDefaultCaptureOnResolve)
+
+	uaCfgScreenshots.RepeatCaptureInterval = screenshots.Key("repeat_capture_interval").MustDuration(0)
+	if uaCfgScreenshots.RepeatCaptureInterval < 0 {
+		return errors.New("value of setting 'repeat_capture_interval' cannot be negative")
+	}
+
+	uaCfgScreenshots.ImageRetention = screenshots.Key("image_retention").MustDuration(screenshotsDefaultImageRetention)
+	if uaCfgScreenshots.ImageRetention <= 0 {
+		return errors.New("value of setting 'image_retention' must be positive")
+	}
+
+	uaCfgScreenshots.Storage = screenshots.Key("storage").MustString(ScreenshotsStorageDisk)
+	uaCfgScreenshots.BlobURL = screenshots.Key("blob_url").MustString("")
+	switch uaCfgScreenshots.Storage {
+	case ScreenshotsStorageDisk, ScreenshotsStorageDatabase:
+	case ScreenshotsStorageBlob:
+		if uaCfgScreenshots.BlobURL == "" {
+			return fmt.Errorf("setting 'blob_url' is required when setting 'storage' is '%s'", ScreenshotsStorageBlob)
+		}
+		// Only the drivers of file systems are registered, object storage services are not supported.
+		if u, err := url.Parse(uaCfgScreenshots.BlobURL); err != nil || u.Scheme != "file" {
+			return fmt.Errorf("setting 'blob_url' must be a file:// URL of a directory, got '%s': object storage services such as Amazon S3, Google Cloud Storage or Azure Blob Storage are not supported", uaCfgScreenshots.BlobURL)
+		}
+	default:
+		return fmt.Errorf("invalid value '%s' of setting 'storage', must be one of '%s', '%s' or '%s'", uaCfgScreenshots.Storage,
+			ScreenshotsStorageDisk, ScreenshotsStorageDatabase, ScreenshotsStorageBlob)
+	}
 	uaCfg.Screenshots = uaCfgScreenshots
 
 	reservedLabels := iniFile.Section("unified_alerting.reserved_labels")
@@ -332,8 +444,42 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		LokiBasicAuthPassword: stateHistory.Key("loki_basic_auth_password").MustString(""),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
 	}
+	uaCfgStateHistory.SQLRetentionPeriod, err = gtime.ParseDuration(valueAsString(stateHistory, "sql_retention_period", stateHistoryDefaultSQLRetention.String()))
+	if err != nil {
+		return err
+	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
+	recordingRules := iniFile.Section("unified_alerting.recording_rules")
+	uaCfgRecordingRules := RecordingRuleSettings{
+		Enabled:           recordingRules.Key("enabled").MustBool(false),
+		URL:               recordingRules.Key("url").MustString(""),
+		BasicAuthUsername: recordingRules.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: recordingRules.Key("basic_auth_password").MustString(""),
+		Timeout:           recordingRules.Key("timeout").MustDuration(recordingRulesDefaultTimeout),
+	}
+	uaCfg.RecordingRules = uaCfgRecordingRules
+
+	stateMetrics := iniFile.Section("unified_alerting.state_metrics")
+	uaCfgStateMetrics := UnifiedAlertingStateMetricsSettings{
+		Enabled:         stateMetrics.Key("enabled").MustBool(false),
+		MaxSeriesPerOrg: stateMetrics.Key("max_series_per_org").MustInt(stateMetricsDefaultMaxSeriesPerOrg),
+	}
+	if uaCfgStateMetrics.MaxSeriesPerOrg < 0 {
+		return errors.New("value of setting 'max_series_per_org' in section 'unified_alerting.state_metrics' cannot be negative")
+	}
+	uaCfg.StateMetrics = uaCfgStateMetrics
+
+	localWebhook := iniFile.Section("unified_alerting.local_webhook")
+	uaCfg.LocalWebhook = UnifiedAlertingLocalWebhookSettings{
+		AllowedDirectories: util.SplitString(localWebhook.Key("allowed_directories").MustString("")),
+	}
+	for _, dir := range uaCfg.LocalWebhook.AllowedDirectories {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("directory '%s' of setting 'allowed_directories' in section 'unified_alerting.local_webhook' must be absolute", dir)
+		}
+	}
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
@@ -266,3 +266,37 @@ func TestMinInterval(t *testing.T) {
 		})
 	}
 }
+
+func TestScreenshotsBlobStorage(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		blobURL string
+		err     bool
+	}{
+		{desc: "accepts a directory", blobURL: "file:///mnt/shared/grafana/alerting-images"},
+		{desc: "rejects a missing URL", blobURL: "", err: true},
+		{desc: "rejects a cloud storage service", blobURL: "s3://alerting-images?region=us-west-1", err: true},
+		{desc: "rejects a path without scheme", blobURL: "/mnt/shared/grafana/alerting-images", err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := ini.Empty()
+			cfg := NewCfg()
+			cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+			s, err := f.NewSection("unified_alerting.screenshots")
+			require.NoError(t, err)
+			_, err = s.NewKey("storage", ScreenshotsStorageBlob)
+			require.NoError(t, err)
+			_, err = s.NewKey("blob_url", tc.blobURL)
+			require.NoError(t, err)
+
+			err = cfg.ReadUnifiedAlertingSettings(f)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.blobURL, cfg.UnifiedAlerting.Screenshots.BlobURL)
+		})
+	}
+}
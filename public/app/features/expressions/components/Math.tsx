@@ -91,6 +91,34 @@ export const Math: FC<Props> = ({ labelWidth, onChange, query, onRunQuery }) =>
                       name="floor"
                       description="rounds the number down to the nearest integer value. It's able to operate on series or escalar values."
                     />
+                    <DocumentedFunction
+                      name="sqrt, exp, and pow"
+                      description="return the square root, e raised to the power of the value, or the value raised to the power of a number. For example pow($A, 2). They are able to operate on series or scalar values."
+                    />
+                    <DocumentedFunction
+                      name="clamp_min and clamp_max"
+                      description="limit values to be no lower or no higher than a number. For example clamp_min($A, 0). They are able to operate on series or scalar values."
+                    />
+                    <DocumentedFunction
+                      name="delta, increase, and rate"
+                      description="return the difference, the counter increase, or the per-second counter rate between each point of a series and the point before it."
+                    />
+                    <DocumentedFunction
+                      name="moving_avg and stddev_over_window"
+                      description="return the average or the standard deviation of the points within a window ending at each point of a series. For example moving_avg($A, &quot;5m&quot;)."
+                    />
+                    <DocumentedFunction
+                      name="ewma"
+                      description="returns the exponentially weighted moving average of a series with a smoothing factor in the range (0, 1]. For example ewma($A, 0.3)."
+                    />
+                    <DocumentedFunction
+                      name="shift"
+                      description="moves the points of a series forward in time by a duration. For example shift($A, &quot;1d&quot;)."
+                    />
+                    <DocumentedFunction
+                      name="percentile"
+                      description="reduces a series to the percentile (0-100) of its values. For example percentile($A, 95)."
+                    />
                   </div>
                   <div>
                     See our additional documentation on{' '}
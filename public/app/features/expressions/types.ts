@@ -48,6 +48,23 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
   { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: ReducerID.first, label: 'First', description: 'Get the first value' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of the values' },
+  { value: 'p95', label: '95th percentile', description: 'Get the 95th percentile of the values' },
+  { value: 'p99', label: '99th percentile', description: 'Get the 99th percentile of the values' },
+  { value: ReducerID.diff, label: 'Difference', description: 'Get the difference between the last and the first value' },
+  {
+    value: 'diff_abs',
+    label: 'Difference (absolute)',
+    description: 'Get the absolute difference between the last and the first value',
+  },
+  {
+    value: 'percent_diff',
+    label: 'Difference (percent)',
+    description: 'Get the difference between the last and the first value in percent of the first value',
+  },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of non-null values' },
 ];
 
 export enum ReducerMode {
@@ -120,6 +137,10 @@ export interface ClassicCondition {
     params: number[];
     type: EvalFunction;
   };
+  unloadEvaluator?: {
+    params: number[];
+    type: EvalFunction;
+  };
   operator?: {
     type: string;
   };
@@ -66,13 +66,48 @@ export interface KeepFieldsFrameProcessorConfig {
 export interface DropFieldsFrameProcessorConfig {
   fieldNames: string[];
 }
+export interface RenameFieldsFrameProcessorConfig {
+  fieldNames?: { [key: string]: string };
+  labelNames?: { [key: string]: string };
+  labels?: { [key: string]: string };
+}
+export interface ExpressionFrameProcessorConfig {
+  fieldName: string;
+  expression: string;
+}
+export interface UnitConversion {
+  fieldName: string;
+  from?: string;
+  to: string;
+}
+export interface ConvertUnitsFrameProcessorConfig {
+  conversions: UnitConversion[];
+}
+export interface DownsampleFrameProcessorConfig {
+  intervalMilliseconds: number;
+  aggregation?: string;
+  fieldAggregations?: { [key: string]: string };
+}
 export interface FrameProcessorConfig {
   type: Omit<keyof FrameProcessorConfig, 'type'>;
   dropFields?: DropFieldsFrameProcessorConfig;
   keepFields?: KeepFieldsFrameProcessorConfig;
   multiple?: MultipleFrameProcessorConfig;
+  renameFields?: RenameFieldsFrameProcessorConfig;
+  expression?: ExpressionFrameProcessorConfig;
+  convertUnits?: ConvertUnitsFrameProcessorConfig;
+  downsample?: DownsampleFrameProcessorConfig;
 }
 export interface JsonFrameConverterConfig {}
+export interface PrometheusConverterConfig {
+  openMetrics?: boolean;
+}
+export interface CSVConverterConfig {
+  delimiter?: string;
+  header?: string[];
+  timeField?: string;
+  labelFields?: string[];
+}
 export interface AutoInfluxConverterConfig {
   frameFormat: string;
 }
@@ -99,6 +134,8 @@ export interface ConverterConfig {
   jsonExact?: ExactJsonConverterConfig;
   influxAuto?: AutoInfluxConverterConfig;
   jsonFrame?: JsonFrameConverterConfig;
+  prometheus?: PrometheusConverterConfig;
+  csv?: CSVConverterConfig;
 }
 export interface LokiOutputConfig {
   uid: string;
@@ -114,9 +151,13 @@ export interface DataOutputterConfig {
 export interface MultipleSubscriberConfig {
   subscribers: SubscriberConfig[];
 }
+export interface DurableSubscriberConfig {
+  maxFrames?: number;
+}
 export interface SubscriberConfig {
   type: Omit<keyof SubscriberConfig, 'type'>;
   multiple?: MultipleSubscriberConfig;
+  durable?: DurableSubscriberConfig;
 }
 export interface ChannelRuleSettings {
   auth?: ChannelAuthConfig;